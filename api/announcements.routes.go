@@ -0,0 +1,184 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/zenazn/goji/web"
+
+	"github.com/YagoCarballo/kumquat-academy-api/tools"
+	"github.com/YagoCarballo/kumquat-academy-api/api/middlewares"
+	"github.com/YagoCarballo/kumquat-academy-api/api/endpoints"
+	"github.com/YagoCarballo/kumquat-academy-api/database/models"
+
+	. "github.com/YagoCarballo/kumquat-academy-api/constants"
+)
+
+func (api *API) LoadAnnouncementsEndpoints() {
+	api.routes.Put("/module/:moduleCode/announcement", middlewares.Restricted(middlewares.Access{ Resource: ModuleResource, Param: "moduleCode", Right: WritePermission }, func(c web.C, w http.ResponseWriter, r *http.Request) {
+		var cookieData *tools.JWTSession = c.Env["token"].(*tools.JWTSession)
+		moduleCode := c.URLParams["moduleCode"]
+
+		// Parse the JSON Body
+		var announcement models.Announcement
+		status, errMessage := tools.ParseBody(r.Body, &announcement)
+		if status != http.StatusOK {
+			api.renderer.JSON(w, status, errMessage); return
+		}
+
+		// Process the action and Give the response
+		status, message := endpoints.CreateModuleAnnouncement(cookieData.UserId, moduleCode, announcement)
+		api.renderer.JSON(w, status, message)
+	}, api.privateKey, api.publicKey))
+
+	api.routes.Get("/module/:moduleCode/announcements", middlewares.Restricted(middlewares.Access{ Resource: ModuleResource, Param: "moduleCode", Right: ReadPermission }, func(c web.C, w http.ResponseWriter, r *http.Request) {
+		var cookieData *tools.JWTSession = c.Env["token"].(*tools.JWTSession)
+		moduleCode := c.URLParams["moduleCode"]
+
+		// Process the action and Give the response
+		status, message := endpoints.FindAnnouncementsForModule(moduleCode, cookieData.UserId)
+		api.renderer.JSON(w, status, message)
+	}, api.privateKey, api.publicKey))
+
+	api.routes.Put("/course/:courseId/announcement", middlewares.Restricted(middlewares.Access{ Resource: CourseResource, Param: "courseId", Right: WritePermission }, func(c web.C, w http.ResponseWriter, r *http.Request) {
+		// Get and Parse the parameters
+		var cookieData *tools.JWTSession = c.Env["token"].(*tools.JWTSession)
+		courseId, status, err := tools.ParseID(c.URLParams["courseId"])
+		if status != http.StatusOK {
+			api.renderer.JSON(w, status, err); return
+		}
+
+		// Parse the JSON Body
+		var announcement models.Announcement
+		status, errMessage := tools.ParseBody(r.Body, &announcement)
+		if status != http.StatusOK {
+			api.renderer.JSON(w, status, errMessage); return
+		}
+
+		// Process the action and Give the response
+		status, message := endpoints.CreateCourseAnnouncement(cookieData.UserId, courseId, announcement)
+		api.renderer.JSON(w, status, message)
+	}, api.privateKey, api.publicKey))
+
+	api.routes.Get("/course/:courseId/announcements", middlewares.Restricted(middlewares.Access{ Resource: CourseResource, Param: "courseId", Right: ReadPermission }, func(c web.C, w http.ResponseWriter, r *http.Request) {
+		// Get and Parse the parameters
+		var cookieData *tools.JWTSession = c.Env["token"].(*tools.JWTSession)
+		courseId, status, err := tools.ParseID(c.URLParams["courseId"])
+		if status != http.StatusOK {
+			api.renderer.JSON(w, status, err); return
+		}
+
+		// Process the action and Give the response
+		status, message := endpoints.FindAnnouncementsForCourse(courseId, cookieData.UserId)
+		api.renderer.JSON(w, status, message)
+	}, api.privateKey, api.publicKey))
+
+	// Creates the GET -> /announcements endpoint
+	// This endpoint aggregates the announcements of all the modules of the user
+	api.routes.Get("/announcements", middlewares.CheckSession(func(c web.C, w http.ResponseWriter, r *http.Request) {
+		var cookieData *tools.JWTSession = c.Env["token"].(*tools.JWTSession)
+
+		params := r.URL.Query()
+		page, status, _ := tools.ParseID(params.Get("page"))
+		if status != http.StatusOK {
+			page = 0
+		}
+
+		// Process the action and Give the response
+		status, message := endpoints.FindAnnouncementsForUser(cookieData.Username, cookieData.UserId, int(page))
+		api.renderer.JSON(w, status, message)
+	}, api.privateKey, api.publicKey))
+
+	api.routes.Get("/announcement/:announcementId", middlewares.Restricted(middlewares.Access{ Resource: AnnouncementResource, Param: "announcementId", Right: ReadPermission }, func(c web.C, w http.ResponseWriter, r *http.Request) {
+		// Get and Parse the parameters
+		var cookieData *tools.JWTSession = c.Env["token"].(*tools.JWTSession)
+		announcementId, status, err := tools.ParseID(c.URLParams["announcementId"])
+		if status != http.StatusOK {
+			api.renderer.JSON(w, status, err); return
+		}
+
+		// Does the user have enough access rights?
+		if !models.DBAnnouncements.IsAnnouncementForUser(cookieData.Username, announcementId) {
+		}
+
+		// Process the action and Give the response
+		status, message := endpoints.GetAnnouncement(announcementId)
+		api.renderer.JSON(w, status, message)
+	}, api.privateKey, api.publicKey))
+
+	api.routes.Delete("/announcement/:announcementId", middlewares.Restricted(middlewares.Access{ Resource: AnnouncementResource, Param: "announcementId", Right: DeletePermission }, func(c web.C, w http.ResponseWriter, r *http.Request) {
+		// Get and Parse the parameters
+		announcementId, status, err := tools.ParseID(c.URLParams["announcementId"])
+		if status != http.StatusOK {
+			api.renderer.JSON(w, status, err); return
+		}
+
+		// Process the action and Give the response
+		status, message := endpoints.DeleteAnnouncement(announcementId)
+		api.renderer.JSON(w, status, message)
+	}, api.privateKey, api.publicKey))
+
+	api.routes.Put("/announcement/:announcementId/read", middlewares.CheckSession(func(c web.C, w http.ResponseWriter, r *http.Request) {
+		// Get and Parse the parameters
+		var cookieData *tools.JWTSession = c.Env["token"].(*tools.JWTSession)
+		announcementId, status, err := tools.ParseID(c.URLParams["announcementId"])
+		if status != http.StatusOK {
+			api.renderer.JSON(w, status, err); return
+		}
+
+		// Process the action and Give the response
+		status, message := endpoints.SetAnnouncementRead(cookieData.Username, cookieData.UserId, announcementId, true)
+		api.renderer.JSON(w, status, message)
+	}, api.privateKey, api.publicKey))
+
+	api.routes.Delete("/announcement/:announcementId/read", middlewares.CheckSession(func(c web.C, w http.ResponseWriter, r *http.Request) {
+		// Get and Parse the parameters
+		var cookieData *tools.JWTSession = c.Env["token"].(*tools.JWTSession)
+		announcementId, status, err := tools.ParseID(c.URLParams["announcementId"])
+		if status != http.StatusOK {
+			api.renderer.JSON(w, status, err); return
+		}
+
+		// Process the action and Give the response
+		status, message := endpoints.SetAnnouncementRead(cookieData.Username, cookieData.UserId, announcementId, false)
+		api.renderer.JSON(w, status, message)
+	}, api.privateKey, api.publicKey))
+
+	api.routes.Put("/announcement/:announcementId/attachment", middlewares.Restricted(middlewares.Access{ Resource: AnnouncementResource, Param: "announcementId", Right: WritePermission }, func(c web.C, w http.ResponseWriter, r *http.Request) {
+		// Get and Parse the parameters
+		var cookieData *tools.JWTSession = c.Env["token"].(*tools.JWTSession)
+		file, header, err := r.FormFile("file")
+
+		announcementId, status, errMsg := tools.ParseID(c.URLParams["announcementId"])
+		if status != http.StatusOK {
+			api.renderer.JSON(w, status, errMsg); return
+		}
+
+		if err != nil {
+			api.renderer.JSON(w, http.StatusConflict, map[string]interface{}{
+				"error": "Conflict",
+				"message": "Invalid or Missing File",
+			}); return
+		}
+
+		// Process the action and Give the response
+		status, message := endpoints.UploadAnnouncementAttachment(cookieData.UserId, announcementId, file, header)
+		api.renderer.JSON(w, status, message)
+	}, api.privateKey, api.publicKey))
+
+	api.routes.Delete("/announcement/:announcementId/attachment/:attachmentId", middlewares.Restricted(middlewares.Access{ Resource: AnnouncementResource, Param: "announcementId", Right: DeletePermission }, func(c web.C, w http.ResponseWriter, r *http.Request) {
+		// Get and Parse the parameters
+		announcementId, status, err := tools.ParseID(c.URLParams["announcementId"])
+		if status != http.StatusOK {
+			api.renderer.JSON(w, status, err); return
+		}
+
+		attachmentId, status, err := tools.ParseID(c.URLParams["attachmentId"])
+		if status != http.StatusOK {
+			api.renderer.JSON(w, status, err); return
+		}
+
+		// Process the action and Give the response
+		status, message := endpoints.RemoveAnnouncementAttachment(announcementId, attachmentId)
+		api.renderer.JSON(w, status, message)
+	}, api.privateKey, api.publicKey))
+}
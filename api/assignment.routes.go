@@ -15,16 +15,7 @@ import (
 )
 
 func (api *API) LoadAssignmentsEndpoints() {
-	api.routes.Put("/module/:moduleCode/assignment", middlewares.CheckSession(func(c web.C, w http.ResponseWriter, r *http.Request) {
-		var cookieData *tools.JWTSession = c.Env["token"].(*tools.JWTSession)
-		moduleCode := c.URLParams["moduleCode"]
-
-		// Does the user have enough access rights?
-		status, err := tools.VerifyAccess(moduleCode, cookieData.UserId, WritePermission, models.DBPermissions.IsActionPermittedOnModuleWithCode)
-		if status != http.StatusOK {
-			api.renderer.JSON(w, status, err); return
-		}
-
+	api.routes.Put("/module/:moduleCode/assignment", middlewares.Restricted(middlewares.Access{ Resource: ModuleResource, Param: "moduleCode", Right: WritePermission }, func(c web.C, w http.ResponseWriter, r *http.Request) {
 		// Parse the JSON Body
 		var assignment models.Assignment
 		status, errMessage := tools.ParseBody(r.Body, &assignment)
@@ -38,6 +29,10 @@ func (api *API) LoadAssignmentsEndpoints() {
 			assignment.Description,
 			assignment.Status,
 			assignment.Weight,
+			assignment.TeamAssignment,
+			assignment.LateDays,
+			assignment.LatePenalty,
+			assignment.AllowedExtensions,
 			assignment.Start,
 			assignment.End,
 			assignment.ModuleCode,
@@ -46,44 +41,32 @@ func (api *API) LoadAssignmentsEndpoints() {
 		api.renderer.JSON(w, status, message)
 	}, api.privateKey, api.publicKey))
 
-	api.routes.Get("/module/:moduleCode/assignment/:assignmentId", middlewares.CheckSession(func(c web.C, w http.ResponseWriter, r *http.Request) {
-		// Get and Parse the parameters
-		var cookieData *tools.JWTSession = c.Env["token"].(*tools.JWTSession)
-		moduleCode := c.URLParams["moduleCode"]
-
+	api.routes.Get("/module/:moduleCode/assignment/:assignmentId", middlewares.Restricted(middlewares.Access{ Resource: ModuleResource, Param: "moduleCode", Right: ReadPermission }, func(c web.C, w http.ResponseWriter, r *http.Request) {
 		assignmentId, status, err := tools.ParseID(c.URLParams["assignmentId"])
 		if status != http.StatusOK {
 			api.renderer.JSON(w, status, err); return
 		}
 
-		// Does the user have enough access rights?
-		status, err = tools.VerifyAccess(moduleCode, cookieData.UserId, ReadPermission, models.DBPermissions.IsActionPermittedOnModuleWithCode)
-		if status != http.StatusOK {
-			api.renderer.JSON(w, status, err); return
-		}
-
 		// Process the action and Give the response
 		status, message := endpoints.GetAssignment(uint32(assignmentId))
 		api.renderer.JSON(w, status, message)
 	}, api.privateKey, api.publicKey))
 
-	api.routes.Post("/module/:moduleCode/assignment/:assignmentId", middlewares.CheckSession(func(c web.C, w http.ResponseWriter, r *http.Request) {
+	api.routes.Post("/module/:moduleCode/assignment/:assignmentId", middlewares.Restricted(middlewares.Access{ Resource: ModuleResource, Param: "moduleCode", Right: UpdatePermission }, func(c web.C, w http.ResponseWriter, r *http.Request) {
 		// Get and Parse the parameters
-		var cookieData *tools.JWTSession = c.Env["token"].(*tools.JWTSession)
-		moduleCode := c.URLParams["moduleCode"]
 		assignmentId, status, err := tools.ParseID(c.URLParams["assignmentId"])
 		if status != http.StatusOK {
 			api.renderer.JSON(w, status, err); return
 		}
 
-		// Does the user have enough access rights?
-		status, err = tools.VerifyAccess(moduleCode, cookieData.UserId, UpdatePermission, models.DBPermissions.IsActionPermittedOnModuleWithCode)
-		if status != http.StatusOK {
-			api.renderer.JSON(w, status, err); return
+		// Parse the JSON Body, the fields given as pointers are only updated when they are sent
+		var assignment struct {
+			models.Assignment
+			TeamAssignment		*bool		`json:"team_assignment"`
+			LateDays			*uint32		`json:"late_days"`
+			LatePenalty			*float64	`json:"late_penalty"`
+			AllowedExtensions	*string		`json:"allowed_extensions"`
 		}
-
-		// Parse the JSON Body
-		var assignment models.Assignment
 		status, errMessage := tools.ParseBody(r.Body, &assignment)
 		if status != http.StatusOK {
 			api.renderer.JSON(w, status, errMessage); return
@@ -96,6 +79,10 @@ func (api *API) LoadAssignmentsEndpoints() {
 			assignment.Description,
 			assignment.Status,
 			assignment.Weight,
+			assignment.TeamAssignment,
+			assignment.LateDays,
+			assignment.LatePenalty,
+			assignment.AllowedExtensions,
 			assignment.Start,
 			assignment.End,
 			assignment.ModuleCode,
@@ -104,37 +91,23 @@ func (api *API) LoadAssignmentsEndpoints() {
 		api.renderer.JSON(w, status, message)
 	}, api.privateKey, api.publicKey))
 
-	api.routes.Delete("/module/:moduleCode/assignment/:assignmentId", middlewares.CheckSession(func(c web.C, w http.ResponseWriter, r *http.Request) {
+	api.routes.Delete("/module/:moduleCode/assignment/:assignmentId", middlewares.Restricted(middlewares.Access{ Resource: ModuleResource, Param: "moduleCode", Right: DeletePermission }, func(c web.C, w http.ResponseWriter, r *http.Request) {
 		// Get and Parse the parameters
-		var cookieData *tools.JWTSession = c.Env["token"].(*tools.JWTSession)
-		moduleCode := c.URLParams["moduleCode"]
 		assignmentId, status, err := tools.ParseID(c.URLParams["assignmentId"])
 		if status != http.StatusOK {
 			api.renderer.JSON(w, status, err); return
 		}
 
-		// Does the user have enough access rights?
-		status, err = tools.VerifyAccess(moduleCode, cookieData.UserId, DeletePermission, models.DBPermissions.IsActionPermittedOnModuleWithCode)
-		if status != http.StatusOK {
-			api.renderer.JSON(w, status, err); return
-		}
-
 		// Process the action and Give the response
 		status, message := endpoints.DeleteAssignment(assignmentId)
 		api.renderer.JSON(w, status, message)
 	}, api.privateKey, api.publicKey))
 
-	api.routes.Get("/module/:moduleCode/assignments", middlewares.CheckSession(func(c web.C, w http.ResponseWriter, r *http.Request) {
+	api.routes.Get("/module/:moduleCode/assignments", middlewares.Restricted(middlewares.Access{ Resource: ModuleResource, Param: "moduleCode", Right: ReadPermission }, func(c web.C, w http.ResponseWriter, r *http.Request) {
 		// Get and Parse the parameters
 		var cookieData *tools.JWTSession = c.Env["token"].(*tools.JWTSession)
 		moduleCode := c.URLParams["moduleCode"]
 
-		// Does the user have enough access rights?
-		status, err := tools.VerifyAccess(moduleCode, cookieData.UserId, ReadPermission, models.DBPermissions.IsActionPermittedOnModuleWithCode)
-		if status != http.StatusOK {
-			api.renderer.JSON(w, status, err); return
-		}
-
 		canWrite := models.DBPermissions.IsActionPermittedOnModuleWithCode(cookieData.UserId, moduleCode, WritePermission)
 
 		// Process the action and Give the response
@@ -142,23 +115,16 @@ func (api *API) LoadAssignmentsEndpoints() {
 		api.renderer.JSON(w, status, message)
 	}, api.privateKey, api.publicKey))
 
-	api.routes.Put("/module/:moduleCode/assignment/:assignmentId/attachment", middlewares.CheckSession(func(c web.C, w http.ResponseWriter, r *http.Request) {
+	api.routes.Put("/module/:moduleCode/assignment/:assignmentId/attachment", middlewares.Restricted(middlewares.Access{ Resource: ModuleResource, Param: "moduleCode", Right: WritePermission }, func(c web.C, w http.ResponseWriter, r *http.Request) {
 		// Get and Parse the parameters
 		var cookieData *tools.JWTSession = c.Env["token"].(*tools.JWTSession)
 		file, header, err := r.FormFile("file")
-		moduleCode := c.URLParams["moduleCode"]
 
 		assignmentId, status, errMsg := tools.ParseID(c.URLParams["assignmentId"])
 		if status != http.StatusOK {
 			api.renderer.JSON(w, status, errMsg); return
 		}
 
-		// Does the user have enough access rights?
-		status, errMsg = tools.VerifyAccess(moduleCode, cookieData.UserId, WritePermission, models.DBPermissions.IsActionPermittedOnModuleWithCode)
-		if status != http.StatusOK {
-			api.renderer.JSON(w, status, errMsg); return
-		}
-
 		if err != nil {
 			api.renderer.JSON(w, http.StatusConflict, map[string]interface{}{
 				"error": "Conflict",
@@ -167,14 +133,12 @@ func (api *API) LoadAssignmentsEndpoints() {
 		}
 
 		// Process the action and Give the response
-		status, message := endpoints.UploadAssignmentAttachments(assignmentId, file, header)
+		status, message := endpoints.UploadAssignmentAttachments(cookieData.UserId, c.URLParams["moduleCode"], assignmentId, file, header)
 		api.renderer.JSON(w, status, message)
 	}, api.privateKey, api.publicKey))
 
-	api.routes.Delete("/module/:moduleCode/assignment/:assignmentId/attachment/:attachmentId", middlewares.CheckSession(func(c web.C, w http.ResponseWriter, r *http.Request) {
+	api.routes.Delete("/module/:moduleCode/assignment/:assignmentId/attachment/:attachmentId", middlewares.Restricted(middlewares.Access{ Resource: ModuleResource, Param: "moduleCode", Right: DeletePermission }, func(c web.C, w http.ResponseWriter, r *http.Request) {
 		// Get and Parse the parameters
-		var cookieData *tools.JWTSession = c.Env["token"].(*tools.JWTSession)
-		moduleCode := c.URLParams["moduleCode"]
 		assignmentId, status, err := tools.ParseID(c.URLParams["assignmentId"])
 		if status != http.StatusOK {
 			api.renderer.JSON(w, status, err); return
@@ -185,12 +149,6 @@ func (api *API) LoadAssignmentsEndpoints() {
 			api.renderer.JSON(w, status, err); return
 		}
 
-		// Does the user have enough access rights?
-		status, err = tools.VerifyAccess(moduleCode, cookieData.UserId, DeletePermission, models.DBPermissions.IsActionPermittedOnModuleWithCode)
-		if status != http.StatusOK {
-			api.renderer.JSON(w, status, err); return
-		}
-
 		// Process the action and Give the response
 		status, message := endpoints.RemoveAssignmentAttachments(assignmentId, attachmentId)
 		api.renderer.JSON(w, status, message)
@@ -232,36 +190,240 @@ func (api *API) LoadAssignmentsEndpoints() {
 		api.renderer.JSON(w, status, message)
 	}, api.privateKey, api.publicKey))
 
-	api.routes.Post("/module/:moduleCode/assignment/:assignmentId/grade", middlewares.CheckSession(func(c web.C, w http.ResponseWriter, r *http.Request) {
+	api.routes.Post("/module/:moduleCode/assignment/:assignmentId/grade", middlewares.Restricted(middlewares.Access{ Resource: ModuleResource, Param: "moduleCode", Right: GradeAssignmentPermission }, func(c web.C, w http.ResponseWriter, r *http.Request) {
+		// Get and Parse the parameters
+		moduleCode := c.URLParams["moduleCode"]
+
+		assignmentId, status, errMsg := tools.ParseID(c.URLParams["assignmentId"])
+		if status != http.StatusOK {
+			api.renderer.JSON(w, status, errMsg); return
+		}
+
+		// Parse the JSON Body
+		var grading struct {
+			ID	uint32	`json:"id"`
+			models.SubmissionGrading
+		}
+		status, errMessage := tools.ParseBody(r.Body, &grading)
+		if status != http.StatusOK {
+			api.renderer.JSON(w, status, errMessage); return
+		}
+
+		// Process the action and Give the response
+		status, message := endpoints.GradeAssignment(moduleCode, assignmentId, grading.ID, grading.SubmissionGrading)
+		api.renderer.JSON(w, status, message)
+	}, api.privateKey, api.publicKey))
+
+	api.routes.Delete("/module/:moduleCode/assignment/:assignmentId/submission", middlewares.Restricted(middlewares.Access{ Resource: ModuleResource, Param: "moduleCode", Right: ReadPermission }, func(c web.C, w http.ResponseWriter, r *http.Request) {
 		// Get and Parse the parameters
 		var cookieData *tools.JWTSession = c.Env["token"].(*tools.JWTSession)
 		moduleCode := c.URLParams["moduleCode"]
+		assignmentId, status, errMsg := tools.ParseID(c.URLParams["assignmentId"])
+		if status != http.StatusOK {
+			api.renderer.JSON(w, status, errMsg); return
+		}
 
-		// Does the user have enough access rights?
-		status, errMsg := tools.VerifyAccess(moduleCode, cookieData.UserId, WritePermission, models.DBPermissions.IsActionPermittedOnModuleWithCode)
+		// Process the action and Give the response
+		status, message := endpoints.WithdrawSubmission(moduleCode, assignmentId, cookieData.UserId)
+		api.renderer.JSON(w, status, message)
+	}, api.privateKey, api.publicKey))
+
+	api.routes.Get("/module/:moduleCode/assignment/:assignmentId/submissions", middlewares.Restricted(middlewares.Access{ Resource: ModuleResource, Param: "moduleCode", Right: ReadPermission }, func(c web.C, w http.ResponseWriter, r *http.Request) {
+		// Get and Parse the parameters
+		var cookieData *tools.JWTSession = c.Env["token"].(*tools.JWTSession)
+		moduleCode := c.URLParams["moduleCode"]
+		assignmentId, status, errMsg := tools.ParseID(c.URLParams["assignmentId"])
+		if status != http.StatusOK {
+			api.renderer.JSON(w, status, errMsg); return
+		}
+
+		// Teachers can see the submissions of any student, students only their own
+		userId := cookieData.UserId
+		canDownload := models.DBPermissions.IsActionPermittedOnModuleWithCode(cookieData.UserId, moduleCode, DownloadSubmissionPermission)
+		if r.URL.Query().Get("user_id") != "" {
+			if !canDownload {
+				api.renderer.JSON(w, http.StatusForbidden, map[string]interface{}{
+					"error":   "AccessDenied",
+					"message": "Not enough permissions to see the submissions of other students.",
+				})
+				return
+			}
+
+			userId, status, errMsg = tools.ParseID(r.URL.Query().Get("user_id"))
+			if status != http.StatusOK {
+				api.renderer.JSON(w, status, errMsg); return
+			}
+		}
+
+		// The grades are withheld until the assignment is returned, except for the ones grading it
+		canGrade := models.DBPermissions.IsActionPermittedOnModuleWithCode(cookieData.UserId, moduleCode, GradeAssignmentPermission)
+
+		// Process the action and Give the response
+		status, message := endpoints.FindSubmissionHistory(moduleCode, assignmentId, userId, canGrade)
+		api.renderer.JSON(w, status, message)
+	}, api.privateKey, api.publicKey))
+
+	api.routes.Post("/module/:moduleCode/assignment/:assignmentId/submission/:submissionId/review", middlewares.Restricted(middlewares.Access{ Resource: ModuleResource, Param: "moduleCode", Right: GradeAssignmentPermission }, func(c web.C, w http.ResponseWriter, r *http.Request) {
+		// Get and Parse the parameters
+		moduleCode := c.URLParams["moduleCode"]
+		assignmentId, status, errMsg := tools.ParseID(c.URLParams["assignmentId"])
+		if status != http.StatusOK {
+			api.renderer.JSON(w, status, errMsg); return
+		}
+
+		submissionId, status, errMsg := tools.ParseID(c.URLParams["submissionId"])
+		if status != http.StatusOK {
+			api.renderer.JSON(w, status, errMsg); return
+		}
+
+		// Process the action and Give the response
+		status, message := endpoints.ReviewSubmission(moduleCode, assignmentId, submissionId)
+		api.renderer.JSON(w, status, message)
+	}, api.privateKey, api.publicKey))
+
+	api.routes.Get("/module/:moduleCode/assignment/:assignmentId/extensions", middlewares.Restricted(middlewares.Access{ Resource: ModuleResource, Param: "moduleCode", Right: WritePermission }, func(c web.C, w http.ResponseWriter, r *http.Request) {
+		// Get and Parse the parameters
+		moduleCode := c.URLParams["moduleCode"]
+		assignmentId, status, errMsg := tools.ParseID(c.URLParams["assignmentId"])
+		if status != http.StatusOK {
+			api.renderer.JSON(w, status, errMsg); return
+		}
+
+		// Process the action and Give the response
+		status, message := endpoints.FindExtensions(moduleCode, assignmentId)
+		api.renderer.JSON(w, status, message)
+	}, api.privateKey, api.publicKey))
+
+	api.routes.Put("/module/:moduleCode/assignment/:assignmentId/extension/:userId", middlewares.Restricted(middlewares.Access{ Resource: ModuleResource, Param: "moduleCode", Right: WritePermission }, func(c web.C, w http.ResponseWriter, r *http.Request) {
+		// Get and Parse the parameters
+		var cookieData *tools.JWTSession = c.Env["token"].(*tools.JWTSession)
+		moduleCode := c.URLParams["moduleCode"]
+		assignmentId, status, errMsg := tools.ParseID(c.URLParams["assignmentId"])
+		if status != http.StatusOK {
+			api.renderer.JSON(w, status, errMsg); return
+		}
+
+		userId, status, errMsg := tools.ParseID(c.URLParams["userId"])
 		if status != http.StatusOK {
 			api.renderer.JSON(w, status, errMsg); return
 		}
 
 		// Parse the JSON Body
-		var submission map[string]interface{}
-		status, errMessage := tools.ParseBody(r.Body, &submission)
+		var extension models.AssignmentExtension
+		status, errMessage := tools.ParseBody(r.Body, &extension)
 		if status != http.StatusOK {
 			api.renderer.JSON(w, status, errMessage); return
 		}
 
-		submissionId, status, errMsg := tools.ParseID(fmt.Sprint("", submission["id"].(float64)))
+		// Process the action and Give the response
+		status, message := endpoints.GrantExtension(moduleCode, assignmentId, userId, extension, cookieData.UserId)
+		api.renderer.JSON(w, status, message)
+	}, api.privateKey, api.publicKey))
+
+	api.routes.Delete("/module/:moduleCode/assignment/:assignmentId/extension/:userId", middlewares.Restricted(middlewares.Access{ Resource: ModuleResource, Param: "moduleCode", Right: WritePermission }, func(c web.C, w http.ResponseWriter, r *http.Request) {
+		// Get and Parse the parameters
+		moduleCode := c.URLParams["moduleCode"]
+		assignmentId, status, errMsg := tools.ParseID(c.URLParams["assignmentId"])
+		if status != http.StatusOK {
+			api.renderer.JSON(w, status, errMsg); return
+		}
+
+		userId, status, errMsg := tools.ParseID(c.URLParams["userId"])
+		if status != http.StatusOK {
+			api.renderer.JSON(w, status, errMsg); return
+		}
+
+		// Process the action and Give the response
+		status, message := endpoints.RevokeExtension(moduleCode, assignmentId, userId)
+		api.renderer.JSON(w, status, message)
+	}, api.privateKey, api.publicKey))
+
+	api.routes.Put("/module/:moduleCode/assignment/:assignmentId/submission/:submissionId/feedback", middlewares.Restricted(middlewares.Access{ Resource: ModuleResource, Param: "moduleCode", Right: GradeAssignmentPermission }, func(c web.C, w http.ResponseWriter, r *http.Request) {
+		// Get and Parse the parameters
+		var cookieData *tools.JWTSession = c.Env["token"].(*tools.JWTSession)
+		moduleCode := c.URLParams["moduleCode"]
+		file, header, err := r.FormFile("file")
+
+		assignmentId, status, errMsg := tools.ParseID(c.URLParams["assignmentId"])
+		if status != http.StatusOK {
+			api.renderer.JSON(w, status, errMsg); return
+		}
+
+		submissionId, status, errMsg := tools.ParseID(c.URLParams["submissionId"])
+		if status != http.StatusOK {
+			api.renderer.JSON(w, status, errMsg); return
+		}
+
+		if err != nil {
+			api.renderer.JSON(w, http.StatusConflict, map[string]interface{}{
+				"error": "Conflict",
+				"message": "Invalid or Missing File",
+			}); return
+		}
+
+		// Process the action and Give the response
+		status, message := endpoints.UploadFeedbackAttachment(cookieData.UserId, moduleCode, assignmentId, submissionId, file, header)
+		api.renderer.JSON(w, status, message)
+	}, api.privateKey, api.publicKey))
+
+	api.routes.Post("/module/:moduleCode/assignment/:assignmentId/release", middlewares.Restricted(middlewares.Access{ Resource: ModuleResource, Param: "moduleCode", Right: GradeAssignmentPermission }, func(c web.C, w http.ResponseWriter, r *http.Request) {
+		// Get and Parse the parameters
+		moduleCode := c.URLParams["moduleCode"]
+		assignmentId, status, errMsg := tools.ParseID(c.URLParams["assignmentId"])
+		if status != http.StatusOK {
+			api.renderer.JSON(w, status, errMsg); return
+		}
+
+		// Process the action and Give the response
+		status, message := endpoints.ReleaseGrades(moduleCode, assignmentId)
+		api.renderer.JSON(w, status, message)
+	}, api.privateKey, api.publicKey))
+
+	api.routes.Get("/module/:moduleCode/assignment/:assignmentId/rubric", middlewares.Restricted(middlewares.Access{ Resource: ModuleResource, Param: "moduleCode", Right: ReadPermission }, func(c web.C, w http.ResponseWriter, r *http.Request) {
+		// Get and Parse the parameters
+		moduleCode := c.URLParams["moduleCode"]
+		assignmentId, status, errMsg := tools.ParseID(c.URLParams["assignmentId"])
+		if status != http.StatusOK {
+			api.renderer.JSON(w, status, errMsg); return
+		}
+
+		// Process the action and Give the response
+		status, message := endpoints.FindRubric(moduleCode, assignmentId)
+		api.renderer.JSON(w, status, message)
+	}, api.privateKey, api.publicKey))
+
+	api.routes.Put("/module/:moduleCode/assignment/:assignmentId/rubric", middlewares.Restricted(middlewares.Access{ Resource: ModuleResource, Param: "moduleCode", Right: WritePermission }, func(c web.C, w http.ResponseWriter, r *http.Request) {
+		// Get and Parse the parameters
+		moduleCode := c.URLParams["moduleCode"]
+		assignmentId, status, errMsg := tools.ParseID(c.URLParams["assignmentId"])
 		if status != http.StatusOK {
 			api.renderer.JSON(w, status, errMsg); return
 		}
 
-		grade, status, errMsg := tools.ParseID(fmt.Sprint("", submission["grade"].(float64)))
+		// Parse the JSON Body
+		var rubric struct {
+			Criteria	[]models.RubricCriterion `json:"criteria"`
+		}
+		status, errMessage := tools.ParseBody(r.Body, &rubric)
+		if status != http.StatusOK {
+			api.renderer.JSON(w, status, errMessage); return
+		}
+
+		// Process the action and Give the response
+		status, message := endpoints.SaveRubric(moduleCode, assignmentId, rubric.Criteria)
+		api.renderer.JSON(w, status, message)
+	}, api.privateKey, api.publicKey))
+
+	api.routes.Delete("/module/:moduleCode/assignment/:assignmentId/rubric", middlewares.Restricted(middlewares.Access{ Resource: ModuleResource, Param: "moduleCode", Right: WritePermission }, func(c web.C, w http.ResponseWriter, r *http.Request) {
+		// Get and Parse the parameters
+		moduleCode := c.URLParams["moduleCode"]
+		assignmentId, status, errMsg := tools.ParseID(c.URLParams["assignmentId"])
 		if status != http.StatusOK {
 			api.renderer.JSON(w, status, errMsg); return
 		}
 
 		// Process the action and Give the response
-		status, message := endpoints.GradeAssignment(submissionId, grade)
+		status, message := endpoints.DeleteRubric(moduleCode, assignmentId)
 		api.renderer.JSON(w, status, message)
 	}, api.privateKey, api.publicKey))
 }
@@ -1,41 +1,49 @@
 package api
 
 import (
+	"fmt"
+	"mime"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/zenazn/goji/web"
 
 	"github.com/YagoCarballo/kumquat-academy-api/api/middlewares"
 	"github.com/YagoCarballo/kumquat-academy-api/api/endpoints"
 	"github.com/YagoCarballo/kumquat-academy-api/tools"
+
+	. "github.com/YagoCarballo/kumquat-academy-api/constants"
 )
 
 func (api *API) LoadAttachmentsEndpoints() {
-	api.routes.Get("/attachment/:name", middlewares.CheckSession(func(c web.C, w http.ResponseWriter, r *http.Request) {
-		fileType, bytes, err := endpoints.ServeFile(c.URLParams["name"])
-		if err != nil || fileType == nil {
-			api.renderer.Text(w, http.StatusNotFound, err.Error())
-			return
-		}
+	api.routes.Get("/attachment/:name", middlewares.Restricted(middlewares.Access{ Resource: AttachmentResource, Param: "name", Right: ReadPermission }, func(c web.C, w http.ResponseWriter, r *http.Request) {
+		api.serveAttachment(w, r, c.URLParams["name"])
+	}, api.privateKey, api.publicKey))
 
-		headers := w.Header()
-		headers["Content-Type"] = []string{ *fileType }
-		api.renderer.Data(w, http.StatusOK, *bytes)
+	api.routes.Get("/attachment/:token/:name", middlewares.Restricted(middlewares.Access{ Resource: AttachmentResource, Param: "token", Right: ReadPermission }, func(c web.C, w http.ResponseWriter, r *http.Request) {
+		api.serveAttachment(w, r, c.URLParams["token"])
+	}, api.privateKey, api.publicKey))
+
+	api.routes.Post("/attachment/:name/link", middlewares.Restricted(middlewares.Access{ Resource: AttachmentResource, Param: "name", Right: ReadPermission }, func(c web.C, w http.ResponseWriter, r *http.Request) {
+		status, message := endpoints.CreateDownloadLink(c.URLParams["name"], api.privateKey)
+		api.renderer.JSON(w, status, message)
 	}, api.privateKey, api.publicKey))
 
-	api.routes.Get("/attachment/:token/:name", middlewares.CheckSession(func(c web.C, w http.ResponseWriter, r *http.Request) {
-		fileType, bytes, err := endpoints.ServeFile(c.URLParams["token"])
-		if err != nil || fileType == nil {
-			api.renderer.Text(w, http.StatusNotFound, err.Error())
-			return
+	// Signed links work without a session, anyone with the link can download the file until it expires
+	api.routes.Get("/download/:signature/:name", func(c web.C, w http.ResponseWriter, r *http.Request) {
+		download, err := tools.ParseDownloadToken(c.URLParams["signature"], api.publicKey, time.Now())
+		if err != nil {
+			api.renderer.JSON(w, http.StatusForbidden, map[string]interface{}{
+				"error": "InvalidLink",
+				"message": err.Error(),
+			}); return
 		}
 
-		headers := w.Header()
-		headers["Content-Type"] = []string{ *fileType }
-		api.renderer.Data(w, http.StatusOK, *bytes)
-	}, api.privateKey, api.publicKey))
+		api.serveAttachment(w, r, download.Attachment)
+	})
 
-	api.routes.Put("/attachment", middlewares.CheckSession(func(c web.C, w http.ResponseWriter, r *http.Request) {
+	api.routes.Put("/attachment", middlewares.Restricted(middlewares.Access{ Resource: AdminResource, Right: WritePermission }, func(c web.C, w http.ResponseWriter, r *http.Request) {
 		// Get and Parse the parameters
 		var cookieData *tools.JWTSession = c.Env["token"].(*tools.JWTSession)
 		file, header, err := r.FormFile("file")
@@ -47,21 +55,12 @@ func (api *API) LoadAttachmentsEndpoints() {
 			}); return
 		}
 
-		// Does the user have enough access rights?
-		if (!cookieData.Admin) {
-			api.renderer.JSON(w, http.StatusForbidden, map[string]interface{}{
-				"error":   "AccessDenied",
-				"message": "Not enough permissions to remove an attachment.",
-			})
-			return
-		}
-
 		// Process the action and Give the response
-		status, message := endpoints.UploadFile(file, header)
+		status, message := endpoints.UploadFile(endpoints.Upload{ UserID: cookieData.UserId }, file, header)
 		api.renderer.JSON(w, status, message)
 	}, api.privateKey, api.publicKey))
 
-	api.routes.Put("/attachments", middlewares.CheckSession(func(c web.C, w http.ResponseWriter, r *http.Request) {
+	api.routes.Put("/attachments", middlewares.Restricted(middlewares.Access{ Resource: AdminResource, Right: WritePermission }, func(c web.C, w http.ResponseWriter, r *http.Request) {
 		// Get and Parse the parameters
 		var cookieData *tools.JWTSession = c.Env["token"].(*tools.JWTSession)
 		err := r.ParseMultipartForm(200000) // grab the multipart form
@@ -72,44 +71,71 @@ func (api *API) LoadAttachmentsEndpoints() {
 			}); return
 		}
 
-		// Does the user have enough access rights?
-		if (!cookieData.Admin) {
-			api.renderer.JSON(w, http.StatusForbidden, map[string]interface{}{
-				"error":   "AccessDenied",
-				"message": "Not enough permissions to remove an attachment.",
-			})
-			return
-		}
-
 		formData := r.MultipartForm // ok, no problem so far, read the Form data
 
 		//get the *fileHeaders
 		fileHeaders := formData.File["files[]"] // grab the filenames
 
 		// Process the action and Give the response
-		status, message := endpoints.UploadFiles(fileHeaders)
+		status, message := endpoints.UploadFiles(endpoints.Upload{ UserID: cookieData.UserId }, fileHeaders)
 		api.renderer.JSON(w, status, message)
 	}, api.privateKey, api.publicKey))
 
-	api.routes.Delete("/attachment/:attachmentId", middlewares.CheckSession(func(c web.C, w http.ResponseWriter, r *http.Request) {
+	api.routes.Delete("/attachment/:attachmentId", middlewares.Restricted(middlewares.Access{ Resource: AdminResource, Right: DeletePermission }, func(c web.C, w http.ResponseWriter, r *http.Request) {
 		// Get and Parse the parameters
-		var cookieData *tools.JWTSession = c.Env["token"].(*tools.JWTSession)
 		attachmentId, status, err := tools.ParseID(c.URLParams["attachmentId"])
 		if status != http.StatusOK {
 			api.renderer.JSON(w, status, err); return
 		}
 
-		// Does the user have enough access rights?
-		if (!cookieData.Admin) {
-			api.renderer.JSON(w, http.StatusForbidden, map[string]interface{}{
-				"error":   "AccessDenied",
-				"message": "Not enough permissions to remove an attachment.",
-			})
-			return
-		}
-
 		// Process the action and Give the response
 		status, message := endpoints.DeleteAttachment(attachmentId)
 		api.renderer.JSON(w, status, message)
 	}, api.privateKey, api.publicKey))
 }
+
+// Streams the file of an attachment, http.ServeContent answers the Range and conditional requests
+func (api *API) serveAttachment(w http.ResponseWriter, r *http.Request, name string) {
+	attachment, file, err := endpoints.ServeFile(name)
+	if err != nil {
+		api.renderer.Text(w, http.StatusNotFound, err.Error())
+		return
+	}
+	defer file.Close()
+
+	// The stored files never change, so the token with the size and date is enough to tell them apart
+	headers := w.Header()
+	headers.Set("ETag", fmt.Sprintf("\"%s-%x-%x\"", attachment.Url, file.Size, file.ModTime.Unix()))
+	headers.Set("Cache-Control", "private, no-cache")
+
+	// Only the types that can't run scripts are shown in the browser, the rest are downloaded.
+	// Without a type, the browser is not left to guess one.
+	mimeType := attachment.Type
+	if mimeType == "" {
+		mimeType = "application/octet-stream"
+	}
+
+	disposition := "attachment"
+	if isInlineType(mimeType) {
+		disposition = "inline"
+	}
+
+	headers.Set("Content-Type", mimeType)
+	headers.Set("X-Content-Type-Options", "nosniff")
+	headers.Set("Content-Disposition", mime.FormatMediaType(disposition, map[string]string{ "filename": attachment.Name }))
+
+	http.ServeContent(w, r, attachment.Name, file.ModTime, file)
+}
+
+// Images, PDFs, audio and video are shown inline, SVG images can hold scripts so they aren't
+func isInlineType(mimeType string) bool {
+	baseType, _, err := mime.ParseMediaType(mimeType)
+	if err != nil || baseType == "image/svg+xml" {
+		return false
+	}
+
+	return baseType == "application/pdf" ||
+		strings.HasPrefix(baseType, "image/") ||
+		strings.HasPrefix(baseType, "audio/") ||
+		strings.HasPrefix(baseType, "video/")
+}
@@ -14,7 +14,7 @@ import (
 )
 
 func (api *API) LoadClassesEndpoints() {
-	api.routes.Get("/course/:courseId/classOf/:year", middlewares.CheckSession(func(c web.C, w http.ResponseWriter, r *http.Request) {
+	api.routes.Get("/course/:courseId/classOf/:year", middlewares.Restricted(middlewares.Access{ Resource: CourseResource, Param: "courseId", Right: ReadPermission }, func(c web.C, w http.ResponseWriter, r *http.Request) {
 		// Get and Parse the parameters
 		var cookieData *tools.JWTSession = c.Env["token"].(*tools.JWTSession)
 		year := c.URLParams["year"]
@@ -25,12 +25,6 @@ func (api *API) LoadClassesEndpoints() {
 			api.renderer.JSON(w, status, err); return
 		}
 
-		// Does the user have enough access rights?
-		status, err = tools.VerifyAccess(courseId, cookieData.UserId, ReadPermission, models.DBPermissions.IsActionPermittedOnCourse)
-		if status != http.StatusOK {
-			api.renderer.JSON(w, status, err); return
-		}
-
 		// Get the class
 		status, message := endpoints.GetClassForYear(courseId, year)
 		if status == http.StatusOK {
@@ -47,21 +41,13 @@ func (api *API) LoadClassesEndpoints() {
 		api.renderer.JSON(w, status, message)
 	}, api.privateKey, api.publicKey))
 
-	api.routes.Put("/course/:courseId/class", middlewares.CheckSession(func(c web.C, w http.ResponseWriter, r *http.Request) {
-		var cookieData *tools.JWTSession = c.Env["token"].(*tools.JWTSession)
-
+	api.routes.Put("/course/:courseId/class", middlewares.Restricted(middlewares.Access{ Resource: CourseResource, Param: "courseId", Right: WritePermission }, func(c web.C, w http.ResponseWriter, r *http.Request) {
 		// Parse the course Id
 		courseId, status, err := tools.ParseID(c.URLParams["courseId"])
 		if status != http.StatusOK {
 			api.renderer.JSON(w, status, err); return
 		}
 
-		// Does the user have enough access rights?
-		status, err = tools.VerifyAccess(courseId, cookieData.UserId, WritePermission, models.DBPermissions.IsActionPermittedOnCourse)
-		if status != http.StatusOK {
-			api.renderer.JSON(w, status, err); return
-		}
-
 		// Parse the JSON Body
 		var class models.Class
 		status, errMessage := tools.ParseBody(r.Body, &class)
@@ -74,35 +60,19 @@ func (api *API) LoadClassesEndpoints() {
 		api.renderer.JSON(w, status, message)
 	}, api.privateKey, api.publicKey))
 
-	api.routes.Get("/course/:courseId/class/:classId", middlewares.CheckSession(func(c web.C, w http.ResponseWriter, r *http.Request) {
-		// Get and Parse the parameters
-		var cookieData *tools.JWTSession = c.Env["token"].(*tools.JWTSession)
-		// Parse the course Id
-		courseId, status, err := tools.ParseID(c.URLParams["courseId"])
-		if status != http.StatusOK {
-			api.renderer.JSON(w, status, err); return
-		}
-
+	api.routes.Get("/course/:courseId/class/:classId", middlewares.Restricted(middlewares.Access{ Resource: CourseResource, Param: "courseId", Right: ReadPermission }, func(c web.C, w http.ResponseWriter, r *http.Request) {
 		// Parse the class Id
 		classId, status, err := tools.ParseID(c.URLParams["classId"])
 		if status != http.StatusOK {
 			api.renderer.JSON(w, status, err); return
 		}
 
-		// Does the user have enough access rights?
-		status, err = tools.VerifyAccess(courseId, cookieData.UserId, ReadPermission, models.DBPermissions.IsActionPermittedOnCourse)
-		if status != http.StatusOK {
-			api.renderer.JSON(w, status, err); return
-		}
-
 		// Process the action and Give the response
 		status, message := endpoints.GetClass(uint32(classId))
 		api.renderer.JSON(w, status, message)
 	}, api.privateKey, api.publicKey))
 
-	api.routes.Post("/course/:courseId/class/:classId", middlewares.CheckSession(func(c web.C, w http.ResponseWriter, r *http.Request) {
-		// Get and Parse the parameters
-		var cookieData *tools.JWTSession = c.Env["token"].(*tools.JWTSession)
+	api.routes.Post("/course/:courseId/class/:classId", middlewares.Restricted(middlewares.Access{ Resource: CourseResource, Param: "courseId", Right: WritePermission }, func(c web.C, w http.ResponseWriter, r *http.Request) {
 		// Parse the course Id
 		courseId, status, err := tools.ParseID(c.URLParams["courseId"])
 		if status != http.StatusOK {
@@ -115,12 +85,6 @@ func (api *API) LoadClassesEndpoints() {
 			api.renderer.JSON(w, status, err); return
 		}
 
-		// Does the user have enough access rights?
-		status, err = tools.VerifyAccess(courseId, cookieData.UserId, WritePermission, models.DBPermissions.IsActionPermittedOnCourse)
-		if status != http.StatusOK {
-			api.renderer.JSON(w, status, err); return
-		}
-
 		// Parse the JSON Body
 		var class models.Class
 		status, errMessage := tools.ParseBody(r.Body, &class)
@@ -133,47 +97,25 @@ func (api *API) LoadClassesEndpoints() {
 		api.renderer.JSON(w, status, message)
 	}, api.privateKey, api.publicKey))
 
-	api.routes.Delete("/course/:courseId/class/:classId", middlewares.CheckSession(func(c web.C, w http.ResponseWriter, r *http.Request) {
-		// Get and Parse the parameters
-		var cookieData *tools.JWTSession = c.Env["token"].(*tools.JWTSession)
-		// Parse the course Id
-		courseId, status, err := tools.ParseID(c.URLParams["courseId"])
-		if status != http.StatusOK {
-			api.renderer.JSON(w, status, err); return
-		}
-
+	api.routes.Delete("/course/:courseId/class/:classId", middlewares.Restricted(middlewares.Access{ Resource: CourseResource, Param: "courseId", Right: DeletePermission }, func(c web.C, w http.ResponseWriter, r *http.Request) {
 		// Parse the class Id
 		classId, status, err := tools.ParseID(c.URLParams["classId"])
 		if status != http.StatusOK {
 			api.renderer.JSON(w, status, err); return
 		}
 
-		// Does the user have enough access rights?
-		status, err = tools.VerifyAccess(courseId, cookieData.UserId, DeletePermission, models.DBPermissions.IsActionPermittedOnCourse)
-		if status != http.StatusOK {
-			api.renderer.JSON(w, status, err); return
-		}
-
 		// Process the action and Give the response
 		status, message := endpoints.DeleteClass(classId)
 		api.renderer.JSON(w, status, message)
 	}, api.privateKey, api.publicKey))
 
-	api.routes.Get("/course/:courseId/classes", middlewares.CheckSession(func(c web.C, w http.ResponseWriter, r *http.Request) {
-		// Get and Parse the parameters
-		var cookieData *tools.JWTSession = c.Env["token"].(*tools.JWTSession)
+	api.routes.Get("/course/:courseId/classes", middlewares.Restricted(middlewares.Access{ Resource: CourseResource, Param: "courseId", Right: ReadPermission }, func(c web.C, w http.ResponseWriter, r *http.Request) {
 		// Parse the course Id
 		courseId, status, err := tools.ParseID(c.URLParams["courseId"])
 		if status != http.StatusOK {
 			api.renderer.JSON(w, status, err); return
 		}
 
-		// Does the user have enough access rights?
-		status, err = tools.VerifyAccess(courseId, cookieData.UserId, ReadPermission, models.DBPermissions.IsActionPermittedOnCourse)
-		if status != http.StatusOK {
-			api.renderer.JSON(w, status, err); return
-		}
-
 		// Process the action and Give the response
 		status, message := endpoints.GetClassesForCourse(courseId)
 		api.renderer.JSON(w, status, message)
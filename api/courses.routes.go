@@ -37,18 +37,7 @@ func (api *API) LoadCoursesEndpoints() {
 	}, api.privateKey, api.publicKey))
 
 	// Creates the PUT -> /course endpoint
-	api.routes.Put("/course", middlewares.CheckSession(func(c web.C, w http.ResponseWriter, r *http.Request) {
-		var cookieData *tools.JWTSession = c.Env["token"].(*tools.JWTSession)
-
-		// Does the user have enough access rights?
-		if cookieData.Admin == false {
-			api.renderer.JSON(w, http.StatusForbidden, map[string]interface{}{
-				"error":   "AccessDenied",
-				"message": "Not enough permissions to create a course.",
-			})
-			return
-		}
-
+	api.routes.Put("/course", middlewares.Restricted(middlewares.Access{ Resource: AdminResource, Right: WritePermission }, func(c web.C, w http.ResponseWriter, r *http.Request) {
 		// Parse the JSON Body
 		var course models.Course
 		status, errMessage := tools.ParseBody(r.Body, &course)
@@ -62,40 +51,26 @@ func (api *API) LoadCoursesEndpoints() {
 	}, api.privateKey, api.publicKey))
 
 	// Creates the GET -> /course/:id endpoint
-	api.routes.Get("/course/:id", middlewares.CheckSession(func(c web.C, w http.ResponseWriter, r *http.Request) {
+	api.routes.Get("/course/:id", middlewares.Restricted(middlewares.Access{ Resource: CourseResource, Param: "id", Right: ReadPermission }, func(c web.C, w http.ResponseWriter, r *http.Request) {
 		// Get and Parse the parameters
-		var cookieData *tools.JWTSession = c.Env["token"].(*tools.JWTSession)
 		courseId, status, err := tools.ParseID(c.URLParams["id"])
 		if status != http.StatusOK {
 			api.renderer.JSON(w, status, err); return
 		}
 
-		// Does the user have enough access rights?
-		status, err = tools.VerifyAccess(courseId, cookieData.UserId, ReadPermission, models.DBPermissions.IsActionPermittedOnCourse)
-		if status != http.StatusOK {
-			api.renderer.JSON(w, status, err); return
-		}
-
 		// Process the action and Give the response
 		status, message := endpoints.GetCourse(uint32(courseId))
 		api.renderer.JSON(w, status, message)
 	}, api.privateKey, api.publicKey))
 
 	// Creates the POST -> /course endpoint
-	api.routes.Post("/course/:id", middlewares.CheckSession(func(c web.C, w http.ResponseWriter, r *http.Request) {
+	api.routes.Post("/course/:id", middlewares.Restricted(middlewares.Access{ Resource: CourseResource, Param: "id", Right: UpdatePermission }, func(c web.C, w http.ResponseWriter, r *http.Request) {
 		// Get and Parse the parameters
-		var cookieData *tools.JWTSession = c.Env["token"].(*tools.JWTSession)
 		courseId, status, err := tools.ParseID(c.URLParams["id"])
 		if status != http.StatusOK {
 			api.renderer.JSON(w, status, err); return
 		}
 
-		// Does the user have enough access rights?
-		status, err = tools.VerifyAccess(courseId, cookieData.UserId, UpdatePermission, models.DBPermissions.IsActionPermittedOnCourse)
-		if status != http.StatusOK {
-			api.renderer.JSON(w, status, err); return
-		}
-
 		// Parse the JSON Body
 		var course models.Course
 		status, errMessage := tools.ParseBody(r.Body, &course)
@@ -109,20 +84,13 @@ func (api *API) LoadCoursesEndpoints() {
 	}, api.privateKey, api.publicKey))
 
 	// Creates the POST -> /course endpoint
-	api.routes.Delete("/course/:id", middlewares.CheckSession(func(c web.C, w http.ResponseWriter, r *http.Request) {
+	api.routes.Delete("/course/:id", middlewares.Restricted(middlewares.Access{ Resource: CourseResource, Param: "id", Right: DeletePermission }, func(c web.C, w http.ResponseWriter, r *http.Request) {
 		// Get and Parse the parameters
-		var cookieData *tools.JWTSession = c.Env["token"].(*tools.JWTSession)
 		courseId, status, err := tools.ParseID(c.URLParams["id"])
 		if status != http.StatusOK {
 			api.renderer.JSON(w, status, err); return
 		}
 
-		// Does the user have enough access rights?
-		status, err = tools.VerifyAccess(courseId, cookieData.UserId, DeletePermission, models.DBPermissions.IsActionPermittedOnCourse)
-		if status != http.StatusOK {
-			api.renderer.JSON(w, status, err); return
-		}
-
 		// Process the action and Give the response
 		status, message := endpoints.DeleteCourse(courseId)
 		api.renderer.JSON(w, status, message)
@@ -0,0 +1,220 @@
+package endpoints
+
+import (
+	"fmt"
+	"net/http"
+	"mime/multipart"
+
+	"github.com/YagoCarballo/kumquat-academy-api/database/models"
+)
+
+// Posts an announcement to a module, or to one of its assignments when the assignment id is set
+func CreateModuleAnnouncement(userId uint32, moduleCode string, announcement models.Announcement) (int, map[string]interface{}) {
+	if announcement.AssignmentID != nil {
+		_, status, message := readModuleAssignment(moduleCode, *announcement.AssignmentID)
+		if status != http.StatusOK {
+			return status, message
+		}
+
+		announcement.ModuleCode = nil
+	} else {
+		announcement.ModuleCode = &moduleCode
+	}
+
+	announcement.CourseID = nil
+	return createAnnouncement(userId, announcement)
+}
+
+func CreateCourseAnnouncement(userId, courseId uint32, announcement models.Announcement) (int, map[string]interface{}) {
+	announcement.CourseID = &courseId
+	announcement.ModuleCode = nil
+	announcement.AssignmentID = nil
+
+	return createAnnouncement(userId, announcement)
+}
+
+func createAnnouncement(userId uint32, announcement models.Announcement) (int, map[string]interface{}) {
+	if announcement.Title == "" || announcement.Body == "" {
+		return http.StatusBadRequest, map[string]interface{}{
+			"error": "InvalidAnnouncement",
+			"message": "The announcement needs a title and a body.",
+		}
+	}
+
+	announcement.ID = 0
+	announcement.UserID = userId
+	announcement.Attachments = nil
+
+	dbAnnouncement, err := models.DBAnnouncements.CreateAnnouncement(announcement)
+	if err != nil {
+		return http.StatusConflict, map[string]interface{}{
+			"error": "Error creating the announcement.",
+		}
+	}
+
+	return http.StatusCreated, map[string]interface{}{
+		"message": "Announcement created successfully",
+		"announcement": dbAnnouncement,
+	}
+}
+
+func GetAnnouncement(announcementId uint32) (int, map[string]interface{}) {
+	announcement, err := models.DBAnnouncements.ReadAnnouncement(announcementId)
+	if err != nil || announcement == nil {
+		return http.StatusNotFound, map[string]interface{}{
+			"error": "NotFound",
+			"message": "Announcement not found.",
+		}
+	}
+
+	return http.StatusOK, map[string]interface{}{
+		"announcement": announcement,
+	}
+}
+
+func DeleteAnnouncement(announcementId uint32) (int, map[string]interface{}) {
+	rows, err := models.DBAnnouncements.DeleteAnnouncement(announcementId)
+	if err != nil || rows <= 0 {
+		return http.StatusExpectationFailed, map[string]interface{}{
+			"error": "Unknown",
+			"message": "Error deleting the announcement",
+		}
+	}
+
+	return http.StatusAccepted, map[string]interface{}{
+		"message": fmt.Sprintf("Announcement %d removed", announcementId),
+	}
+}
+
+func FindAnnouncementsForModule(moduleCode string, userId uint32) (int, map[string]interface{}) {
+	announcements, err := models.DBAnnouncements.FindAnnouncementsForModule(moduleCode, userId)
+	if err != nil {
+		return http.StatusNotFound, map[string]interface{}{
+			"error": "NotFound",
+			"message": "Announcements not found.",
+		}
+	}
+
+	return http.StatusOK, map[string]interface{}{
+		"announcements": announcements,
+	}
+}
+
+func FindAnnouncementsForCourse(courseId, userId uint32) (int, map[string]interface{}) {
+	announcements, err := models.DBAnnouncements.FindAnnouncementsForCourse(courseId, userId)
+	if err != nil {
+		return http.StatusNotFound, map[string]interface{}{
+			"error": "NotFound",
+			"message": "Announcements not found.",
+		}
+	}
+
+	return http.StatusOK, map[string]interface{}{
+		"announcements": announcements,
+	}
+}
+
+// Gets the feed of announcements of all the modules of a user
+func FindAnnouncementsForUser(username string, userId uint32, page int) (int, map[string]interface{}) {
+	announcements, err := models.DBAnnouncements.FindAnnouncementsForUser(username, userId, page)
+	if err != nil {
+		return http.StatusNotFound, map[string]interface{}{
+			"error": "NotFound",
+			"message": "Announcements not found.",
+		}
+	}
+
+	unread, err := models.DBAnnouncements.CountUnreadAnnouncements(username, userId)
+	if err != nil {
+		return http.StatusExpectationFailed, map[string]interface{}{
+			"error": "ExpectationFailed",
+			"message": "Error counting the unread announcements",
+		}
+	}
+
+	return http.StatusOK, map[string]interface{}{
+		"unread": unread,
+		"announcements": announcements,
+	}
+}
+
+func SetAnnouncementRead(username string, userId, announcementId uint32, read bool) (int, map[string]interface{}) {
+	if !models.DBAnnouncements.IsAnnouncementForUser(username, announcementId) {
+		return http.StatusNotFound, map[string]interface{}{
+			"error": "NotFound",
+			"message": "Announcement not found.",
+		}
+	}
+
+	var err error
+	if read {
+		_, err = models.DBAnnouncements.MarkAsRead(announcementId, userId)
+	} else {
+		_, err = models.DBAnnouncements.MarkAsUnread(announcementId, userId)
+	}
+	if err != nil {
+		return http.StatusExpectationFailed, map[string]interface{}{
+			"error": "ExpectationFailed",
+			"message": "Error updating the announcement",
+		}
+	}
+
+	return http.StatusOK, map[string]interface{}{
+		"message": "Announcement updated",
+		"read": read,
+	}
+}
+
+func UploadAnnouncementAttachment(userId, announcementId uint32, file multipart.File, header *multipart.FileHeader) (int, FileResponseMessage) {
+	announcement, err := models.DBAnnouncements.ReadAnnouncement(announcementId)
+	if err != nil || announcement == nil {
+		return http.StatusNotFound, FileResponseMessage{
+			Error: "NotFound",
+			Message: "Announcement not found.",
+		}
+	}
+
+	// Announcements of a course or of the whole site only count against the user
+	upload := Upload{ UserID: userId }
+	if announcement.ModuleCode != nil {
+		upload.ModuleCode = *announcement.ModuleCode
+	}
+
+	status, response := UploadFile(upload, file, header)
+	if status != http.StatusOK {
+		return status, response
+	}
+
+	count, err := models.DBAnnouncements.AddAttachmentToAnnouncement(announcementId, response.Attachment.ID)
+	if err != nil || count <= 0 {
+		return http.StatusExpectationFailed, FileResponseMessage{
+			Error: "Unknown",
+			Message: "Error adding the attachment to the announcement.",
+		}
+	}
+
+	return status, response
+}
+
+func RemoveAnnouncementAttachment(announcementId, attachmentId uint32) (int, map[string]interface{}) {
+	count, err := models.DBAnnouncements.RemoveAttachmentFromAnnouncement(announcementId, attachmentId)
+	if err != nil {
+		return http.StatusExpectationFailed, map[string]interface{}{
+			"error": "Unknown",
+			"message": "Error deleting the attachment from the announcement.",
+		}
+	}
+
+	if count <= 0 {
+		return http.StatusNotFound, map[string]interface{}{
+			"error": "NotFound",
+			"message": "An attachment with that Id was not found inside the announcement.",
+		}
+	}
+
+	removeAttachment(attachmentId)
+
+	return http.StatusOK, map[string]interface{}{
+		"message": "Attachment removed.",
+	}
+}
@@ -7,6 +7,8 @@ import (
 	"fmt"
 	"mime/multipart"
 	"os"
+	"path/filepath"
+	"strings"
 	"github.com/YagoCarballo/kumquat-academy-api/tools"
 	"github.com/wayn3h0/go-uuid"
 )
@@ -15,14 +17,34 @@ func CreateAssignment(
 	title, description string,
 	status models.AssignmentStatus,
 	weight float64,
+	teamAssignment bool,
+	lateDays uint32,
+	latePenalty float64,
+	allowedExtensions string,
 	start, end time.Time,
 	moduleCode string,
 ) (int, map[string]interface{}) {
+	// New assignments start as drafts unless they are published straight away
+	if status == "" {
+		status = models.AssignmentDraft
+	}
+
+	if status != models.AssignmentDraft && status != models.AssignmentCreated && status != models.AssignmentAvailable {
+		return http.StatusBadRequest, map[string]interface{}{
+			"error": "InvalidStatus",
+			"message": "New assignments must be draft, created or available.",
+		}
+	}
+
 	assignment := models.Assignment{
 		Title: title,
 		Description: description,
 		Status: status,
 		Weight: weight,
+		TeamAssignment: teamAssignment,
+		LateDays: lateDays,
+		LatePenalty: latePenalty,
+		AllowedExtensions: strings.Join(tools.SplitExtensions(allowedExtensions), ","),
 		Start: start,
 		End: end,
 		ModuleCode: moduleCode,
@@ -55,26 +77,74 @@ func GetAssignment(assignmentId uint32) (int, map[string]interface{}) {
 	}
 }
 
+// Updates an assignment, the team flag, late policy and allowed extensions are only changed when given (not nil)
 func UpdateAssignment(
 	assignmentId uint32,
 	title, description string,
 	status models.AssignmentStatus,
 	weight float64,
+	teamAssignment *bool,
+	lateDays *uint32,
+	latePenalty *float64,
+	allowedExtensions *string,
 	start, end time.Time,
 	moduleCode string,
 ) (int, map[string]interface{}) {
 
+	if status != "" && !status.IsValid() {
+		return http.StatusBadRequest, map[string]interface{}{
+			"error": "InvalidStatus",
+			"message": fmt.Sprintf("Unknown status '%s'.", status),
+		}
+	}
+
+	current, err := models.DBAssignments.ReadAssignment(assignmentId)
+	if err != nil || current == nil {
+		return http.StatusExpectationFailed, map[string]interface{}{
+			"error": "Unknown",
+			"message": "Assignment not updated.",
+		}
+	}
+
+	// The model always writes these fields, so the ones left out keep their current value
 	assignment := models.Assignment{
 		Title: title,
 		Description: description,
 		Status: status,
 		Weight: weight,
+		TeamAssignment: current.TeamAssignment,
+		LateDays: current.LateDays,
+		LatePenalty: current.LatePenalty,
+		AllowedExtensions: current.AllowedExtensions,
 		Start: start,
 		End: end,
 		ModuleCode: moduleCode,
 	}
 
+	if teamAssignment != nil {
+		assignment.TeamAssignment = *teamAssignment
+	}
+
+	if lateDays != nil {
+		assignment.LateDays = *lateDays
+	}
+
+	if latePenalty != nil {
+		assignment.LatePenalty = *latePenalty
+	}
+
+	if allowedExtensions != nil {
+		assignment.AllowedExtensions = strings.Join(tools.SplitExtensions(*allowedExtensions), ",")
+	}
+
 	dbAssignment, err := models.DBAssignments.UpdateAssignment(assignmentId, assignment)
+	if transitionErr, ok := err.(models.InvalidTransitionError); ok {
+		return http.StatusConflict, map[string]interface{}{
+			"error": "InvalidTransition",
+			"message": transitionErr.Error(),
+		}
+	}
+
 	if err != nil || dbAssignment == nil {
 		return http.StatusExpectationFailed, map[string]interface{}{
 			"error": "Unknown",
@@ -124,13 +194,10 @@ func FindAssignmentsForModule(username, code string, readOnly bool) (int, map[st
 	}
 }
 
-func UploadAssignmentAttachments(assignmentId uint32, file multipart.File, header *multipart.FileHeader) (int, FileResponseMessage) {
-	status, response := UploadFile(file, header)
+func UploadAssignmentAttachments(userId uint32, moduleCode string, assignmentId uint32, file multipart.File, header *multipart.FileHeader) (int, FileResponseMessage) {
+	status, response := UploadFile(Upload{ UserID: userId, ModuleCode: moduleCode }, file, header)
 	if status != http.StatusOK {
-		return http.StatusExpectationFailed, FileResponseMessage{
-			Error: "Unknown",
-			Message: "Error uploading the file.",
-		}
+		return status, response
 	}
 
 	count, err := models.DBAssignments.AddAttachmentToAssignment(assignmentId, response.Attachment.ID)
@@ -147,13 +214,6 @@ func UploadAssignmentAttachments(assignmentId uint32, file multipart.File, heade
 func RemoveAssignmentAttachments(assignmentId, attachmentId uint32) (int, map[string]interface{}) {
 	var err error
 
-	attachment, _ := models.DBAttachment.ReadAttachment(attachmentId)
-	if attachment != nil {
-		serverSettings := tools.GetSettings().Server
-		path := fmt.Sprintf("%s/%s", serverSettings.UploadsPath, attachment.Url)
-		os.Remove(path)
-	}
-
 	count, err := models.DBAssignments.RemoveAttachmentFromAssignment(assignmentId, attachmentId)
 	if err != nil {
 		return http.StatusExpectationFailed, map[string]interface{}{
@@ -169,14 +229,14 @@ func RemoveAssignmentAttachments(assignmentId, attachmentId uint32) (int, map[st
 		}
 	}
 
+	removeAttachment(attachmentId)
+
 	return http.StatusOK, map[string]interface{}{
 		"message": "Attachment removed.",
 	}
 }
 
 func SubmitAssignment(username, description string, assignmentId uint32, fileHeaders []*multipart.FileHeader) (int, map[string]interface{}) {
-	serverSettings := tools.GetSettings().Server
-
 	user, err := models.DBUser.FindUser(username)
 	if err != nil {
 		return http.StatusUnauthorized, map[string]interface{}{
@@ -185,6 +245,34 @@ func SubmitAssignment(username, description string, assignmentId uint32, fileHea
 		}
 	}
 
+	assignment, err := models.DBAssignments.ReadAssignment(assignmentId)
+	if err != nil || assignment == nil {
+		return http.StatusNotFound, map[string]interface{}{
+			"error": "NotFound",
+			"message": "Assignment not found.",
+		}
+	}
+
+	status, response := checkSubmittedFiles(*assignment, fileHeaders)
+	if status != http.StatusOK {
+		return status, response
+	}
+
+	// Team assignments are submitted by one member on behalf of the whole team
+	var team *models.Team
+	zipName := fmt.Sprintf("Assignment_%d_Student_%s.zip", assignmentId, user.MatricNumber)
+	if assignment.TeamAssignment {
+		team, err = models.DBTeams.FindTeamForUser(assignmentId, user.ID)
+		if err != nil || team == nil {
+			return http.StatusConflict, map[string]interface{}{
+				"error": "NoTeam",
+				"message": "You must be part of a team to submit this assignment",
+			}
+		}
+
+		zipName = fmt.Sprintf("Assignment_%d_Team_%d.zip", assignmentId, team.ID)
+	}
+
 	token, err := uuid.NewV4()
 	if err != nil {
 		return http.StatusExpectationFailed, map[string]interface{}{
@@ -193,8 +281,14 @@ func SubmitAssignment(username, description string, assignmentId uint32, fileHea
 		}
 	}
 
-	zipPath := fmt.Sprintf("%s/%s", serverSettings.UploadsPath, token.String())
+	zipPath := filepath.Join(os.TempDir(), token.String())
+	defer os.Remove(zipPath)
+
+	var zipInfo os.FileInfo
 	_, err = tools.ZipFiles(zipPath, fileHeaders)
+	if err == nil {
+		zipInfo, err = os.Stat(zipPath)
+	}
 	if err != nil {
 		fmt.Println(err)
 		return http.StatusExpectationFailed, map[string]interface{}{
@@ -203,10 +297,29 @@ func SubmitAssignment(username, description string, assignmentId uint32, fileHea
 		}
 	}
 
-	// Upload Info to DB
-	zipName := fmt.Sprintf("Assignment_%d_Student_%s.zip", assignmentId, user.MatricNumber)
-	attachment, err := models.DBAttachment.CreateAttachment(zipName, "application/zip", token.String())
+	// The submission counts against the quotas of the student and of the module
+	upload := Upload{ UserID: user.ID, ModuleCode: assignment.ModuleCode }
+	status, message := checkQuota(upload, zipInfo.Size())
+	if status != http.StatusOK {
+		return status, map[string]interface{}{
+			"error": message.Error,
+			"message": message.Message,
+		}
+	}
+
+	zipFile, err := os.Open(zipPath)
 	if err != nil {
+		fmt.Println(err)
+		return http.StatusExpectationFailed, map[string]interface{}{
+			"error": "ExpectationFailed",
+			"message": "Unable to package the uploaded files.",
+		}
+	}
+	defer zipFile.Close()
+
+	attachment, err := storeUpload(upload, zipName, "application/zip", zipFile)
+	if err != nil {
+		fmt.Println(err)
 		return http.StatusExpectationFailed, map[string]interface{}{
 			"error": "ExpectationFailed",
 			"message": "Error creating the attachment",
@@ -214,8 +327,21 @@ func SubmitAssignment(username, description string, assignmentId uint32, fileHea
 	}
 
 	// Register the submission into the Database
-	_, err = models.DBAssignments.SubmitAssignment(user.ID, assignmentId, attachment.ID, description)
+	if team != nil {
+		_, err = models.DBAssignments.SubmitTeamAssignment(team, assignmentId, attachment.ID, description)
+	} else {
+		_, err = models.DBAssignments.SubmitAssignment(user.ID, assignmentId, attachment.ID, description)
+	}
+	if err == models.ErrDeadlinePassed || err == models.ErrSubmissionLocked {
+		removeAttachment(attachment.ID)
+		return http.StatusConflict, map[string]interface{}{
+			"error": "Conflict",
+			"message": err.Error(),
+		}
+	}
+
 	if err != nil {
+		removeAttachment(attachment.ID)
 		return http.StatusExpectationFailed, map[string]interface{}{
 			"error": "ExpectationFailed",
 			"message": "Error registering the submission",
@@ -228,10 +354,65 @@ func SubmitAssignment(username, description string, assignmentId uint32, fileHea
 	}
 }
 
+// Checks the files of a submission against the limits, and against the extensions allowed by the assignment
+func checkSubmittedFiles(assignment models.Assignment, fileHeaders []*multipart.FileHeader) (int, map[string]interface{}) {
+	status, response := checkFileCount(len(fileHeaders))
+	if status != http.StatusOK {
+		return status, response
+	}
 
-func GradeAssignment(submissionId, grade uint32) (int, map[string]interface{}) {
-	submission, err := models.DBAssignments.GradeAssignment(submissionId, grade)
-	if err != nil {
+	upload := Upload{ Extensions: tools.SplitExtensions(assignment.AllowedExtensions) }
+	for _, fileHeader := range fileHeaders {
+		status, message := checkSize(upload, fileHeader.Filename, fileHeader.Size)
+		if status == http.StatusOK && !tools.HasExtension(fileHeader.Filename, upload.Extensions) {
+			status, message = http.StatusUnsupportedMediaType, FileResponseMessage{
+				Error: "UnsupportedType",
+				Message: fmt.Sprintf("Only %s files can be submitted.", strings.Join(upload.Extensions, ", ")),
+			}
+		}
+
+		if status != http.StatusOK {
+			return status, map[string]interface{}{
+				"error": message.Error,
+				"message": message.Message,
+			}
+		}
+	}
+
+	return http.StatusOK, nil
+}
+
+// Grades a submission of the assignment, scoring it against the rubric when there is one
+func GradeAssignment(moduleCode string, assignmentId, submissionId uint32, grading models.SubmissionGrading) (int, map[string]interface{}) {
+	_, status, message := readModuleAssignment(moduleCode, assignmentId)
+	if status != http.StatusOK {
+		return status, message
+	}
+
+	submission, err := models.DBAssignments.ReadSubmission(assignmentId, submissionId)
+	if err != nil || submission == nil {
+		return http.StatusNotFound, map[string]interface{}{
+			"error": "NotFound",
+			"message": "Submission not found.",
+		}
+	}
+
+	if submission.Status == models.SubmissionCanceled {
+		return http.StatusConflict, map[string]interface{}{
+			"error": "Conflict",
+			"message": "The submission was withdrawn.",
+		}
+	}
+
+	submission, err = models.DBAssignments.GradeSubmission(submissionId, grading)
+	if err == models.ErrInvalidScores || err == models.ErrInvalidGrade {
+		return http.StatusBadRequest, map[string]interface{}{
+			"error": "InvalidGrade",
+			"message": err.Error(),
+		}
+	}
+
+	if err != nil || submission == nil {
 		return http.StatusExpectationFailed, map[string]interface{}{
 			"error": "ExpectationFailed",
 			"message": "Error grading the assignment",
@@ -243,3 +424,16 @@ func GradeAssignment(submissionId, grade uint32) (int, map[string]interface{}) {
 		"submission": submission,
 	}
 }
+
+// Reads an assignment, making sure it belongs to the given module
+func readModuleAssignment(moduleCode string, assignmentId uint32) (*models.Assignment, int, map[string]interface{}) {
+	assignment, err := models.DBAssignments.ReadAssignment(assignmentId)
+	if err != nil || assignment == nil || assignment.ModuleCode != moduleCode {
+		return nil, http.StatusNotFound, map[string]interface{}{
+			"error": "NotFound",
+			"message": "Assignment not found.",
+		}
+	}
+
+	return assignment, http.StatusOK, nil
+}
@@ -1,17 +1,23 @@
 package endpoints
 
 import (
-	"os"
 	"io"
+	"log"
+	"time"
+	"net/url"
 	"net/http"
+	"crypto/rsa"
 	"mime/multipart"
 	"fmt"
 	"github.com/wayn3h0/go-uuid"
 	"github.com/YagoCarballo/kumquat-academy-api/database/models"
+	"github.com/YagoCarballo/kumquat-academy-api/storage"
 	"github.com/YagoCarballo/kumquat-academy-api/tools"
-	"bufio"
 )
 
+// How long a signed download link works for
+const DOWNLOAD_LINK_DURATION = 15 * time.Minute
+
 type (
 	MultipleFilesResponse struct  {
 		Status int `json:"status"`
@@ -32,54 +38,34 @@ type (
 	}
 )
 
-func UploadFile(file multipart.File, header *multipart.FileHeader) (int, FileResponseMessage) {
-	serverSettings := tools.GetSettings().Server
-	mimeType := header.Header.Get("Content-Type")
-
-	token, err := uuid.NewV4()
-	if err != nil {
-		return http.StatusExpectationFailed, FileResponseMessage{
-			Error: "ExpectationFailed",
-			Message: "Error when handling the uploaded file",
-		}
+// Checks the file against the limits of the upload, stores it and creates its attachment
+func UploadFile(upload Upload, file multipart.File, header *multipart.FileHeader) (int, FileResponseMessage) {
+	mimeType, status, message := checkUpload(upload, header.Filename, header.Size, file)
+	if status != http.StatusOK {
+		return status, message
 	}
 
-	path := fmt.Sprintf("%s/%s", serverSettings.UploadsPath, token.String())
-	out, err := os.Create(path)
-	defer out.Close()
+	attachment, err := storeUpload(upload, header.Filename, mimeType, file)
 	if err != nil {
 		fmt.Println(err)
-		return http.StatusUnauthorized, FileResponseMessage{
-			Error: "Unauthorized",
-			Message: "Unable to create the file for writing. Check your write access privilege",
-		}
-	}
-
-	// write the content from POST to the file
-	_, err = io.Copy(out, file)
-	if err != nil {
 		return http.StatusExpectationFailed, FileResponseMessage{
 			Error: "ExpectationFailed",
 			Message: "Error when handling the uploaded file",
 		}
 	}
 
-	// Upload Info to DB
-	attachment, err := models.DBAttachment.CreateAttachment(header.Filename, mimeType, token.String())
-	if err != nil {
-		return http.StatusExpectationFailed, FileResponseMessage{
-			Error: "ExpectationFailed",
-			Message: "Error creating the attachment",
-		}
-	}
-
 	return http.StatusOK, FileResponseMessage{
 		Message: "File uploaded successfully",
 		Attachment: attachment,
 	}
 }
 
-func UploadFiles(fileHeaders []*multipart.FileHeader) (int, map[string]interface{}) {
+func UploadFiles(upload Upload, fileHeaders []*multipart.FileHeader) (int, map[string]interface{}) {
+	status, response := checkFileCount(len(fileHeaders))
+	if status != http.StatusOK {
+		return status, response
+	}
+
 	output := MultipleFilesResponse{
 		Status: http.StatusOK,
 		Message: "Files uploaded Successfully.",
@@ -97,7 +83,7 @@ func UploadFiles(fileHeaders []*multipart.FileHeader) (int, map[string]interface
 			continue;
 		}
 
-		status, fileResponse := UploadFile(file, fileHeaders[index])
+		status, fileResponse := UploadFile(upload, file, fileHeaders[index])
 		output.Messages = append(output.Messages, FileResponse{ Status: status, Message: fileResponse })
 
 		if status != http.StatusOK {
@@ -115,34 +101,122 @@ func UploadFiles(fileHeaders []*multipart.FileHeader) (int, map[string]interface
 	}
 }
 
-func ServeFile(name string) (*string, *[]byte, error) {
-	serverSettings := tools.GetSettings().Server
+// Finds an attachment and opens its stored file, which is only read as it's sent
+func ServeFile(name string) (*models.Attachment, *storage.File, error) {
 	attachment, err := models.DBAttachment.FindAttachment(name)
 	if err != nil || attachment == nil {
 		return nil, nil, fmt.Errorf("404 -> Attachment not found.")
 	}
 
-	path := fmt.Sprintf("%s/%s", serverSettings.UploadsPath, attachment.Url)
-	file, err := os.Open(path)
-	defer file.Close()
+	store, err := storage.Default()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	file, err := storage.Open(store, attachment.StorageName())
 	if err != nil {
 		return nil, nil, fmt.Errorf("404 -> Attachment not found.")
 	}
 
-	fileInfo, _ := file.Stat()
-	var size int64 = fileInfo.Size()
-	bytes := make([]byte, size)
+	return attachment, file, nil
+}
+
+// Signs a link to download the attachment without a session, so it can be shared for a while
+func CreateDownloadLink(name string, privateKey *rsa.PrivateKey) (int, map[string]interface{}) {
+	attachment, err := models.DBAttachment.FindAttachment(name)
+	if err != nil || attachment == nil {
+		return http.StatusNotFound, map[string]interface{}{
+			"error": "NotFound",
+			"message": "Attachment not found.",
+		}
+	}
 
-	// read file into bytes
-	buffer := bufio.NewReader(file)
-	_, err = buffer.Read(bytes)
+	expiresIn := time.Now().Add(DOWNLOAD_LINK_DURATION)
+	token, err := tools.SignDownloadToken(attachment.Url, expiresIn, privateKey)
+	if err != nil {
+		return http.StatusExpectationFailed, map[string]interface{}{
+			"error": "ExpectationFailed",
+			"message": "Error signing the download link.",
+		}
+	}
+
+	api := tools.GetSettings().Api
+	return http.StatusOK, map[string]interface{}{
+		"url": fmt.Sprintf("%s/v%d/download/%s/%s", api.Prefix, api.Version, token, url.PathEscape(attachment.Name)),
+		"expires_in": expiresIn,
+	}
+}
+
+// Stores the content under its hash and creates its attachment.
+// Content already stored for another attachment is shared with it instead of being stored again.
+func storeUpload(upload Upload, name, mimeType string, content io.ReadSeeker) (*models.Attachment, error) {
+	hash, size, err := storage.HashContent(content)
+	if err != nil {
+		return nil, err
+	}
+
+	store, err := storage.Default()
+	if err != nil {
+		return nil, err
+	}
+
+	// Each attachment keeps its own token, so it is still downloaded (and checked) on its own
+	token, err := uuid.NewV4()
+	if err != nil {
+		return nil, err
+	}
+
+	attachment, newBlob, err := models.DBAttachment.CreateUpload(models.Attachment{
+		Name: name,
+		Type: mimeType,
+		Url: token.String(),
+		Size: size,
+		UserID: userIdOf(upload),
+		ModuleCode: moduleCodeOf(upload),
+		Hash: &hash,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// A shared file that went missing from the storage is stored again
+	if !newBlob {
+		_, err = store.Stat(hash)
+		if err != storage.ErrNotFound {
+			return attachment, err
+		}
+	}
+
+	err = store.Put(hash, content, size, mimeType)
+	if err != nil {
+		models.DBAttachment.DeleteAttachment(attachment.ID, nil)
+		return nil, err
+	}
+
+	return attachment, nil
+}
+
+// Deletes an attachment, its stored file is removed along with the last attachment using it.
+// Returns false when the attachment wasn't deleted, which is kept when its file can't be removed.
+func removeAttachment(attachmentId uint32) bool {
+	store, err := storage.Default()
+	if err != nil {
+		log.Printf("Error removing the attachment %d: %s\n", attachmentId, err)
+		return false
+	}
+
+	// The file is removed while the blob is locked, so an upload of the same content can't reuse it meanwhile
+	count, err := models.DBAttachment.DeleteAttachment(attachmentId, store.Delete)
+	if err != nil {
+		log.Printf("Error removing the attachment %d: %s\n", attachmentId, err)
+		return false
+	}
 
-	return &attachment.Type, &bytes, nil
+	return count > 0
 }
 
 func DeleteAttachment(attachmentId uint32) (int, map[string]interface{}) {
-	rows, err := models.DBAttachment.DeleteAttachment(attachmentId)
-	if err != nil || rows <= 0 {
+	if !removeAttachment(attachmentId) {
 		return http.StatusExpectationFailed, map[string]interface{}{
 			"error": "Unknown",
 			"message": "Error deleting the attachment",
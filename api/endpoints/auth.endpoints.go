@@ -1,18 +1,14 @@
 package endpoints
 
 import (
-	"fmt"
 	"log"
 	"net/http"
-	"net/smtp"
-	"net/textproto"
 
 	"golang.org/x/crypto/bcrypt"
 
 	"github.com/YagoCarballo/kumquat-academy-api/database/models"
+	"github.com/YagoCarballo/kumquat-academy-api/mailer"
 	"github.com/YagoCarballo/kumquat-academy-api/tools"
-
-	emailHandler "github.com/jordan-wright/email"
 )
 
 //
@@ -64,6 +60,7 @@ func SignIn(username, password, deviceId string) (int, map[string]interface{}, *
 		Device: 		session.DeviceID,
 		Username: 		dbUser.Username,
 		Admin:			dbUser.Admin,
+		RefreshToken:	session.RefreshToken,
 	}
 
 	modulesWithAccess, _ := models.DBUser.ListOfAreasWithWritePermissionForUser(dbUser.ID)
@@ -240,9 +237,8 @@ func ChangePassword(token, newPassword string) (int, map[string]interface{}) {
 	}
 }
 
-func ForgotPassword(email string) (int, map[string]interface{}) {
-	emailSettings := tools.GetSettings().Email
-
+// Sends the instructions to reset the password in the given language (the default one when empty)
+func ForgotPassword(email, language string) (int, map[string]interface{}) {
 	user, err := models.DBUser.FindUserWithEmail(email)
 	if err != nil || user == nil {
 		return http.StatusNotFound, map[string]interface{}{
@@ -259,49 +255,9 @@ func ForgotPassword(email string) (int, map[string]interface{}) {
 		}
 	}
 
-	resetUrl := fmt.Sprintf("%s%s", "https://kumquat.academy/password/reset/", *token)
-
-	plainText := fmt.Sprintf(`
-		A password reset has been triggered for your account. If you haven’t triggered this please ignore this email.\n
-		To reset your password open the following link and follow the instructions:\n\n
-		%s\n\n
-		Thanks,\n
-		Kumquat Academy Team\n
-	`, resetUrl);
-
-	htmlText := fmt.Sprintf(`
-    	<div style="word-wrap: break-word; -webkit-nbsp-mode: space; -webkit-line-break: after-white-space;">
-    		A password reset has been triggered for your account. If you haven’t triggered this please ignore this email.
-        	<br />
-        	<div>To reset your password open the following link and follow the instructions:</div>
-       		<br />
-			<div>
-				<a href="%s">
-					<span style="color: rgb(0, 0, 0);">Click Here to reset your password</span>
-				</a>
-			</div>
-			<br />
-			<br />
-			<p>Thanks,</p>
-			<p>Kumquat Academy Team</p>
-		</div>
-	`, resetUrl);
-
-
-	emailTemplate := &emailHandler.Email {
-		To: []string{ user.Email },
-		From: emailSettings.Sender,
-		Subject: "Password Reset",
-		Text: []byte(plainText),
-		HTML: []byte(htmlText),
-		Headers: textproto.MIMEHeader{},
-	}
-
-	smtpServer := fmt.Sprintf("%s:%d", emailSettings.Server, emailSettings.Port)
-	err = emailTemplate.Send(
-		smtpServer,
-		smtp.PlainAuth("", emailSettings.User, emailSettings.Password, emailSettings.Server),
-	)
+	_, err = mailer.Queue(user.Email, "reset_password", language, map[string]interface{}{
+		"Token": *token,
+	})
 
 	if err != nil {
 		return http.StatusExpectationFailed, map[string]interface{}{
@@ -24,6 +24,10 @@ var (
 func init() {
 	tools.LoadSettings(SETTINGS_PATH)
 	_, db = database.InitDatabase()
+
+	// Brings a fresh database to the current schema and loads the fixtures
+	database.Migrate()
+	models.Seed(false)
 }
 
 func Test_Auth(t *testing.T) {
@@ -93,6 +97,44 @@ func Test_Auth(t *testing.T) {
 		})
 	})
 
+	g.Describe("Manages the sessions of a user", func() {
+		g.It("Should refresh a session with the refresh token", func() {
+			g.Assert(sessionData.RefreshToken != "").IsTrue()
+
+			status, data, refreshed := RefreshSession(sessionData.RefreshToken)
+			g.Assert(status).Equal(http.StatusOK)
+			g.Assert(data["access_token"]).Equal(refreshed.AccessToken)
+			g.Assert(refreshed.AccessToken != sessionData.AccessToken).IsTrue()
+			g.Assert(refreshed.Device).Equal("127.0.0.1")
+
+			status, data, _ = RefreshSession(sessionData.RefreshToken)
+			g.Assert(status).Equal(http.StatusUnauthorized)
+			g.Assert(data["error"]).Equal("InvalidToken")
+
+			sessionData = refreshed
+		})
+
+		g.It("Should list the devices without their tokens", func() {
+			status, data := FindSessions(sessionData.UserId, sessionData.AccessToken)
+			g.Assert(status).Equal(http.StatusOK)
+
+			sessions := data["sessions"].([]map[string]interface{})
+			g.Assert(len(sessions) > 0).IsTrue()
+			for _, session := range sessions {
+				g.Assert(session["token"] == nil).IsTrue()
+				if session["device_id"] == "127.0.0.1" {
+					g.Assert(session["current"]).Equal(true)
+				}
+			}
+		})
+
+		g.It("Should fail to revoke a missing device", func() {
+			status, data := RevokeSession(sessionData.UserId, "-missing-")
+			g.Assert(status).Equal(http.StatusNotFound)
+			g.Assert(data["error"]).Equal("NotFound")
+		})
+	})
+
 	g.Describe("Logs out a user", func() {
 		g.It("Should get the User's Info", func() {
 			session, err := models.DBSession.FindSession(sessionData.AccessToken)
@@ -1,8 +1,8 @@
 package endpoints
 
 import (
+	"github.com/YagoCarballo/kumquat-academy-api/database"
 	"fmt"
-	"strings"
 	"net/http"
 	"github.com/YagoCarballo/kumquat-academy-api/database/models"
 	"time"
@@ -13,10 +13,9 @@ import (
 func CreateClass(courseId uint32, title string, start, end time.Time, levels []*models.CourseLevel) (int, map[string]interface{}) {
 	class, err := models.DBClass.CreateClass(courseId, title, start, end, levels)
 	if err != nil {
-		dbError := err.Error()
 		errorCode := "Unknown"
 		errorMessage := "Error creating the class."
-		duplicated := strings.HasPrefix(dbError, "Error 1062")
+		duplicated := database.IsDuplicatedError(err)
 		if duplicated {
 			errorCode = "Duplicated"
 			errorMessage = "There is already a class with that title."
@@ -32,10 +31,9 @@ func CreateClass(courseId uint32, title string, start, end time.Time, levels []*
 	for index, level := range levels {
 		dbLevel, err := models.DBLevel.CreateLevel(courseId, class.ID, level.Level, level.Start, level.End)
 		if err != nil {
-			dbError := err.Error()
 			errorCode := "Unknown"
 			errorMessage := "Error creating the level."
-			duplicated := strings.HasPrefix(dbError, "Error 1062")
+			duplicated := database.IsDuplicatedError(err)
 			if duplicated {
 				errorCode = "Duplicated"
 				errorMessage = fmt.Sprintf("This class already has the level %d", level.Level)
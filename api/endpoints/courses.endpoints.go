@@ -1,10 +1,10 @@
 package endpoints
 
 import (
+	"github.com/YagoCarballo/kumquat-academy-api/database"
 	"net/http"
 	"github.com/YagoCarballo/kumquat-academy-api/database/models"
 	"fmt"
-	"strings"
 )
 
 // CRUD
@@ -12,10 +12,9 @@ import (
 func CreateCourse(title, description string) (int, map[string]interface{}) {
 	course, err := models.DBCourse.CreateCourse(title, description)
 	if err != nil {
-		dbError := err.Error()
 		errorCode := "Unknown"
 		errorMessage := "Error creating the course."
-		duplicated := strings.HasPrefix(dbError, "Error 1062")
+		duplicated := database.IsDuplicatedError(err)
 		if duplicated {
 			errorCode = "Duplicated"
 			errorMessage = "There is already a course with that title."
@@ -0,0 +1,257 @@
+package endpoints
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"mime/multipart"
+
+	"github.com/YagoCarballo/kumquat-academy-api/database/models"
+)
+
+func CreateExam(exam models.Exam, moduleCode string) (int, map[string]interface{}) {
+	// The exam always belongs to the module in the URL
+	exam.ID = 0
+	exam.ModuleCode = moduleCode
+	exam.AttachmentID = 0
+
+	dbExam, err := models.DBExams.CreateExam(exam)
+	if err != nil {
+		return http.StatusConflict, map[string]interface{}{
+			"error": "Error creating the exam.",
+		}
+	}
+
+	return http.StatusCreated, map[string]interface{}{
+		"message": "Exam created successfully",
+		"exam": dbExam,
+	}
+}
+
+func GetExam(moduleCode string, examId uint32) (int, map[string]interface{}) {
+	exam, err := models.DBExams.ReadExam(moduleCode, examId)
+	if err != nil || exam == nil {
+		return http.StatusNotFound, map[string]interface{}{
+			"error": "NotFound",
+			"message": "Exam not found.",
+		}
+	}
+
+	return http.StatusOK, map[string]interface{}{
+		"exam": exam,
+	}
+}
+
+func UpdateExam(moduleCode string, examId uint32, exam models.Exam) (int, map[string]interface{}) {
+	dbExam, err := models.DBExams.UpdateExam(moduleCode, examId, exam)
+	if err != nil || dbExam == nil {
+		return http.StatusExpectationFailed, map[string]interface{}{
+			"error": "Unknown",
+			"message": "Exam not updated.",
+		}
+	}
+
+	return http.StatusOK, map[string]interface{}{
+		"exam": dbExam,
+	}
+}
+
+func DeleteExam(moduleCode string, examId uint32) (int, map[string]interface{}) {
+	rows, err := models.DBExams.DeleteExam(moduleCode, examId)
+	if err != nil || rows <= 0 {
+		return http.StatusExpectationFailed, map[string]interface{}{
+			"error": "Unknown",
+			"message": "Error deleting the exam",
+		}
+	}
+
+	return http.StatusAccepted, map[string]interface{}{
+		"message": fmt.Sprintf("Exam %d removed", examId),
+	}
+}
+
+func FindExamsForModule(moduleCode string) (int, map[string]interface{}) {
+	exams, err := models.DBExams.FindExamsForModule(moduleCode)
+	if err != nil {
+		return http.StatusNotFound, map[string]interface{}{
+			"error": "NotFound",
+			"message": "Exams not found.",
+		}
+	}
+
+	// Add all the weights to get a total weight
+	var totalWeight float64
+	for _, exam := range exams {
+		totalWeight += exam.Weight
+	}
+
+	return http.StatusOK, map[string]interface{}{
+		"total_weight": totalWeight,
+		"exams": exams,
+	}
+}
+
+func UploadExamAttachment(userId uint32, moduleCode string, examId uint32, file multipart.File, header *multipart.FileHeader) (int, FileResponseMessage) {
+	exam, err := models.DBExams.ReadExam(moduleCode, examId)
+	if err != nil || exam == nil {
+		return http.StatusNotFound, FileResponseMessage{
+			Error: "NotFound",
+			Message: "Exam not found.",
+		}
+	}
+
+	status, response := UploadFile(Upload{ UserID: userId, ModuleCode: moduleCode }, file, header)
+	if status != http.StatusOK {
+		return status, response
+	}
+
+	count, err := models.DBExams.SetExamAttachment(moduleCode, examId, response.Attachment.ID)
+	if err != nil || count <= 0 {
+		removeAttachment(response.Attachment.ID)
+		return http.StatusExpectationFailed, FileResponseMessage{
+			Error: "Unknown",
+			Message: "Error adding the attachment to the exam.",
+		}
+	}
+
+	// The paper it replaces only belonged to this exam, so its file is removed too
+	if exam.AttachmentID != 0 && exam.AttachmentID != response.Attachment.ID {
+		removeAttachment(exam.AttachmentID)
+	}
+
+	return status, response
+}
+
+func RemoveExamAttachment(moduleCode string, examId, attachmentId uint32) (int, map[string]interface{}) {
+	count, err := models.DBExams.RemoveExamAttachment(moduleCode, examId, attachmentId)
+	if err != nil {
+		return http.StatusExpectationFailed, map[string]interface{}{
+			"error": "Unknown",
+			"message": "Error deleting the attachment from the exam.",
+		}
+	}
+
+	if count <= 0 {
+		return http.StatusNotFound, map[string]interface{}{
+			"error": "NotFound",
+			"message": "An attachment with that Id was not found inside the exam.",
+		}
+	}
+
+	// The exam paper only belonged to this exam, so the file is removed too
+	removeAttachment(attachmentId)
+
+	return http.StatusOK, map[string]interface{}{
+		"message": "Attachment removed.",
+	}
+}
+
+func SetExamResult(moduleCode string, examId, userId uint32, grade float64, status models.ExamStatus) (int, map[string]interface{}) {
+	switch status {
+	case "":
+		status = models.ExamGraded
+	case models.ExamComplete, models.ExamReview, models.ExamGraded:
+	default:
+		return http.StatusBadRequest, map[string]interface{}{
+			"error": "InvalidStatus",
+			"message": fmt.Sprintf("'%s' is not a valid exam status.", status),
+		}
+	}
+
+	if math.IsNaN(grade) || grade < 0 || grade > 100 {
+		return http.StatusBadRequest, map[string]interface{}{
+			"error": "InvalidGrade",
+			"message": "The grade must be a number between 0 and 100.",
+		}
+	}
+
+	exam, err := models.DBExams.ReadExam(moduleCode, examId)
+	if err != nil || exam == nil {
+		return http.StatusNotFound, map[string]interface{}{
+			"error": "NotFound",
+			"message": "Exam not found.",
+		}
+	}
+
+	student, err := models.DBModule.GetModuleStudent(userId, moduleCode)
+	if err != nil || student == nil {
+		return http.StatusNotFound, map[string]interface{}{
+			"error": "NotFound",
+			"message": "That student is not enrolled in this module.",
+		}
+	}
+
+	result, err := models.DBExams.SetStudentResult(examId, userId, grade, status)
+	if err != nil {
+		return http.StatusExpectationFailed, map[string]interface{}{
+			"error": "ExpectationFailed",
+			"message": "Error saving the exam result",
+		}
+	}
+
+	return http.StatusOK, map[string]interface{}{
+		"message": "Exam result saved",
+		"result": result,
+	}
+}
+
+// Lists the results of an exam, or only the result of the given user when userId is not nil
+func FindExamResults(moduleCode string, examId uint32, userId *uint32) (int, map[string]interface{}) {
+	exam, err := models.DBExams.ReadExam(moduleCode, examId)
+	if err != nil || exam == nil {
+		return http.StatusNotFound, map[string]interface{}{
+			"error": "NotFound",
+			"message": "Exam not found.",
+		}
+	}
+
+	results := []models.StudentExam{}
+	if userId != nil {
+		result, err := models.DBExams.FindStudentResult(examId, *userId)
+		if err != nil {
+			return http.StatusExpectationFailed, map[string]interface{}{
+				"error": "ExpectationFailed",
+				"message": "Error reading the exam results",
+			}
+		}
+
+		if result != nil {
+			results = append(results, *result)
+		}
+	} else {
+		results, err = models.DBExams.FindResultsForExam(examId)
+		if err != nil {
+			return http.StatusExpectationFailed, map[string]interface{}{
+				"error": "ExpectationFailed",
+				"message": "Error reading the exam results",
+			}
+		}
+	}
+
+	return http.StatusOK, map[string]interface{}{
+		"exam": exam,
+		"results": results,
+	}
+}
+
+func RemoveExamResult(moduleCode string, examId, userId uint32) (int, map[string]interface{}) {
+	exam, err := models.DBExams.ReadExam(moduleCode, examId)
+	if err != nil || exam == nil {
+		return http.StatusNotFound, map[string]interface{}{
+			"error": "NotFound",
+			"message": "Exam not found.",
+		}
+	}
+
+	count, err := models.DBExams.RemoveStudentResult(examId, userId)
+	if err != nil || count <= 0 {
+		return http.StatusExpectationFailed, map[string]interface{}{
+			"error": "Unknown",
+			"message": "Error deleting the exam result",
+		}
+	}
+
+	return http.StatusAccepted, map[string]interface{}{
+		"message": fmt.Sprintf("Result of student %d removed", userId),
+	}
+}
@@ -0,0 +1,298 @@
+package endpoints
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/YagoCarballo/kumquat-academy-api/database/models"
+	"github.com/YagoCarballo/kumquat-academy-api/tools"
+)
+
+type (
+	// A row of an imported grades file that can't be applied
+	GradeImportError struct {
+		Row				int		`json:"row"`
+		MatricNumber	string	`json:"matric_number"`
+		Error			string	`json:"error"`
+		Message			string	`json:"message"`
+	}
+
+	// A row of an imported grades file that would replace an existing grade
+	GradeImportConflict struct {
+		Row				int		`json:"row"`
+		MatricNumber	string	`json:"matric_number"`
+		CurrentGrade	float64	`json:"current_grade"`
+		NewGrade		float64	`json:"new_grade"`
+	}
+
+	// A row of an imported grades file with a different grade than a teammate, a team gets a single grade
+	GradeImportTeamConflict struct {
+		Row				int		`json:"row"`
+		MatricNumber	string	`json:"matric_number"`
+		Grade			float64	`json:"grade"`
+		TeammateRow		int		`json:"teammate_row"`
+		TeammateGrade	float64	`json:"teammate_grade"`
+	}
+)
+
+// The first row of a team in an imported grades file, the one its grade is taken from
+type teamRow struct {
+	row		int
+	grade	float64
+}
+
+// The grade column holds the grade before late penalties, as it is the one read back when importing
+var gradesHeader = []string{ "matric_number", "first_name", "last_name", "status", "late", "grade", "penalty", "final_grade" }
+
+// Gets the gradebook of a module, when the user id is set only the released marks of that student are returned
+func FindGradebook(moduleCode string, userId *uint32) (int, map[string]interface{}) {
+	gradebook, err := models.DBGradebook.FindGradebook(moduleCode, userId != nil)
+	if err != nil || gradebook == nil {
+		return http.StatusNotFound, map[string]interface{}{
+			"error": "NotFound",
+			"message": "Gradebook not found.",
+		}
+	}
+
+	if userId != nil {
+		students := []models.StudentGrades{}
+		for _, student := range gradebook.Students {
+			if student.User.ID == *userId {
+				students = append(students, student)
+			}
+		}
+
+		gradebook.Students = students
+	}
+
+	return http.StatusOK, map[string]interface{}{
+		"gradebook": gradebook,
+	}
+}
+
+// Exports the students of an assignment with the status and grade of their submissions as CSV or XLSX
+func ExportAssignmentGrades(moduleCode string, assignmentId uint32, format string) (string, *[]byte, int, map[string]interface{}) {
+	assignment, status, message := readModuleAssignment(moduleCode, assignmentId)
+	if status != http.StatusOK {
+		return "", nil, status, message
+	}
+
+	if format != tools.SpreadsheetCSV && format != tools.SpreadsheetXLSX {
+		return "", nil, http.StatusBadRequest, map[string]interface{}{
+			"error": "InvalidFormat",
+			"message": "The grades can be exported as csv or xlsx.",
+		}
+	}
+
+	roster, err := models.DBGradebook.FindAssignmentRoster(moduleCode, assignment.ID)
+	if err != nil {
+		return "", nil, http.StatusNotFound, map[string]interface{}{
+			"error": "NotFound",
+			"message": "Students not found.",
+		}
+	}
+
+	rows := [][]string{ gradesHeader }
+	for _, entry := range roster {
+		submissionStatus, late, grade, penalty, finalGrade := "not submitted", "", "", "", ""
+		if entry.Submission != nil {
+			submissionStatus = string(entry.Submission.Status)
+			late = strconv.FormatBool(entry.Submission.Late)
+			if entry.Submission.GradedOn != nil {
+				submissionStatus = string(models.SubmissionGraded)
+				grade = strconv.FormatFloat(entry.Submission.RawGrade, 'f', -1, 64)
+				penalty = strconv.FormatFloat(entry.Submission.Penalty, 'f', -1, 64)
+				finalGrade = strconv.FormatFloat(entry.Submission.Grade, 'f', -1, 64)
+			}
+		}
+
+		rows = append(rows, []string{ entry.User.MatricNumber, entry.User.FirstName, entry.User.LastName, submissionStatus, late, grade, penalty, finalGrade })
+	}
+
+	content, err := tools.WriteSpreadsheet(format, assignment.Title, rows)
+	if err != nil {
+		return "", nil, http.StatusExpectationFailed, map[string]interface{}{
+			"error": "ExpectationFailed",
+			"message": "Unable to export the grades.",
+		}
+	}
+
+	return fmt.Sprintf("%s-assignment-%d-grades.%s", moduleCode, assignment.ID, format), &content, http.StatusOK, nil
+}
+
+// Grades the submissions of an assignment from an exported (and edited) grades file.
+// Nothing is saved on a dry run, or when a row has errors, or when a grade would be replaced without overwrite.
+// The members of a team get a single grade, so nothing is saved either when they have different grades in the file.
+func ImportAssignmentGrades(moduleCode string, assignmentId uint32, fileName string, content []byte, dryRun, overwrite bool) (int, map[string]interface{}) {
+	assignment, status, message := readModuleAssignment(moduleCode, assignmentId)
+	if status != http.StatusOK {
+		return status, message
+	}
+
+	rows, err := tools.ReadSpreadsheet(fileName, content)
+	if err != nil || len(rows) <= 0 {
+		return http.StatusBadRequest, map[string]interface{}{
+			"error": "InvalidFile",
+			"message": "The file is not a valid CSV or XLSX file.",
+		}
+	}
+
+	// Find the columns from the header row
+	matricColumn, gradeColumn := -1, -1
+	for index, column := range rows[0] {
+		switch strings.ToLower(strings.TrimSpace(column)) {
+		case "matric_number":
+			matricColumn = index
+		case "grade":
+			gradeColumn = index
+		}
+	}
+
+	if matricColumn < 0 || gradeColumn < 0 {
+		return http.StatusBadRequest, map[string]interface{}{
+			"error": "InvalidFile",
+			"message": "The file needs a matric_number and a grade column.",
+		}
+	}
+
+	roster, err := models.DBGradebook.FindAssignmentRoster(moduleCode, assignment.ID)
+	if err != nil {
+		return http.StatusNotFound, map[string]interface{}{
+			"error": "NotFound",
+			"message": "Students not found.",
+		}
+	}
+
+	students := map[string]models.RosterEntry{}
+	for _, entry := range roster {
+		students[entry.User.MatricNumber] = entry
+	}
+
+	errors := []GradeImportError{}
+	conflicts := []GradeImportConflict{}
+	teamConflicts := []GradeImportTeamConflict{}
+	teams := map[uint32]teamRow{}
+	grades := map[uint32]float64{}
+	seen := map[string]bool{}
+	unchanged := 0
+
+	for index, row := range rows[1:] {
+		rowNumber := index + 2
+		matricNumber := cellValue(row, matricColumn)
+		gradeValue := cellValue(row, gradeColumn)
+
+		if matricNumber == "" && gradeValue == "" {
+			continue
+		}
+
+		entry, ok := students[matricNumber]
+		if !ok {
+			errors = append(errors, GradeImportError{ rowNumber, matricNumber, "UnknownMatricNumber", "No student of the module has this matric number." })
+			continue
+		}
+
+		if seen[matricNumber] {
+			errors = append(errors, GradeImportError{ rowNumber, matricNumber, "DuplicatedMatricNumber", "The student appears more than once in the file." })
+			continue
+		}
+		seen[matricNumber] = true
+
+		// Rows without a grade are left as they are
+		if gradeValue == "" {
+			continue
+		}
+
+		grade, err := strconv.ParseFloat(gradeValue, 64)
+		if err != nil || math.IsNaN(grade) || grade < 0 || grade > 100 {
+			errors = append(errors, GradeImportError{ rowNumber, matricNumber, "InvalidGrade", "The grade must be a number between 0 and 100." })
+			continue
+		}
+
+		if entry.Submission == nil {
+			errors = append(errors, GradeImportError{ rowNumber, matricNumber, "MissingSubmission", "The student hasn't submitted the assignment." })
+			continue
+		}
+
+		// The members of a team share the grade, so only the first row of a team is applied
+		if entry.Submission.TeamID != nil {
+			first, found := teams[*entry.Submission.TeamID]
+			if found {
+				if first.grade != grade {
+					teamConflicts = append(teamConflicts, GradeImportTeamConflict{ rowNumber, matricNumber, grade, first.row, first.grade })
+				}
+				continue
+			}
+
+			teams[*entry.Submission.TeamID] = teamRow{ rowNumber, grade }
+		}
+
+		if entry.Submission.GradedOn != nil {
+			if entry.Submission.RawGrade == grade {
+				unchanged++
+				continue
+			}
+
+			conflicts = append(conflicts, GradeImportConflict{ rowNumber, matricNumber, entry.Submission.RawGrade, grade })
+			if !overwrite {
+				continue
+			}
+		}
+
+		grades[entry.Submission.ID] = grade
+	}
+
+	report := map[string]interface{}{
+		"dry_run": dryRun,
+		"rows": len(rows) - 1,
+		"grades": len(grades),
+		"unchanged": unchanged,
+		"errors": errors,
+		"conflicts": conflicts,
+		"team_conflicts": teamConflicts,
+	}
+
+	if dryRun {
+		report["message"] = "Nothing was saved, this was a dry run."
+		return http.StatusOK, report
+	}
+
+	if len(errors) > 0 {
+		report["error"] = "InvalidGrades"
+		report["message"] = "Some rows have errors, nothing was saved."
+		return http.StatusUnprocessableEntity, report
+	}
+
+	if len(teamConflicts) > 0 {
+		report["error"] = "ConflictingTeamGrades"
+		report["message"] = "Some members of a team have different grades, nothing was saved."
+		return http.StatusConflict, report
+	}
+
+	if len(conflicts) > 0 && !overwrite {
+		report["error"] = "ConflictingGrades"
+		report["message"] = "Some students already have a different grade, nothing was saved."
+		return http.StatusConflict, report
+	}
+
+	_, err = models.DBGradebook.GradeSubmissions(assignment.ID, grades)
+	if err != nil {
+		return http.StatusExpectationFailed, map[string]interface{}{
+			"error": "ExpectationFailed",
+			"message": "Error grading the submissions, nothing was saved.",
+		}
+	}
+
+	report["message"] = "Grades imported"
+	return http.StatusOK, report
+}
+
+func cellValue(row []string, column int) string {
+	if column >= len(row) {
+		return ""
+	}
+
+	return strings.TrimSpace(row[column])
+}
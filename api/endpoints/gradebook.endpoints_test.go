@@ -0,0 +1,131 @@
+package endpoints
+
+import (
+	"testing"
+	"net/http"
+	"time"
+
+	. "github.com/franela/goblin"
+
+	"github.com/YagoCarballo/kumquat-academy-api/database/models"
+	"github.com/YagoCarballo/kumquat-academy-api/tools"
+)
+
+func Test_Gradebook(t *testing.T) {
+	g := Goblin(t)
+	var submissionId uint32
+
+	// Disable Verbose Logger
+	db.LogMode(false)
+
+	g.Describe("Tests the grades import and export endpoints", func() {
+		g.Before(func() {
+			attachment, _ := models.DBAttachment.CreateAttachment("cluster.zip", "application/zip", "gradebook-endpoint-token")
+			submission, _ := models.DBAssignments.SubmitAssignment(3, 1, attachment.ID, "My cluster")
+			submissionId = submission.ID
+		})
+
+		g.It("Should export the students of the assignment", func() {
+			name, content, status, _ := ExportAssignmentGrades("AC31007", 1, tools.SpreadsheetCSV)
+			g.Assert(status).Equal(http.StatusOK)
+			g.Assert(name).Equal("AC31007-assignment-1-grades.csv")
+
+			rows, err := tools.ReadSpreadsheet(name, *content)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(rows[0][0]).Equal("matric_number")
+			g.Assert(len(rows)).Equal(3)
+		})
+
+		g.It("Should not export an assignment from another module", func() {
+			_, _, status, _ := ExportAssignmentGrades("AC22001", 1, tools.SpreadsheetCSV)
+			g.Assert(status).Equal(http.StatusNotFound)
+		})
+
+		g.It("Should report the invalid rows on a dry run", func() {
+			content := []byte("matric_number,grade\n130000003,75\n999999999,50\n130000005,40\n130000003,101\n")
+
+			status, report := ImportAssignmentGrades("AC31007", 1, "grades.csv", content, true, false)
+			g.Assert(status).Equal(http.StatusOK)
+			g.Assert(report["grades"]).Equal(1)
+
+			errors := report["errors"].([]GradeImportError)
+			g.Assert(len(errors)).Equal(3)
+			g.Assert(errors[0].Error).Equal("UnknownMatricNumber")
+			g.Assert(errors[1].Error).Equal("MissingSubmission")
+			g.Assert(errors[2].Error).Equal("DuplicatedMatricNumber")
+
+			roster, _ := models.DBGradebook.FindAssignmentRoster("AC31007", 1)
+			for _, entry := range roster {
+				if entry.User.ID == 3 {
+					g.Assert(entry.Submission.GradedOn == nil).IsTrue()
+				}
+			}
+		})
+
+		g.It("Should not save anything when a row has errors", func() {
+			content := []byte("matric_number,grade\n130000003,75\n130000005,40\n")
+
+			status, _ := ImportAssignmentGrades("AC31007", 1, "grades.csv", content, false, true)
+			g.Assert(status).Equal(http.StatusUnprocessableEntity)
+		})
+
+		g.It("Should only replace existing grades when overwriting", func() {
+			models.DBAssignments.GradeAssignment(submissionId, 60)
+			content := []byte("matric_number,grade\n130000003,75\n130000005,\n")
+
+			status, report := ImportAssignmentGrades("AC31007", 1, "grades.csv", content, false, false)
+			g.Assert(status).Equal(http.StatusConflict)
+			g.Assert(len(report["conflicts"].([]GradeImportConflict))).Equal(1)
+
+			status, _ = ImportAssignmentGrades("AC31007", 1, "grades.csv", content, false, true)
+			g.Assert(status).Equal(http.StatusOK)
+
+			roster, _ := models.DBGradebook.FindAssignmentRoster("AC31007", 1)
+			for _, entry := range roster {
+				if entry.User.ID == 3 {
+					g.Assert(entry.Submission.Grade).Equal(float64(75))
+				}
+			}
+		})
+
+		g.It("Should give a single grade to the members of a team", func() {
+			// The graded submission of the student would stop the team from submitting
+			db.Where("id = ?", submissionId).Delete(models.Submission{})
+
+			// The deadline of the assignment has passed, so the team needs an extension to submit
+			for _, userId := range []uint32{ 3, 5 } {
+				models.DBAssignments.GrantExtension(models.AssignmentExtension{ AssignmentID: 1, UserID: userId, End: time.Now().AddDate(0, 0, 1), GrantedBy: 2 })
+			}
+
+			team, _ := models.DBTeams.CreateTeam(1, "Gradebook Team", []uint32{ 3, 5 })
+			attachment, _ := models.DBAttachment.CreateAttachment("team.zip", "application/zip", "gradebook-team-token")
+			_, err := models.DBAssignments.SubmitTeamAssignment(team, 1, attachment.ID, "Our cluster")
+			g.Assert(err == nil).IsTrue()
+
+			content := []byte("matric_number,grade\n130000003,70\n130000005,80\n")
+			status, report := ImportAssignmentGrades("AC31007", 1, "grades.csv", content, true, false)
+			g.Assert(status).Equal(http.StatusOK)
+
+			teamConflicts := report["team_conflicts"].([]GradeImportTeamConflict)
+			g.Assert(len(teamConflicts)).Equal(1)
+			g.Assert(teamConflicts[0].TeammateRow).Equal(2)
+
+			status, _ = ImportAssignmentGrades("AC31007", 1, "grades.csv", content, false, true)
+			g.Assert(status).Equal(http.StatusConflict)
+
+			content = []byte("matric_number,grade\n130000003,70\n130000005,70\n")
+			status, report = ImportAssignmentGrades("AC31007", 1, "grades.csv", content, false, true)
+			g.Assert(status).Equal(http.StatusOK)
+			g.Assert(report["grades"]).Equal(1)
+
+			db.Where("team_id = ?", team.ID).Delete(models.Submission{})
+			models.DBTeams.DeleteTeam(1, team.ID)
+			models.DBAssignments.RevokeExtension(1, 3)
+			models.DBAssignments.RevokeExtension(1, 5)
+		})
+
+		g.After(func() {
+			db.Where("id = ?", submissionId).Delete(models.Submission{})
+		})
+	})
+}
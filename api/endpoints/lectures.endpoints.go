@@ -7,7 +7,6 @@ import (
 	"github.com/YagoCarballo/kumquat-academy-api/database/models"
 	"github.com/YagoCarballo/kumquat-academy-api/tools"
 	"mime/multipart"
-	"os"
 )
 
 // CRUD
@@ -112,16 +111,58 @@ func FindLectureWeeksForModule(moduleId string) (int, map[string]interface{}) {
 	}
 }
 
-func FindLectureWeeksAndSlotsForModule(moduleCode string) (int, map[string]interface{}) {
-	// Create the Weeks Array
-	weeks := []map[string]interface{}{}
+type moduleWeek struct {
+	Number	uint32
+	Start	time.Time
+	End		time.Time
+}
+
+// Splits the duration of a module into weeks, starting on the Monday of the week the module starts
+func findModuleWeeks(levelModule *models.LevelModule) []moduleWeek {
+	weeks := []moduleWeek{}
 
 	// Get the GMT Timezone to use as base
 	gmt := time.FixedZone("GMT", 0)
 
+	// Get the duration of the Day
+	dayDuration := 23 * time.Hour + 59 * time.Minute
+
+	// Get the Module Start Date
+	startYear, startWeek := levelModule.Start.ISOWeek()
+	parsedStart := tools.FirstDayOfISOWeek(startYear, startWeek, gmt)
+
+	// Get the Course End Year
+	endYear, endWeek := levelModule.Start.AddDate(0, 0, int(levelModule.Module.Duration * 7)).ISOWeek()
+	parsedEnd := tools.FirstDayOfISOWeek(endYear, endWeek, gmt).AddDate(0, 0, 6)
+
+	// Loop through each week
+	var count uint32
+	for current := parsedStart; current.Before(parsedEnd); current = current.AddDate(0, 0, 7) {
+		count++;
+
+		// Get the start of the Day and end of the Day
+		weeks = append(weeks, moduleWeek{
+			Number: count,
+			Start: current,
+			End: current.AddDate(0, 0, 6).Add(dayDuration),
+		})
+
+		// Once the duration is reached, Stop
+		if count >= levelModule.Module.Duration {
+			break;
+		}
+	}
+
+	return weeks
+}
+
+func FindLectureWeeksAndSlotsForModule(moduleCode string) (int, map[string]interface{}) {
+	// Create the Weeks Array
+	weeks := []map[string]interface{}{}
+
 	// Get the Module Information
 	levelModule, err := models.DBModule.FindModuleWithCode(moduleCode)
-	if err != nil {
+	if err != nil || levelModule == nil {
 		return http.StatusNotFound, map[string]interface{}{
 			"error": "Unknown",
 			"message": "Error fetching the module",
@@ -137,47 +178,18 @@ func FindLectureWeeksAndSlotsForModule(moduleCode string) (int, map[string]inter
 		}
 	}
 
-	// Get the duration of the Day
-	dayDuration, err := time.ParseDuration("23h59m")
-	if err != nil {
-		return http.StatusNotFound, map[string]interface{}{
-			"error": "Unknown",
-			"message": "Error parsing dates.",
-		}
-	}
-
-	// Get the Module Start Date
-	startYear, startWeek := levelModule.Start.ISOWeek()
-	parsedStart := tools.FirstDayOfISOWeek(startYear, startWeek, gmt)
-
-	// Get the Course End Year
-	endYear, endWeek := levelModule.Start.AddDate(0, 0, int(levelModule.Module.Duration * 7)).ISOWeek()
-	parsedEnd := tools.FirstDayOfISOWeek(endYear, endWeek, gmt).AddDate(0, 0, 6)
-
 	// Loop through each week
-	count := 0
-	for current := parsedStart; current.Before(parsedEnd); current = current.AddDate(0, 0, 7) {
-		count++;
-
-		// Get the start of the Day and end of the Day
-		weekStart := current
-		weekEnd := current.AddDate(0, 0, 6).Add(dayDuration)
-
+	for _, week := range findModuleWeeks(levelModule) {
 		// Get the lectures for the week and group them into week days
-		lectures, _ := models.DBLecture.FindLecturesForModuleInRange(moduleCode, &weekStart, &weekEnd, -1);
+		lectures, _ := models.DBLecture.FindLecturesForModuleInRange(moduleCode, &week.Start, &week.End, -1);
 		lecturesMap := models.GroupLecturesInWeeks(&lectures)
 
 		weeks = append(weeks, map[string]interface{}{
-			"week": count,
-			"start": weekStart,
-			"end": weekEnd,
+			"week": week.Number,
+			"start": week.Start,
+			"end": week.End,
 			"lectures": lecturesMap,
 		})
-
-		// Once the duration is reached, Stop
-		if count >= int(levelModule.Module.Duration) {
-			break;
-		}
 	}
 
 	return http.StatusOK, map[string]interface{}{
@@ -187,13 +199,10 @@ func FindLectureWeeksAndSlotsForModule(moduleCode string) (int, map[string]inter
 }
 
 
-func UploadLectureAttachments(lectureId uint32, file multipart.File, header *multipart.FileHeader) (int, FileResponseMessage) {
-	status, response := UploadFile(file, header)
+func UploadLectureAttachments(userId uint32, moduleCode string, lectureId uint32, file multipart.File, header *multipart.FileHeader) (int, FileResponseMessage) {
+	status, response := UploadFile(Upload{ UserID: userId, ModuleCode: moduleCode }, file, header)
 	if status != http.StatusOK {
-		return http.StatusExpectationFailed, FileResponseMessage{
-			Error: "Unknown",
-			Message: "Error uploading the file.",
-		}
+		return status, response
 	}
 
 	count, err := models.DBLecture.AddAttachmentToLecture(lectureId, response.Attachment.ID)
@@ -210,13 +219,6 @@ func UploadLectureAttachments(lectureId uint32, file multipart.File, header *mul
 func RemoveLectureAttachments(lectureId, attachmentId uint32) (int, map[string]interface{}) {
 	var err error
 
-	attachment, _ := models.DBAttachment.ReadAttachment(attachmentId)
-	if attachment != nil {
-		serverSettings := tools.GetSettings().Server
-		path := fmt.Sprintf("%s/%s", serverSettings.UploadsPath, attachment.Url)
-		os.Remove(path)
-	}
-
 	count, err := models.DBLecture.RemoveAttachmentFromLecture(lectureId, attachmentId)
 	if err != nil {
 		return http.StatusExpectationFailed, map[string]interface{}{
@@ -232,6 +234,8 @@ func RemoveLectureAttachments(lectureId, attachmentId uint32) (int, map[string]i
 		}
 	}
 
+	removeAttachment(attachmentId)
+
 	return http.StatusOK, map[string]interface{}{
 		"message": "Attachment removed.",
 	}
@@ -1,10 +1,10 @@
 package endpoints
 
 import (
+	"github.com/YagoCarballo/kumquat-academy-api/database"
 	"net/http"
 	"github.com/YagoCarballo/kumquat-academy-api/database/models"
 	"fmt"
-	"strings"
 	"time"
 )
 
@@ -13,10 +13,9 @@ import (
 func CreateLevel(lvl, classId, courseId uint32, start, end time.Time) (int, map[string]interface{}) {
 	level, err := models.DBLevel.CreateLevel(courseId, classId, lvl, start, end)
 	if err != nil {
-		dbError := err.Error()
 		errorCode := "Unknown"
 		errorMessage := "Error creating the level."
-		duplicated := strings.HasPrefix(dbError, "Error 1062")
+		duplicated := database.IsDuplicatedError(err)
 		if duplicated {
 			errorCode = "Duplicated"
 			errorMessage = "There is already a level in that class."
@@ -78,10 +77,9 @@ func DeleteLevel(courseId, classId, lvl uint32) (int, map[string]interface{}) {
 func AddModuleToLevel(code string, lvl, classId, moduleId uint32, start time.Time) (int, map[string]interface{}) {
 	levelModule, err := models.DBLevel.AddModule(code, lvl, classId, moduleId, start)
 	if err != nil {
-		dbError := err.Error()
 		errorCode := "Unknown"
 		errorMessage := "Error adding the module to the level."
-		duplicated := strings.HasPrefix(dbError, "Error 1062")
+		duplicated := database.IsDuplicatedError(err)
 		if duplicated {
 			errorCode = "Duplicated"
 			errorMessage = "That module is already in that level"
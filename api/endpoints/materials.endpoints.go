@@ -0,0 +1,449 @@
+package endpoints
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/wayn3h0/go-uuid"
+	"github.com/YagoCarballo/kumquat-academy-api/database/models"
+	"github.com/YagoCarballo/kumquat-academy-api/storage"
+	"github.com/YagoCarballo/kumquat-academy-api/tools"
+)
+
+type materialsWeek struct {
+	moduleWeek
+	Lectures	[]models.Lecture
+	Materials	[]models.Materials
+}
+
+func CreateMaterial(moduleCode string, material models.Materials) (int, map[string]interface{}) {
+	levelModule, err := models.DBModule.FindModuleWithCode(moduleCode)
+	if err != nil || levelModule == nil {
+		return http.StatusNotFound, map[string]interface{}{
+			"error": "NotFound",
+			"message": "Module not found.",
+		}
+	}
+
+	status, message := validateMaterial(levelModule, &material)
+	if status != http.StatusOK {
+		return status, message
+	}
+
+	// The material always belongs to the module in the URL, files are uploaded separately
+	material.ID = 0
+	material.ModuleID = levelModule.ModuleID
+	material.AttachmentID = nil
+	material.Attachment = nil
+
+	dbMaterial, err := models.DBMaterials.CreateMaterial(material)
+	if err != nil {
+		return http.StatusConflict, map[string]interface{}{
+			"error": "Error creating the material.",
+		}
+	}
+
+	return http.StatusCreated, map[string]interface{}{
+		"message": "Material created successfully",
+		"material": dbMaterial,
+	}
+}
+
+func GetMaterial(moduleCode string, materialId uint32) (int, map[string]interface{}) {
+	moduleId, status, message := findModuleId(moduleCode)
+	if status != http.StatusOK {
+		return status, message
+	}
+
+	material, err := models.DBMaterials.ReadMaterial(moduleId, materialId)
+	if err != nil || material == nil {
+		return http.StatusNotFound, map[string]interface{}{
+			"error": "NotFound",
+			"message": "Material not found.",
+		}
+	}
+
+	return http.StatusOK, map[string]interface{}{
+		"material": material,
+	}
+}
+
+func UpdateMaterial(moduleCode string, materialId uint32, material models.Materials) (int, map[string]interface{}) {
+	levelModule, err := models.DBModule.FindModuleWithCode(moduleCode)
+	if err != nil || levelModule == nil {
+		return http.StatusNotFound, map[string]interface{}{
+			"error": "NotFound",
+			"message": "Module not found.",
+		}
+	}
+
+	status, message := validateMaterial(levelModule, &material)
+	if status != http.StatusOK {
+		return status, message
+	}
+
+	dbMaterial, err := models.DBMaterials.UpdateMaterial(levelModule.ModuleID, materialId, material)
+	if err != nil || dbMaterial == nil {
+		return http.StatusExpectationFailed, map[string]interface{}{
+			"error": "Unknown",
+			"message": "Material not updated.",
+		}
+	}
+
+	return http.StatusOK, map[string]interface{}{
+		"material": dbMaterial,
+	}
+}
+
+// Deletes a material along with its file
+func DeleteMaterial(moduleCode string, materialId uint32) (int, map[string]interface{}) {
+	moduleId, status, message := findModuleId(moduleCode)
+	if status != http.StatusOK {
+		return status, message
+	}
+
+	material, err := models.DBMaterials.ReadMaterial(moduleId, materialId)
+	if err != nil || material == nil {
+		return http.StatusNotFound, map[string]interface{}{
+			"error": "NotFound",
+			"message": "Material not found.",
+		}
+	}
+
+	rows, err := models.DBMaterials.DeleteMaterial(moduleId, materialId)
+	if err != nil || rows <= 0 {
+		return http.StatusExpectationFailed, map[string]interface{}{
+			"error": "Unknown",
+			"message": "Error deleting the material",
+		}
+	}
+
+	removeMaterialAttachment(material)
+
+	return http.StatusAccepted, map[string]interface{}{
+		"message": fmt.Sprintf("Material %d removed", materialId),
+	}
+}
+
+// Uploads the file of a material, replacing the previous one
+func UploadMaterialAttachment(userId uint32, moduleCode string, materialId uint32, file multipart.File, header *multipart.FileHeader) (int, FileResponseMessage) {
+	moduleId, status, _ := findModuleId(moduleCode)
+	if status != http.StatusOK {
+		return status, FileResponseMessage{
+			Error: "NotFound",
+			Message: "Module not found.",
+		}
+	}
+
+	material, err := models.DBMaterials.ReadMaterial(moduleId, materialId)
+	if err != nil || material == nil {
+		return http.StatusNotFound, FileResponseMessage{
+			Error: "NotFound",
+			Message: "Material not found.",
+		}
+	}
+
+	if material.Type == models.MaterialVideo {
+		return http.StatusConflict, FileResponseMessage{
+			Error: "InvalidType",
+			Message: "Videos are added as links, not as files.",
+		}
+	}
+
+	status, response := UploadFile(Upload{ UserID: userId, ModuleCode: moduleCode }, file, header)
+	if status != http.StatusOK {
+		return status, response
+	}
+
+	count, err := models.DBMaterials.SetMaterialAttachment(moduleId, materialId, response.Attachment.ID)
+	if err != nil || count <= 0 {
+		return http.StatusExpectationFailed, FileResponseMessage{
+			Error: "Unknown",
+			Message: "Error adding the file to the material.",
+		}
+	}
+
+	removeMaterialAttachment(material)
+
+	return status, response
+}
+
+// Gets the materials library of a module, split into the weeks of the module.
+// Materials of a lecture are listed inside the lecture, the rest are listed in their week.
+func FindMaterialsForModule(moduleCode string, materialType models.MaterialType) (int, map[string]interface{}) {
+	if materialType != "" && !materialType.IsValid() {
+		return http.StatusBadRequest, map[string]interface{}{
+			"error": "InvalidType",
+			"message": "The material type must be slides, reading, video or dataset.",
+		}
+	}
+
+	levelModule, err := models.DBModule.FindModuleWithCode(moduleCode)
+	if err != nil || levelModule == nil {
+		return http.StatusNotFound, map[string]interface{}{
+			"error": "NotFound",
+			"message": "Module not found.",
+		}
+	}
+
+	materialsWeeks, err := findMaterialsWeeks(levelModule, materialType)
+	if err != nil {
+		return http.StatusNotFound, map[string]interface{}{
+			"error": "NotFound",
+			"message": "Materials not found.",
+		}
+	}
+
+	weeks := []map[string]interface{}{}
+	for _, week := range materialsWeeks {
+		weeks = append(weeks, map[string]interface{}{
+			"week": week.Number,
+			"start": week.Start,
+			"end": week.End,
+			"lectures": models.GroupLecturesInWeeks(&week.Lectures),
+			"materials": week.Materials,
+		})
+	}
+
+	return http.StatusOK, map[string]interface{}{
+		"weeks": weeks,
+	}
+}
+
+// Packages every file of a week (materials and lecture attachments) into a ZIP.
+// Video links are listed in a text file inside the ZIP.
+func ZipWeekMaterials(moduleCode string, weekNumber uint32) (string, *[]byte, int, map[string]interface{}) {
+	levelModule, err := models.DBModule.FindModuleWithCode(moduleCode)
+	if err != nil || levelModule == nil {
+		return "", nil, http.StatusNotFound, map[string]interface{}{
+			"error": "NotFound",
+			"message": "Module not found.",
+		}
+	}
+
+	materialsWeeks, err := findMaterialsWeeks(levelModule, "")
+	if err != nil {
+		return "", nil, http.StatusNotFound, map[string]interface{}{
+			"error": "NotFound",
+			"message": "Materials not found.",
+		}
+	}
+
+	if weekNumber < 1 || int(weekNumber) > len(materialsWeeks) {
+		return "", nil, http.StatusNotFound, map[string]interface{}{
+			"error": "NotFound",
+			"message": fmt.Sprintf("The module doesn't have a week %d.", weekNumber),
+		}
+	}
+
+	store, err := storage.Default()
+	if err != nil {
+		fmt.Println(err)
+		return "", nil, http.StatusExpectationFailed, map[string]interface{}{
+			"error": "ExpectationFailed",
+			"message": "Unable to package the materials.",
+		}
+	}
+
+	week := materialsWeeks[weekNumber - 1]
+	names := map[string]bool{}
+	sources := []tools.ZipSource{}
+	links := []string{}
+
+	addMaterial := func(material models.Materials) {
+		if material.Type == models.MaterialVideo {
+			links = append(links, fmt.Sprintf("%s: %s", material.Title, material.Url))
+			return
+		}
+
+		if material.Attachment != nil {
+			name := uniqueZipName(names, fmt.Sprintf("%s/%s", material.Type, material.Attachment.Name))
+			sources = append(sources, storage.ZipSource(store, name, material.Attachment.StorageName()))
+		}
+	}
+
+	for _, lecture := range week.Lectures {
+		for _, attachment := range lecture.Attachments {
+			name := uniqueZipName(names, fmt.Sprintf("lectures/%s", attachment.Name))
+			sources = append(sources, storage.ZipSource(store, name, attachment.StorageName()))
+		}
+
+		for _, material := range lecture.Materials {
+			addMaterial(material)
+		}
+	}
+
+	for _, material := range week.Materials {
+		addMaterial(material)
+	}
+
+	if len(links) > 0 {
+		content := strings.Join(links, "\n") + "\n"
+		sources = append(sources, tools.ZipSource{ Name: uniqueZipName(names, "videos.txt"), Open: func() (io.ReadCloser, error) {
+			return ioutil.NopCloser(strings.NewReader(content)), nil
+		}})
+	}
+
+	if len(sources) <= 0 {
+		return "", nil, http.StatusNotFound, map[string]interface{}{
+			"error": "NotFound",
+			"message": fmt.Sprintf("There are no materials for week %d.", weekNumber),
+		}
+	}
+
+	token, err := uuid.NewV4()
+	if err != nil {
+		return "", nil, http.StatusExpectationFailed, map[string]interface{}{
+			"error": "ExpectationFailed",
+			"message": "Unable to package the materials.",
+		}
+	}
+
+	zipPath := filepath.Join(os.TempDir(), token.String())
+	defer os.Remove(zipPath)
+
+	_, err = tools.ZipSources(zipPath, sources)
+	if err != nil {
+		fmt.Println(err)
+		return "", nil, http.StatusExpectationFailed, map[string]interface{}{
+			"error": "ExpectationFailed",
+			"message": "Unable to package the materials.",
+		}
+	}
+
+	bytes, err := ioutil.ReadFile(zipPath)
+	if err != nil {
+		return "", nil, http.StatusExpectationFailed, map[string]interface{}{
+			"error": "ExpectationFailed",
+			"message": "Unable to package the materials.",
+		}
+	}
+
+	return fmt.Sprintf("%s-week-%d.zip", moduleCode, weekNumber), &bytes, http.StatusOK, nil
+}
+
+// Checks the fields of a material, the week of materials linked to a lecture is taken from the lecture
+func validateMaterial(levelModule *models.LevelModule, material *models.Materials) (int, map[string]interface{}) {
+	if !material.Type.IsValid() {
+		return http.StatusBadRequest, map[string]interface{}{
+			"error": "InvalidType",
+			"message": "The material type must be slides, reading, video or dataset.",
+		}
+	}
+
+	if material.Title == "" {
+		return http.StatusBadRequest, map[string]interface{}{
+			"error": "InvalidTitle",
+			"message": "The material needs a title.",
+		}
+	}
+
+	if material.Type == models.MaterialVideo && material.Url == "" {
+		return http.StatusBadRequest, map[string]interface{}{
+			"error": "InvalidUrl",
+			"message": "Videos need a link.",
+		}
+	}
+
+	weeks := findModuleWeeks(levelModule)
+	if material.LectureID != nil {
+		lecture, err := models.DBLecture.ReadLecture(*material.LectureID)
+		if err != nil || lecture == nil || lecture.ModuleID != levelModule.ModuleID {
+			return http.StatusNotFound, map[string]interface{}{
+				"error": "NotFound",
+				"message": "Lecture not found.",
+			}
+		}
+
+		material.Week = 0
+		for _, week := range weeks {
+			if !lecture.Start.Before(week.Start) && !lecture.Start.After(week.End) {
+				material.Week = week.Number
+			}
+		}
+	}
+
+	if material.Week < 1 || int(material.Week) > len(weeks) {
+		return http.StatusBadRequest, map[string]interface{}{
+			"error": "InvalidWeek",
+			"message": fmt.Sprintf("The week must be between 1 and %d.", len(weeks)),
+		}
+	}
+
+	return http.StatusOK, nil
+}
+
+// Splits the lectures and materials of a module into its weeks
+func findMaterialsWeeks(levelModule *models.LevelModule, materialType models.MaterialType) ([]materialsWeek, error) {
+	materials, err := models.DBMaterials.FindMaterialsForModule(levelModule.ModuleID, materialType)
+	if err != nil {
+		return nil, err
+	}
+
+	lectureMaterials := map[uint32][]models.Materials{}
+	for _, material := range materials {
+		if material.LectureID != nil {
+			lectureMaterials[*material.LectureID] = append(lectureMaterials[*material.LectureID], material)
+		}
+	}
+
+	weeks := []materialsWeek{}
+	for _, week := range findModuleWeeks(levelModule) {
+		lectures, err := models.DBLecture.FindLecturesForModuleInRange(levelModule.Code, &week.Start, &week.End, -1)
+		if err != nil {
+			return nil, err
+		}
+
+		for index, lecture := range lectures {
+			lectures[index].Materials = lectureMaterials[lecture.ID]
+			delete(lectureMaterials, lecture.ID)
+		}
+
+		weeks = append(weeks, materialsWeek{ moduleWeek: week, Lectures: lectures, Materials: []models.Materials{} })
+	}
+
+	// Materials whose lecture is not inside the module weeks are kept in their own week
+	for _, material := range materials {
+		if material.LectureID != nil {
+			if _, lectureOutside := lectureMaterials[*material.LectureID]; !lectureOutside {
+				continue
+			}
+		}
+
+		if material.Week >= 1 && int(material.Week) <= len(weeks) {
+			weeks[material.Week - 1].Materials = append(weeks[material.Week - 1].Materials, material)
+		}
+	}
+
+	return weeks, nil
+}
+
+// Deletes the file of a material, if it has one
+func removeMaterialAttachment(material *models.Materials) {
+	if material.AttachmentID == nil {
+		return
+	}
+
+	removeAttachment(*material.AttachmentID)
+}
+
+// Adds a number to a file name when the ZIP already has a file with that name
+func uniqueZipName(names map[string]bool, name string) string {
+	extension := path.Ext(name)
+	base := strings.TrimSuffix(name, extension)
+
+	unique := name
+	for count := 2; names[unique]; count++ {
+		unique = fmt.Sprintf("%s (%d)%s", base, count, extension)
+	}
+
+	names[unique] = true
+	return unique
+}
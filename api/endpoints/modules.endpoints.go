@@ -66,7 +66,8 @@ func GetModulesForLevel(classId, lvl uint32) (int, map[string]interface{}) {
 	}
 }
 
-func GetStudentsForModule(moduleCode string) (int, map[string]interface{}) {
+// The emails are only shown to the users that are allowed to see them
+func GetStudentsForModule(moduleCode string, showEmails bool) (int, map[string]interface{}) {
 	students, err := models.DBModule.FindStudentsForModule(moduleCode, "Student")
 	if err != nil {
 		return http.StatusConflict, map[string]interface{}{
@@ -94,6 +95,10 @@ func GetStudentsForModule(moduleCode string) (int, map[string]interface{}) {
 			studentMap["avatar"] = student.Avatar.Url
 		}
 
+		if !showEmails {
+			delete(studentMap, "email")
+		}
+
 		parsedStudents = append(parsedStudents, studentMap)
 	}
 
@@ -147,3 +152,39 @@ func AddStudentToModule(moduleCode string, userId uint32) (int, map[string]inter
 		"student": studentMap,
 	}
 }
+
+// Moves a module to a new status, only the transitions of the module state machine are allowed
+func SetModuleStatus(moduleCode string, status models.ModuleStatus) (int, map[string]interface{}) {
+	if !status.IsValid() {
+		return http.StatusBadRequest, map[string]interface{}{
+			"error": "InvalidStatus",
+			"message": "The status must be draft, future, ongoing or ended.",
+		}
+	}
+
+	levelModule, err := models.DBModule.SetModuleStatus(moduleCode, status)
+	if transitionErr, ok := err.(models.InvalidTransitionError); ok {
+		return http.StatusConflict, map[string]interface{}{
+			"error": "InvalidTransition",
+			"message": transitionErr.Error(),
+		}
+	}
+
+	if err != nil {
+		return http.StatusExpectationFailed, map[string]interface{}{
+			"error": "Unknown",
+			"message": "Error updating the module.",
+		}
+	}
+
+	if levelModule == nil {
+		return http.StatusNotFound, map[string]interface{}{
+			"error": "NotFound",
+			"message": "Module not found.",
+		}
+	}
+
+	return http.StatusOK, map[string]interface{}{
+		"module": levelModule,
+	}
+}
@@ -0,0 +1,280 @@
+package endpoints
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/YagoCarballo/kumquat-academy-api/database/models"
+	"github.com/YagoCarballo/kumquat-academy-api/tools"
+)
+
+// Finds the id of the module taught with the given code
+func findModuleId(moduleCode string) (uint32, int, map[string]interface{}) {
+	module, err := models.DBModule.FindModuleWithCode(moduleCode)
+	if err != nil || module == nil {
+		return 0, http.StatusNotFound, map[string]interface{}{
+			"error": "NotFound",
+			"message": "Module not found.",
+		}
+	}
+
+	return module.ModuleID, http.StatusOK, nil
+}
+
+func CreatePage(moduleCode string, page models.Page, userId uint32) (int, map[string]interface{}) {
+	moduleId, status, message := findModuleId(moduleCode)
+	if status != http.StatusOK {
+		return status, message
+	}
+
+	if page.Title == "" {
+		return http.StatusBadRequest, map[string]interface{}{
+			"error": "InvalidTitle",
+			"message": "The page needs a title.",
+		}
+	}
+
+	if page.ParentID != nil {
+		parent, err := models.DBPages.ReadPage(moduleId, *page.ParentID)
+		if err != nil || parent == nil {
+			return http.StatusNotFound, map[string]interface{}{
+				"error": "NotFound",
+				"message": "Parent page not found.",
+			}
+		}
+	}
+
+	// The page always belongs to the module in the URL
+	page.ID = 0
+	page.ModuleID = moduleId
+
+	dbPage, err := models.DBPages.CreatePage(page, userId)
+	if err != nil {
+		return http.StatusConflict, map[string]interface{}{
+			"error": "Error creating the page.",
+		}
+	}
+
+	dbPage.Html = tools.RenderMarkdown(dbPage.Content)
+	return http.StatusCreated, map[string]interface{}{
+		"message": "Page created successfully",
+		"page": dbPage,
+	}
+}
+
+func GetPage(moduleCode string, pageId uint32) (int, map[string]interface{}) {
+	moduleId, status, message := findModuleId(moduleCode)
+	if status != http.StatusOK {
+		return status, message
+	}
+
+	page, err := models.DBPages.ReadPage(moduleId, pageId)
+	if err != nil || page == nil {
+		return http.StatusNotFound, map[string]interface{}{
+			"error": "NotFound",
+			"message": "Page not found.",
+		}
+	}
+
+	page.Html = tools.RenderMarkdown(page.Content)
+	return http.StatusOK, map[string]interface{}{
+		"page": page,
+	}
+}
+
+func UpdatePage(moduleCode string, pageId uint32, page models.Page, userId uint32) (int, map[string]interface{}) {
+	moduleId, status, message := findModuleId(moduleCode)
+	if status != http.StatusOK {
+		return status, message
+	}
+
+	if page.Title == "" {
+		return http.StatusBadRequest, map[string]interface{}{
+			"error": "InvalidTitle",
+			"message": "The page needs a title.",
+		}
+	}
+
+	dbPage, err := models.DBPages.UpdatePage(moduleId, pageId, page, userId)
+	if err != nil || dbPage == nil {
+		return http.StatusExpectationFailed, map[string]interface{}{
+			"error": "Unknown",
+			"message": "Page not updated.",
+		}
+	}
+
+	dbPage.Html = tools.RenderMarkdown(dbPage.Content)
+	return http.StatusOK, map[string]interface{}{
+		"page": dbPage,
+	}
+}
+
+func MovePage(moduleCode string, pageId uint32, parentId *uint32, position uint32) (int, map[string]interface{}) {
+	moduleId, status, message := findModuleId(moduleCode)
+	if status != http.StatusOK {
+		return status, message
+	}
+
+	page, err := models.DBPages.MovePage(moduleId, pageId, parentId, position)
+	if err == models.ErrInvalidParentPage {
+		return http.StatusConflict, map[string]interface{}{
+			"error": "InvalidParent",
+			"message": err.Error(),
+		}
+	}
+
+	if err != nil || page == nil {
+		return http.StatusExpectationFailed, map[string]interface{}{
+			"error": "Unknown",
+			"message": "Page not moved.",
+		}
+	}
+
+	return http.StatusOK, map[string]interface{}{
+		"page": page,
+	}
+}
+
+func DeletePage(moduleCode string, pageId uint32) (int, map[string]interface{}) {
+	moduleId, status, message := findModuleId(moduleCode)
+	if status != http.StatusOK {
+		return status, message
+	}
+
+	rows, err := models.DBPages.DeletePage(moduleId, pageId)
+	if err != nil || rows <= 0 {
+		return http.StatusExpectationFailed, map[string]interface{}{
+			"error": "Unknown",
+			"message": "Error deleting the page",
+		}
+	}
+
+	return http.StatusAccepted, map[string]interface{}{
+		"message": fmt.Sprintf("Page %d removed", pageId),
+	}
+}
+
+func FindPagesForModule(moduleCode string) (int, map[string]interface{}) {
+	moduleId, status, message := findModuleId(moduleCode)
+	if status != http.StatusOK {
+		return status, message
+	}
+
+	pages, err := models.DBPages.FindTableOfContents(moduleId)
+	if err != nil {
+		return http.StatusNotFound, map[string]interface{}{
+			"error": "NotFound",
+			"message": "Pages not found.",
+		}
+	}
+
+	return http.StatusOK, map[string]interface{}{
+		"pages": pages,
+	}
+}
+
+func FindPageRevisions(moduleCode string, pageId uint32) (int, map[string]interface{}) {
+	moduleId, status, message := findModuleId(moduleCode)
+	if status != http.StatusOK {
+		return status, message
+	}
+
+	page, err := models.DBPages.ReadPage(moduleId, pageId)
+	if err != nil || page == nil {
+		return http.StatusNotFound, map[string]interface{}{
+			"error": "NotFound",
+			"message": "Page not found.",
+		}
+	}
+
+	revisions, err := models.DBPages.FindRevisions(pageId)
+	if err != nil {
+		return http.StatusNotFound, map[string]interface{}{
+			"error": "NotFound",
+			"message": "Revisions not found.",
+		}
+	}
+
+	return http.StatusOK, map[string]interface{}{
+		"revisions": revisions,
+	}
+}
+
+func GetPageRevision(moduleCode string, pageId, revisionId uint32) (int, map[string]interface{}) {
+	moduleId, status, message := findModuleId(moduleCode)
+	if status != http.StatusOK {
+		return status, message
+	}
+
+	page, err := models.DBPages.ReadPage(moduleId, pageId)
+	if err != nil || page == nil {
+		return http.StatusNotFound, map[string]interface{}{
+			"error": "NotFound",
+			"message": "Page not found.",
+		}
+	}
+
+	revision, err := models.DBPages.ReadRevision(pageId, revisionId)
+	if err != nil || revision == nil {
+		return http.StatusNotFound, map[string]interface{}{
+			"error": "NotFound",
+			"message": "Revision not found.",
+		}
+	}
+
+	return http.StatusOK, map[string]interface{}{
+		"revision": revision,
+		"html": tools.RenderMarkdown(revision.Content),
+	}
+}
+
+// Compares the content of two revisions of a page, when toId is nil the current content of the page is used
+func DiffPageRevisions(moduleCode string, pageId, fromId uint32, toId *uint32) (int, map[string]interface{}) {
+	moduleId, status, message := findModuleId(moduleCode)
+	if status != http.StatusOK {
+		return status, message
+	}
+
+	page, err := models.DBPages.ReadPage(moduleId, pageId)
+	if err != nil || page == nil {
+		return http.StatusNotFound, map[string]interface{}{
+			"error": "NotFound",
+			"message": "Page not found.",
+		}
+	}
+
+	from, err := models.DBPages.ReadRevision(pageId, fromId)
+	if err != nil || from == nil {
+		return http.StatusNotFound, map[string]interface{}{
+			"error": "NotFound",
+			"message": fmt.Sprintf("Revision %d not found.", fromId),
+		}
+	}
+
+	content := page.Content
+	if toId != nil {
+		to, err := models.DBPages.ReadRevision(pageId, *toId)
+		if err != nil || to == nil {
+			return http.StatusNotFound, map[string]interface{}{
+				"error": "NotFound",
+				"message": fmt.Sprintf("Revision %d not found.", *toId),
+			}
+		}
+
+		content = to.Content
+	}
+
+	diff, err := tools.DiffLines(from.Content, content)
+	if err != nil {
+		return http.StatusRequestEntityTooLarge, map[string]interface{}{
+			"error": "DiffTooLarge",
+			"message": err.Error(),
+		}
+	}
+
+	return http.StatusOK, map[string]interface{}{
+		"from": fromId,
+		"to": toId,
+		"diff": diff,
+	}
+}
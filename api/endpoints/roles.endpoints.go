@@ -0,0 +1,245 @@
+package endpoints
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/YagoCarballo/kumquat-academy-api/database"
+	"github.com/YagoCarballo/kumquat-academy-api/database/models"
+
+	. "github.com/YagoCarballo/kumquat-academy-api/constants"
+)
+
+func GetRoles() (int, map[string]interface{}) {
+	roles, err := models.DBUserRole.FindRoles()
+	if err != nil {
+		return http.StatusExpectationFailed, map[string]interface{}{
+			"error": "Unknown",
+			"message": "Error reading the roles.",
+		}
+	}
+
+	return http.StatusOK, map[string]interface{}{
+		"roles": roles,
+	}
+}
+
+// The capabilities roles can grant, with the flag that grants each one to roles without capabilities
+func GetCapabilities() (int, map[string]interface{}) {
+	return http.StatusOK, map[string]interface{}{
+		"capabilities": Capabilities,
+	}
+}
+
+func CreateRole(role models.Role) (int, map[string]interface{}) {
+	status, message := validateRole(role)
+	if status != http.StatusOK {
+		return status, message
+	}
+
+	dbRole, err := models.DBUserRole.CreateRole(role)
+	if err != nil {
+		return roleError(err, "Error creating the role.")
+	}
+
+	return http.StatusCreated, map[string]interface{}{
+		"message": "Role created successfully",
+		"role": dbRole,
+	}
+}
+
+func UpdateRole(roleId uint32, role models.Role) (int, map[string]interface{}) {
+	status, message := validateRole(role)
+	if status != http.StatusOK {
+		return status, message
+	}
+
+	dbRole, err := models.DBUserRole.UpdateRole(roleId, role)
+	if err != nil {
+		return roleError(err, "Error updating the role.")
+	}
+
+	if dbRole == nil {
+		return http.StatusNotFound, map[string]interface{}{
+			"error": "NotFound",
+			"message": "Role not found.",
+		}
+	}
+
+	return http.StatusOK, map[string]interface{}{
+		"message": "Role updated successfully",
+		"role": dbRole,
+	}
+}
+
+func validateRole(role models.Role) (int, map[string]interface{}) {
+	if role.Name == "" {
+		return http.StatusBadRequest, map[string]interface{}{
+			"error": "InvalidRole",
+			"message": "The role needs a name.",
+		}
+	}
+
+	for _, capability := range role.Capabilities {
+		if !IsCapability(capability.Capability) {
+			return http.StatusBadRequest, map[string]interface{}{
+				"error": "InvalidCapability",
+				"message": fmt.Sprintf("There is no capability named %s.", capability.Capability),
+			}
+		}
+	}
+
+	return http.StatusOK, nil
+}
+
+func roleError(err error, message string) (int, map[string]interface{}) {
+	if database.IsDuplicatedError(err) {
+		return http.StatusConflict, map[string]interface{}{
+			"error": "Duplicated",
+			"message": "There is already a role with that name.",
+		}
+	}
+
+	return http.StatusExpectationFailed, map[string]interface{}{
+		"error": "Unknown",
+		"message": message,
+	}
+}
+
+func AssignModuleRole(moduleCode string, userId, roleId uint32) (int, map[string]interface{}) {
+	status, message := checkRoleAssignment(userId, roleId)
+	if status != http.StatusOK {
+		return status, message
+	}
+
+	userModule, err := models.DBUserRole.AssignModuleRole(moduleCode, userId, roleId)
+	if err != nil {
+		return http.StatusExpectationFailed, map[string]interface{}{
+			"error": "Unknown",
+			"message": "Error assigning the role.",
+		}
+	}
+
+	if userModule == nil {
+		return http.StatusNotFound, map[string]interface{}{
+			"error": "NotFound",
+			"message": "Module not found.",
+		}
+	}
+
+	return http.StatusOK, map[string]interface{}{
+		"message": "Role assigned successfully",
+		"user_module": userModule,
+	}
+}
+
+func UnassignModuleRole(moduleCode string, userId uint32) (int, map[string]interface{}) {
+	rows, err := models.DBUserRole.UnassignModuleRole(moduleCode, userId)
+	if err != nil || rows <= 0 {
+		return http.StatusExpectationFailed, map[string]interface{}{
+			"error": "Unknown",
+			"message": "The user has no role on that module.",
+		}
+	}
+
+	return http.StatusAccepted, map[string]interface{}{
+		"message": fmt.Sprintf("User %d removed from Module %s", userId, moduleCode),
+	}
+}
+
+func AssignCourseRole(courseId, userId, roleId uint32) (int, map[string]interface{}) {
+	status, message := checkRoleAssignment(userId, roleId)
+	if status != http.StatusOK {
+		return status, message
+	}
+
+	course, err := models.DBCourse.ReadCourse(courseId)
+	if err != nil || course == nil {
+		return http.StatusNotFound, map[string]interface{}{
+			"error": "NotFound",
+			"message": "Course not found.",
+		}
+	}
+
+	userCourse, err := models.DBUserRole.AssignCourseRole(courseId, userId, roleId)
+	if err != nil {
+		return http.StatusExpectationFailed, map[string]interface{}{
+			"error": "Unknown",
+			"message": "Error assigning the role.",
+		}
+	}
+
+	return http.StatusOK, map[string]interface{}{
+		"message": "Role assigned successfully",
+		"user_course": userCourse,
+	}
+}
+
+func UnassignCourseRole(courseId, userId uint32) (int, map[string]interface{}) {
+	rows, err := models.DBUserRole.UnassignCourseRole(courseId, userId)
+	if err != nil || rows <= 0 {
+		return http.StatusExpectationFailed, map[string]interface{}{
+			"error": "Unknown",
+			"message": "The user has no role on that course.",
+		}
+	}
+
+	return http.StatusAccepted, map[string]interface{}{
+		"message": fmt.Sprintf("User %d removed from Course %d", userId, courseId),
+	}
+}
+
+// Both the user and the role have to exist before the role is given
+func checkRoleAssignment(userId, roleId uint32) (int, map[string]interface{}) {
+	user, err := models.DBUser.FindUserWithId(userId)
+	if err != nil || user == nil {
+		return http.StatusNotFound, map[string]interface{}{
+			"error": "NotFound",
+			"message": "User not found.",
+		}
+	}
+
+	role, err := models.DBUserRole.ReadRole(roleId)
+	if err != nil || role == nil {
+		return http.StatusNotFound, map[string]interface{}{
+			"error": "NotFound",
+			"message": "Role not found.",
+		}
+	}
+
+	return http.StatusOK, nil
+}
+
+// The rights the user ends up with on the module, merging their roles on the module and its course
+func GetModulePermissions(moduleCode string, userId uint32) (int, map[string]interface{}) {
+	var moduleId uint32
+	permissions, err := models.DBPermissions.GetPermissionsForModule(&userId, &moduleId, &moduleCode)
+	if err != nil {
+		return http.StatusExpectationFailed, map[string]interface{}{
+			"error": "Unknown",
+			"message": "Error reading the permissions.",
+		}
+	}
+
+	return http.StatusOK, map[string]interface{}{
+		"module_code": moduleCode,
+		"user_id": userId,
+		"permissions": permissions,
+	}
+}
+
+func GetCoursePermissions(courseId, userId uint32) (int, map[string]interface{}) {
+	permissions, err := models.DBPermissions.GetPermissionsForCourse(userId, courseId)
+	if err != nil {
+		return http.StatusExpectationFailed, map[string]interface{}{
+			"error": "Unknown",
+			"message": "Error reading the permissions.",
+		}
+	}
+
+	return http.StatusOK, map[string]interface{}{
+		"course_id": courseId,
+		"user_id": userId,
+		"permissions": permissions,
+	}
+}
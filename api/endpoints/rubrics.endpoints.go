@@ -0,0 +1,107 @@
+package endpoints
+
+import (
+	"net/http"
+
+	"github.com/YagoCarballo/kumquat-academy-api/database/models"
+)
+
+func FindRubric(moduleCode string, assignmentId uint32) (int, map[string]interface{}) {
+	_, status, message := readModuleAssignment(moduleCode, assignmentId)
+	if status != http.StatusOK {
+		return status, message
+	}
+
+	criteria, err := models.DBRubrics.FindRubric(assignmentId)
+	if err != nil {
+		return http.StatusNotFound, map[string]interface{}{
+			"error": "NotFound",
+			"message": "Rubric not found.",
+		}
+	}
+
+	return http.StatusOK, map[string]interface{}{
+		"criteria": criteria,
+	}
+}
+
+// Replaces the rubric of an assignment, the criteria are kept in the given order
+func SaveRubric(moduleCode string, assignmentId uint32, criteria []models.RubricCriterion) (int, map[string]interface{}) {
+	_, status, message := readModuleAssignment(moduleCode, assignmentId)
+	if status != http.StatusOK {
+		return status, message
+	}
+
+	status, message = validateRubric(criteria)
+	if status != http.StatusOK {
+		return status, message
+	}
+
+	dbCriteria, err := models.DBRubrics.SaveRubric(assignmentId, criteria)
+	if err == models.ErrRubricInUse {
+		return http.StatusConflict, map[string]interface{}{
+			"error": "Conflict",
+			"message": err.Error(),
+		}
+	}
+
+	if err != nil {
+		return http.StatusExpectationFailed, map[string]interface{}{
+			"error": "Unknown",
+			"message": "Error saving the rubric.",
+		}
+	}
+
+	return http.StatusOK, map[string]interface{}{
+		"message": "Rubric saved",
+		"criteria": dbCriteria,
+	}
+}
+
+func DeleteRubric(moduleCode string, assignmentId uint32) (int, map[string]interface{}) {
+	_, status, message := readModuleAssignment(moduleCode, assignmentId)
+	if status != http.StatusOK {
+		return status, message
+	}
+
+	err := models.DBRubrics.DeleteRubric(assignmentId)
+	if err == models.ErrRubricInUse {
+		return http.StatusConflict, map[string]interface{}{
+			"error": "Conflict",
+			"message": err.Error(),
+		}
+	}
+
+	if err != nil {
+		return http.StatusExpectationFailed, map[string]interface{}{
+			"error": "Unknown",
+			"message": "Error deleting the rubric.",
+		}
+	}
+
+	return http.StatusAccepted, map[string]interface{}{
+		"message": "Rubric removed",
+	}
+}
+
+func validateRubric(criteria []models.RubricCriterion) (int, map[string]interface{}) {
+	for _, criterion := range criteria {
+		if criterion.Title == "" || len(criterion.Levels) <= 0 {
+			return http.StatusBadRequest, map[string]interface{}{
+				"error": "InvalidCriterion",
+				"message": "Every criterion needs a title and at least one level.",
+			}
+		}
+
+		for _, level := range criterion.Levels {
+			if level.Title == "" || level.Points < 0 {
+				return http.StatusBadRequest, map[string]interface{}{
+					"error": "InvalidLevel",
+					"message": "Every level needs a title and can't have negative points.",
+				}
+			}
+		}
+	}
+
+	return http.StatusOK, nil
+}
@@ -0,0 +1,116 @@
+package endpoints
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/YagoCarballo/kumquat-academy-api/database/models"
+	"github.com/YagoCarballo/kumquat-academy-api/tools"
+)
+
+// Lists the devices where the user is signed in, the tokens aren't included
+func FindSessions(userId uint32, currentToken string) (int, map[string]interface{}) {
+	sessions, err := models.DBSession.FindUserSessions(userId, time.Now())
+	if err != nil {
+		return http.StatusExpectationFailed, map[string]interface{}{
+			"error": "Unknown",
+			"message": "Error reading the sessions.",
+		}
+	}
+
+	devices := []map[string]interface{}{}
+	for _, session := range sessions {
+		devices = append(devices, map[string]interface{}{
+			"device_id": session.DeviceID,
+			"created_on": session.CreatedOn,
+			"last_seen": session.LastSeen,
+			"expires_in": session.ExpiresIn,
+			"current": session.Token == currentToken,
+		})
+	}
+
+	return http.StatusOK, map[string]interface{}{
+		"sessions": devices,
+	}
+}
+
+// Signs the user out of one of their devices
+func RevokeSession(userId uint32, deviceId string) (int, map[string]interface{}) {
+	removed, err := models.DBSession.RemoveUserSessionOnDevice(userId, deviceId)
+	if err != nil {
+		return http.StatusExpectationFailed, map[string]interface{}{
+			"error": "Unknown",
+			"message": "Error revoking the session.",
+		}
+	}
+
+	if removed <= 0 {
+		return http.StatusNotFound, map[string]interface{}{
+			"error": "NotFound",
+			"message": "There is no session on that device.",
+		}
+	}
+
+	return http.StatusAccepted, map[string]interface{}{
+		"message": "Session revoked",
+	}
+}
+
+// Signs the user out of every device except the current one (or all of them if the token is empty)
+func RevokeSessions(userId uint32, currentToken string) (int, map[string]interface{}) {
+	removed, err := models.DBSession.RemoveUserSessions(userId, currentToken)
+	if err != nil {
+		return http.StatusExpectationFailed, map[string]interface{}{
+			"error": "Unknown",
+			"message": "Error revoking the sessions.",
+		}
+	}
+
+	return http.StatusAccepted, map[string]interface{}{
+		"message": "Sessions revoked",
+		"revoked": removed,
+	}
+}
+
+// Swaps a refresh token for a new session, the old tokens stop working
+func RefreshSession(refreshToken string) (int, map[string]interface{}, *tools.JWTSession) {
+	session, err := models.DBSession.RefreshSession(refreshToken, time.Now())
+	if err != nil {
+		return http.StatusExpectationFailed, map[string]interface{}{
+			"error": "Unknown",
+			"message": "Error refreshing the session.",
+		}, nil
+	}
+
+	if session == nil {
+		return http.StatusUnauthorized, map[string]interface{}{
+			"error": "InvalidToken",
+			"message": "The refresh token is invalid or has expired.",
+		}, nil
+	}
+
+	user, err := models.DBUser.FindUserWithId(session.UserID)
+	if err != nil || user == nil {
+		return http.StatusUnauthorized, map[string]interface{}{
+			"error": "InvalidToken",
+			"message": "The refresh token is invalid or has expired.",
+		}, nil
+	}
+
+	jwtSession := &tools.JWTSession{
+		AccessToken: 	session.Token,
+		UserId: 		session.UserID,
+		ExpiresIn: 		session.ExpiresIn,
+		Device: 		session.DeviceID,
+		Username: 		user.Username,
+		Admin:			user.Admin,
+		RefreshToken:	session.RefreshToken,
+	}
+
+	return http.StatusOK, map[string]interface{}{
+		"message": "Session refreshed",
+		"access_token": session.Token,
+		"refresh_token": session.RefreshToken,
+		"expires_in": session.ExpiresIn,
+	}, jwtSession
+}
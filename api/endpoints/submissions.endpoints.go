@@ -0,0 +1,258 @@
+package endpoints
+
+import (
+	"fmt"
+	"mime/multipart"
+	"net/http"
+
+	"github.com/YagoCarballo/kumquat-academy-api/database/models"
+)
+
+// Withdraws the submission of a student before the deadline, so the assignment can be submitted again
+func WithdrawSubmission(moduleCode string, assignmentId, userId uint32) (int, map[string]interface{}) {
+	_, status, message := readModuleAssignment(moduleCode, assignmentId)
+	if status != http.StatusOK {
+		return status, message
+	}
+
+	submission, err := models.DBAssignments.CancelSubmission(assignmentId, userId)
+	if err == models.ErrDeadlinePassed || err == models.ErrSubmissionLocked {
+		return http.StatusConflict, map[string]interface{}{
+			"error": "Conflict",
+			"message": err.Error(),
+		}
+	}
+
+	if err != nil {
+		return http.StatusExpectationFailed, map[string]interface{}{
+			"error": "Unknown",
+			"message": "Error withdrawing the submission.",
+		}
+	}
+
+	if submission == nil {
+		return http.StatusNotFound, map[string]interface{}{
+			"error": "NotFound",
+			"message": "Submission not found.",
+		}
+	}
+
+	return http.StatusOK, map[string]interface{}{
+		"message": "Submission withdrawn",
+		"submission": submission,
+	}
+}
+
+// Gets all the submissions of a student for an assignment, along with their deadline.
+// Students don't see their grades and feedback until the grades of the assignment are released.
+func FindSubmissionHistory(moduleCode string, assignmentId, userId uint32, teacher bool) (int, map[string]interface{}) {
+	assignment, status, message := readModuleAssignment(moduleCode, assignmentId)
+	if status != http.StatusOK {
+		return status, message
+	}
+
+	submissions, err := models.DBAssignments.FindSubmissionHistory(assignmentId, userId)
+	if err != nil {
+		return http.StatusNotFound, map[string]interface{}{
+			"error": "NotFound",
+			"message": "Submissions not found.",
+		}
+	}
+
+	if !teacher && assignment.Status != models.AssignmentReturned {
+		for index := range submissions {
+			submissions[index].WithholdGrade()
+		}
+	}
+
+	deadline, err := models.DBAssignments.FindDeadline(assignment, userId)
+	if err != nil {
+		return http.StatusExpectationFailed, map[string]interface{}{
+			"error": "Unknown",
+			"message": "Error reading the deadline.",
+		}
+	}
+
+	return http.StatusOK, map[string]interface{}{
+		"deadline": deadline,
+		"late_until": models.LateWindowEnd(assignment, deadline),
+		"submissions": submissions,
+	}
+}
+
+func ReviewSubmission(moduleCode string, assignmentId, submissionId uint32) (int, map[string]interface{}) {
+	_, status, message := readModuleAssignment(moduleCode, assignmentId)
+	if status != http.StatusOK {
+		return status, message
+	}
+
+	count, err := models.DBAssignments.ReviewSubmission(assignmentId, submissionId)
+	if err != nil {
+		return http.StatusExpectationFailed, map[string]interface{}{
+			"error": "Unknown",
+			"message": "Error updating the submission.",
+		}
+	}
+
+	if count <= 0 {
+		return http.StatusNotFound, map[string]interface{}{
+			"error": "NotFound",
+			"message": "No submission pending review was found with that Id.",
+		}
+	}
+
+	return http.StatusOK, map[string]interface{}{
+		"message": "Submission under review",
+	}
+}
+
+// Returns an annotated file with the feedback of a submission, replacing the previous one
+func UploadFeedbackAttachment(userId uint32, moduleCode string, assignmentId, submissionId uint32, file multipart.File, header *multipart.FileHeader) (int, FileResponseMessage) {
+	_, status, _ := readModuleAssignment(moduleCode, assignmentId)
+	if status != http.StatusOK {
+		return status, FileResponseMessage{
+			Error: "NotFound",
+			Message: "Assignment not found.",
+		}
+	}
+
+	submission, err := models.DBAssignments.ReadSubmission(assignmentId, submissionId)
+	if err != nil || submission == nil {
+		return http.StatusNotFound, FileResponseMessage{
+			Error: "NotFound",
+			Message: "Submission not found.",
+		}
+	}
+
+	status, response := UploadFile(Upload{ UserID: userId, ModuleCode: moduleCode }, file, header)
+	if status != http.StatusOK {
+		return status, response
+	}
+
+	count, err := models.DBAssignments.SetFeedbackAttachment(*submission, response.Attachment.ID)
+	if err != nil || count <= 0 {
+		return http.StatusExpectationFailed, FileResponseMessage{
+			Error: "Unknown",
+			Message: "Error adding the feedback to the submission.",
+		}
+	}
+
+	if submission.FeedbackAttachmentID != nil {
+		removeAttachment(*submission.FeedbackAttachmentID)
+	}
+
+	return status, response
+}
+
+// Returns the graded submissions of an assignment to the students
+func ReleaseGrades(moduleCode string, assignmentId uint32) (int, map[string]interface{}) {
+	_, status, message := readModuleAssignment(moduleCode, assignmentId)
+	if status != http.StatusOK {
+		return status, message
+	}
+
+	assignment, err := models.DBAssignments.ReleaseGrades(assignmentId)
+	if transitionErr, ok := err.(models.InvalidTransitionError); ok {
+		return http.StatusConflict, map[string]interface{}{
+			"error": "InvalidTransition",
+			"message": transitionErr.Error(),
+		}
+	}
+
+	if err != nil || assignment == nil {
+		return http.StatusExpectationFailed, map[string]interface{}{
+			"error": "Unknown",
+			"message": "Error releasing the grades.",
+		}
+	}
+
+	return http.StatusOK, map[string]interface{}{
+		"message": "Grades released",
+		"assignment": assignment,
+	}
+}
+
+func FindExtensions(moduleCode string, assignmentId uint32) (int, map[string]interface{}) {
+	_, status, message := readModuleAssignment(moduleCode, assignmentId)
+	if status != http.StatusOK {
+		return status, message
+	}
+
+	extensions, err := models.DBAssignments.FindExtensions(assignmentId)
+	if err != nil {
+		return http.StatusNotFound, map[string]interface{}{
+			"error": "NotFound",
+			"message": "Extensions not found.",
+		}
+	}
+
+	return http.StatusOK, map[string]interface{}{
+		"extensions": extensions,
+	}
+}
+
+// Moves the deadline of an assignment for one student of the module
+func GrantExtension(moduleCode string, assignmentId, userId uint32, extension models.AssignmentExtension, grantedBy uint32) (int, map[string]interface{}) {
+	assignment, status, message := readModuleAssignment(moduleCode, assignmentId)
+	if status != http.StatusOK {
+		return status, message
+	}
+
+	student, err := models.DBModule.GetModuleStudent(userId, moduleCode)
+	if err != nil || student == nil {
+		return http.StatusNotFound, map[string]interface{}{
+			"error": "NotFound",
+			"message": "Student not found.",
+		}
+	}
+
+	if !extension.End.After(assignment.End) {
+		return http.StatusBadRequest, map[string]interface{}{
+			"error": "InvalidDate",
+			"message": "The extension must end after the deadline of the assignment.",
+		}
+	}
+
+	extension.AssignmentID = assignmentId
+	extension.UserID = userId
+	extension.GrantedBy = grantedBy
+
+	dbExtension, err := models.DBAssignments.GrantExtension(extension)
+	if err != nil {
+		return http.StatusExpectationFailed, map[string]interface{}{
+			"error": "Unknown",
+			"message": "Error granting the extension.",
+		}
+	}
+
+	return http.StatusOK, map[string]interface{}{
+		"message": "Extension granted",
+		"extension": dbExtension,
+	}
+}
+
+func RevokeExtension(moduleCode string, assignmentId, userId uint32) (int, map[string]interface{}) {
+	_, status, message := readModuleAssignment(moduleCode, assignmentId)
+	if status != http.StatusOK {
+		return status, message
+	}
+
+	count, err := models.DBAssignments.RevokeExtension(assignmentId, userId)
+	if err != nil {
+		return http.StatusExpectationFailed, map[string]interface{}{
+			"error": "Unknown",
+			"message": "Error revoking the extension.",
+		}
+	}
+
+	if count <= 0 {
+		return http.StatusNotFound, map[string]interface{}{
+			"error": "NotFound",
+			"message": "The student doesn't have an extension.",
+		}
+	}
+
+	return http.StatusAccepted, map[string]interface{}{
+		"message": fmt.Sprintf("Extension of user %d revoked", userId),
+	}
+}
@@ -0,0 +1,189 @@
+package endpoints
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/YagoCarballo/kumquat-academy-api/database/models"
+)
+
+func CreateTask(moduleCode string, assignmentId uint32, task models.Task) (int, map[string]interface{}) {
+	_, status, message := readModuleAssignment(moduleCode, assignmentId)
+	if status != http.StatusOK {
+		return status, message
+	}
+
+	if task.Title == "" {
+		return http.StatusBadRequest, map[string]interface{}{
+			"error": "InvalidTitle",
+			"message": "The task needs a title.",
+		}
+	}
+
+	// The task always belongs to the assignment in the URL
+	task.ID = 0
+	task.AssignmentID = assignmentId
+
+	dbTask, err := models.DBTasks.CreateTask(task)
+	if err != nil {
+		return http.StatusConflict, map[string]interface{}{
+			"error": "Error creating the task.",
+		}
+	}
+
+	return http.StatusCreated, map[string]interface{}{
+		"message": "Task created successfully",
+		"task": dbTask,
+	}
+}
+
+func UpdateTask(moduleCode string, assignmentId, taskId uint32, task models.Task) (int, map[string]interface{}) {
+	_, status, message := readModuleAssignment(moduleCode, assignmentId)
+	if status != http.StatusOK {
+		return status, message
+	}
+
+	dbTask, err := models.DBTasks.UpdateTask(assignmentId, taskId, task)
+	if err != nil || dbTask == nil {
+		return http.StatusExpectationFailed, map[string]interface{}{
+			"error": "Unknown",
+			"message": "Task not updated.",
+		}
+	}
+
+	return http.StatusOK, map[string]interface{}{
+		"task": dbTask,
+	}
+}
+
+func DeleteTask(moduleCode string, assignmentId, taskId uint32) (int, map[string]interface{}) {
+	_, status, message := readModuleAssignment(moduleCode, assignmentId)
+	if status != http.StatusOK {
+		return status, message
+	}
+
+	rows, err := models.DBTasks.DeleteTask(assignmentId, taskId)
+	if err != nil || rows <= 0 {
+		return http.StatusExpectationFailed, map[string]interface{}{
+			"error": "Unknown",
+			"message": "Error deleting the task",
+		}
+	}
+
+	return http.StatusAccepted, map[string]interface{}{
+		"message": fmt.Sprintf("Task %d removed", taskId),
+	}
+}
+
+// Lists the checklist of an assignment along with the progress of the given user
+func FindTasksForAssignment(moduleCode string, assignmentId, userId uint32) (int, map[string]interface{}) {
+	_, status, message := readModuleAssignment(moduleCode, assignmentId)
+	if status != http.StatusOK {
+		return status, message
+	}
+
+	tasks, err := models.DBTasks.FindTasksForAssignment(assignmentId, userId)
+	if err != nil {
+		return http.StatusNotFound, map[string]interface{}{
+			"error": "NotFound",
+			"message": "Tasks not found.",
+		}
+	}
+
+	progress, err := models.DBTasks.GetProgress(assignmentId, userId)
+	if err != nil {
+		return http.StatusExpectationFailed, map[string]interface{}{
+			"error": "ExpectationFailed",
+			"message": "Error calculating the progress",
+		}
+	}
+
+	return http.StatusOK, map[string]interface{}{
+		"progress": progress,
+		"tasks": tasks,
+	}
+}
+
+func ReorderTasks(moduleCode string, assignmentId uint32, taskIds []uint32) (int, map[string]interface{}) {
+	_, status, message := readModuleAssignment(moduleCode, assignmentId)
+	if status != http.StatusOK {
+		return status, message
+	}
+
+	err := models.DBTasks.ReorderTasks(assignmentId, taskIds)
+	if err != nil {
+		return http.StatusExpectationFailed, map[string]interface{}{
+			"error": "ExpectationFailed",
+			"message": "Error reordering the tasks",
+		}
+	}
+
+	tasks, err := models.DBTasks.FindTasksForAssignment(assignmentId, 0)
+	if err != nil {
+		return http.StatusNotFound, map[string]interface{}{
+			"error": "NotFound",
+			"message": "Tasks not found.",
+		}
+	}
+
+	return http.StatusOK, map[string]interface{}{
+		"tasks": tasks,
+	}
+}
+
+// Ticks (or unticks) a task for a student, on team assignments the task is ticked for the whole team
+func SetTaskCompleted(moduleCode string, assignmentId, taskId, userId uint32, completed bool) (int, map[string]interface{}) {
+	assignment, status, message := readModuleAssignment(moduleCode, assignmentId)
+	if status != http.StatusOK {
+		return status, message
+	}
+
+	task, err := models.DBTasks.ReadTask(assignmentId, taskId)
+	if err != nil || task == nil {
+		return http.StatusNotFound, map[string]interface{}{
+			"error": "NotFound",
+			"message": "Task not found.",
+		}
+	}
+
+	var team *models.Team
+	if assignment.TeamAssignment {
+		team, err = models.DBTeams.FindTeamForUser(assignmentId, userId)
+		if err != nil || team == nil {
+			return http.StatusConflict, map[string]interface{}{
+				"error": "NoTeam",
+				"message": "You must be part of a team to work on this assignment",
+			}
+		}
+	}
+
+	switch {
+	case team != nil && completed:
+		_, err = models.DBTasks.CompleteTeamTask(team.ID, taskId)
+	case team != nil:
+		_, err = models.DBTasks.UncompleteTeamTask(team.ID, taskId)
+	case completed:
+		_, err = models.DBTasks.CompleteTask(userId, taskId)
+	default:
+		_, err = models.DBTasks.UncompleteTask(userId, taskId)
+	}
+	if err != nil {
+		return http.StatusExpectationFailed, map[string]interface{}{
+			"error": "ExpectationFailed",
+			"message": "Error updating the task",
+		}
+	}
+
+	progress, err := models.DBTasks.GetProgress(assignmentId, userId)
+	if err != nil {
+		return http.StatusExpectationFailed, map[string]interface{}{
+			"error": "ExpectationFailed",
+			"message": "Error calculating the progress",
+		}
+	}
+
+	return http.StatusOK, map[string]interface{}{
+		"message": "Task updated",
+		"progress": progress,
+	}
+}
@@ -0,0 +1,222 @@
+package endpoints
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/YagoCarballo/kumquat-academy-api/database/models"
+)
+
+// Reads a team assignment, making sure it belongs to the given module
+func readTeamAssignment(moduleCode string, assignmentId uint32) (*models.Assignment, int, map[string]interface{}) {
+	assignment, status, message := readModuleAssignment(moduleCode, assignmentId)
+	if status != http.StatusOK {
+		return nil, status, message
+	}
+
+	if !assignment.TeamAssignment {
+		return nil, http.StatusConflict, map[string]interface{}{
+			"error": "NotATeamAssignment",
+			"message": "This assignment is not a team assignment.",
+		}
+	}
+
+	return assignment, http.StatusOK, nil
+}
+
+// Checks that the student is enrolled in the module and is not part of another team of the assignment
+func canJoinTeam(moduleCode string, assignmentId, userId uint32) (int, map[string]interface{}) {
+	student, err := models.DBModule.GetModuleStudent(userId, moduleCode)
+	if err != nil || student == nil {
+		return http.StatusNotFound, map[string]interface{}{
+			"error": "NotFound",
+			"message": fmt.Sprintf("The student %d is not enrolled in this module.", userId),
+		}
+	}
+
+	team, err := models.DBTeams.FindTeamForUser(assignmentId, userId)
+	if err != nil {
+		return http.StatusExpectationFailed, map[string]interface{}{
+			"error": "ExpectationFailed",
+			"message": "Error reading the teams of the assignment",
+		}
+	}
+
+	if team != nil {
+		return http.StatusConflict, map[string]interface{}{
+			"error": "AlreadyInTeam",
+			"message": fmt.Sprintf("The student %d is already part of the team '%s'.", userId, team.Name),
+		}
+	}
+
+	return http.StatusOK, nil
+}
+
+func CreateTeam(moduleCode string, assignmentId uint32, name string, memberIds []uint32) (int, map[string]interface{}) {
+	_, status, message := readTeamAssignment(moduleCode, assignmentId)
+	if status != http.StatusOK {
+		return status, message
+	}
+
+	for _, userId := range memberIds {
+		status, message = canJoinTeam(moduleCode, assignmentId, userId)
+		if status != http.StatusOK {
+			return status, message
+		}
+	}
+
+	team, err := models.DBTeams.CreateTeam(assignmentId, name, memberIds)
+	if err != nil {
+		return http.StatusConflict, map[string]interface{}{
+			"error": "Error creating the team.",
+		}
+	}
+
+	return http.StatusCreated, map[string]interface{}{
+		"message": "Team created successfully",
+		"team": team,
+	}
+}
+
+func GenerateTeams(moduleCode string, assignmentId uint32, size int) (int, map[string]interface{}) {
+	_, status, message := readTeamAssignment(moduleCode, assignmentId)
+	if status != http.StatusOK {
+		return status, message
+	}
+
+	if size <= 0 {
+		return http.StatusBadRequest, map[string]interface{}{
+			"error": "InvalidSize",
+			"message": "The size of the teams must be greater than zero.",
+		}
+	}
+
+	teams, err := models.DBTeams.GenerateTeams(assignmentId, moduleCode, size)
+	if err != nil {
+		return http.StatusExpectationFailed, map[string]interface{}{
+			"error": "ExpectationFailed",
+			"message": "Error generating the teams",
+		}
+	}
+
+	return http.StatusCreated, map[string]interface{}{
+		"message": "Teams generated successfully",
+		"teams": teams,
+	}
+}
+
+func FindTeamsForAssignment(moduleCode string, assignmentId uint32) (int, map[string]interface{}) {
+	_, status, message := readTeamAssignment(moduleCode, assignmentId)
+	if status != http.StatusOK {
+		return status, message
+	}
+
+	teams, err := models.DBTeams.FindTeamsForAssignment(assignmentId)
+	if err != nil {
+		return http.StatusNotFound, map[string]interface{}{
+			"error": "NotFound",
+			"message": "Teams not found.",
+		}
+	}
+
+	return http.StatusOK, map[string]interface{}{
+		"teams": teams,
+	}
+}
+
+func GetUserTeam(moduleCode string, assignmentId, userId uint32) (int, map[string]interface{}) {
+	_, status, message := readTeamAssignment(moduleCode, assignmentId)
+	if status != http.StatusOK {
+		return status, message
+	}
+
+	team, err := models.DBTeams.FindTeamForUser(assignmentId, userId)
+	if err != nil || team == nil {
+		return http.StatusNotFound, map[string]interface{}{
+			"error": "NotFound",
+			"message": "You are not part of any team for this assignment.",
+		}
+	}
+
+	return http.StatusOK, map[string]interface{}{
+		"team": team,
+	}
+}
+
+func DeleteTeam(moduleCode string, assignmentId, teamId uint32) (int, map[string]interface{}) {
+	_, status, message := readTeamAssignment(moduleCode, assignmentId)
+	if status != http.StatusOK {
+		return status, message
+	}
+
+	rows, err := models.DBTeams.DeleteTeam(assignmentId, teamId)
+	if err != nil || rows <= 0 {
+		return http.StatusExpectationFailed, map[string]interface{}{
+			"error": "Unknown",
+			"message": "Error deleting the team",
+		}
+	}
+
+	return http.StatusAccepted, map[string]interface{}{
+		"message": fmt.Sprintf("Team %d removed", teamId),
+	}
+}
+
+func AddTeamMember(moduleCode string, assignmentId, teamId, userId uint32) (int, map[string]interface{}) {
+	_, status, message := readTeamAssignment(moduleCode, assignmentId)
+	if status != http.StatusOK {
+		return status, message
+	}
+
+	team, err := models.DBTeams.ReadTeam(assignmentId, teamId)
+	if err != nil || team == nil {
+		return http.StatusNotFound, map[string]interface{}{
+			"error": "NotFound",
+			"message": "Team not found.",
+		}
+	}
+
+	status, message = canJoinTeam(moduleCode, assignmentId, userId)
+	if status != http.StatusOK {
+		return status, message
+	}
+
+	member, err := models.DBTeams.AddTeamMember(teamId, userId)
+	if err != nil || member == nil {
+		return http.StatusExpectationFailed, map[string]interface{}{
+			"error": "ExpectationFailed",
+			"message": "Error adding the student to the team",
+		}
+	}
+
+	return http.StatusOK, map[string]interface{}{
+		"message": "Student added to the team",
+	}
+}
+
+func RemoveTeamMember(moduleCode string, assignmentId, teamId, userId uint32) (int, map[string]interface{}) {
+	_, status, message := readTeamAssignment(moduleCode, assignmentId)
+	if status != http.StatusOK {
+		return status, message
+	}
+
+	team, err := models.DBTeams.ReadTeam(assignmentId, teamId)
+	if err != nil || team == nil {
+		return http.StatusNotFound, map[string]interface{}{
+			"error": "NotFound",
+			"message": "Team not found.",
+		}
+	}
+
+	count, err := models.DBTeams.RemoveTeamMember(teamId, userId)
+	if err != nil || count <= 0 {
+		return http.StatusNotFound, map[string]interface{}{
+			"error": "NotFound",
+			"message": "That student is not part of the team.",
+		}
+	}
+
+	return http.StatusOK, map[string]interface{}{
+		"message": "Student removed from the team",
+	}
+}
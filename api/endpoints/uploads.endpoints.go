@@ -0,0 +1,185 @@
+package endpoints
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/YagoCarballo/kumquat-academy-api/database/models"
+	"github.com/YagoCarballo/kumquat-academy-api/tools"
+)
+
+// Who uploads a file and where to, which decides the limits the file is checked against
+type Upload struct {
+	UserID		uint32
+	// The module the file is added to, empty when it doesn't belong to one
+	ModuleCode	string
+	// Avatars have their own size limit and can only be images
+	Avatar		bool
+	// The extensions the file can have (like ".zip"), any extension when empty
+	Extensions	[]string
+}
+
+// Checks a file against the limits of where it's uploaded to, its type is found from its content.
+// Files too large (or over a quota) get a 413 and files of the wrong type a 415.
+func checkUpload(upload Upload, name string, size int64, content io.ReadSeeker) (string, int, FileResponseMessage) {
+	limits := tools.GetSettings().Uploads.WithDefaults()
+
+	status, message := checkSize(upload, name, size)
+	if status != http.StatusOK {
+		return "", status, message
+	}
+
+	if !tools.HasExtension(name, upload.Extensions) {
+		return "", http.StatusUnsupportedMediaType, FileResponseMessage{
+			Error: "UnsupportedType",
+			Message: fmt.Sprintf("Only %s files are allowed.", strings.Join(upload.Extensions, ", ")),
+		}
+	}
+
+	mimeType, err := tools.SniffContentType(name, content)
+	if err != nil {
+		return "", http.StatusExpectationFailed, FileResponseMessage{
+			Error: "ExpectationFailed",
+			Message: "Error when handling the uploaded file",
+		}
+	}
+
+	if upload.Avatar {
+		baseType, _, _ := mime.ParseMediaType(mimeType)
+		if !containsType(limits.AvatarTypes, baseType) {
+			return "", http.StatusUnsupportedMediaType, FileResponseMessage{
+				Error: "UnsupportedType",
+				Message: "Avatars must be PNG, JPEG, GIF or WebP images.",
+			}
+		}
+	}
+
+	status, message = checkQuota(upload, size)
+	if status != http.StatusOK {
+		return "", status, message
+	}
+
+	return mimeType, http.StatusOK, FileResponseMessage{}
+}
+
+// Checks the size of a single file, avatars have a smaller limit
+func checkSize(upload Upload, name string, size int64) (int, FileResponseMessage) {
+	limits := tools.GetSettings().Uploads.WithDefaults()
+
+	maxSize := limits.MaxSize
+	if upload.Avatar {
+		maxSize = limits.AvatarSize
+	}
+
+	if size > maxSize {
+		return http.StatusRequestEntityTooLarge, FileResponseMessage{
+			Error: "FileTooLarge",
+			Message: fmt.Sprintf("The file %s is larger than %s.", name, tools.FormatSize(maxSize)),
+		}
+	}
+
+	return http.StatusOK, FileResponseMessage{}
+}
+
+// Checks that the file fits in what is left of the quotas of the user and of the module
+func checkQuota(upload Upload, size int64) (int, FileResponseMessage) {
+	limits := tools.GetSettings().Uploads
+
+	if limits.UserQuota > 0 && upload.UserID != 0 {
+		used, err := models.DBAttachment.UserUsage(upload.UserID)
+		if err != nil || used + size > limits.UserQuota {
+			return http.StatusRequestEntityTooLarge, FileResponseMessage{
+				Error: "QuotaExceeded",
+				Message: fmt.Sprintf("The file doesn't fit in your quota, %s of %s are used.", tools.FormatSize(used), tools.FormatSize(limits.UserQuota)),
+			}
+		}
+	}
+
+	if limits.ModuleQuota > 0 && upload.ModuleCode != "" {
+		used, err := models.DBAttachment.ModuleUsage(upload.ModuleCode)
+		if err != nil || used + size > limits.ModuleQuota {
+			return http.StatusRequestEntityTooLarge, FileResponseMessage{
+				Error: "QuotaExceeded",
+				Message: fmt.Sprintf("The file doesn't fit in the quota of the module, %s of %s are used.", tools.FormatSize(used), tools.FormatSize(limits.ModuleQuota)),
+			}
+		}
+	}
+
+	return http.StatusOK, FileResponseMessage{}
+}
+
+// Checks how many files are sent at once
+func checkFileCount(count int) (int, map[string]interface{}) {
+	limits := tools.GetSettings().Uploads.WithDefaults()
+	if count > limits.MaxFiles {
+		return http.StatusRequestEntityTooLarge, map[string]interface{}{
+			"error": "TooManyFiles",
+			"message": fmt.Sprintf("Up to %d files can be uploaded at once.", limits.MaxFiles),
+		}
+	}
+
+	return http.StatusOK, nil
+}
+
+// The user and module kept on the attachment, so they count towards the quotas
+func userIdOf(upload Upload) *uint32 {
+	if upload.UserID == 0 {
+		return nil
+	}
+
+	return &upload.UserID
+}
+
+func moduleCodeOf(upload Upload) *string {
+	if upload.ModuleCode == "" {
+		return nil
+	}
+
+	return &upload.ModuleCode
+}
+
+func containsType(types []string, mimeType string) bool {
+	for _, allowed := range types {
+		if allowed == mimeType {
+			return true
+		}
+	}
+
+	return false
+}
+
+// The space used by the files of a user, and how much they can use
+func GetUserStorage(userId uint32) (int, map[string]interface{}) {
+	used, err := models.DBAttachment.UserUsage(userId)
+	if err != nil {
+		return http.StatusExpectationFailed, map[string]interface{}{
+			"error": "Unknown",
+			"message": "Error reading the storage used.",
+		}
+	}
+
+	return http.StatusOK, map[string]interface{}{
+		"user_id": userId,
+		"used": used,
+		"quota": tools.GetSettings().Uploads.UserQuota,
+	}
+}
+
+func GetModuleStorage(moduleCode string) (int, map[string]interface{}) {
+	used, err := models.DBAttachment.ModuleUsage(moduleCode)
+	if err != nil {
+		return http.StatusExpectationFailed, map[string]interface{}{
+			"error": "Unknown",
+			"message": "Error reading the storage used.",
+		}
+	}
+
+	return http.StatusOK, map[string]interface{}{
+		"module_code": moduleCode,
+		"used": used,
+		"quota": tools.GetSettings().Uploads.ModuleQuota,
+	}
+}
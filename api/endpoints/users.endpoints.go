@@ -1,60 +1,20 @@
 package endpoints
 
 import (
-	"fmt"
 	"time"
 	"net/http"
-	"net/smtp"
-	"net/textproto"
 
-	"github.com/YagoCarballo/kumquat-academy-api/tools"
+	"github.com/YagoCarballo/kumquat-academy-api/mailer"
 	"github.com/YagoCarballo/kumquat-academy-api/database/models"
 
-	emailHandler "github.com/jordan-wright/email"
 	"mime/multipart"
 )
 
+// Queues the welcome email with the link to set the password of a new student
 func emailInstructionsToSetPassword(email, token string) (error) {
-	emailSettings := tools.GetSettings().Email
-
-	resetUrl := fmt.Sprintf("%s%s", "https://kumquat.academy/password/reset/", token)
-
-	plainText := fmt.Sprintf(`
-		Welcome to Kumquat Academy!!\n\n
-		You are now registered as a Student, to access your learning follow the link and set your password.\n\n
-		%s\n\n
-		Thanks,\n
-		Kumquat Academy Team\n
-	`, resetUrl);
-
-	htmlText := fmt.Sprintf(`
-		<b>Welcome to Kumquat Academy!!</b>
-		<br />
-		<br />
-		You are now registered as a Student, to access your learning follow the link and set your password.
-		<br />
-		<br />
-		<p><a href="%s"><span style="color: rgb(0, 0, 0);">Click Here to set your password</span></a></p>
-		<br />
-		<p>Thanks,</p>
-		<p>Kumquat Academy Team</p>
-	`, resetUrl);
-
-
-	emailTemplate := &emailHandler.Email {
-		To: []string{ email },
-		From: emailSettings.Sender,
-		Subject: "Welcome to Kumquat Academy",
-		Text: []byte(plainText),
-		HTML: []byte(htmlText),
-		Headers: textproto.MIMEHeader{},
-	}
-
-	smtpServer := fmt.Sprintf("%s:%d", emailSettings.Server, emailSettings.Port)
-	err := emailTemplate.Send(
-		smtpServer,
-		smtp.PlainAuth("", emailSettings.User, emailSettings.Password, emailSettings.Server),
-	)
+	_, err := mailer.Queue(email, "welcome", "", map[string]interface{}{
+		"Token": token,
+	})
 
 	return err
 }
@@ -108,7 +68,7 @@ func CreateStudentAndAddToModule(moduleCode, firstName, lastName, username, emai
 	if err != nil {
 		return http.StatusExpectationFailed, map[string]interface{}{
 			"error":   "EmailError",
-			"message": "Error sending welcome email.",
+			"message": "Error queuing the welcome email.",
 		}
 	}
 
@@ -179,7 +139,7 @@ func UpdateStudent(userId uint32, moduleCode, firstName, lastName, username, ema
 }
 
 
-func SearchUsers(query, moduleCode string) (int, map[string]interface{}) {
+func SearchUsers(query, moduleCode string, showEmails bool) (int, map[string]interface{}) {
 	students, err := models.DBUser.SearchUsers(query, moduleCode)
 	if err != nil {
 		return http.StatusConflict, map[string]interface{}{
@@ -207,6 +167,10 @@ func SearchUsers(query, moduleCode string) (int, map[string]interface{}) {
 			studentMap["avatar"] = student.Avatar.Url
 		}
 
+		if !showEmails {
+			delete(studentMap, "email")
+		}
+
 		studentMaps = append(studentMaps, studentMap)
 	}
 
@@ -216,12 +180,9 @@ func SearchUsers(query, moduleCode string) (int, map[string]interface{}) {
 }
 
 func UploadAvatar(userId uint32, file multipart.File, header *multipart.FileHeader) (int, FileResponseMessage) {
-	status, response := UploadFile(file, header)
+	status, response := UploadFile(Upload{ UserID: userId, Avatar: true }, file, header)
 	if status != http.StatusOK {
-		return http.StatusExpectationFailed, FileResponseMessage{
-			Error: "Unknown",
-			Message: "Error uploading the file.",
-		}
+		return status, response
 	}
 
 	count, err := models.DBUser.AddAvatarToUser(userId, &response.Attachment.ID)
@@ -0,0 +1,187 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/zenazn/goji/web"
+
+	"github.com/YagoCarballo/kumquat-academy-api/tools"
+	"github.com/YagoCarballo/kumquat-academy-api/api/middlewares"
+	"github.com/YagoCarballo/kumquat-academy-api/api/endpoints"
+
+	. "github.com/YagoCarballo/kumquat-academy-api/constants"
+	"github.com/YagoCarballo/kumquat-academy-api/database/models"
+)
+
+func (api *API) LoadExamsEndpoints() {
+	api.routes.Put("/module/:moduleCode/exam", middlewares.Restricted(middlewares.Access{ Resource: ModuleResource, Param: "moduleCode", Right: WritePermission }, func(c web.C, w http.ResponseWriter, r *http.Request) {
+		moduleCode := c.URLParams["moduleCode"]
+
+		// Parse the JSON Body
+		var exam models.Exam
+		status, errMessage := tools.ParseBody(r.Body, &exam)
+		if status != http.StatusOK {
+			api.renderer.JSON(w, status, errMessage); return
+		}
+
+		// Process the action and Give the response
+		status, message := endpoints.CreateExam(exam, moduleCode)
+		api.renderer.JSON(w, status, message)
+	}, api.privateKey, api.publicKey))
+
+	api.routes.Get("/module/:moduleCode/exam/:examId", middlewares.Restricted(middlewares.Access{ Resource: ModuleResource, Param: "moduleCode", Right: ReadPermission }, func(c web.C, w http.ResponseWriter, r *http.Request) {
+		// Get and Parse the parameters
+		moduleCode := c.URLParams["moduleCode"]
+
+		examId, status, err := tools.ParseID(c.URLParams["examId"])
+		if status != http.StatusOK {
+			api.renderer.JSON(w, status, err); return
+		}
+
+		// Process the action and Give the response
+		status, message := endpoints.GetExam(moduleCode, examId)
+		api.renderer.JSON(w, status, message)
+	}, api.privateKey, api.publicKey))
+
+	api.routes.Post("/module/:moduleCode/exam/:examId", middlewares.Restricted(middlewares.Access{ Resource: ModuleResource, Param: "moduleCode", Right: UpdatePermission }, func(c web.C, w http.ResponseWriter, r *http.Request) {
+		// Get and Parse the parameters
+		moduleCode := c.URLParams["moduleCode"]
+		examId, status, err := tools.ParseID(c.URLParams["examId"])
+		if status != http.StatusOK {
+			api.renderer.JSON(w, status, err); return
+		}
+
+		// Parse the JSON Body
+		var exam models.Exam
+		status, errMessage := tools.ParseBody(r.Body, &exam)
+		if status != http.StatusOK {
+			api.renderer.JSON(w, status, errMessage); return
+		}
+
+		// Process the action and Give the response
+		status, message := endpoints.UpdateExam(moduleCode, examId, exam)
+		api.renderer.JSON(w, status, message)
+	}, api.privateKey, api.publicKey))
+
+	api.routes.Delete("/module/:moduleCode/exam/:examId", middlewares.Restricted(middlewares.Access{ Resource: ModuleResource, Param: "moduleCode", Right: DeletePermission }, func(c web.C, w http.ResponseWriter, r *http.Request) {
+		// Get and Parse the parameters
+		moduleCode := c.URLParams["moduleCode"]
+		examId, status, err := tools.ParseID(c.URLParams["examId"])
+		if status != http.StatusOK {
+			api.renderer.JSON(w, status, err); return
+		}
+
+		// Process the action and Give the response
+		status, message := endpoints.DeleteExam(moduleCode, examId)
+		api.renderer.JSON(w, status, message)
+	}, api.privateKey, api.publicKey))
+
+	api.routes.Get("/module/:moduleCode/exams", middlewares.Restricted(middlewares.Access{ Resource: ModuleResource, Param: "moduleCode", Right: ReadPermission }, func(c web.C, w http.ResponseWriter, r *http.Request) {
+		// Get and Parse the parameters
+		moduleCode := c.URLParams["moduleCode"]
+
+		// Process the action and Give the response
+		status, message := endpoints.FindExamsForModule(moduleCode)
+		api.renderer.JSON(w, status, message)
+	}, api.privateKey, api.publicKey))
+
+	api.routes.Put("/module/:moduleCode/exam/:examId/attachment", middlewares.Restricted(middlewares.Access{ Resource: ModuleResource, Param: "moduleCode", Right: WritePermission }, func(c web.C, w http.ResponseWriter, r *http.Request) {
+		// Get and Parse the parameters
+		var cookieData *tools.JWTSession = c.Env["token"].(*tools.JWTSession)
+		file, header, err := r.FormFile("file")
+		moduleCode := c.URLParams["moduleCode"]
+
+		examId, status, errMsg := tools.ParseID(c.URLParams["examId"])
+		if status != http.StatusOK {
+			api.renderer.JSON(w, status, errMsg); return
+		}
+
+		if err != nil {
+			api.renderer.JSON(w, http.StatusConflict, map[string]interface{}{
+				"error": "Conflict",
+				"message": "Invalid or Missing File",
+			}); return
+		}
+
+		// Process the action and Give the response
+		status, message := endpoints.UploadExamAttachment(cookieData.UserId, moduleCode, examId, file, header)
+		api.renderer.JSON(w, status, message)
+	}, api.privateKey, api.publicKey))
+
+	api.routes.Delete("/module/:moduleCode/exam/:examId/attachment/:attachmentId", middlewares.Restricted(middlewares.Access{ Resource: ModuleResource, Param: "moduleCode", Right: DeletePermission }, func(c web.C, w http.ResponseWriter, r *http.Request) {
+		// Get and Parse the parameters
+		moduleCode := c.URLParams["moduleCode"]
+		examId, status, err := tools.ParseID(c.URLParams["examId"])
+		if status != http.StatusOK {
+			api.renderer.JSON(w, status, err); return
+		}
+
+		attachmentId, status, err := tools.ParseID(c.URLParams["attachmentId"])
+		if status != http.StatusOK {
+			api.renderer.JSON(w, status, err); return
+		}
+
+		// Process the action and Give the response
+		status, message := endpoints.RemoveExamAttachment(moduleCode, examId, attachmentId)
+		api.renderer.JSON(w, status, message)
+	}, api.privateKey, api.publicKey))
+
+	api.routes.Put("/module/:moduleCode/exam/:examId/result", middlewares.Restricted(middlewares.Access{ Resource: ModuleResource, Param: "moduleCode", Right: WritePermission }, func(c web.C, w http.ResponseWriter, r *http.Request) {
+		// Get and Parse the parameters
+		moduleCode := c.URLParams["moduleCode"]
+		examId, status, err := tools.ParseID(c.URLParams["examId"])
+		if status != http.StatusOK {
+			api.renderer.JSON(w, status, err); return
+		}
+
+		// Parse the JSON Body
+		var result models.StudentExam
+		status, errMessage := tools.ParseBody(r.Body, &result)
+		if status != http.StatusOK {
+			api.renderer.JSON(w, status, errMessage); return
+		}
+
+		// Process the action and Give the response
+		status, message := endpoints.SetExamResult(moduleCode, examId, result.UserID, result.Grade, result.Status)
+		api.renderer.JSON(w, status, message)
+	}, api.privateKey, api.publicKey))
+
+	api.routes.Get("/module/:moduleCode/exam/:examId/results", middlewares.Restricted(middlewares.Access{ Resource: ModuleResource, Param: "moduleCode", Right: ReadPermission }, func(c web.C, w http.ResponseWriter, r *http.Request) {
+		// Get and Parse the parameters
+		var cookieData *tools.JWTSession = c.Env["token"].(*tools.JWTSession)
+		moduleCode := c.URLParams["moduleCode"]
+		examId, status, err := tools.ParseID(c.URLParams["examId"])
+		if status != http.StatusOK {
+			api.renderer.JSON(w, status, err); return
+		}
+
+		// Students can only see their own result
+		var userId *uint32
+		canWrite := models.DBPermissions.IsActionPermittedOnModuleWithCode(cookieData.UserId, moduleCode, WritePermission)
+		if !canWrite {
+			userId = &cookieData.UserId
+		}
+
+		// Process the action and Give the response
+		status, message := endpoints.FindExamResults(moduleCode, examId, userId)
+		api.renderer.JSON(w, status, message)
+	}, api.privateKey, api.publicKey))
+
+	api.routes.Delete("/module/:moduleCode/exam/:examId/result/:userId", middlewares.Restricted(middlewares.Access{ Resource: ModuleResource, Param: "moduleCode", Right: DeletePermission }, func(c web.C, w http.ResponseWriter, r *http.Request) {
+		// Get and Parse the parameters
+		moduleCode := c.URLParams["moduleCode"]
+		examId, status, err := tools.ParseID(c.URLParams["examId"])
+		if status != http.StatusOK {
+			api.renderer.JSON(w, status, err); return
+		}
+
+		userId, status, err := tools.ParseID(c.URLParams["userId"])
+		if status != http.StatusOK {
+			api.renderer.JSON(w, status, err); return
+		}
+
+		// Process the action and Give the response
+		status, message := endpoints.RemoveExamResult(moduleCode, examId, userId)
+		api.renderer.JSON(w, status, message)
+	}, api.privateKey, api.publicKey))
+}
@@ -0,0 +1,100 @@
+package api
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/zenazn/goji/web"
+
+	"github.com/YagoCarballo/kumquat-academy-api/tools"
+	"github.com/YagoCarballo/kumquat-academy-api/api/middlewares"
+	"github.com/YagoCarballo/kumquat-academy-api/api/endpoints"
+	"github.com/YagoCarballo/kumquat-academy-api/database/models"
+
+	. "github.com/YagoCarballo/kumquat-academy-api/constants"
+)
+
+func (api *API) LoadGradebookEndpoints() {
+	// Creates the GET -> /module/:moduleCode/gradebook endpoint
+	// Returns the weighted marks of the students of the module along with the class statistics
+	api.routes.Get("/module/:moduleCode/gradebook", middlewares.Restricted(middlewares.Access{ Resource: ModuleResource, Param: "moduleCode", Right: ReadPermission }, func(c web.C, w http.ResponseWriter, r *http.Request) {
+		var cookieData *tools.JWTSession = c.Env["token"].(*tools.JWTSession)
+		moduleCode := c.URLParams["moduleCode"]
+
+		// Students can only see their own marks
+		var userId *uint32
+		canGrade := models.DBPermissions.IsActionPermittedOnModuleWithCode(cookieData.UserId, moduleCode, GradeAssignmentPermission)
+		if !canGrade {
+			userId = &cookieData.UserId
+		}
+
+		// Process the action and Give the response
+		status, message := endpoints.FindGradebook(moduleCode, userId)
+		api.renderer.JSON(w, status, message)
+	}, api.privateKey, api.publicKey))
+
+	// Creates the GET -> /module/:moduleCode/assignment/:assignmentId/grades/export?format=csv|xlsx endpoint
+	// Returns the students of the assignment with the status and grade of their submissions
+	api.routes.Get("/module/:moduleCode/assignment/:assignmentId/grades/export", middlewares.Restricted(middlewares.Access{ Resource: ModuleResource, Param: "moduleCode", Right: GradeAssignmentPermission }, func(c web.C, w http.ResponseWriter, r *http.Request) {
+		// Get and Parse the parameters
+		moduleCode := c.URLParams["moduleCode"]
+		assignmentId, status, err := tools.ParseID(c.URLParams["assignmentId"])
+		if status != http.StatusOK {
+			api.renderer.JSON(w, status, err); return
+		}
+
+		format := r.URL.Query().Get("format")
+		if format == "" {
+			format = tools.SpreadsheetCSV
+		}
+
+		// Process the action and Give the response
+		name, bytes, status, message := endpoints.ExportAssignmentGrades(moduleCode, assignmentId, format)
+		if status != http.StatusOK {
+			api.renderer.JSON(w, status, message); return
+		}
+
+		headers := w.Header()
+		headers["Content-Type"] = []string{ tools.SpreadsheetContentType(format) }
+		headers["Content-Disposition"] = []string{ fmt.Sprintf("attachment; filename=\"%s\"", name) }
+		api.renderer.Data(w, http.StatusOK, *bytes)
+	}, api.privateKey, api.publicKey))
+
+	// Creates the PUT -> /module/:moduleCode/assignment/:assignmentId/grades/import?dry_run=true&overwrite=true endpoint
+	// Grades the submissions from a CSV or XLSX file, a dry run only reports what would change
+	api.routes.Put("/module/:moduleCode/assignment/:assignmentId/grades/import", middlewares.Restricted(middlewares.Access{ Resource: ModuleResource, Param: "moduleCode", Right: GradeAssignmentPermission }, func(c web.C, w http.ResponseWriter, r *http.Request) {
+		// Get and Parse the parameters
+		moduleCode := c.URLParams["moduleCode"]
+		file, header, err := r.FormFile("file")
+
+		assignmentId, status, errMsg := tools.ParseID(c.URLParams["assignmentId"])
+		if status != http.StatusOK {
+			api.renderer.JSON(w, status, errMsg); return
+		}
+
+		params := r.URL.Query()
+		dryRun := params.Get("dry_run") == "true"
+		overwrite := params.Get("overwrite") == "true"
+
+		if err != nil {
+			api.renderer.JSON(w, http.StatusConflict, map[string]interface{}{
+				"error": "Conflict",
+				"message": "Invalid or Missing File",
+			}); return
+		}
+		defer file.Close()
+
+		content, err := ioutil.ReadAll(file)
+		if err != nil {
+			api.renderer.JSON(w, http.StatusConflict, map[string]interface{}{
+				"error": "Conflict",
+				"message": "Invalid or Missing File",
+			}); return
+		}
+
+		// Process the action and Give the response
+		status, message := endpoints.ImportAssignmentGrades(moduleCode, assignmentId, header.Filename, content, dryRun, overwrite)
+		api.renderer.JSON(w, status, message)
+	}, api.privateKey, api.publicKey))
+}
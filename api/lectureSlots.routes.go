@@ -14,16 +14,9 @@ import (
 )
 
 func (api *API) LoadLectureSlotEndpoints() {
-	api.routes.Put("/module/:moduleCode/lecture-slot", middlewares.CheckSession(func(c web.C, w http.ResponseWriter, r *http.Request) {
-		var cookieData *tools.JWTSession = c.Env["token"].(*tools.JWTSession)
+	api.routes.Put("/module/:moduleCode/lecture-slot", middlewares.Restricted(middlewares.Access{ Resource: ModuleResource, Param: "moduleCode", Right: WritePermission }, func(c web.C, w http.ResponseWriter, r *http.Request) {
 		moduleCode := c.URLParams["moduleCode"]
 
-		// Does the user have enough access rights?
-		status, err := tools.VerifyAccess(moduleCode, cookieData.UserId, WritePermission, models.DBPermissions.IsActionPermittedOnModuleWithCode)
-		if status != http.StatusOK {
-			api.renderer.JSON(w, status, err); return
-		}
-
 		// Parse the JSON Body
 		var lectureSlot models.LectureSlot
 		status, errMessage := tools.ParseBody(r.Body, &lectureSlot)
@@ -42,29 +35,18 @@ func (api *API) LoadLectureSlotEndpoints() {
 		api.renderer.JSON(w, status, message)
 	}, api.privateKey, api.publicKey))
 
-	api.routes.Get("/module/:moduleCode/lecture-slot/:lectureSlotId", middlewares.CheckSession(func(c web.C, w http.ResponseWriter, r *http.Request) {
-		// Get and Parse the parameters
-		var cookieData *tools.JWTSession = c.Env["token"].(*tools.JWTSession)
-		moduleCode := c.URLParams["moduleCode"]
-
+	api.routes.Get("/module/:moduleCode/lecture-slot/:lectureSlotId", middlewares.Restricted(middlewares.Access{ Resource: ModuleResource, Param: "moduleCode", Right: ReadPermission }, func(c web.C, w http.ResponseWriter, r *http.Request) {
 		lectureSlotId, status, err := tools.ParseID(c.URLParams["lectureSlotId"])
 		if status != http.StatusOK {
 			api.renderer.JSON(w, status, err); return
 		}
 
-		// Does the user have enough access rights?
-		status, err = tools.VerifyAccess(moduleCode, cookieData.UserId, ReadPermission, models.DBPermissions.IsActionPermittedOnModuleWithCode)
-		if status != http.StatusOK {
-			api.renderer.JSON(w, status, err); return
-		}
-
 		// Process the action and Give the response
 		status, message := endpoints.GetLectureSlot(uint32(lectureSlotId))
 		api.renderer.JSON(w, status, message)
 	}, api.privateKey, api.publicKey))
 
-	api.routes.Post("/module/:moduleCode/lecture-slot/:lectureSlotId", middlewares.CheckSession(func(c web.C, w http.ResponseWriter, r *http.Request) {
-		var cookieData *tools.JWTSession = c.Env["token"].(*tools.JWTSession)
+	api.routes.Post("/module/:moduleCode/lecture-slot/:lectureSlotId", middlewares.Restricted(middlewares.Access{ Resource: ModuleResource, Param: "moduleCode", Right: UpdatePermission }, func(c web.C, w http.ResponseWriter, r *http.Request) {
 		moduleCode := c.URLParams["moduleCode"]
 
 		lectureSlotId, status, err := tools.ParseID(c.URLParams["lectureSlotId"])
@@ -72,12 +54,6 @@ func (api *API) LoadLectureSlotEndpoints() {
 			api.renderer.JSON(w, status, err); return
 		}
 
-		// Does the user have enough access rights?
-		status, err = tools.VerifyAccess(moduleCode, cookieData.UserId, UpdatePermission, models.DBPermissions.IsActionPermittedOnModuleWithCode)
-		if status != http.StatusOK {
-			api.renderer.JSON(w, status, err); return
-		}
-
 		// Parse the JSON Body
 		var lectureSlot models.LectureSlot
 		status, errMessage := tools.ParseBody(r.Body, &lectureSlot)
@@ -97,38 +73,21 @@ func (api *API) LoadLectureSlotEndpoints() {
 		api.renderer.JSON(w, status, message)
 	}, api.privateKey, api.publicKey))
 
-	api.routes.Delete("/module/:moduleCode/lecture-slot/:lectureSlotId", middlewares.CheckSession(func(c web.C, w http.ResponseWriter, r *http.Request) {
-		// Get and Parse the parameters
-		var cookieData *tools.JWTSession = c.Env["token"].(*tools.JWTSession)
-		moduleCode := c.URLParams["moduleCode"]
-
+	api.routes.Delete("/module/:moduleCode/lecture-slot/:lectureSlotId", middlewares.Restricted(middlewares.Access{ Resource: ModuleResource, Param: "moduleCode", Right: DeletePermission }, func(c web.C, w http.ResponseWriter, r *http.Request) {
 		lectureSlotId, status, err := tools.ParseID(c.URLParams["lectureSlotId"])
 		if status != http.StatusOK {
 			api.renderer.JSON(w, status, err); return
 		}
 
-		// Does the user have enough access rights?
-		status, err = tools.VerifyAccess(moduleCode, cookieData.UserId, DeletePermission, models.DBPermissions.IsActionPermittedOnModuleWithCode)
-		if status != http.StatusOK {
-			api.renderer.JSON(w, status, err); return
-		}
-
 		// Process the action and Give the response
 		status, message := endpoints.DeleteLectureSlot(uint32(lectureSlotId))
 		api.renderer.JSON(w, status, message)
 	}, api.privateKey, api.publicKey))
 
-	api.routes.Get("/module/:moduleCode/lecture-slots", middlewares.CheckSession(func(c web.C, w http.ResponseWriter, r *http.Request) {
+	api.routes.Get("/module/:moduleCode/lecture-slots", middlewares.Restricted(middlewares.Access{ Resource: ModuleResource, Param: "moduleCode", Right: ReadPermission }, func(c web.C, w http.ResponseWriter, r *http.Request) {
 		// Get and Parse the parameters
-		var cookieData *tools.JWTSession = c.Env["token"].(*tools.JWTSession)
 		moduleCode := c.URLParams["moduleCode"]
 
-		// Does the user have enough access rights?
-		status, err := tools.VerifyAccess(moduleCode, cookieData.UserId, ReadPermission, models.DBPermissions.IsActionPermittedOnModuleWithCode)
-		if status != http.StatusOK {
-			api.renderer.JSON(w, status, err); return
-		}
-
 		// Process the action and Give the response
 		status, message := endpoints.FindLectureSlotsForModule(moduleCode)
 		api.renderer.JSON(w, status, message)
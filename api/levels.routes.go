@@ -14,8 +14,7 @@ import (
 )
 
 func (api *API) LoadLevelsEndpoints() {
-	api.routes.Put("/course/:courseId/class/:classId/level", middlewares.CheckSession(func(c web.C, w http.ResponseWriter, r *http.Request) {
-		var cookieData *tools.JWTSession = c.Env["token"].(*tools.JWTSession)
+	api.routes.Put("/course/:courseId/class/:classId/level", middlewares.Restricted(middlewares.Access{ Resource: AdminResource, Right: WritePermission }, func(c web.C, w http.ResponseWriter, r *http.Request) {
 		courseId, status, err := tools.ParseID(c.URLParams["courseId"])
 		if status != http.StatusOK {
 			api.renderer.JSON(w, status, err); return
@@ -26,15 +25,6 @@ func (api *API) LoadLevelsEndpoints() {
 			api.renderer.JSON(w, status, err); return
 		}
 
-		// Does the user have enough access rights?
-		if cookieData.Admin == false {
-			api.renderer.JSON(w, http.StatusForbidden, map[string]interface{}{
-				"error":   "AccessDenied",
-				"message": "Not enough permissions to create a course.",
-			})
-			return
-		}
-
 		// Parse the JSON Body
 		var level models.CourseLevel
 		status, errMessage := tools.ParseBody(r.Body, &level)
@@ -48,10 +38,7 @@ func (api *API) LoadLevelsEndpoints() {
 	}, api.privateKey, api.publicKey))
 
 	// Creates the GET -> /course/:id endpoint
-	api.routes.Get("/course/:courseId/class/:classId/level/:level", middlewares.CheckSession(func(c web.C, w http.ResponseWriter, r *http.Request) {
-		// Get and Parse the parameters
-		var cookieData *tools.JWTSession = c.Env["token"].(*tools.JWTSession)
-
+	api.routes.Get("/course/:courseId/class/:classId/level/:level", middlewares.Restricted(middlewares.Access{ Resource: CourseResource, Param: "courseId", Right: ReadPermission }, func(c web.C, w http.ResponseWriter, r *http.Request) {
 		courseId, status, err := tools.ParseID(c.URLParams["courseId"])
 		if status != http.StatusOK {
 			api.renderer.JSON(w, status, err); return
@@ -67,21 +54,14 @@ func (api *API) LoadLevelsEndpoints() {
 			api.renderer.JSON(w, status, err); return
 		}
 
-		// Does the user have enough access rights?
-		status, err = tools.VerifyAccess(courseId, cookieData.UserId, ReadPermission, models.DBPermissions.IsActionPermittedOnCourse)
-		if status != http.StatusOK {
-			api.renderer.JSON(w, status, err); return
-		}
-
 		// Process the action and Give the response
 		status, message := endpoints.GetLevel(uint32(courseId), uint32(classId), uint32(level))
 		api.renderer.JSON(w, status, message)
 	}, api.privateKey, api.publicKey))
 
 	// Creates the POST -> /course endpoint
-	api.routes.Post("/course/:courseId/class/:classId/level/:level", middlewares.CheckSession(func(c web.C, w http.ResponseWriter, r *http.Request) {
+	api.routes.Post("/course/:courseId/class/:classId/level/:level", middlewares.Restricted(middlewares.Access{ Resource: CourseResource, Param: "courseId", Right: UpdatePermission }, func(c web.C, w http.ResponseWriter, r *http.Request) {
 		// Get and Parse the parameters
-		var cookieData *tools.JWTSession = c.Env["token"].(*tools.JWTSession)
 		courseId, status, err := tools.ParseID(c.URLParams["courseId"])
 		if status != http.StatusOK {
 			api.renderer.JSON(w, status, err); return
@@ -97,12 +77,6 @@ func (api *API) LoadLevelsEndpoints() {
 			api.renderer.JSON(w, status, err); return
 		}
 
-		// Does the user have enough access rights?
-		status, err = tools.VerifyAccess(courseId, cookieData.UserId, UpdatePermission, models.DBPermissions.IsActionPermittedOnCourse)
-		if status != http.StatusOK {
-			api.renderer.JSON(w, status, err); return
-		}
-
 		// Parse the JSON Body
 		var level models.CourseLevel
 		status, errMessage := tools.ParseBody(r.Body, &level)
@@ -116,9 +90,8 @@ func (api *API) LoadLevelsEndpoints() {
 	}, api.privateKey, api.publicKey))
 
 	// Creates the POST -> /course endpoint
-	api.routes.Delete("/course/:courseId/class/:classId/level/:level", middlewares.CheckSession(func(c web.C, w http.ResponseWriter, r *http.Request) {
+	api.routes.Delete("/course/:courseId/class/:classId/level/:level", middlewares.Restricted(middlewares.Access{ Resource: CourseResource, Param: "courseId", Right: DeletePermission }, func(c web.C, w http.ResponseWriter, r *http.Request) {
 		// Get and Parse the parameters
-		var cookieData *tools.JWTSession = c.Env["token"].(*tools.JWTSession)
 		courseId, status, err := tools.ParseID(c.URLParams["courseId"])
 		if status != http.StatusOK {
 			api.renderer.JSON(w, status, err); return
@@ -134,19 +107,12 @@ func (api *API) LoadLevelsEndpoints() {
 			api.renderer.JSON(w, status, err); return
 		}
 
-		// Does the user have enough access rights?
-		status, err = tools.VerifyAccess(courseId, cookieData.UserId, DeletePermission, models.DBPermissions.IsActionPermittedOnCourse)
-		if status != http.StatusOK {
-			api.renderer.JSON(w, status, err); return
-		}
-
 		// Process the action and Give the response
 		status, message := endpoints.DeleteLevel(courseId, classId, lvl)
 		api.renderer.JSON(w, status, message)
 	}, api.privateKey, api.publicKey))
 
-	api.routes.Put("/course/:courseId/class/:classId/level/:level/module", middlewares.CheckSession(func(c web.C, w http.ResponseWriter, r *http.Request) {
-		var cookieData *tools.JWTSession = c.Env["token"].(*tools.JWTSession)
+	api.routes.Put("/course/:courseId/class/:classId/level/:level/module", middlewares.Restricted(middlewares.Access{ Resource: AdminResource, Right: WritePermission }, func(c web.C, w http.ResponseWriter, r *http.Request) {
 		classId, status, err := tools.ParseID(c.URLParams["classId"])
 		if status != http.StatusOK {
 			api.renderer.JSON(w, status, err); return
@@ -157,15 +123,6 @@ func (api *API) LoadLevelsEndpoints() {
 			api.renderer.JSON(w, status, err); return
 		}
 
-		// Does the user have enough access rights?
-		if cookieData.Admin == false {
-			api.renderer.JSON(w, http.StatusForbidden, map[string]interface{}{
-				"error":   "AccessDenied",
-				"message": "Not enough permissions to create a course.",
-			})
-			return
-		}
-
 		// Parse the JSON Body
 		var levelModule models.LevelModule
 		status, errMessage := tools.ParseBody(r.Body, &levelModule)
@@ -178,15 +135,7 @@ func (api *API) LoadLevelsEndpoints() {
 		api.renderer.JSON(w, status, message)
 	}, api.privateKey, api.publicKey))
 
-	api.routes.Get("/course/:courseId/class/:classId/level/:level/modules", middlewares.CheckSession(func(c web.C, w http.ResponseWriter, r *http.Request) {
-		// Get and Parse the parameters
-		var cookieData *tools.JWTSession = c.Env["token"].(*tools.JWTSession)
-
-		courseId, status, err := tools.ParseID(c.URLParams["courseId"])
-		if status != http.StatusOK {
-			api.renderer.JSON(w, status, err); return
-		}
-
+	api.routes.Get("/course/:courseId/class/:classId/level/:level/modules", middlewares.Restricted(middlewares.Access{ Resource: CourseResource, Param: "courseId", Right: ReadPermission }, func(c web.C, w http.ResponseWriter, r *http.Request) {
 		classId, status, err := tools.ParseID(c.URLParams["classId"])
 		if status != http.StatusOK {
 			api.renderer.JSON(w, status, err); return
@@ -197,12 +146,6 @@ func (api *API) LoadLevelsEndpoints() {
 			api.renderer.JSON(w, status, err); return
 		}
 
-		// Does the user have enough access rights?
-		status, err = tools.VerifyAccess(courseId, cookieData.UserId, ReadPermission, models.DBPermissions.IsActionPermittedOnCourse)
-		if status != http.StatusOK {
-			api.renderer.JSON(w, status, err); return
-		}
-
 		// Process the action and Give the response
 		status, message := endpoints.GetModulesForLevel(uint32(classId), uint32(level))
 		api.renderer.JSON(w, status, message)
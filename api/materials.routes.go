@@ -0,0 +1,134 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/zenazn/goji/web"
+
+	"github.com/YagoCarballo/kumquat-academy-api/tools"
+	"github.com/YagoCarballo/kumquat-academy-api/api/middlewares"
+	"github.com/YagoCarballo/kumquat-academy-api/api/endpoints"
+	"github.com/YagoCarballo/kumquat-academy-api/database/models"
+
+	. "github.com/YagoCarballo/kumquat-academy-api/constants"
+)
+
+func (api *API) LoadMaterialsEndpoints() {
+	api.routes.Put("/module/:moduleCode/material", middlewares.Restricted(middlewares.Access{ Resource: ModuleResource, Param: "moduleCode", Right: WritePermission }, func(c web.C, w http.ResponseWriter, r *http.Request) {
+		moduleCode := c.URLParams["moduleCode"]
+
+		// Parse the JSON Body
+		var material models.Materials
+		status, errMessage := tools.ParseBody(r.Body, &material)
+		if status != http.StatusOK {
+			api.renderer.JSON(w, status, errMessage); return
+		}
+
+		// Process the action and Give the response
+		status, message := endpoints.CreateMaterial(moduleCode, material)
+		api.renderer.JSON(w, status, message)
+	}, api.privateKey, api.publicKey))
+
+	// Creates the GET -> /module/:moduleCode/materials?type=:type endpoint
+	// Returns the materials of the module grouped by week and lecture, optionally filtered by type
+	api.routes.Get("/module/:moduleCode/materials", middlewares.Restricted(middlewares.Access{ Resource: ModuleResource, Param: "moduleCode", Right: ReadPermission }, func(c web.C, w http.ResponseWriter, r *http.Request) {
+		moduleCode := c.URLParams["moduleCode"]
+		materialType := models.MaterialType(r.URL.Query().Get("type"))
+
+		// Process the action and Give the response
+		status, message := endpoints.FindMaterialsForModule(moduleCode, materialType)
+		api.renderer.JSON(w, status, message)
+	}, api.privateKey, api.publicKey))
+
+	// Creates the GET -> /module/:moduleCode/materials/week/:week/download endpoint
+	// Returns a ZIP with all the files of the week
+	api.routes.Get("/module/:moduleCode/materials/week/:week/download", middlewares.Restricted(middlewares.Access{ Resource: ModuleResource, Param: "moduleCode", Right: ReadPermission }, func(c web.C, w http.ResponseWriter, r *http.Request) {
+		// Get and Parse the parameters
+		moduleCode := c.URLParams["moduleCode"]
+		week, status, err := tools.ParseID(c.URLParams["week"])
+		if status != http.StatusOK {
+			api.renderer.JSON(w, status, err); return
+		}
+
+		// Process the action and Give the response
+		name, bytes, status, message := endpoints.ZipWeekMaterials(moduleCode, week)
+		if status != http.StatusOK {
+			api.renderer.JSON(w, status, message); return
+		}
+
+		headers := w.Header()
+		headers["Content-Type"] = []string{ "application/zip" }
+		headers["Content-Disposition"] = []string{ fmt.Sprintf("attachment; filename=\"%s\"", name) }
+		api.renderer.Data(w, http.StatusOK, *bytes)
+	}, api.privateKey, api.publicKey))
+
+	api.routes.Get("/module/:moduleCode/material/:materialId", middlewares.Restricted(middlewares.Access{ Resource: ModuleResource, Param: "moduleCode", Right: ReadPermission }, func(c web.C, w http.ResponseWriter, r *http.Request) {
+		// Get and Parse the parameters
+		moduleCode := c.URLParams["moduleCode"]
+		materialId, status, err := tools.ParseID(c.URLParams["materialId"])
+		if status != http.StatusOK {
+			api.renderer.JSON(w, status, err); return
+		}
+
+		// Process the action and Give the response
+		status, message := endpoints.GetMaterial(moduleCode, materialId)
+		api.renderer.JSON(w, status, message)
+	}, api.privateKey, api.publicKey))
+
+	api.routes.Post("/module/:moduleCode/material/:materialId", middlewares.Restricted(middlewares.Access{ Resource: ModuleResource, Param: "moduleCode", Right: UpdatePermission }, func(c web.C, w http.ResponseWriter, r *http.Request) {
+		// Get and Parse the parameters
+		moduleCode := c.URLParams["moduleCode"]
+		materialId, status, err := tools.ParseID(c.URLParams["materialId"])
+		if status != http.StatusOK {
+			api.renderer.JSON(w, status, err); return
+		}
+
+		// Parse the JSON Body
+		var material models.Materials
+		status, errMessage := tools.ParseBody(r.Body, &material)
+		if status != http.StatusOK {
+			api.renderer.JSON(w, status, errMessage); return
+		}
+
+		// Process the action and Give the response
+		status, message := endpoints.UpdateMaterial(moduleCode, materialId, material)
+		api.renderer.JSON(w, status, message)
+	}, api.privateKey, api.publicKey))
+
+	api.routes.Delete("/module/:moduleCode/material/:materialId", middlewares.Restricted(middlewares.Access{ Resource: ModuleResource, Param: "moduleCode", Right: DeletePermission }, func(c web.C, w http.ResponseWriter, r *http.Request) {
+		// Get and Parse the parameters
+		moduleCode := c.URLParams["moduleCode"]
+		materialId, status, err := tools.ParseID(c.URLParams["materialId"])
+		if status != http.StatusOK {
+			api.renderer.JSON(w, status, err); return
+		}
+
+		// Process the action and Give the response
+		status, message := endpoints.DeleteMaterial(moduleCode, materialId)
+		api.renderer.JSON(w, status, message)
+	}, api.privateKey, api.publicKey))
+
+	api.routes.Put("/module/:moduleCode/material/:materialId/attachment", middlewares.Restricted(middlewares.Access{ Resource: ModuleResource, Param: "moduleCode", Right: WritePermission }, func(c web.C, w http.ResponseWriter, r *http.Request) {
+		// Get and Parse the parameters
+		var cookieData *tools.JWTSession = c.Env["token"].(*tools.JWTSession)
+		moduleCode := c.URLParams["moduleCode"]
+		file, header, err := r.FormFile("file")
+
+		materialId, status, errMsg := tools.ParseID(c.URLParams["materialId"])
+		if status != http.StatusOK {
+			api.renderer.JSON(w, status, errMsg); return
+		}
+
+		if err != nil {
+			api.renderer.JSON(w, http.StatusConflict, map[string]interface{}{
+				"error": "Conflict",
+				"message": "Invalid or Missing File",
+			}); return
+		}
+
+		// Process the action and Give the response
+		status, message := endpoints.UploadMaterialAttachment(cookieData.UserId, moduleCode, materialId, file, header)
+		api.renderer.JSON(w, status, message)
+	}, api.privateKey, api.publicKey))
+}
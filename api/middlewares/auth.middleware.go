@@ -1,6 +1,9 @@
 package middlewares
 
 import (
+	"errors"
+	"log"
+	"time"
 	"strings"
 	"net/http"
 	"crypto/rsa"
@@ -12,73 +15,131 @@ import (
 
 	"github.com/YagoCarballo/kumquat-academy-api/tools"
 	"github.com/YagoCarballo/kumquat-academy-api/database/models"
+
+	. "github.com/YagoCarballo/kumquat-academy-api/constants"
 )
 
-type Permissions struct {
-	Read  bool
-	Write bool
+var ErrInvalidAuthorization = errors.New("The Authorization header must be a Bearer token.")
+
+// What a restricted route needs: an action on a resource, the ID (or code) of the resource is read from a URL parameter.
+// Admin routes don't need a parameter.
+type Access struct {
+	Resource	ResourceType
+	Param		string
+	Right		AccessRight
 }
 
 type checkToken struct {
 	h           func(web.C, http.ResponseWriter, *http.Request)
-	permissions *Permissions
+	access		*Access
 	privateKey	*rsa.PrivateKey
 	publicKey	*rsa.PublicKey
 }
 
 func (e checkToken) checkPermisions(c web.C, w http.ResponseWriter, r *http.Request) {
-	// TODO: Check Permissions for Restricted Areas
-	if e.permissions.Write {
-		c.Env["permissions"] = &e.permissions
-		e.h(c, w, r)
-		return
+	tokenData := c.Env["token"].(*tools.JWTSession)
+	renderer := render.New()
+
+	// Modules are found by their code and attachments by their token, the rest of resources by their ID
+	var id interface{} = c.URLParams[e.access.Param]
+	if e.access.Resource != ModuleResource && e.access.Resource != AttachmentResource && e.access.Resource != AdminResource {
+		parsedId, status, err := tools.ParseID(c.URLParams[e.access.Param])
+		if status != http.StatusOK {
+			renderer.JSON(w, status, err); return
+		}
+		id = parsedId
 	}
 
-	renderer := render.New()
-	renderer.JSON(w, http.StatusForbidden, map[string]interface{}{
-		"error":   "AccessDenied",
-		"message": "Not enough permissions to access this area.",
+	status, err := tools.VerifyAccess(id, tokenData.UserId, e.access.Right, func(userId uint32, id interface{}, action AccessRight) bool {
+		return models.DBPermissions.IsActionPermitted(userId, e.access.Resource, id, action)
 	})
+	if status != http.StatusOK {
+		renderer.JSON(w, status, err); return
+	}
+
+	c.Env["access"] = e.access
+	e.h(c, w, r)
 }
 
 func (e checkToken) ServeHTTPC(context web.C, writter http.ResponseWriter, request *http.Request) {
-	var authorization string
-	//	deviceId := r.Header.Get("Device") // TODO: Check Device ID matches token
+	tokenData, err := e.readToken(request)
+	if err != nil || tokenData == nil {
+		TriggerUnautorizedError(writter); return
+	}
 
-	cookie, err := request.Cookie("token")
-	if err != nil {
+	session, err := models.DBSession.FindSession(tokenData.AccessToken)
+	if err != nil || session == nil {
 		TriggerUnautorizedError(writter); return
-	} else if (err != http.ErrNoCookie) {
-		cookieData, err := e.parseCookie(cookie)
-		if err != nil {
-			TriggerUnautorizedError(writter); return
-		}
+	}
 
-		authorization = cookieData.AccessToken
-		context.Env["token"] = cookieData
+	// The token only works on the device it was given to
+	deviceId := request.Header.Get("Device")
+	if tokenData.Device != session.DeviceID || (deviceId != "" && deviceId != session.DeviceID) {
+		TriggerUnautorizedError(writter); return
 	}
 
-	if authorization == "" && strings.HasPrefix(authorization, "Bearer ") == false {
+	// The details of the user are read again, in case they changed since signing in
+	user, err := models.DBUser.FindUserWithId(session.UserID)
+	if err != nil || user == nil {
 		TriggerUnautorizedError(writter); return
+	}
+
+	// Using the session keeps it alive
+	err = models.DBSession.TouchSession(session, time.Now())
+	if err != nil {
+		log.Printf("Error updating the session: %s\n", err)
+	}
+
+	context.Env["token"] = &tools.JWTSession{
+		AccessToken:	session.Token,
+		UserId:			session.UserID,
+		ExpiresIn:		session.ExpiresIn,
+		Device:			session.DeviceID,
+		Username:		user.Username,
+		Admin:			user.Admin,
+	}
+	context.Env["session"] = session
+
+	// If URL is restricted, check permissions as well
+	if e.access != nil {
+		e.checkPermisions(context, writter, request)
 
 	} else {
-		token := strings.Replace(authorization, "Bearer ", "", 1)
-		session, err := models.DBSession.FindSession(token)
+		e.h(context, writter, request)
+	}
+}
 
-		if err != nil || session == nil {
-			TriggerUnautorizedError(writter); return
-		} else {
-			context.Env["session"] = &session
+// Reads the token from the Authorization header (Bearer) or else from the session cookie.
+// Bearer tokens can be the plain access token, sent with the Device header, or the encrypted token of the cookie.
+func (e checkToken) readToken(request *http.Request) (*tools.JWTSession, error) {
+	authorization := request.Header.Get("Authorization")
+	if authorization != "" {
+		if !strings.HasPrefix(authorization, "Bearer ") {
+			return nil, ErrInvalidAuthorization
+		}
 
-			// If URL is restricted, check permissions as well
-			if e.permissions != nil {
-				e.checkPermisions(context, writter, request)
+		token := strings.TrimSpace(strings.TrimPrefix(authorization, "Bearer "))
+		if token == "" {
+			return nil, ErrInvalidAuthorization
+		}
 
-			} else {
-				e.h(context, writter, request)
-			}
+		// Encrypted tokens (JWE) have five parts separated by dots
+		if strings.Count(token, ".") == 4 {
+			return e.decodeToken(token)
 		}
+
+		return &tools.JWTSession{
+			AccessToken: token,
+			Device: request.Header.Get("Device"),
+		}, nil
+	}
+
+	cookie, err := request.Cookie("token")
+	if err != nil {
+		return nil, err
 	}
+
+	return e.parseCookie(cookie)
 }
 
 func TriggerUnautorizedError(writter http.ResponseWriter) {
@@ -90,27 +151,32 @@ func TriggerUnautorizedError(writter http.ResponseWriter) {
 }
 
 func (e checkToken) parseCookie(cookie *http.Cookie) (*tools.JWTSession, error) {
-	var cookieData tools.JWTSession
+	return e.decodeToken(cookie.Value)
+}
+
+func (e checkToken) decodeToken(token string) (*tools.JWTSession, error) {
+	var tokenData tools.JWTSession
 
-	// Decodes the Encripted Cookie JWT Token
-	payload, _, err := jose.Decode(cookie.Value, e.privateKey)
+	// Decodes the Encripted JWT Token
+	payload, _, err := jose.Decode(token, e.privateKey)
 	if err != nil {
-		return &cookieData, err
+		return &tokenData, err
 	}
 
 	// Parses the Decoded JSON
-	err = json.Unmarshal([]byte(payload), &cookieData)
+	err = json.Unmarshal([]byte(payload), &tokenData)
 	if err != nil {
-		return &cookieData, err
+		return &tokenData, err
 	}
 
-	return &cookieData, nil
+	return &tokenData, nil
 }
 
 func CheckSession(h func(web.C, http.ResponseWriter, *http.Request), privateKey *rsa.PrivateKey, publicKey *rsa.PublicKey) web.Handler {
 	return checkToken{h, nil, privateKey, publicKey}
 }
 
-func Restricted(permissions Permissions, h func(web.C, http.ResponseWriter, *http.Request), privateKey *rsa.PrivateKey, publicKey *rsa.PublicKey) web.Handler {
-	return checkToken{h, &permissions, privateKey, publicKey}
+// Checks the session and that the user can do the action on the resource of the route
+func Restricted(access Access, h func(web.C, http.ResponseWriter, *http.Request), privateKey *rsa.PrivateKey, publicKey *rsa.PublicKey) web.Handler {
+	return checkToken{h, &access, privateKey, publicKey}
 }
@@ -0,0 +1,218 @@
+package middlewares
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/franela/goblin"
+	"github.com/dvsekhvalnov/jose2go"
+	"github.com/zenazn/goji/web"
+
+	"github.com/YagoCarballo/kumquat-academy-api/database"
+	"github.com/YagoCarballo/kumquat-academy-api/database/models"
+	"github.com/YagoCarballo/kumquat-academy-api/tools"
+
+	. "github.com/YagoCarballo/kumquat-academy-api/constants"
+)
+
+// The path to the Settings file
+const SETTINGS_PATH = "test-settings.toml"
+
+func init() {
+	tools.LoadSettings(SETTINGS_PATH)
+	database.InitDatabase()
+
+	// Brings a fresh database to the current schema and loads the fixtures
+	database.Migrate()
+	models.Seed(false)
+}
+
+// Sends a request through the CheckSession middleware, returning the status and the session it saw
+func checkRequest(request *http.Request, privateKey *rsa.PrivateKey) (int, *tools.JWTSession) {
+	var seen *tools.JWTSession
+	handler := CheckSession(func(c web.C, w http.ResponseWriter, r *http.Request) {
+		seen = c.Env["token"].(*tools.JWTSession)
+		w.WriteHeader(http.StatusOK)
+	}, privateKey, &privateKey.PublicKey)
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTPC(web.C{ Env: map[interface{}]interface{}{} }, recorder, request)
+	return recorder.Code, seen
+}
+
+// Sends a request through the Restricted middleware with the given URL parameters
+func restrictedRequest(access Access, params map[string]string, request *http.Request, privateKey *rsa.PrivateKey) int {
+	handler := Restricted(access, func(c web.C, w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}, privateKey, &privateKey.PublicKey)
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTPC(web.C{ Env: map[interface{}]interface{}{}, URLParams: params }, recorder, request)
+	return recorder.Code
+}
+
+func bearerRequest(session *models.Session) *http.Request {
+	request, _ := http.NewRequest("GET", "/restricted", nil)
+	request.Header.Set("Authorization", "Bearer " + session.Token)
+	request.Header.Set("Device", session.DeviceID)
+	return request
+}
+
+func encryptToken(session *models.Session, publicKey *rsa.PublicKey) string {
+	payload, _ := json.Marshal(tools.JWTSession{ AccessToken: session.Token, UserId: session.UserID, Device: session.DeviceID })
+	token, _ := jose.Encrypt(string(payload), jose.RSA_OAEP, jose.A256GCM, publicKey)
+	return token
+}
+
+func Test_Middleware_Auth(t *testing.T) {
+	g := Goblin(t)
+	privateKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	var session *models.Session
+
+	g.Describe("When checking the session of a request", func() {
+		g.Before(func() {
+			session, _ = models.DBSession.Create(2, "-middleware-phone-")
+		})
+
+		g.It("Should reject a request without credentials", func() {
+			request, _ := http.NewRequest("GET", "/private", nil)
+			status, _ := checkRequest(request, privateKey)
+			g.Assert(status).Equal(http.StatusUnauthorized)
+		})
+
+		g.It("Should accept a plain Bearer token from its device", func() {
+			request, _ := http.NewRequest("GET", "/private", nil)
+			request.Header.Set("Authorization", "Bearer " + session.Token)
+			request.Header.Set("Device", "-middleware-phone-")
+
+			status, seen := checkRequest(request, privateKey)
+			g.Assert(status).Equal(http.StatusOK)
+			g.Assert(seen.UserId).Equal(uint32(2))
+			g.Assert(seen.Username).Equal("teacher")
+		})
+
+		g.It("Should reject a plain Bearer token from another device", func() {
+			request, _ := http.NewRequest("GET", "/private", nil)
+			request.Header.Set("Authorization", "Bearer " + session.Token)
+			request.Header.Set("Device", "-another-device-")
+
+			status, _ := checkRequest(request, privateKey)
+			g.Assert(status).Equal(http.StatusUnauthorized)
+
+			// Or without saying which device it is
+			request.Header.Del("Device")
+			status, _ = checkRequest(request, privateKey)
+			g.Assert(status).Equal(http.StatusUnauthorized)
+		})
+
+		g.It("Should accept an encrypted Bearer token", func() {
+			request, _ := http.NewRequest("GET", "/private", nil)
+			request.Header.Set("Authorization", "Bearer " + encryptToken(session, &privateKey.PublicKey))
+
+			status, seen := checkRequest(request, privateKey)
+			g.Assert(status).Equal(http.StatusOK)
+			g.Assert(seen.AccessToken).Equal(session.Token)
+		})
+
+		g.It("Should reject a token encrypted with another key", func() {
+			otherKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+
+			request, _ := http.NewRequest("GET", "/private", nil)
+			request.Header.Set("Authorization", "Bearer " + encryptToken(session, &otherKey.PublicKey))
+
+			status, _ := checkRequest(request, privateKey)
+			g.Assert(status).Equal(http.StatusUnauthorized)
+		})
+
+		g.It("Should reject other kinds of Authorization", func() {
+			request, _ := http.NewRequest("GET", "/private", nil)
+			request.Header.Set("Authorization", "Basic " + session.Token)
+
+			status, _ := checkRequest(request, privateKey)
+			g.Assert(status).Equal(http.StatusUnauthorized)
+		})
+
+		g.It("Should accept the session cookie", func() {
+			request, _ := http.NewRequest("GET", "/private", nil)
+			request.AddCookie(&http.Cookie{ Name: "token", Value: encryptToken(session, &privateKey.PublicKey) })
+
+			status, seen := checkRequest(request, privateKey)
+			g.Assert(status).Equal(http.StatusOK)
+			g.Assert(seen.Device).Equal("-middleware-phone-")
+
+			// The Device header has to match when it is sent
+			request.Header.Set("Device", "-another-device-")
+			status, _ = checkRequest(request, privateKey)
+			g.Assert(status).Equal(http.StatusUnauthorized)
+		})
+
+		g.It("Should reject a revoked session", func() {
+			models.DBSession.RemoveSession(session.Token)
+
+			request, _ := http.NewRequest("GET", "/private", nil)
+			request.Header.Set("Authorization", "Bearer " + session.Token)
+			request.Header.Set("Device", "-middleware-phone-")
+
+			status, _ := checkRequest(request, privateKey)
+			g.Assert(status).Equal(http.StatusUnauthorized)
+		})
+
+		g.After(func() {
+			models.DBSession.DB().Where("device_id = ?", "-middleware-phone-").Delete(models.Session{})
+		})
+	})
+}
+
+func Test_Middleware_Restricted(t *testing.T) {
+	g := Goblin(t)
+	privateKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	var admin, teacher, student *models.Session
+
+	g.Describe("When checking the access a route needs", func() {
+		g.Before(func() {
+			admin, _ = models.DBSession.Create(1, "-restricted-admin-")
+			teacher, _ = models.DBSession.Create(2, "-restricted-teacher-")
+			student, _ = models.DBSession.Create(3, "-restricted-student-")
+		})
+
+		g.It("Should find modules by their code", func() {
+			access := Access{ Resource: ModuleResource, Param: "moduleCode", Right: WritePermission }
+			params := map[string]string{ "moduleCode": "AC31007" }
+
+			g.Assert(restrictedRequest(access, params, bearerRequest(teacher), privateKey)).Equal(http.StatusOK)
+			g.Assert(restrictedRequest(access, params, bearerRequest(student), privateKey)).Equal(http.StatusForbidden)
+		})
+
+		g.It("Should find courses by their ID", func() {
+			access := Access{ Resource: CourseResource, Param: "courseId", Right: UpdatePermission }
+
+			status := restrictedRequest(access, map[string]string{ "courseId": "2" }, bearerRequest(teacher), privateKey)
+			g.Assert(status).Equal(http.StatusOK)
+
+			status = restrictedRequest(access, map[string]string{ "courseId": "abc" }, bearerRequest(teacher), privateKey)
+			g.Assert(status).Equal(http.StatusConflict)
+		})
+
+		g.It("Should only let admins in admin routes", func() {
+			access := Access{ Resource: AdminResource, Right: ReadPermission }
+
+			g.Assert(restrictedRequest(access, nil, bearerRequest(admin), privateKey)).Equal(http.StatusOK)
+			g.Assert(restrictedRequest(access, nil, bearerRequest(teacher), privateKey)).Equal(http.StatusForbidden)
+		})
+
+		g.It("Should check the session before the access", func() {
+			access := Access{ Resource: AdminResource, Right: ReadPermission }
+			request, _ := http.NewRequest("GET", "/restricted", nil)
+
+			g.Assert(restrictedRequest(access, nil, request, privateKey)).Equal(http.StatusUnauthorized)
+		})
+
+		g.After(func() {
+			models.DBSession.DB().Where("device_id LIKE ?", "-restricted-%").Delete(models.Session{})
+		})
+	})
+}
@@ -0,0 +1,40 @@
+package middlewares
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/unrolled/render"
+
+	"github.com/YagoCarballo/kumquat-academy-api/tools"
+)
+
+// Room left for the form fields and the headers of each part of a multipart body
+const MULTIPART_OVERHEAD = 1 << 20
+
+// Limits the size of multipart bodies to as many files as can be uploaded at once,
+// so a request too large is rejected before it is written to the disk.
+// Each file is checked on its own once the form is read.
+func LimitUploads(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/") {
+			h.ServeHTTP(w, r); return
+		}
+
+		limits := tools.GetSettings().Uploads.WithDefaults()
+		maxBody := limits.MaxSize * int64(limits.MaxFiles) + MULTIPART_OVERHEAD
+
+		if r.ContentLength > maxBody {
+			renderer := render.New()
+			renderer.JSON(w, http.StatusRequestEntityTooLarge, map[string]interface{}{
+				"error": "RequestTooLarge",
+				"message": fmt.Sprintf("Uploads can't be larger than %s.", tools.FormatSize(maxBody)),
+			}); return
+		}
+
+		// The length can be missing (or wrong), so the body is cut off after the limit as well
+		r.Body = http.MaxBytesReader(w, r.Body, maxBody)
+		h.ServeHTTP(w, r)
+	})
+}
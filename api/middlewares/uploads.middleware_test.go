@@ -0,0 +1,79 @@
+package middlewares
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	. "github.com/franela/goblin"
+
+	"github.com/YagoCarballo/kumquat-academy-api/tools"
+)
+
+// Sends a request through the LimitUploads middleware, returning the status and whether the body could be read
+func limitRequest(request *http.Request) (int, bool) {
+	readBody := false
+	handler := LimitUploads(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, err := ioutil.ReadAll(r.Body)
+		readBody = err == nil
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+	return recorder.Code, readBody
+}
+
+func Test_Upload_Limits(t *testing.T) {
+	g := Goblin(t)
+
+	// Small limits, so a body over them is quick to send
+	settings := tools.GetSettings()
+	previous := settings.Uploads
+	settings.Uploads = tools.Uploads{ MaxSize: 1024, MaxFiles: 2 }
+	defer func() { settings.Uploads = previous }()
+
+	maxBody := int64(2 * 1024 + MULTIPART_OVERHEAD)
+
+	g.Describe("When limiting the size of the uploads", func() {
+		g.It("Should let through an upload within the limits", func() {
+			request, _ := http.NewRequest("PUT", "/attachment", strings.NewReader("--boundary--"))
+			request.Header.Set("Content-Type", "multipart/form-data; boundary=boundary")
+
+			status, readBody := limitRequest(request)
+			g.Assert(status).Equal(http.StatusOK)
+			g.Assert(readBody).IsTrue()
+		})
+
+		g.It("Should reject an upload announced as too large", func() {
+			request, _ := http.NewRequest("PUT", "/attachment", strings.NewReader("--boundary--"))
+			request.Header.Set("Content-Type", "multipart/form-data; boundary=boundary")
+			request.ContentLength = maxBody + 1
+
+			status, readBody := limitRequest(request)
+			g.Assert(status).Equal(http.StatusRequestEntityTooLarge)
+			g.Assert(readBody).IsFalse()
+		})
+
+		g.It("Should stop reading an upload that is larger than it said", func() {
+			body := strings.NewReader(strings.Repeat("x", int(maxBody) + 1))
+			request, _ := http.NewRequest("PUT", "/attachment", ioutil.NopCloser(body))
+			request.Header.Set("Content-Type", "multipart/form-data; boundary=boundary")
+			request.ContentLength = -1
+
+			_, readBody := limitRequest(request)
+			g.Assert(readBody).IsFalse()
+		})
+
+		g.It("Should not limit the requests that are not uploads", func() {
+			request, _ := http.NewRequest("POST", "/module", strings.NewReader("{}"))
+			request.Header.Set("Content-Type", "application/json")
+			request.ContentLength = maxBody + 1
+
+			status, _ := limitRequest(request)
+			g.Assert(status).Equal(http.StatusOK)
+		})
+	})
+}
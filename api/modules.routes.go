@@ -34,16 +34,7 @@ func (api *API) LoadModulesEndpoints() {
 	}, api.privateKey, api.publicKey))
 
 	// Creates the GET -> /modules/raw endpoint
-	api.routes.Get("/modules/raw", middlewares.CheckSession(func(c web.C, w http.ResponseWriter, r *http.Request) {
-		var cookieData *tools.JWTSession = c.Env["token"].(*tools.JWTSession)
-
-		if !cookieData.Admin {
-			api.renderer.JSON(w, http.StatusForbidden, map[string]interface{}{
-				"error": "AccessDenied",
-				"message": "Not enough permissions to access this area.",
-			}); return
-		}
-
+	api.routes.Get("/modules/raw", middlewares.Restricted(middlewares.Access{ Resource: AdminResource, Right: ReadPermission }, func(c web.C, w http.ResponseWriter, r *http.Request) {
 		params := r.URL.Query()
 		query := params.Get("q")
 		page, status, _ := tools.ParseID(params.Get("page"))
@@ -64,17 +55,7 @@ func (api *API) LoadModulesEndpoints() {
 
 	}, api.privateKey, api.publicKey))
 
-	api.routes.Put("/module", middlewares.CheckSession(func(c web.C, w http.ResponseWriter, r *http.Request) {
-		var cookieData *tools.JWTSession = c.Env["token"].(*tools.JWTSession)
-
-		if cookieData.Admin == false {
-			api.renderer.JSON(w, http.StatusForbidden, map[string]interface{}{
-				"error":   "AccessDenied",
-				"message": "Not enough permissions to create a module.",
-			})
-			return
-		}
-
+	api.routes.Put("/module", middlewares.Restricted(middlewares.Access{ Resource: AdminResource, Right: WritePermission }, func(c web.C, w http.ResponseWriter, r *http.Request) {
 		decoder := json.NewDecoder(r.Body)
 		var module models.Module
 		err := decoder.Decode(&module)
@@ -98,23 +79,16 @@ func (api *API) LoadModulesEndpoints() {
 
 	}, api.privateKey, api.publicKey))
 
-	api.routes.Get("/module/:moduleCode/students", middlewares.CheckSession(func(c web.C, w http.ResponseWriter, r *http.Request) {
+	api.routes.Get("/module/:moduleCode/students", middlewares.Restricted(middlewares.Access{ Resource: ModuleResource, Param: "moduleCode", Right: ReadPermission }, func(c web.C, w http.ResponseWriter, r *http.Request) {
 		var cookieData *tools.JWTSession = c.Env["token"].(*tools.JWTSession)
 		var moduleCode = c.URLParams["moduleCode"]
 
-		// Does the user have enough access rights?
-		status, err := tools.VerifyAccess(moduleCode, cookieData.UserId, ReadPermission, models.DBPermissions.IsActionPermittedOnModuleWithCode)
-		if status != http.StatusOK {
-			api.renderer.JSON(w, status, err); return
-		}
-
-		status, message := endpoints.GetStudentsForModule(moduleCode)
+		showEmails := models.DBPermissions.IsActionPermittedOnModuleWithCode(cookieData.UserId, moduleCode, StudentEmailsPermission)
+		status, message := endpoints.GetStudentsForModule(moduleCode, showEmails)
 		api.renderer.JSON(w, status, message)
-
 	}, api.privateKey, api.publicKey))
 
-	api.routes.Put("/module/:moduleCode/student/:studentId", middlewares.CheckSession(func(c web.C, w http.ResponseWriter, r *http.Request) {
-		var cookieData *tools.JWTSession = c.Env["token"].(*tools.JWTSession)
+	api.routes.Put("/module/:moduleCode/student/:studentId", middlewares.Restricted(middlewares.Access{ Resource: ModuleResource, Param: "moduleCode", Right: EnrolStudentPermission }, func(c web.C, w http.ResponseWriter, r *http.Request) {
 		var moduleCode = c.URLParams["moduleCode"]
 
 		studentId, status, err := tools.ParseID(c.URLParams["studentId"])
@@ -122,14 +96,28 @@ func (api *API) LoadModulesEndpoints() {
 			api.renderer.JSON(w, status, err); return
 		}
 
-		// Does the user have enough access rights?
-		status, err = tools.VerifyAccess(moduleCode, cookieData.UserId, WritePermission, models.DBPermissions.IsActionPermittedOnModuleWithCode)
+		status, message := endpoints.AddStudentToModule(moduleCode, studentId)
+		api.renderer.JSON(w, status, message)
+	}, api.privateKey, api.publicKey))
+
+	api.routes.Post("/module/:moduleCode/status", middlewares.Restricted(middlewares.Access{ Resource: ModuleResource, Param: "moduleCode", Right: WritePermission }, func(c web.C, w http.ResponseWriter, r *http.Request) {
+		var moduleCode = c.URLParams["moduleCode"]
+
+		// Parse the JSON Body
+		var body struct {
+			Status	models.ModuleStatus `json:"status"`
+		}
+		status, errMessage := tools.ParseBody(r.Body, &body)
 		if status != http.StatusOK {
-			api.renderer.JSON(w, status, err); return
+			api.renderer.JSON(w, status, errMessage); return
 		}
 
-		status, message := endpoints.AddStudentToModule(moduleCode, studentId)
+		status, message := endpoints.SetModuleStatus(moduleCode, body.Status)
 		api.renderer.JSON(w, status, message)
+	}, api.privateKey, api.publicKey))
 
+	api.routes.Get("/module/:moduleCode/storage", middlewares.Restricted(middlewares.Access{ Resource: ModuleResource, Param: "moduleCode", Right: WritePermission }, func(c web.C, w http.ResponseWriter, r *http.Request) {
+		status, message := endpoints.GetModuleStorage(c.URLParams["moduleCode"])
+		api.renderer.JSON(w, status, message)
 	}, api.privateKey, api.publicKey))
 }
@@ -0,0 +1,178 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/zenazn/goji/web"
+
+	"github.com/YagoCarballo/kumquat-academy-api/tools"
+	"github.com/YagoCarballo/kumquat-academy-api/api/middlewares"
+	"github.com/YagoCarballo/kumquat-academy-api/api/endpoints"
+	"github.com/YagoCarballo/kumquat-academy-api/database/models"
+
+	. "github.com/YagoCarballo/kumquat-academy-api/constants"
+)
+
+type (
+	PagePosition struct {
+		ParentID *uint32 `json:"parent_id"`
+		Position uint32 `json:"position"`
+	}
+)
+
+func (api *API) LoadPagesEndpoints() {
+	api.routes.Put("/module/:moduleCode/page", middlewares.Restricted(middlewares.Access{ Resource: ModuleResource, Param: "moduleCode", Right: WritePermission }, func(c web.C, w http.ResponseWriter, r *http.Request) {
+		var cookieData *tools.JWTSession = c.Env["token"].(*tools.JWTSession)
+		moduleCode := c.URLParams["moduleCode"]
+
+		// Parse the JSON Body
+		var page models.Page
+		status, errMessage := tools.ParseBody(r.Body, &page)
+		if status != http.StatusOK {
+			api.renderer.JSON(w, status, errMessage); return
+		}
+
+		// Process the action and Give the response
+		status, message := endpoints.CreatePage(moduleCode, page, cookieData.UserId)
+		api.renderer.JSON(w, status, message)
+	}, api.privateKey, api.publicKey))
+
+	// Creates the GET -> /module/:moduleCode/pages endpoint
+	// Returns the table of contents of the module (pages without content, nested inside their parents)
+	api.routes.Get("/module/:moduleCode/pages", middlewares.Restricted(middlewares.Access{ Resource: ModuleResource, Param: "moduleCode", Right: ReadPermission }, func(c web.C, w http.ResponseWriter, r *http.Request) {
+		moduleCode := c.URLParams["moduleCode"]
+
+		// Process the action and Give the response
+		status, message := endpoints.FindPagesForModule(moduleCode)
+		api.renderer.JSON(w, status, message)
+	}, api.privateKey, api.publicKey))
+
+	api.routes.Get("/module/:moduleCode/page/:pageId", middlewares.Restricted(middlewares.Access{ Resource: ModuleResource, Param: "moduleCode", Right: ReadPermission }, func(c web.C, w http.ResponseWriter, r *http.Request) {
+		// Get and Parse the parameters
+		moduleCode := c.URLParams["moduleCode"]
+		pageId, status, err := tools.ParseID(c.URLParams["pageId"])
+		if status != http.StatusOK {
+			api.renderer.JSON(w, status, err); return
+		}
+
+		// Process the action and Give the response
+		status, message := endpoints.GetPage(moduleCode, pageId)
+		api.renderer.JSON(w, status, message)
+	}, api.privateKey, api.publicKey))
+
+	api.routes.Post("/module/:moduleCode/page/:pageId", middlewares.Restricted(middlewares.Access{ Resource: ModuleResource, Param: "moduleCode", Right: UpdatePermission }, func(c web.C, w http.ResponseWriter, r *http.Request) {
+		// Get and Parse the parameters
+		var cookieData *tools.JWTSession = c.Env["token"].(*tools.JWTSession)
+		moduleCode := c.URLParams["moduleCode"]
+		pageId, status, err := tools.ParseID(c.URLParams["pageId"])
+		if status != http.StatusOK {
+			api.renderer.JSON(w, status, err); return
+		}
+
+		// Parse the JSON Body
+		var page models.Page
+		status, errMessage := tools.ParseBody(r.Body, &page)
+		if status != http.StatusOK {
+			api.renderer.JSON(w, status, errMessage); return
+		}
+
+		// Process the action and Give the response
+		status, message := endpoints.UpdatePage(moduleCode, pageId, page, cookieData.UserId)
+		api.renderer.JSON(w, status, message)
+	}, api.privateKey, api.publicKey))
+
+	api.routes.Post("/module/:moduleCode/page/:pageId/move", middlewares.Restricted(middlewares.Access{ Resource: ModuleResource, Param: "moduleCode", Right: UpdatePermission }, func(c web.C, w http.ResponseWriter, r *http.Request) {
+		// Get and Parse the parameters
+		moduleCode := c.URLParams["moduleCode"]
+		pageId, status, err := tools.ParseID(c.URLParams["pageId"])
+		if status != http.StatusOK {
+			api.renderer.JSON(w, status, err); return
+		}
+
+		// Parse the JSON Body
+		var position PagePosition
+		status, errMessage := tools.ParseBody(r.Body, &position)
+		if status != http.StatusOK {
+			api.renderer.JSON(w, status, errMessage); return
+		}
+
+		// Process the action and Give the response
+		status, message := endpoints.MovePage(moduleCode, pageId, position.ParentID, position.Position)
+		api.renderer.JSON(w, status, message)
+	}, api.privateKey, api.publicKey))
+
+	api.routes.Delete("/module/:moduleCode/page/:pageId", middlewares.Restricted(middlewares.Access{ Resource: ModuleResource, Param: "moduleCode", Right: DeletePermission }, func(c web.C, w http.ResponseWriter, r *http.Request) {
+		// Get and Parse the parameters
+		moduleCode := c.URLParams["moduleCode"]
+		pageId, status, err := tools.ParseID(c.URLParams["pageId"])
+		if status != http.StatusOK {
+			api.renderer.JSON(w, status, err); return
+		}
+
+		// Process the action and Give the response
+		status, message := endpoints.DeletePage(moduleCode, pageId)
+		api.renderer.JSON(w, status, message)
+	}, api.privateKey, api.publicKey))
+
+	api.routes.Get("/module/:moduleCode/page/:pageId/revisions", middlewares.Restricted(middlewares.Access{ Resource: ModuleResource, Param: "moduleCode", Right: ReadPermission }, func(c web.C, w http.ResponseWriter, r *http.Request) {
+		// Get and Parse the parameters
+		moduleCode := c.URLParams["moduleCode"]
+		pageId, status, err := tools.ParseID(c.URLParams["pageId"])
+		if status != http.StatusOK {
+			api.renderer.JSON(w, status, err); return
+		}
+
+		// Process the action and Give the response
+		status, message := endpoints.FindPageRevisions(moduleCode, pageId)
+		api.renderer.JSON(w, status, message)
+	}, api.privateKey, api.publicKey))
+
+	api.routes.Get("/module/:moduleCode/page/:pageId/revision/:revisionId", middlewares.Restricted(middlewares.Access{ Resource: ModuleResource, Param: "moduleCode", Right: ReadPermission }, func(c web.C, w http.ResponseWriter, r *http.Request) {
+		// Get and Parse the parameters
+		moduleCode := c.URLParams["moduleCode"]
+		pageId, status, err := tools.ParseID(c.URLParams["pageId"])
+		if status != http.StatusOK {
+			api.renderer.JSON(w, status, err); return
+		}
+
+		revisionId, status, err := tools.ParseID(c.URLParams["revisionId"])
+		if status != http.StatusOK {
+			api.renderer.JSON(w, status, err); return
+		}
+
+		// Process the action and Give the response
+		status, message := endpoints.GetPageRevision(moduleCode, pageId, revisionId)
+		api.renderer.JSON(w, status, message)
+	}, api.privateKey, api.publicKey))
+
+	// Creates the GET -> /module/:moduleCode/page/:pageId/diff?from=:revisionId&to=:revisionId endpoint
+	// When "to" is missing the revision is compared with the current content of the page
+	api.routes.Get("/module/:moduleCode/page/:pageId/diff", middlewares.Restricted(middlewares.Access{ Resource: ModuleResource, Param: "moduleCode", Right: ReadPermission }, func(c web.C, w http.ResponseWriter, r *http.Request) {
+		// Get and Parse the parameters
+		moduleCode := c.URLParams["moduleCode"]
+		pageId, status, err := tools.ParseID(c.URLParams["pageId"])
+		if status != http.StatusOK {
+			api.renderer.JSON(w, status, err); return
+		}
+
+		params := r.URL.Query()
+		fromId, status, err := tools.ParseID(params.Get("from"))
+		if status != http.StatusOK {
+			api.renderer.JSON(w, status, err); return
+		}
+
+		var toId *uint32
+		if params.Get("to") != "" {
+			revisionId, status, err := tools.ParseID(params.Get("to"))
+			if status != http.StatusOK {
+				api.renderer.JSON(w, status, err); return
+			}
+
+			toId = &revisionId
+		}
+
+		// Process the action and Give the response
+		status, message := endpoints.DiffPageRevisions(moduleCode, pageId, fromId, toId)
+		api.renderer.JSON(w, status, message)
+	}, api.privateKey, api.publicKey))
+}
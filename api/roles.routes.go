@@ -0,0 +1,165 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/zenazn/goji/web"
+
+	"github.com/YagoCarballo/kumquat-academy-api/tools"
+	"github.com/YagoCarballo/kumquat-academy-api/api/middlewares"
+	"github.com/YagoCarballo/kumquat-academy-api/api/endpoints"
+	"github.com/YagoCarballo/kumquat-academy-api/database/models"
+
+	. "github.com/YagoCarballo/kumquat-academy-api/constants"
+)
+
+type (
+	AssignRole struct {
+		RoleID uint32 `json:"role_id"`
+	}
+)
+
+// Only admins can manage the roles and who has them
+func (api *API) LoadRolesEndpoints() {
+	api.routes.Get("/roles", middlewares.Restricted(middlewares.Access{ Resource: AdminResource, Right: ReadPermission }, func(c web.C, w http.ResponseWriter, r *http.Request) {
+		status, message := endpoints.GetRoles()
+		api.renderer.JSON(w, status, message)
+	}, api.privateKey, api.publicKey))
+
+	api.routes.Get("/roles/capabilities", middlewares.Restricted(middlewares.Access{ Resource: AdminResource, Right: ReadPermission }, func(c web.C, w http.ResponseWriter, r *http.Request) {
+		status, message := endpoints.GetCapabilities()
+		api.renderer.JSON(w, status, message)
+	}, api.privateKey, api.publicKey))
+
+	api.routes.Put("/role", middlewares.Restricted(middlewares.Access{ Resource: AdminResource, Right: WritePermission }, func(c web.C, w http.ResponseWriter, r *http.Request) {
+		// Parse the JSON Body
+		var role models.Role
+		status, errMessage := tools.ParseBody(r.Body, &role)
+		if status != http.StatusOK {
+			api.renderer.JSON(w, status, errMessage); return
+		}
+
+		// Process the action and Give the response
+		status, message := endpoints.CreateRole(role)
+		api.renderer.JSON(w, status, message)
+	}, api.privateKey, api.publicKey))
+
+	api.routes.Post("/role/:roleId", middlewares.Restricted(middlewares.Access{ Resource: AdminResource, Right: UpdatePermission }, func(c web.C, w http.ResponseWriter, r *http.Request) {
+		roleId, status, err := tools.ParseID(c.URLParams["roleId"])
+		if status != http.StatusOK {
+			api.renderer.JSON(w, status, err); return
+		}
+
+		// Parse the JSON Body
+		var role models.Role
+		status, errMessage := tools.ParseBody(r.Body, &role)
+		if status != http.StatusOK {
+			api.renderer.JSON(w, status, errMessage); return
+		}
+
+		// Process the action and Give the response
+		status, message := endpoints.UpdateRole(roleId, role)
+		api.renderer.JSON(w, status, message)
+	}, api.privateKey, api.publicKey))
+
+	api.routes.Put("/module/:moduleCode/user/:userId/role", middlewares.Restricted(middlewares.Access{ Resource: AdminResource, Right: WritePermission }, func(c web.C, w http.ResponseWriter, r *http.Request) {
+		moduleCode := c.URLParams["moduleCode"]
+
+		userId, status, err := tools.ParseID(c.URLParams["userId"])
+		if status != http.StatusOK {
+			api.renderer.JSON(w, status, err); return
+		}
+
+		// Parse the JSON Body
+		var body AssignRole
+		status, errMessage := tools.ParseBody(r.Body, &body)
+		if status != http.StatusOK {
+			api.renderer.JSON(w, status, errMessage); return
+		}
+
+		// Process the action and Give the response
+		status, message := endpoints.AssignModuleRole(moduleCode, userId, body.RoleID)
+		api.renderer.JSON(w, status, message)
+	}, api.privateKey, api.publicKey))
+
+	api.routes.Delete("/module/:moduleCode/user/:userId/role", middlewares.Restricted(middlewares.Access{ Resource: AdminResource, Right: DeletePermission }, func(c web.C, w http.ResponseWriter, r *http.Request) {
+		moduleCode := c.URLParams["moduleCode"]
+
+		userId, status, err := tools.ParseID(c.URLParams["userId"])
+		if status != http.StatusOK {
+			api.renderer.JSON(w, status, err); return
+		}
+
+		// Process the action and Give the response
+		status, message := endpoints.UnassignModuleRole(moduleCode, userId)
+		api.renderer.JSON(w, status, message)
+	}, api.privateKey, api.publicKey))
+
+	api.routes.Get("/module/:moduleCode/user/:userId/permissions", middlewares.Restricted(middlewares.Access{ Resource: AdminResource, Right: ReadPermission }, func(c web.C, w http.ResponseWriter, r *http.Request) {
+		moduleCode := c.URLParams["moduleCode"]
+
+		userId, status, err := tools.ParseID(c.URLParams["userId"])
+		if status != http.StatusOK {
+			api.renderer.JSON(w, status, err); return
+		}
+
+		// Process the action and Give the response
+		status, message := endpoints.GetModulePermissions(moduleCode, userId)
+		api.renderer.JSON(w, status, message)
+	}, api.privateKey, api.publicKey))
+
+	api.routes.Put("/course/:courseId/user/:userId/role", middlewares.Restricted(middlewares.Access{ Resource: AdminResource, Right: WritePermission }, func(c web.C, w http.ResponseWriter, r *http.Request) {
+		courseId, status, err := tools.ParseID(c.URLParams["courseId"])
+		if status != http.StatusOK {
+			api.renderer.JSON(w, status, err); return
+		}
+
+		userId, status, err := tools.ParseID(c.URLParams["userId"])
+		if status != http.StatusOK {
+			api.renderer.JSON(w, status, err); return
+		}
+
+		// Parse the JSON Body
+		var body AssignRole
+		status, errMessage := tools.ParseBody(r.Body, &body)
+		if status != http.StatusOK {
+			api.renderer.JSON(w, status, errMessage); return
+		}
+
+		// Process the action and Give the response
+		status, message := endpoints.AssignCourseRole(courseId, userId, body.RoleID)
+		api.renderer.JSON(w, status, message)
+	}, api.privateKey, api.publicKey))
+
+	api.routes.Delete("/course/:courseId/user/:userId/role", middlewares.Restricted(middlewares.Access{ Resource: AdminResource, Right: DeletePermission }, func(c web.C, w http.ResponseWriter, r *http.Request) {
+		courseId, status, err := tools.ParseID(c.URLParams["courseId"])
+		if status != http.StatusOK {
+			api.renderer.JSON(w, status, err); return
+		}
+
+		userId, status, err := tools.ParseID(c.URLParams["userId"])
+		if status != http.StatusOK {
+			api.renderer.JSON(w, status, err); return
+		}
+
+		// Process the action and Give the response
+		status, message := endpoints.UnassignCourseRole(courseId, userId)
+		api.renderer.JSON(w, status, message)
+	}, api.privateKey, api.publicKey))
+
+	api.routes.Get("/course/:courseId/user/:userId/permissions", middlewares.Restricted(middlewares.Access{ Resource: AdminResource, Right: ReadPermission }, func(c web.C, w http.ResponseWriter, r *http.Request) {
+		courseId, status, err := tools.ParseID(c.URLParams["courseId"])
+		if status != http.StatusOK {
+			api.renderer.JSON(w, status, err); return
+		}
+
+		userId, status, err := tools.ParseID(c.URLParams["userId"])
+		if status != http.StatusOK {
+			api.renderer.JSON(w, status, err); return
+		}
+
+		// Process the action and Give the response
+		status, message := endpoints.GetCoursePermissions(courseId, userId)
+		api.renderer.JSON(w, status, message)
+	}, api.privateKey, api.publicKey))
+}
@@ -14,6 +14,8 @@ import (
 	"github.com/YagoCarballo/kumquat-academy-api/api/middlewares"
 	"github.com/YagoCarballo/kumquat-academy-api/database/models"
 	"github.com/YagoCarballo/kumquat-academy-api/tools"
+
+	. "github.com/YagoCarballo/kumquat-academy-api/constants"
 )
 
 type (
@@ -24,6 +26,10 @@ type (
 		privateKey	 *rsa.PrivateKey
 		publicKey	 *rsa.PublicKey
 	}
+
+	RefreshSession struct {
+		RefreshToken string `json:"refresh_token"`
+	}
 )
 
 // Constructor
@@ -55,10 +61,18 @@ func (api *API) SetupRoutes(baseRouter *web.Mux) {
 	api.LoadClassesEndpoints()
 	api.LoadLevelsEndpoints()
 	api.LoadAssignmentsEndpoints()
+	api.LoadTeamsEndpoints()
+	api.LoadTasksEndpoints()
+	api.LoadExamsEndpoints()
+	api.LoadGradebookEndpoints()
+	api.LoadAnnouncementsEndpoints()
+	api.LoadPagesEndpoints()
+	api.LoadMaterialsEndpoints()
 	api.LoadAttachmentsEndpoints()
 	api.LoadUsersEndpoints()
 	api.LoadLectureEndpoints()
 	api.LoadLectureSlotEndpoints()
+	api.LoadRolesEndpoints()
 }
 
 func (api *API) LoadAuthEndpoints() {
@@ -82,8 +96,11 @@ func (api *API) LoadAuthEndpoints() {
 		// Processes the Request
 		status, message, sessionData := endpoints.SignIn(data.Username, data.Password, deviceId)
 
-		// Attaches the Access token to the response
-		message["access_token"] = sessionData.AccessToken
+		// Attaches the Access and Refresh tokens to the response
+		if status == http.StatusAccepted {
+			message["access_token"] = sessionData.AccessToken
+			message["refresh_token"] = sessionData.RefreshToken
+		}
 
 		// Returns the JSON
 		api.renderer.JSON(w, status, message)
@@ -172,6 +189,54 @@ func (api *API) LoadAuthEndpoints() {
 		api.renderer.JSON(w, status, message)
 	}, api.privateKey, api.publicKey))
 
+	// Swaps a refresh token for a new session, the cookie is updated as well
+	api.routes.Post("/auth/refresh", func(c web.C, w http.ResponseWriter, r *http.Request) {
+		var data RefreshSession
+		status, errMessage := tools.ParseBody(r.Body, &data)
+		if status != http.StatusOK {
+			api.renderer.JSON(w, status, errMessage); return
+		}
+
+		status, message, sessionData := endpoints.RefreshSession(data.RefreshToken)
+		if sessionData != nil {
+			err := tools.SetJWTCookie("token", sessionData, w, api.publicKey)
+			if err != nil {
+				api.renderer.JSON(w, http.StatusConflict, map[string]interface{}{
+					"error":   "InvalidSession",
+					"message": "Error when trying to secure the session.",
+				})
+				return
+			}
+		}
+
+		api.renderer.JSON(w, status, message)
+	})
+
+	// Lists the devices where the user is signed in
+	api.routes.Get("/auth/sessions", middlewares.CheckSession(func(c web.C, w http.ResponseWriter, r *http.Request) {
+		var cookieData *tools.JWTSession = c.Env["token"].(*tools.JWTSession)
+
+		status, message := endpoints.FindSessions(cookieData.UserId, cookieData.AccessToken)
+		api.renderer.JSON(w, status, message)
+	}, api.privateKey, api.publicKey))
+
+	// Signs out of every other device
+	api.routes.Delete("/auth/sessions", middlewares.CheckSession(func(c web.C, w http.ResponseWriter, r *http.Request) {
+		var cookieData *tools.JWTSession = c.Env["token"].(*tools.JWTSession)
+
+		status, message := endpoints.RevokeSessions(cookieData.UserId, cookieData.AccessToken)
+		api.renderer.JSON(w, status, message)
+	}, api.privateKey, api.publicKey))
+
+	// Signs out of one device
+	api.routes.Delete("/auth/sessions/:deviceId", middlewares.CheckSession(func(c web.C, w http.ResponseWriter, r *http.Request) {
+		var cookieData *tools.JWTSession = c.Env["token"].(*tools.JWTSession)
+		var deviceId = c.URLParams["deviceId"]
+
+		status, message := endpoints.RevokeSession(cookieData.UserId, deviceId)
+		api.renderer.JSON(w, status, message)
+	}, api.privateKey, api.publicKey))
+
 	// Creates the GET -> /auth/info endpoint
 	// This endpoint recovers information about a user linked to a valid session. (Used when rendering on the server)
 	api.routes.Get("/auth/info", middlewares.CheckSession(func(c web.C, w http.ResponseWriter, r *http.Request) {
@@ -222,10 +287,7 @@ func (api *API) LoadEndpoints() {
 	}, api.privateKey, api.publicKey))
 
 	api.routes.Get("/restricted", middlewares.Restricted(
-		middlewares.Permissions{
-			true,  // Read
-			false, // Write
-		},
+		middlewares.Access{ Resource: AdminResource, Right: ReadPermission },
 		func(c web.C, w http.ResponseWriter, r *http.Request) {
 			// Creates the Output
 			output := map[string]interface{}{
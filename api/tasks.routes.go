@@ -0,0 +1,148 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/zenazn/goji/web"
+
+	"github.com/YagoCarballo/kumquat-academy-api/tools"
+	"github.com/YagoCarballo/kumquat-academy-api/api/middlewares"
+	"github.com/YagoCarballo/kumquat-academy-api/api/endpoints"
+	"github.com/YagoCarballo/kumquat-academy-api/database/models"
+
+	. "github.com/YagoCarballo/kumquat-academy-api/constants"
+)
+
+type (
+	TasksOrder struct {
+		Tasks []uint32 `json:"tasks"`
+	}
+)
+
+func (api *API) LoadTasksEndpoints() {
+	api.routes.Put("/module/:moduleCode/assignment/:assignmentId/task", middlewares.Restricted(middlewares.Access{ Resource: ModuleResource, Param: "moduleCode", Right: WritePermission }, func(c web.C, w http.ResponseWriter, r *http.Request) {
+		// Get and Parse the parameters
+		moduleCode := c.URLParams["moduleCode"]
+		assignmentId, status, err := tools.ParseID(c.URLParams["assignmentId"])
+		if status != http.StatusOK {
+			api.renderer.JSON(w, status, err); return
+		}
+
+		// Parse the JSON Body
+		var task models.Task
+		status, errMessage := tools.ParseBody(r.Body, &task)
+		if status != http.StatusOK {
+			api.renderer.JSON(w, status, errMessage); return
+		}
+
+		// Process the action and Give the response
+		status, message := endpoints.CreateTask(moduleCode, assignmentId, task)
+		api.renderer.JSON(w, status, message)
+	}, api.privateKey, api.publicKey))
+
+	api.routes.Get("/module/:moduleCode/assignment/:assignmentId/tasks", middlewares.Restricted(middlewares.Access{ Resource: ModuleResource, Param: "moduleCode", Right: ReadPermission }, func(c web.C, w http.ResponseWriter, r *http.Request) {
+		// Get and Parse the parameters
+		var cookieData *tools.JWTSession = c.Env["token"].(*tools.JWTSession)
+		moduleCode := c.URLParams["moduleCode"]
+		assignmentId, status, err := tools.ParseID(c.URLParams["assignmentId"])
+		if status != http.StatusOK {
+			api.renderer.JSON(w, status, err); return
+		}
+
+		// Process the action and Give the response
+		status, message := endpoints.FindTasksForAssignment(moduleCode, assignmentId, cookieData.UserId)
+		api.renderer.JSON(w, status, message)
+	}, api.privateKey, api.publicKey))
+
+	api.routes.Post("/module/:moduleCode/assignment/:assignmentId/tasks/order", middlewares.Restricted(middlewares.Access{ Resource: ModuleResource, Param: "moduleCode", Right: UpdatePermission }, func(c web.C, w http.ResponseWriter, r *http.Request) {
+		// Get and Parse the parameters
+		moduleCode := c.URLParams["moduleCode"]
+		assignmentId, status, err := tools.ParseID(c.URLParams["assignmentId"])
+		if status != http.StatusOK {
+			api.renderer.JSON(w, status, err); return
+		}
+
+		// Parse the JSON Body
+		var order TasksOrder
+		status, errMessage := tools.ParseBody(r.Body, &order)
+		if status != http.StatusOK {
+			api.renderer.JSON(w, status, errMessage); return
+		}
+
+		// Process the action and Give the response
+		status, message := endpoints.ReorderTasks(moduleCode, assignmentId, order.Tasks)
+		api.renderer.JSON(w, status, message)
+	}, api.privateKey, api.publicKey))
+
+	api.routes.Post("/module/:moduleCode/assignment/:assignmentId/task/:taskId", middlewares.Restricted(middlewares.Access{ Resource: ModuleResource, Param: "moduleCode", Right: UpdatePermission }, func(c web.C, w http.ResponseWriter, r *http.Request) {
+		// Get and Parse the parameters
+		moduleCode := c.URLParams["moduleCode"]
+		assignmentId, status, err := tools.ParseID(c.URLParams["assignmentId"])
+		if status != http.StatusOK {
+			api.renderer.JSON(w, status, err); return
+		}
+
+		taskId, status, err := tools.ParseID(c.URLParams["taskId"])
+		if status != http.StatusOK {
+			api.renderer.JSON(w, status, err); return
+		}
+
+		// Parse the JSON Body
+		var task models.Task
+		status, errMessage := tools.ParseBody(r.Body, &task)
+		if status != http.StatusOK {
+			api.renderer.JSON(w, status, errMessage); return
+		}
+
+		// Process the action and Give the response
+		status, message := endpoints.UpdateTask(moduleCode, assignmentId, taskId, task)
+		api.renderer.JSON(w, status, message)
+	}, api.privateKey, api.publicKey))
+
+	api.routes.Delete("/module/:moduleCode/assignment/:assignmentId/task/:taskId", middlewares.Restricted(middlewares.Access{ Resource: ModuleResource, Param: "moduleCode", Right: DeletePermission }, func(c web.C, w http.ResponseWriter, r *http.Request) {
+		// Get and Parse the parameters
+		moduleCode := c.URLParams["moduleCode"]
+		assignmentId, status, err := tools.ParseID(c.URLParams["assignmentId"])
+		if status != http.StatusOK {
+			api.renderer.JSON(w, status, err); return
+		}
+
+		taskId, status, err := tools.ParseID(c.URLParams["taskId"])
+		if status != http.StatusOK {
+			api.renderer.JSON(w, status, err); return
+		}
+
+		// Process the action and Give the response
+		status, message := endpoints.DeleteTask(moduleCode, assignmentId, taskId)
+		api.renderer.JSON(w, status, message)
+	}, api.privateKey, api.publicKey))
+
+	// Any member of the module can keep track of its own progress
+	api.routes.Put("/module/:moduleCode/assignment/:assignmentId/task/:taskId/complete", middlewares.Restricted(middlewares.Access{ Resource: ModuleResource, Param: "moduleCode", Right: ReadPermission }, func(c web.C, w http.ResponseWriter, r *http.Request) {
+		api.setTaskCompleted(c, w, true)
+	}, api.privateKey, api.publicKey))
+
+	api.routes.Delete("/module/:moduleCode/assignment/:assignmentId/task/:taskId/complete", middlewares.Restricted(middlewares.Access{ Resource: ModuleResource, Param: "moduleCode", Right: ReadPermission }, func(c web.C, w http.ResponseWriter, r *http.Request) {
+		api.setTaskCompleted(c, w, false)
+	}, api.privateKey, api.publicKey))
+}
+
+// Ticks or unticks a task of the checklist for the user of the session
+func (api *API) setTaskCompleted(c web.C, w http.ResponseWriter, completed bool) {
+	// Get and Parse the parameters
+	var cookieData *tools.JWTSession = c.Env["token"].(*tools.JWTSession)
+	moduleCode := c.URLParams["moduleCode"]
+	assignmentId, status, err := tools.ParseID(c.URLParams["assignmentId"])
+	if status != http.StatusOK {
+		api.renderer.JSON(w, status, err); return
+	}
+
+	taskId, status, err := tools.ParseID(c.URLParams["taskId"])
+	if status != http.StatusOK {
+		api.renderer.JSON(w, status, err); return
+	}
+
+	// Process the action and Give the response
+	status, message := endpoints.SetTaskCompleted(moduleCode, assignmentId, taskId, cookieData.UserId, completed)
+	api.renderer.JSON(w, status, message)
+}
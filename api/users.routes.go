@@ -9,6 +9,7 @@ import (
 	"github.com/YagoCarballo/kumquat-academy-api/tools"
 	"github.com/YagoCarballo/kumquat-academy-api/api/middlewares"
 	"github.com/YagoCarballo/kumquat-academy-api/api/endpoints"
+	"github.com/YagoCarballo/kumquat-academy-api/mailer"
 	"github.com/YagoCarballo/kumquat-academy-api/database/models"
 
 	. "github.com/YagoCarballo/kumquat-academy-api/constants"
@@ -32,16 +33,9 @@ type (
 )
 
 func (api *API) LoadUsersEndpoints() {
-	api.routes.Put("/module/:moduleCode/student", middlewares.CheckSession(func(c web.C, w http.ResponseWriter, r *http.Request) {
-		var cookieData *tools.JWTSession = c.Env["token"].(*tools.JWTSession)
+	api.routes.Put("/module/:moduleCode/student", middlewares.Restricted(middlewares.Access{ Resource: ModuleResource, Param: "moduleCode", Right: EnrolStudentPermission }, func(c web.C, w http.ResponseWriter, r *http.Request) {
 		var moduleCode = c.URLParams["moduleCode"]
 
-		// Does the user have enough access rights?
-		status, err := tools.VerifyAccess(moduleCode, cookieData.UserId, WritePermission, models.DBPermissions.IsActionPermittedOnModuleWithCode)
-		if status != http.StatusOK {
-			api.renderer.JSON(w, status, err); return
-		}
-
 		// Parse the JSON Body
 		var studentData StudentForModule
 		status, errMessage := tools.ParseBody(r.Body, &studentData)
@@ -62,11 +56,9 @@ func (api *API) LoadUsersEndpoints() {
 		)
 
 		api.renderer.JSON(w, status, message)
-
 	}, api.privateKey, api.publicKey))
 
-	api.routes.Post("/module/:moduleCode/student/:studentId", middlewares.CheckSession(func(c web.C, w http.ResponseWriter, r *http.Request) {
-		var cookieData *tools.JWTSession = c.Env["token"].(*tools.JWTSession)
+	api.routes.Post("/module/:moduleCode/student/:studentId", middlewares.Restricted(middlewares.Access{ Resource: ModuleResource, Param: "moduleCode", Right: WritePermission }, func(c web.C, w http.ResponseWriter, r *http.Request) {
 		var moduleCode = c.URLParams["moduleCode"]
 
 		studentId, status, err := tools.ParseID(c.URLParams["studentId"])
@@ -74,13 +66,6 @@ func (api *API) LoadUsersEndpoints() {
 			api.renderer.JSON(w, status, err); return
 		}
 
-		// Does the user have enough access rights?
-		status, err = tools.VerifyAccess(moduleCode, cookieData.UserId, WritePermission, models.DBPermissions.IsActionPermittedOnModuleWithCode)
-		if status != http.StatusOK {
-			api.renderer.JSON(w, status, err); return
-		}
-
-
 		// Parse the JSON Body
 		var student StudentForModule
 		status, errMessage := tools.ParseBody(r.Body, &student)
@@ -102,30 +87,26 @@ func (api *API) LoadUsersEndpoints() {
 		)
 
 		api.renderer.JSON(w, status, message)
-
 	}, api.privateKey, api.publicKey))
 
 
-	api.routes.Get("/module/:moduleCode/students/search/:query", middlewares.CheckSession(func(c web.C, w http.ResponseWriter, r *http.Request) {
+	api.routes.Get("/module/:moduleCode/students/search/:query", middlewares.Restricted(middlewares.Access{ Resource: ModuleResource, Param: "moduleCode", Right: ReadPermission }, func(c web.C, w http.ResponseWriter, r *http.Request) {
 		var cookieData *tools.JWTSession = c.Env["token"].(*tools.JWTSession)
 		var moduleCode = c.URLParams["moduleCode"]
 		var query = c.URLParams["query"]
 
-		// Does the user have enough access rights?
-		status, err := tools.VerifyAccess(moduleCode, cookieData.UserId, ReadPermission, models.DBPermissions.IsActionPermittedOnModuleWithCode)
-		if status != http.StatusOK {
-			api.renderer.JSON(w, status, err); return
-		}
-
-		status, message := endpoints.SearchUsers(query, moduleCode)
+		showEmails := models.DBPermissions.IsActionPermittedOnModuleWithCode(cookieData.UserId, moduleCode, StudentEmailsPermission)
+		status, message := endpoints.SearchUsers(query, moduleCode, showEmails)
 		api.renderer.JSON(w, status, message)
-
 	}, api.privateKey, api.publicKey))
 
 	api.routes.Get("/password/:email/reset", func(c web.C, w http.ResponseWriter, r *http.Request) {
 		var email = c.URLParams["email"]
 
-		status, message := endpoints.ForgotPassword(email)
+		// The email is sent in the language of the browser when there is a template for it
+		language := mailer.HeaderLanguage(r.Header.Get("Accept-Language"))
+
+		status, message := endpoints.ForgotPassword(email, language)
 		api.renderer.JSON(w, status, message)
 
 	})
@@ -145,6 +126,17 @@ func (api *API) LoadUsersEndpoints() {
 
 	})
 
+	// Signs a user out of every device (only admins)
+	api.routes.Delete("/user/:userId/sessions", middlewares.Restricted(middlewares.Access{ Resource: AdminResource, Right: WritePermission }, func(c web.C, w http.ResponseWriter, r *http.Request) {
+		userId, status, errMessage := tools.ParseID(c.URLParams["userId"])
+		if status != http.StatusOK {
+			api.renderer.JSON(w, status, errMessage); return
+		}
+
+		status, message := endpoints.RevokeSessions(userId, "")
+		api.renderer.JSON(w, status, message)
+	}, api.privateKey, api.publicKey))
+
 	api.routes.Put("/user/:userId/avatar", middlewares.CheckSession(func(c web.C, w http.ResponseWriter, r *http.Request) {
 		// Get and Parse the parameters
 		var cookieData *tools.JWTSession = c.Env["token"].(*tools.JWTSession)
@@ -175,4 +167,25 @@ func (api *API) LoadUsersEndpoints() {
 		status, message := endpoints.UploadAvatar(userId, file, header)
 		api.renderer.JSON(w, status, message)
 	}, api.privateKey, api.publicKey))
+
+	api.routes.Get("/user/:userId/storage", middlewares.CheckSession(func(c web.C, w http.ResponseWriter, r *http.Request) {
+		// Get and Parse the parameters
+		var cookieData *tools.JWTSession = c.Env["token"].(*tools.JWTSession)
+		userId, status, errMsg := tools.ParseID(c.URLParams["userId"])
+		if status != http.StatusOK {
+			api.renderer.JSON(w, status, errMsg); return
+		}
+
+		if userId != cookieData.UserId && !cookieData.Admin {
+			api.renderer.JSON(w, http.StatusForbidden, map[string]interface{}{
+				"error":   "AccessDenied",
+				"message": "Not enough permissions to see the storage used by this user",
+			})
+			return
+		}
+
+		// Process the action and Give the response
+		status, message := endpoints.GetUserStorage(userId)
+		api.renderer.JSON(w, status, message)
+	}, api.privateKey, api.publicKey))
 }
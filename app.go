@@ -15,6 +15,7 @@ import (
 	// My Libs
 	"crypto/rsa"
 	"github.com/YagoCarballo/kumquat-academy-api/api"
+	"github.com/YagoCarballo/kumquat-academy-api/api/middlewares"
 	"github.com/YagoCarballo/kumquat-academy-api/database"
 	"github.com/YagoCarballo/kumquat-academy-api/tools"
 )
@@ -34,6 +35,7 @@ func setupMiddlewares(router *web.Mux) {
 	router.Use(middleware.EnvInit)   // Creates an Environment Map if the current one is Nil
 	router.Use(middleware.RealIP)    // Finds the Real IP of the Client
 	router.Use(middleware.Recoverer) // Recovers from Panics and throws a 500 error
+	router.Use(middlewares.LimitUploads) // Rejects uploads larger than the limits before they are read
 
 	if tools.GetSettings().Server.Debug {
 		router.Use(middleware.Logger) // Enables Logging of routes
@@ -43,6 +45,7 @@ func setupMiddlewares(router *web.Mux) {
 	// Sets the Secure Middleware Options
 	secureMiddleware := secure.New(secure.Options{
 		IsDevelopment: tools.GetSettings().Server.Debug,
+		ContentTypeNosniff: true,
 	})
 
 	// Adds the Secure Middleware
@@ -60,6 +63,23 @@ func StartServer() {
 		panic(err)
 	}
 
+	err = checkEmailDelivery(tools.GetSettings())
+	if err != nil {
+		panic(err)
+	}
+
+	// Starts the background jobs (stopped before the server shuts down)
+	schedulerSettings := tools.GetSettings().Scheduler
+	if !schedulerSettings.Disabled {
+		jobs, err := loadJobs(schedulerSettings)
+		if err != nil {
+			panic(err)
+		}
+
+		jobs.Start()
+		graceful.PreHook(jobs.Stop)
+	}
+
 	// Loads the Server Settings
 	serverSettings := tools.GetSettings().Server
 
@@ -91,9 +111,16 @@ func StartServer() {
 	if err != nil {
 		panic(err)
 	}
+
+	// Waits for the running requests and jobs to finish
+	graceful.Wait()
 }
 
-// Starts the Server
+// Starts the Server (or runs a maintenance command if one is given)
 func main() {
+	if runCommand(os.Args[1:]) {
+		return
+	}
+
 	StartServer()
 }
@@ -0,0 +1,350 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"strconv"
+
+	"github.com/YagoCarballo/kumquat-academy-api/database"
+	"github.com/YagoCarballo/kumquat-academy-api/database/models"
+	"github.com/YagoCarballo/kumquat-academy-api/storage"
+	"github.com/YagoCarballo/kumquat-academy-api/tools"
+)
+
+const usage = `Usage:
+  kumquat-academy-api                     Starts the server
+  kumquat-academy-api migrate [up]        Applies all the pending migrations
+  kumquat-academy-api migrate down [n]    Reverts the last n migrations (default 1)
+  kumquat-academy-api migrate status      Prints the applied and pending migrations
+  kumquat-academy-api seed [-reset]       Loads the fixture dataset (-reset replaces existing data)
+  kumquat-academy-api storage migrate [-delete] <from> <to>
+                                          Copies the attachments between storage drivers ("local" or "s3"),
+                                          -delete removes them from the old storage once copied
+  kumquat-academy-api storage verify      Checks the stored files against their SHA-256 and the references to them
+  kumquat-academy-api storage dedupe      Moves the files uploaded before the blobs to their content hash,
+                                          files with the same content are then stored once`
+
+// Runs a maintenance command instead of the server, returns false if the command is unknown
+func runCommand(args []string) bool {
+	if len(args) <= 0 {
+		return false
+	}
+
+	switch args[0] {
+	case "migrate":
+		connect()
+		migrateCommand(args[1:])
+	case "seed":
+		connect()
+		seedCommand(args[1:])
+	case "storage":
+		connect()
+		storageCommand(args[1:])
+	case "help":
+		fmt.Println(usage)
+	default:
+		fmt.Println(usage)
+		log.Fatalf("Unknown command '%s'\n", args[0])
+	}
+
+	return true
+}
+
+// Loads the settings and opens the database
+func connect() {
+	err := tools.LoadSettings(SETTINGS_PATH)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err, _ = database.InitDatabase()
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func migrateCommand(args []string) {
+	action := "up"
+	if len(args) > 0 {
+		action = args[0]
+	}
+
+	switch action {
+	case "up":
+		applied, err := database.Migrate()
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		log.Printf("%d migration(s) applied\n", applied)
+
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			var err error
+			steps, err = strconv.Atoi(args[1])
+			if err != nil || steps <= 0 {
+				log.Fatalf("Invalid number of steps '%s'\n", args[1])
+			}
+		}
+
+		reverted, err := database.Rollback(steps)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		log.Printf("%d migration(s) reverted\n", reverted)
+
+	case "status":
+		versions, err := database.AppliedVersions()
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		for _, migration := range database.Migrations() {
+			state := "pending"
+			if versions[migration.Version] {
+				state = "applied"
+			}
+
+			fmt.Printf("%4d  %-30s %s\n", migration.Version, migration.Name, state)
+		}
+
+	default:
+		fmt.Println(usage)
+		log.Fatalf("Unknown migrate action '%s'\n", action)
+	}
+}
+
+func seedCommand(args []string) {
+	flags := flag.NewFlagSet("seed", flag.ExitOnError)
+	reset := flags.Bool("reset", false, "Removes the existing data before loading the fixtures")
+	flags.Parse(args)
+
+	err := models.Seed(*reset)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	log.Println("Fixture dataset loaded")
+}
+
+func storageCommand(args []string) {
+	if len(args) <= 0 {
+		fmt.Println(usage)
+		log.Fatalln("Unknown storage action")
+	}
+
+	switch args[0] {
+	case "migrate":
+		storageMigrateCommand(args[1:])
+	case "verify":
+		storageVerifyCommand()
+	case "dedupe":
+		storageDedupeCommand()
+	default:
+		fmt.Println(usage)
+		log.Fatalln("Unknown storage action")
+	}
+}
+
+func storageMigrateCommand(args []string) {
+	flags := flag.NewFlagSet("storage migrate", flag.ExitOnError)
+	remove := flags.Bool("delete", false, "Removes the files from the old storage once copied")
+	flags.Parse(args)
+
+	if flags.NArg() != 2 {
+		fmt.Println(usage)
+		log.Fatalln("The storage migration needs the driver to copy from and the driver to copy to")
+	}
+
+	settings := *tools.GetSettings()
+	from, err := storage.New(flags.Arg(0), settings)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	to, err := storage.New(flags.Arg(1), settings)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	attachments, err := models.DBAttachment.FindAttachments()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// A file that fails is reported and the rest are still copied, so the command can be run again
+	// Attachments with the same content share a file, which is only copied once
+	copied, skipped, failed := 0, 0, 0
+	seen := map[string]bool{}
+	for _, attachment := range attachments {
+		name := attachment.StorageName()
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		moved, err := storage.Copy(from, to, name, attachment.Type)
+		if err != nil {
+			log.Printf("Error copying %s (%s): %s\n", name, attachment.Name, err)
+			failed++
+			continue
+		}
+
+		if moved {
+			copied++
+		} else {
+			skipped++
+		}
+
+		if *remove {
+			err = from.Delete(name)
+			if err != nil {
+				log.Printf("Error removing %s from the old storage: %s\n", name, err)
+			}
+		}
+	}
+
+	log.Printf("%d file(s) copied, %d already there, %d failed\n", copied, skipped, failed)
+	if failed > 0 {
+		log.Fatalln("Some files could not be copied")
+	}
+}
+
+// Reads every blob back from the storage and checks its hash, size and the attachments referencing it
+func storageVerifyCommand() {
+	store, err := storage.Default()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	blobs, err := models.DBAttachment.FindBlobs()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	references, err := models.DBAttachment.CountBlobReferences()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	valid, failed := 0, 0
+	known := map[string]bool{}
+	for _, blob := range blobs {
+		known[blob.Hash] = true
+
+		hash, size, err := storage.Checksum(store, blob.Hash)
+		switch {
+		case err == storage.ErrNotFound:
+			log.Printf("%s is missing\n", blob.Hash)
+		case err != nil:
+			log.Printf("Error reading %s: %s\n", blob.Hash, err)
+		case hash != blob.Hash:
+			log.Printf("%s is corrupted, its content hashes to %s\n", blob.Hash, hash)
+		case size != blob.Size:
+			log.Printf("%s has %d bytes instead of %d\n", blob.Hash, size, blob.Size)
+		case references[blob.Hash] != blob.ReferenceCount:
+			log.Printf("%s is used by %d attachment(s) but counts %d\n", blob.Hash, references[blob.Hash], blob.ReferenceCount)
+		default:
+			valid++
+			continue
+		}
+
+		failed++
+	}
+
+	// Attachments pointing to a blob that is gone
+	for hash, count := range references {
+		if !known[hash] {
+			log.Printf("%s is used by %d attachment(s) but has no blob\n", hash, count)
+			failed++
+		}
+	}
+
+	attachments, err := models.DBAttachment.FindAttachments()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	unhashed := 0
+	for _, attachment := range attachments {
+		if attachment.Hash == nil {
+			unhashed++
+		}
+	}
+
+	log.Printf("%d file(s) valid, %d failed, %d attachment(s) not stored by their content yet\n", valid, failed, unhashed)
+	if failed > 0 {
+		log.Fatalln("Some files failed the verification")
+	}
+}
+
+// Moves the attachments uploaded before the blobs to the blob of their content, removing the old files
+func storageDedupeCommand() {
+	store, err := storage.Default()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	attachments, err := models.DBAttachment.FindAttachments()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// A file that fails is reported and the rest are still moved, so the command can be run again
+	stored, shared, failed := 0, 0, 0
+	for _, attachment := range attachments {
+		if attachment.Hash != nil {
+			continue
+		}
+
+		hash, size, err := storage.Checksum(store, attachment.Url)
+		if err != nil {
+			log.Printf("Error reading %s (%s): %s\n", attachment.Url, attachment.Name, err)
+			failed++
+			continue
+		}
+
+		// The file is copied to its hash before the attachment points to it
+		_, err = store.Stat(hash)
+		if err == storage.ErrNotFound {
+			var file io.ReadCloser
+			file, err = store.Get(attachment.Url)
+			if err == nil {
+				err = store.Put(hash, file, size, attachment.Type)
+				file.Close()
+			}
+		}
+		if err != nil {
+			log.Printf("Error storing %s (%s): %s\n", attachment.Url, attachment.Name, err)
+			failed++
+			continue
+		}
+
+		newBlob, err := models.DBAttachment.SetAttachmentBlob(attachment.ID, hash, size)
+		if err != nil {
+			log.Printf("Error moving %s (%s): %s\n", attachment.Url, attachment.Name, err)
+			failed++
+			continue
+		}
+
+		if newBlob {
+			stored++
+		} else {
+			shared++
+		}
+
+		err = store.Delete(attachment.Url)
+		if err != nil {
+			log.Printf("Error removing %s: %s\n", attachment.Url, err)
+		}
+	}
+
+	log.Printf("%d file(s) stored by their content, %d shared with another attachment, %d failed\n", stored, shared, failed)
+	if failed > 0 {
+		log.Fatalln("Some files could not be moved")
+	}
+}
@@ -6,9 +6,48 @@ const (
 	DeletePermission	AccessRight = "DELETE"
 	UpdatePermission	AccessRight = "UPDATE"
 
+	// Capabilities, finer rights that roles grant on top of the CRUD flags
+	GradeAssignmentPermission		AccessRight = "assignment.grade"
+	EnrolStudentPermission			AccessRight = "student.enrol"
+	StudentEmailsPermission			AccessRight = "student.emails"
+	EditLecturePermission			AccessRight = "lecture.edit"
+	DownloadSubmissionPermission	AccessRight = "submission.download"
+
 	ModuleResource		ResourceType = "module"
 	CourseResource		ResourceType = "course"
+	AnnouncementResource	ResourceType = "announcement"
+
+	// Attachments are found by their token, and checked against whatever they belong to
+	AttachmentResource	ResourceType = "attachment"
+
+	// The whole platform, only admins have access to it
+	AdminResource		ResourceType = "admin"
 )
 
 type AccessRight string
-type ResourceType string
\ No newline at end of file
+type ResourceType string
+
+// A capability roles can grant, roles that don't list any capabilities get the ones allowed by their flags
+type Capability struct {
+	Name		AccessRight	`json:"name"`
+	Flag		AccessRight	`json:"flag"`
+	Description	string		`json:"description"`
+}
+
+var Capabilities = []Capability{
+	{GradeAssignmentPermission, WritePermission, "Grade, review and give feedback on submissions."},
+	{EnrolStudentPermission, WritePermission, "Enrol students in a module."},
+	{StudentEmailsPermission, WritePermission, "See the email of the students."},
+	{EditLecturePermission, WritePermission, "Create, edit and remove lectures."},
+	{DownloadSubmissionPermission, WritePermission, "See and download the submissions of every student."},
+}
+
+func IsCapability(right AccessRight) bool {
+	for _, capability := range Capabilities {
+		if capability.Name == right {
+			return true
+		}
+	}
+
+	return false
+}
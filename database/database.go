@@ -3,6 +3,7 @@ package database
 import (
 	"log"
 	"fmt"
+	"net/url"
 	"strings"
 
 	"github.com/jinzhu/gorm"
@@ -36,9 +37,30 @@ func connectWithDB() error {
 			dbSettings.Mysql.Host,
 			dbSettings.Mysql.Name,
 		)
+	case "postgres", "postgresql":
+		dbType = "postgres"
+		sslMode := dbSettings.Postgres.SSLMode
+		if sslMode == "" {
+			sslMode = "disable"
+		}
+
+		postgresUri := url.URL{
+			Scheme: "postgres",
+			User: url.UserPassword(dbSettings.Postgres.Username, dbSettings.Postgres.Password),
+			Host: dbSettings.Postgres.Host,
+			Path: dbSettings.Postgres.Name,
+			RawQuery: fmt.Sprintf("sslmode=%s", url.QueryEscape(sslMode)),
+		}
+		uri = postgresUri.String()
 	default:
 		dbType = "sqlite3"
-		uri = fmt.Sprintf("%s", dbSettings.Sqlite.Path)
+
+		// SQLite only enforces the foreign keys when asked to, on every connection
+		separator := "?"
+		if strings.Contains(dbSettings.Sqlite.Path, "?") {
+			separator = "&"
+		}
+		uri = fmt.Sprintf("%s%s_foreign_keys=1", dbSettings.Sqlite.Path, separator)
 	}
 
 	// Connects to the Database
@@ -56,6 +78,8 @@ func connectWithDB() error {
 	switch strings.ToLower(dbSettings.Type) {
 	case "mysql":
 		log.Printf("Connected to MySQL { server: %s, db: %s }\n", dbSettings.Mysql.Host, dbSettings.Mysql.Name)
+	case "postgres", "postgresql":
+		log.Printf("Connected to Postgres { server: %s, db: %s }\n", dbSettings.Postgres.Host, dbSettings.Postgres.Name)
 	default:
 		log.Printf("Connected to SQLite { db: %s }\n", dbSettings.Sqlite.Path)
 	}
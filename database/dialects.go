@@ -0,0 +1,165 @@
+package database
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jinzhu/gorm"
+	"github.com/lib/pq"
+	"github.com/go-sql-driver/mysql"
+	"github.com/mattn/go-sqlite3"
+)
+
+const (
+	mysqlDuplicatedEntry	= 1062
+	postgresUniqueViolation	= "23505"
+)
+
+// Returns true if the error was caused by a duplicated primary key or unique index, whatever the dialect
+func IsDuplicatedError(err error) bool {
+	switch dbError := err.(type) {
+	case *mysql.MySQLError:
+		return dbError.Number == mysqlDuplicatedEntry
+	case *pq.Error:
+		return dbError.Code == postgresUniqueViolation
+	case sqlite3.Error:
+		return dbError.ExtendedCode == sqlite3.ErrConstraintUnique || dbError.ExtendedCode == sqlite3.ErrConstraintPrimaryKey
+	}
+
+	return false
+}
+
+// Creates or updates the tables for the given models.
+// The models declare some of their keys as "int unsigned" (needed by the MySQL foreign keys),
+// Postgres has no unsigned types so they are created as plain integers instead.
+func AutoMigrate(db *gorm.DB, values ...interface{}) error {
+	if db.Dialect().GetName() == "postgres" {
+		for _, value := range values {
+			for _, field := range db.NewScope(value).GetModelStruct().StructFields {
+				sqlType, found := field.TagSettings["TYPE"]
+				if found && strings.Contains(sqlType, "unsigned") {
+					field.TagSettings["TYPE"] = "integer"
+				}
+			}
+		}
+	}
+
+	return db.AutoMigrate(values...).Error
+}
+
+// What is done with a row when the row its foreign key references is deleted
+const (
+	Cascade		= "CASCADE"
+	Restrict	= "RESTRICT"
+)
+
+// A foreign key from a column of a table to the column of another ("users(id)").
+// Rows are deleted in cascade unless OnDelete says otherwise, so history (like submissions
+// or grades) should be restricted instead of being lost along with what it references.
+type ForeignKey struct {
+	Field		string
+	Reference	string
+	OnDelete	string
+}
+
+func (key ForeignKey) onDelete() string {
+	if key.OnDelete == "" {
+		return Cascade
+	}
+
+	return key.OnDelete
+}
+
+// Adds the foreign keys of a table, updating in cascade and deleting as each key says.
+// SQLite can't alter the constraints of an existing table, so the table is rebuilt with them instead.
+func AddForeignKeys(db *gorm.DB, model interface{}, keys ...ForeignKey) error {
+	if db.Dialect().GetName() == "sqlite3" {
+		return rebuildSQLiteTable(db, model, keys)
+	}
+
+	for _, key := range keys {
+		query := db.Model(model).AddForeignKey(key.Field, key.Reference, key.onDelete(), Cascade)
+		if query.Error != nil {
+			return query.Error
+		}
+	}
+
+	return nil
+}
+
+// Removes the foreign keys added with AddForeignKeys
+func DropForeignKeys(db *gorm.DB, model interface{}, keys ...ForeignKey) error {
+	if db.Dialect().GetName() == "sqlite3" {
+		return rebuildSQLiteTable(db, model, nil)
+	}
+
+	scope := db.NewScope(model)
+	statement := "ALTER TABLE %s DROP FOREIGN KEY %s"
+	if db.Dialect().GetName() == "postgres" {
+		statement = "ALTER TABLE %s DROP CONSTRAINT %s"
+	}
+
+	for index := len(keys) - 1; index >= 0; index-- {
+		keyName := db.Dialect().BuildForeignKeyName(scope.TableName(), keys[index].Field, keys[index].Reference)
+
+		query := db.Exec(fmt.Sprintf(statement, scope.QuotedTableName(), scope.Quote(keyName)))
+		if query.Error != nil {
+			return query.Error
+		}
+	}
+
+	return nil
+}
+
+// Recreates a SQLite table with the given foreign keys (replacing the ones it had), keeping its rows and indexes.
+// The table must not be referenced yet by any other table, otherwise dropping it would cascade.
+func rebuildSQLiteTable(db *gorm.DB, model interface{}, keys []ForeignKey) error {
+	scope := db.NewScope(model)
+	table := scope.TableName()
+	newTable := table + "_rebuild"
+
+	var createTable string
+	err := db.Raw("select sql from sqlite_master where type = 'table' and name = ?", table).Row().Scan(&createTable)
+	if err != nil {
+		return err
+	}
+
+	var indexes []string
+	query := db.Raw("select sql from sqlite_master where type = 'index' and tbl_name = ? and sql is not null", table).Pluck("sql", &indexes)
+	if query.Error != nil {
+		return query.Error
+	}
+
+	// Removes the previous constraints (always added at the end) and the closing parenthesis
+	if position := strings.Index(createTable, ", CONSTRAINT "); position >= 0 {
+		createTable = createTable[:position]
+	} else {
+		createTable = createTable[:strings.LastIndex(createTable, ")")]
+	}
+
+	for _, key := range keys {
+		keyName := db.Dialect().BuildForeignKeyName(table, key.Field, key.Reference)
+		createTable += fmt.Sprintf(
+			", CONSTRAINT %s FOREIGN KEY (%s) REFERENCES %s ON DELETE %s ON UPDATE %s",
+			scope.Quote(keyName), scope.Quote(key.Field), key.Reference, key.onDelete(), Cascade,
+		)
+	}
+	createTable = strings.Replace(createTable + ")", scope.Quote(table), scope.Quote(newTable), 1)
+
+	statements := []string{
+		createTable,
+		fmt.Sprintf("INSERT INTO %s SELECT * FROM %s", scope.Quote(newTable), scope.Quote(table)),
+		fmt.Sprintf("DROP TABLE %s", scope.Quote(table)),
+		fmt.Sprintf("ALTER TABLE %s RENAME TO %s", scope.Quote(newTable), scope.Quote(table)),
+	}
+	statements = append(statements, indexes...)
+
+	for _, statement := range statements {
+		query = db.Exec(statement)
+		if query.Error != nil {
+			return query.Error
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,184 @@
+package database
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+type (
+	// A single, versioned step of the database schema
+	Migration struct {
+		Version	uint32
+		Name	string
+		Up		func(db *gorm.DB) error
+		Down	func(db *gorm.DB) error
+	}
+
+	// Row stored in the schema table for every applied migration
+	SchemaMigration struct {
+		Version		uint32		`json:"version" gorm:"primary_key" sql:"type:int unsigned"`
+		Name		string		`json:"name" sql:"not null"`
+		AppliedAt	time.Time	`json:"applied_at" sql:"not null"`
+	}
+)
+
+var migrations []Migration
+
+// Registers a migration, to be called from the init() of the package that declares it
+func RegisterMigration(migration Migration) {
+	for _, registered := range migrations {
+		if registered.Version == migration.Version {
+			panic(fmt.Sprintf("Migration %d is already registered (%s)", migration.Version, registered.Name))
+		}
+	}
+
+	migrations = append(migrations, migration)
+	sort.Sort(byVersion(migrations))
+}
+
+// Returns all the registered migrations, sorted by version
+func Migrations() []Migration {
+	return migrations
+}
+
+// Returns the version of the last applied migration (0 if none)
+func SchemaVersion() (uint32, error) {
+	err := createSchemaTable()
+	if err != nil {
+		return 0, err
+	}
+
+	var versions []uint32
+	query := DB.Model(&SchemaMigration{}).Order("version desc").Limit(1).Pluck("version", &versions)
+	if query.Error != nil {
+		return 0, query.Error
+	}
+
+	if len(versions) <= 0 {
+		return 0, nil
+	}
+
+	return versions[0], nil
+}
+
+// Returns the versions of the applied migrations
+func AppliedVersions() (map[uint32]bool, error) {
+	err := createSchemaTable()
+	if err != nil {
+		return nil, err
+	}
+
+	var versions []uint32
+	query := DB.Model(&SchemaMigration{}).Pluck("version", &versions)
+	if query.Error != nil {
+		return nil, query.Error
+	}
+
+	applied := map[uint32]bool{}
+	for _, version := range versions {
+		applied[version] = true
+	}
+
+	return applied, nil
+}
+
+// Applies all the pending migrations in order and returns how many were applied.
+// A migration older than the last applied one (like one added in another branch) is applied as well.
+func Migrate() (int, error) {
+	versions, err := AppliedVersions()
+	if err != nil {
+		return 0, err
+	}
+
+	applied := 0
+	for _, migration := range migrations {
+		if versions[migration.Version] {
+			continue
+		}
+
+		// Schema changes are not transactional on MySQL, so each step runs directly on the connection
+		err = migration.Up(DB)
+		if err != nil {
+			return applied, fmt.Errorf("Migration %d (%s) failed: %s", migration.Version, migration.Name, err)
+		}
+
+		query := DB.Create(&SchemaMigration{
+			Version: migration.Version,
+			Name: migration.Name,
+			AppliedAt: time.Now(),
+		})
+		if query.Error != nil {
+			return applied, query.Error
+		}
+
+		log.Printf("Applied migration %d (%s)\n", migration.Version, migration.Name)
+		applied++
+	}
+
+	return applied, nil
+}
+
+// Reverts the last N applied migrations and returns how many were reverted
+func Rollback(steps int) (int, error) {
+	err := createSchemaTable()
+	if err != nil {
+		return 0, err
+	}
+
+	var applied []SchemaMigration
+	query := DB.Order("version desc").Limit(steps).Find(&applied)
+	if query.Error != nil {
+		return 0, query.Error
+	}
+
+	reverted := 0
+	for _, schemaMigration := range applied {
+		migration, found := findMigration(schemaMigration.Version)
+		if !found {
+			return reverted, fmt.Errorf("Migration %d is applied but not registered", schemaMigration.Version)
+		}
+
+		err = migration.Down(DB)
+		if err != nil {
+			return reverted, fmt.Errorf("Rollback of migration %d (%s) failed: %s", migration.Version, migration.Name, err)
+		}
+
+		query = DB.Where("version = ?", migration.Version).Delete(SchemaMigration{})
+		if query.Error != nil {
+			return reverted, query.Error
+		}
+
+		log.Printf("Reverted migration %d (%s)\n", migration.Version, migration.Name)
+		reverted++
+	}
+
+	return reverted, nil
+}
+
+func createSchemaTable() error {
+	if DB == nil {
+		return fmt.Errorf("The database is not connected")
+	}
+
+	return DB.AutoMigrate(&SchemaMigration{}).Error
+}
+
+func findMigration(version uint32) (Migration, bool) {
+	for _, migration := range migrations {
+		if migration.Version == version {
+			return migration, true
+		}
+	}
+
+	return Migration{}, false
+}
+
+type byVersion []Migration
+
+func (list byVersion) Len() int           { return len(list) }
+func (list byVersion) Swap(i, j int)      { list[i], list[j] = list[j], list[i] }
+func (list byVersion) Less(i, j int) bool { return list[i].Version < list[j].Version }
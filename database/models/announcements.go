@@ -0,0 +1,273 @@
+package models
+
+import (
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/YagoCarballo/kumquat-academy-api/database"
+)
+
+type AnnouncementsModel struct{}
+var DBAnnouncements AnnouncementsModel
+
+func (model AnnouncementsModel) DB() *gorm.DB {
+	return database.DB
+}
+
+func (model AnnouncementsModel) CreateAnnouncement(announcement Announcement) (*Announcement, error) {
+	query := model.DB().Create(&announcement)
+	if query.Error != nil {
+		return nil, query.Error
+	}
+
+	return &announcement, nil
+}
+
+func (model AnnouncementsModel) ReadAnnouncement(id uint32) (*Announcement, error) {
+	var announcement Announcement
+
+	query := model.DB().Preload("Attachments").Preload("User").First(&announcement, "id = ?", id)
+	if query.Error != nil {
+		// If no Records found, return NIL otherwise return the error
+		switch query.Error {
+		case gorm.ErrRecordNotFound:
+			return nil, nil
+		default:
+			return nil, query.Error
+		}
+	}
+
+	return &announcement, nil
+}
+
+func (model AnnouncementsModel) DeleteAnnouncement(id uint32) (int64, error) {
+	query := model.DB().
+		Table("announcements").
+		Where("id = ?", id).
+		Delete(Announcement{})
+	if query.Error != nil {
+		return 0, query.Error
+	}
+
+	return query.RowsAffected, nil
+}
+
+// Lists the announcements of a module, including the ones posted on its assignments
+func (model AnnouncementsModel) FindAnnouncementsForModule(moduleCode string, userId uint32) ([]Announcement, error) {
+	announcements := []Announcement{}
+
+	query := model.DB().
+		Preload("Attachments").
+		Preload("User").
+		Scopes(announcementsFor([]string{moduleCode}, nil)).
+		Order("created_at desc").
+		Find(&announcements)
+	if query.Error != nil {
+		return nil, query.Error
+	}
+
+	return announcements, model.markRead(announcements, userId)
+}
+
+func (model AnnouncementsModel) FindAnnouncementsForCourse(courseId, userId uint32) ([]Announcement, error) {
+	announcements := []Announcement{}
+
+	query := model.DB().
+		Preload("Attachments").
+		Preload("User").
+		Order("created_at desc").
+		Find(&announcements, "course_id = ?", courseId)
+	if query.Error != nil {
+		return nil, query.Error
+	}
+
+	return announcements, model.markRead(announcements, userId)
+}
+
+// Builds the feed of a user with the announcements of all its modules (and their courses and assignments)
+func (model AnnouncementsModel) FindAnnouncementsForUser(username string, userId uint32, page int) ([]Announcement, error) {
+	announcements := []Announcement{}
+
+	moduleCodes, courseIds, err := model.userAudience(username)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(moduleCodes) <= 0 && len(courseIds) <= 0 {
+		return announcements, nil
+	}
+
+	query := model.DB().
+		Preload("Attachments").
+		Preload("User").
+		Scopes(announcementsFor(moduleCodes, courseIds)).
+		Order("created_at desc").
+		Limit(10).Offset(page * 10).
+		Find(&announcements)
+	if query.Error != nil {
+		return nil, query.Error
+	}
+
+	return announcements, model.markRead(announcements, userId)
+}
+
+// Checks if an announcement is part of the feed of a user
+func (model AnnouncementsModel) IsAnnouncementForUser(username string, announcementId uint32) bool {
+	var count int
+
+	moduleCodes, courseIds, err := model.userAudience(username)
+	if err != nil || (len(moduleCodes) <= 0 && len(courseIds) <= 0) {
+		return false
+	}
+
+	query := model.DB().Model(&Announcement{}).
+		Scopes(announcementsFor(moduleCodes, courseIds)).
+		Where("id = ?", announcementId).
+		Count(&count)
+
+	return query.Error == nil && count > 0
+}
+
+func (model AnnouncementsModel) CountUnreadAnnouncements(username string, userId uint32) (int, error) {
+	var count int
+
+	moduleCodes, courseIds, err := model.userAudience(username)
+	if err != nil || (len(moduleCodes) <= 0 && len(courseIds) <= 0) {
+		return 0, err
+	}
+
+	query := model.DB().Model(&Announcement{}).
+		Scopes(announcementsFor(moduleCodes, courseIds)).
+		Where("not exists (select 1 from announcement_reads " +
+			"where announcement_reads.announcement_id = announcements.id and announcement_reads.user_id = ?)", userId).
+		Count(&count)
+	if query.Error != nil {
+		return 0, query.Error
+	}
+
+	return count, nil
+}
+
+func (model AnnouncementsModel) MarkAsRead(announcementId, userId uint32) (*AnnouncementRead, error) {
+	read := AnnouncementRead{
+		AnnouncementID: announcementId,
+		UserID: userId,
+		Date: time.Now(),
+	}
+
+	query := model.DB().Create(&read)
+	if query.Error != nil {
+		isDuplicated := database.IsDuplicatedError(query.Error)
+		if isDuplicated {
+			return nil, nil
+		}
+
+		return nil, query.Error
+	}
+
+	return &read, nil
+}
+
+func (model AnnouncementsModel) MarkAsUnread(announcementId, userId uint32) (int64, error) {
+	query := model.DB().
+		Where("announcement_id = ? and user_id = ?", announcementId, userId).
+		Delete(AnnouncementRead{})
+	if query.Error != nil {
+		return 0, query.Error
+	}
+
+	return query.RowsAffected, nil
+}
+
+func (model AnnouncementsModel) AddAttachmentToAnnouncement(announcementId, attachmentId uint32) (int64, error) {
+	announcementAttachment := AnnouncementAttachments{
+		AnnouncementID: announcementId,
+		AttachmentID: attachmentId,
+	}
+
+	query := model.DB().Create(&announcementAttachment)
+	if query.Error != nil {
+		return query.RowsAffected, query.Error
+	}
+
+	return query.RowsAffected, nil
+}
+
+func (model AnnouncementsModel) RemoveAttachmentFromAnnouncement(announcementId, attachmentId uint32) (int64, error) {
+	query := model.DB().
+				Table("announcement_attachments").
+				Where("announcement_id = ? and attachment_id = ?", announcementId, attachmentId).
+				Delete(AnnouncementAttachments{})
+	if query.Error != nil {
+		return 0, query.Error
+	}
+
+	return query.RowsAffected, nil
+}
+
+// Gets the module codes and courses of the modules of a user
+func (model AnnouncementsModel) userAudience(username string) ([]string, []uint32, error) {
+	modules, err := DBModule.FindModulesForUser(username)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	moduleCodes := []string{}
+	courseIds := []uint32{}
+	for _, module := range modules {
+		moduleCodes = append(moduleCodes, module.Code)
+		courseIds = append(courseIds, module.CourseId)
+	}
+
+	return moduleCodes, courseIds, nil
+}
+
+// Sets the read flag of the announcements read by the user
+func (model AnnouncementsModel) markRead(announcements []Announcement, userId uint32) error {
+	if len(announcements) <= 0 {
+		return nil
+	}
+
+	ids := []uint32{}
+	for _, announcement := range announcements {
+		ids = append(ids, announcement.ID)
+	}
+
+	var readIds []uint32
+	query := model.DB().Table("announcement_reads").
+		Where("user_id = ? and announcement_id in (?)", userId, ids).
+		Pluck("announcement_id", &readIds)
+	if query.Error != nil {
+		return query.Error
+	}
+
+	for index, announcement := range announcements {
+		for _, readId := range readIds {
+			if announcement.ID == readId {
+				announcements[index].Read = true
+			}
+		}
+	}
+
+	return nil
+}
+
+// Filters the announcements posted to any of the modules (or their assignments) or courses given
+func announcementsFor(moduleCodes []string, courseIds []uint32) func (db *gorm.DB) *gorm.DB {
+	// An empty list would generate an invalid "in ()" clause
+	if len(moduleCodes) <= 0 {
+		moduleCodes = []string{""}
+	}
+
+	if len(courseIds) <= 0 {
+		courseIds = []uint32{0}
+	}
+
+	return func (db *gorm.DB) *gorm.DB {
+		return db.Where(
+			"announcements.module_code in (?) or announcements.course_id in (?) or " +
+			"announcements.assignment_id in (select id from assignments where assignments.module_code in (?))",
+			moduleCodes, courseIds, moduleCodes,
+		)
+	}
+}
@@ -0,0 +1,92 @@
+package models
+
+import (
+	"testing"
+
+	. "github.com/franela/goblin"
+	. "github.com/YagoCarballo/kumquat-academy-api/constants"
+)
+
+func Test_Database_Announcements(t *testing.T) {
+	g := Goblin(t)
+	var moduleAnnouncementId, courseAnnouncementId, otherAnnouncementId uint32
+
+	g.Describe("When posting announcements", func() {
+		g.Before(func() {
+			moduleCode := "AC31007"
+			announcement, err := DBAnnouncements.CreateAnnouncement(Announcement{
+				Title: "Lab moved", Body: "The lab is now in room 2.", UserID: 2, ModuleCode: &moduleCode,
+			})
+			g.Assert(err == nil).IsTrue()
+			moduleAnnouncementId = announcement.ID
+
+			courseId := uint32(1)
+			announcement, err = DBAnnouncements.CreateAnnouncement(Announcement{
+				Title: "Welcome", Body: "Welcome to Computing.", UserID: 2, CourseID: &courseId,
+			})
+			g.Assert(err == nil).IsTrue()
+			courseAnnouncementId = announcement.ID
+
+			otherCode := "AC51001"
+			announcement, err = DBAnnouncements.CreateAnnouncement(Announcement{
+				Title: "Other", Body: "Not for the students of AC31007.", UserID: 2, ModuleCode: &otherCode,
+			})
+			g.Assert(err == nil).IsTrue()
+			otherAnnouncementId = announcement.ID
+		})
+
+		g.After(func() {
+			DBAnnouncements.DeleteAnnouncement(moduleAnnouncementId)
+			DBAnnouncements.DeleteAnnouncement(courseAnnouncementId)
+			DBAnnouncements.DeleteAnnouncement(otherAnnouncementId)
+		})
+
+		g.It("Should list the announcements of a module", func() {
+			announcements, err := DBAnnouncements.FindAnnouncementsForModule("AC31007", 3)
+
+			g.Assert(err == nil).IsTrue()
+			g.Assert(len(announcements)).Equal(1)
+			g.Assert(announcements[0].Read).IsFalse()
+		})
+
+		g.It("Should build the feed of a student from all its modules and courses", func() {
+			announcements, err := DBAnnouncements.FindAnnouncementsForUser("student", 3, 0)
+
+			g.Assert(err == nil).IsTrue()
+			g.Assert(len(announcements)).Equal(2)
+			g.Assert(DBAnnouncements.IsAnnouncementForUser("student", otherAnnouncementId)).IsFalse()
+		})
+
+		g.It("Should give an empty feed to users without modules", func() {
+			announcements, err := DBAnnouncements.FindAnnouncementsForUser("guest", 4, 0)
+
+			g.Assert(err == nil).IsTrue()
+			g.Assert(len(announcements)).Equal(0)
+		})
+
+		g.It("Should track the announcements read by a user", func() {
+			unread, err := DBAnnouncements.CountUnreadAnnouncements("student", 3)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(unread).Equal(2)
+
+			read, err := DBAnnouncements.MarkAsRead(courseAnnouncementId, 3)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(read != nil).IsTrue()
+
+			unread, _ = DBAnnouncements.CountUnreadAnnouncements("student", 3)
+			g.Assert(unread).Equal(1)
+
+			announcements, _ := DBAnnouncements.FindAnnouncementsForCourse(1, 3)
+			g.Assert(announcements[0].Read).IsTrue()
+
+			count, err := DBAnnouncements.MarkAsUnread(courseAnnouncementId, 3)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(count == 1).IsTrue()
+		})
+
+		g.It("Should check the permissions of the module of the announcement", func() {
+			g.Assert(DBPermissions.IsActionPermittedOnAnnouncement(2, moduleAnnouncementId, DeletePermission)).IsTrue()
+			g.Assert(DBPermissions.IsActionPermittedOnAnnouncement(3, moduleAnnouncementId, DeletePermission)).IsFalse()
+		})
+	})
+}
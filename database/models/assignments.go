@@ -1,14 +1,27 @@
 package models
 
 import (
+	"errors"
+	"math"
+
 	"github.com/jinzhu/gorm"
 	"github.com/YagoCarballo/kumquat-academy-api/database"
 	"time"
 )
 
+// The grading of a submission, when the assignment has a rubric the grade is worked out from the scores
+type SubmissionGrading struct {
+	Grade		*float64	`json:"grade"`
+	Feedback	string		`json:"feedback"`
+	Scores		[]SubmissionScore `json:"scores"`
+}
+
 type AssignmentsModel struct{}
 var DBAssignments AssignmentsModel
 
+var ErrDeadlinePassed = errors.New("The deadline of the assignment has passed.")
+var ErrSubmissionLocked = errors.New("The submission is already being reviewed or graded.")
+
 func (model AssignmentsModel) DB() *gorm.DB {
 	return database.DB
 }
@@ -39,8 +52,29 @@ func (model AssignmentsModel) ReadAssignment(id uint32) (*Assignment, error) {
 	return &assignment, nil
 }
 
+// Updates an assignment, the status (when given) must be reachable from the current one
 func (model AssignmentsModel) UpdateAssignment(id uint32, assignment Assignment) (*Assignment, error) {
-	query := model.DB().Table("assignments").Where("id = ?", id).Update(&assignment)
+	current, err := model.ReadAssignment(id)
+	if err != nil || current == nil {
+		return nil, err
+	}
+
+	if assignment.Status != "" && !current.Status.CanTransitionTo(assignment.Status) {
+		return nil, InvalidTransitionError{ From: string(current.Status), To: string(assignment.Status) }
+	}
+
+	// Updating with a struct skips zero values, so the team flag, late policy and allowed extensions are always set on their own
+	query := model.DB().Table("assignments").Where("id = ?", id).Updates(map[string]interface{}{
+		"team_assignment": assignment.TeamAssignment,
+		"late_days": assignment.LateDays,
+		"late_penalty": assignment.LatePenalty,
+		"allowed_extensions": assignment.AllowedExtensions,
+	})
+	if query.Error != nil {
+		return nil, query.Error
+	}
+
+	query = model.DB().Table("assignments").Where("id = ?", id).Update(&assignment)
 	if query.Error != nil {
 		return nil, query.Error
 	}
@@ -98,6 +132,7 @@ func (model AssignmentsModel) FindAssignmentsForModule(code string, readOnly boo
 					"avatar_id":		student.AvatarId,
 					"avatar":			nil,
 					"submission":		nil,
+					"progress":			0,
 				};
 
 				if student.Avatar != nil {
@@ -106,11 +141,18 @@ func (model AssignmentsModel) FindAssignmentsForModule(code string, readOnly boo
 
 				// Get the submission
 				submission := &Submission{}
-				query := model.DB().Preload("Attachment").Where("user_id = ? and assignment_id = ?", student.ID, assignment.ID).First(submission)
+				query := model.DB().Preload("Attachment").Order("submitted_on desc, id desc").
+					Where("user_id = ? and assignment_id = ? and status != ?", student.ID, assignment.ID, SubmissionCanceled).First(submission)
 				if query.Error == nil && submission != nil {
 					studentMap["submission"] = submission
 				}
 
+				// Get the percentage of completed tasks
+				progress, err := DBTasks.GetProgress(assignment.ID, student.ID)
+				if err == nil {
+					studentMap["progress"] = progress
+				}
+
 				assignments[index].Students = append(assignments[index].Students, studentMap)
 			}
 		}
@@ -173,13 +215,21 @@ func (model AssignmentsModel) RemoveAttachmentFromAssignment(assignmentId, attac
 
 
 func (model AssignmentsModel) SubmitAssignment(userId, assignmentId, attachmentId uint32, description string) (*Submission, error) {
+	submittedOn := time.Now()
+
+	late, err := model.isLate(assignmentId, userId, submittedOn)
+	if err != nil {
+		return nil, err
+	}
+
 	submission := Submission{
 		UserID: userId,
 		AssignmentID: assignmentId,
 		AttachmentID: attachmentId,
 		Status: SubmissionSent,
 		Description: description,
-		SubmittedOn: time.Now(),
+		Late: late,
+		SubmittedOn: submittedOn,
 	}
 
 	query := model.DB().Create(&submission)
@@ -190,23 +240,624 @@ func (model AssignmentsModel) SubmitAssignment(userId, assignmentId, attachmentI
 	return &submission, nil
 }
 
+// Submits a team assignment on behalf of the whole team, every member gets a submission with the same attachment.
+// The submissions the members made before are canceled, and nothing is submitted when a member is past their
+// late window or has a submission that is already being reviewed or graded.
+// Whether the submission is late is decided by the deadline of the team, the same for every member.
+func (model AssignmentsModel) SubmitTeamAssignment(team *Team, assignmentId, attachmentId uint32, description string) ([]Submission, error) {
+	assignment, err := model.ReadAssignment(assignmentId)
+	if err != nil || assignment == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	teamDeadline, err := model.FindTeamDeadline(assignment, team.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	submissions := []Submission{}
+	submittedOn := time.Now()
+	tx := model.DB().Begin()
+
+	for _, member := range team.Members {
+		// Each member has their own deadline, as some can have an extension
+		deadline, err := model.FindDeadline(assignment, member.ID)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+
+		if submittedOn.After(LateWindowEnd(assignment, deadline)) {
+			tx.Rollback()
+			return nil, ErrDeadlinePassed
+		}
+
+		var previous []Submission
+		query := tx.Find(&previous, "assignment_id = ? and user_id = ? and status != ?", assignmentId, member.ID, SubmissionCanceled)
+		if query.Error != nil {
+			tx.Rollback()
+			return nil, query.Error
+		}
+
+		for _, submission := range previous {
+			if submission.Status != SubmissionSent {
+				tx.Rollback()
+				return nil, ErrSubmissionLocked
+			}
+		}
+
+		if len(previous) > 0 {
+			query = tx.Table("submissions").
+				Where("assignment_id = ? and user_id = ? and status = ?", assignmentId, member.ID, SubmissionSent).
+				Updates(map[string]interface{}{
+					"status": SubmissionCanceled,
+					"canceled_on": &submittedOn,
+				})
+			if query.Error != nil {
+				tx.Rollback()
+				return nil, query.Error
+			}
+		}
+
+		submission := Submission{
+			UserID: member.ID,
+			AssignmentID: assignmentId,
+			AttachmentID: attachmentId,
+			TeamID: &team.ID,
+			Status: SubmissionSent,
+			Description: description,
+			Late: submittedOn.After(teamDeadline),
+			SubmittedOn: submittedOn,
+		}
+
+		query = tx.Create(&submission)
+		if query.Error != nil {
+			tx.Rollback()
+			return nil, query.Error
+		}
+
+		submissions = append(submissions, submission)
+	}
+
+	query := tx.Commit()
+	if query.Error != nil {
+		return nil, query.Error
+	}
+
+	return submissions, nil
+}
+
+// Grades a submission, if it was made by a team the grade is given to all the members.
+// Late submissions get the penalty of the assignment deducted from the grade.
 func (model AssignmentsModel) GradeAssignment(submissionId, grade uint32) (*Submission, error) {
 	var submission Submission
-	gradedOn := time.Now()
 
-	query := DBUser.DB().Table("submissions").Where("id = ?", submissionId).Updates(map[string]interface{}{
-		"grade": grade,
-		"graded_on": &gradedOn,
-	})
+	query := model.DB().Where("id = ?", submissionId).First(&submission)
 	if query.Error != nil {
-		return &submission, query.Error
+		return nil, query.Error
+	}
+
+	err := model.gradeSubmission(model.DB(), submission, float64(grade), time.Now())
+	if err != nil {
+		return nil, err
 	}
 
 	// Get the submission
 	query = model.DB().Preload("Attachment").Where("id = ?", submissionId).First(&submission)
-	if query.Error == nil {
-		return &submission, query.Error
+	if query.Error != nil {
+		return nil, query.Error
+	}
+
+	return &submission, nil
+}
+
+func (model AssignmentsModel) gradeSubmission(db *gorm.DB, submission Submission, rawGrade float64, gradedOn time.Time) error {
+	assignment, err := model.ReadAssignment(submission.AssignmentID)
+	if err != nil || assignment == nil {
+		return gorm.ErrRecordNotFound
+	}
+
+	// Every member of a team gets the same penalty, whichever of their submissions is graded
+	var deadline time.Time
+	if submission.TeamID != nil {
+		deadline, err = model.FindTeamDeadline(assignment, *submission.TeamID)
+	} else {
+		deadline, err = model.FindDeadline(assignment, submission.UserID)
+	}
+	if err != nil {
+		return err
+	}
+
+	penalty := LatePenalty(assignment, submission.SubmittedOn, deadline)
+
+	update := db.Table("submissions").Where("id = ?", submission.ID)
+	if submission.TeamID != nil {
+		update = update.Or("team_id = ? and assignment_id = ? and status != ?", *submission.TeamID, submission.AssignmentID, SubmissionCanceled)
+	}
+
+	query := update.Updates(map[string]interface{}{
+		"grade": rawGrade * (100 - penalty) / 100,
+		"raw_grade": rawGrade,
+		"penalty": penalty,
+		"status": SubmissionGraded,
+		"graded_on": &gradedOn,
+	})
+	return query.Error
+}
+
+// Grades a submission with written feedback, scoring it against the rubric of the assignment if it has one.
+// Team submissions share the grade, feedback and scores with all the members.
+func (model AssignmentsModel) GradeSubmission(submissionId uint32, grading SubmissionGrading) (*Submission, error) {
+	var submission Submission
+
+	query := model.DB().Where("id = ?", submissionId).First(&submission)
+	if query.Error != nil {
+		// If no Records found, return NIL otherwise return the error
+		switch query.Error {
+		case gorm.ErrRecordNotFound:
+			return nil, nil
+		default:
+			return nil, query.Error
+		}
+	}
+
+	criteria, err := DBRubrics.FindRubric(submission.AssignmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	var grade float64
+	var scores []SubmissionScore
+	if len(criteria) > 0 {
+		scores, grade, err = ScoreRubric(criteria, grading.Scores)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		if grading.Grade == nil || *grading.Grade < 0 || *grading.Grade > 100 {
+			return nil, ErrInvalidGrade
+		}
+		grade = *grading.Grade
+	}
+
+	submissions, err := model.findSubmissionGroup(model.DB(), submission)
+	if err != nil {
+		return nil, err
+	}
+
+	tx := model.DB().Begin()
+
+	err = model.gradeSubmission(tx, submission, grade, time.Now())
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	query = tx.Table("submissions").Where("id in (?)", submissions).Update("feedback", grading.Feedback)
+	if query.Error != nil {
+		tx.Rollback()
+		return nil, query.Error
+	}
+
+	query = tx.Where("submission_id in (?)", submissions).Delete(SubmissionScore{})
+	if query.Error != nil {
+		tx.Rollback()
+		return nil, query.Error
+	}
+
+	for _, id := range submissions {
+		for _, score := range scores {
+			score.SubmissionID = id
+
+			query = tx.Create(&score)
+			if query.Error != nil {
+				tx.Rollback()
+				return nil, query.Error
+			}
+		}
+	}
+
+	query = tx.Commit()
+	if query.Error != nil {
+		return nil, query.Error
+	}
+
+	return model.ReadSubmission(submission.AssignmentID, submissionId)
+}
+
+// Gets the ids of a submission and, for team assignments, the active submissions of the rest of the team
+func (model AssignmentsModel) findSubmissionGroup(db *gorm.DB, submission Submission) ([]uint32, error) {
+	if submission.TeamID == nil {
+		return []uint32{ submission.ID }, nil
+	}
+
+	var submissions []uint32
+	query := db.Table("submissions").Where("id = ?", submission.ID).
+		Or("team_id = ? and assignment_id = ? and status != ?", *submission.TeamID, submission.AssignmentID, SubmissionCanceled).
+		Pluck("id", &submissions)
+	if query.Error != nil {
+		return nil, query.Error
+	}
+
+	return submissions, nil
+}
+
+func (model AssignmentsModel) ReadSubmission(assignmentId, submissionId uint32) (*Submission, error) {
+	var submission Submission
+
+	query := model.DB().Preload("Attachment").Preload("FeedbackAttachment").Preload("Scores").
+		First(&submission, "id = ? and assignment_id = ?", submissionId, assignmentId)
+	if query.Error != nil {
+		// If no Records found, return NIL otherwise return the error
+		switch query.Error {
+		case gorm.ErrRecordNotFound:
+			return nil, nil
+		default:
+			return nil, query.Error
+		}
+	}
+
+	return &submission, nil
+}
+
+// Returns an annotated file to the student (or the team) along with the feedback
+func (model AssignmentsModel) SetFeedbackAttachment(submission Submission, attachmentId uint32) (int64, error) {
+	submissions, err := model.findSubmissionGroup(model.DB(), submission)
+	if err != nil {
+		return 0, err
+	}
+
+	query := model.DB().Table("submissions").Where("id in (?)", submissions).Update("feedback_attachment_id", attachmentId)
+	if query.Error != nil {
+		return 0, query.Error
+	}
+
+	return query.RowsAffected, nil
+}
+
+// Returns the graded submissions to the students, until then they can't see their grades
+func (model AssignmentsModel) ReleaseGrades(assignmentId uint32) (*Assignment, error) {
+	return model.SetAssignmentStatus(assignmentId, AssignmentReturned)
+}
+
+// Moves an assignment to a new status, failing with an InvalidTransitionError if it can't be reached from the current one
+func (model AssignmentsModel) SetAssignmentStatus(id uint32, status AssignmentStatus) (*Assignment, error) {
+	assignment, err := model.ReadAssignment(id)
+	if err != nil || assignment == nil {
+		return nil, err
+	}
+
+	if !assignment.Status.CanTransitionTo(status) {
+		return nil, InvalidTransitionError{ From: string(assignment.Status), To: string(status) }
+	}
+
+	query := model.DB().Table("assignments").Where("id = ?", id).Update("status", status)
+	if query.Error != nil {
+		return nil, query.Error
+	}
+
+	assignment.Status = status
+	return assignment, nil
+}
+
+// Opens the assignments that have started and closes the ones whose late window has ended for every student,
+// returns the number of assignments opened and closed
+func (model AssignmentsModel) UpdateScheduledStatuses(now time.Time) (int64, int64, error) {
+	query := model.DB().Table("assignments").
+		Where("status = ? and start <= ?", AssignmentCreated, now).
+		Update("status", AssignmentAvailable)
+	if query.Error != nil {
+		return 0, 0, query.Error
+	}
+	opened := query.RowsAffected
+
+	// End is a reserved word in some databases, so the deadlines are checked here
+	var assignments []Assignment
+	query = model.DB().Find(&assignments, "status = ?", AssignmentAvailable)
+	if query.Error != nil {
+		return opened, 0, query.Error
+	}
+
+	overdue := []Assignment{}
+	overdueIds := []uint32{}
+	for _, assignment := range assignments {
+		if now.After(assignment.End) {
+			overdue = append(overdue, assignment)
+			overdueIds = append(overdueIds, assignment.ID)
+		}
+	}
+
+	if len(overdue) <= 0 {
+		return opened, 0, nil
+	}
+
+	// Students with an extension can keep submitting until their own late window ends
+	var extensions []AssignmentExtension
+	query = model.DB().Find(&extensions, "assignment_id in (?)", overdueIds)
+	if query.Error != nil {
+		return opened, 0, query.Error
+	}
+
+	deadlines := map[uint32]time.Time{}
+	for _, extension := range extensions {
+		if extension.End.After(deadlines[extension.AssignmentID]) {
+			deadlines[extension.AssignmentID] = extension.End
+		}
+	}
+
+	closedIds := []uint32{}
+	for _, assignment := range overdue {
+		deadline := assignment.End
+		if deadlines[assignment.ID].After(deadline) {
+			deadline = deadlines[assignment.ID]
+		}
+
+		if now.After(LateWindowEnd(&assignment, deadline)) {
+			closedIds = append(closedIds, assignment.ID)
+		}
+	}
+
+	if len(closedIds) <= 0 {
+		return opened, 0, nil
+	}
+
+	query = model.DB().Table("assignments").Where("id in (?) and status = ?", closedIds, AssignmentAvailable).Update("status", AssignmentSent)
+	if query.Error != nil {
+		return opened, 0, query.Error
+	}
+
+	return opened, query.RowsAffected, nil
+}
+
+// Gets the open assignments whose deadline is after from and not after to
+func (model AssignmentsModel) FindAssignmentsDueBetween(from, to time.Time) ([]Assignment, error) {
+	due := []Assignment{}
+
+	// End is a reserved word in some databases, so the deadlines are checked here
+	var assignments []Assignment
+	query := model.DB().Order("id").Find(&assignments, "status = ?", AssignmentAvailable)
+	if query.Error != nil {
+		return nil, query.Error
+	}
+
+	for _, assignment := range assignments {
+		if assignment.End.After(from) && !assignment.End.After(to) {
+			due = append(due, assignment)
+		}
+	}
+
+	return due, nil
+}
+
+// Gets the deadline of an assignment for a student, taking their extension into account
+func (model AssignmentsModel) FindDeadline(assignment *Assignment, userId uint32) (time.Time, error) {
+	extension, err := model.FindExtension(assignment.ID, userId)
+	if err != nil {
+		return assignment.End, err
+	}
+
+	if extension != nil {
+		return extension.End, nil
+	}
+
+	return assignment.End, nil
+}
+
+// Gets the deadline of an assignment for a team, which is the latest of the deadlines of its members
+func (model AssignmentsModel) FindTeamDeadline(assignment *Assignment, teamId uint32) (time.Time, error) {
+	var memberIds []uint32
+	query := model.DB().Table("team_members").Where("team_id = ?", teamId).Pluck("user_id", &memberIds)
+	if query.Error != nil {
+		return assignment.End, query.Error
+	}
+
+	if len(memberIds) <= 0 {
+		return assignment.End, nil
+	}
+
+	var extensions []AssignmentExtension
+	query = model.DB().Find(&extensions, "assignment_id = ? and user_id in (?)", assignment.ID, memberIds)
+	if query.Error != nil {
+		return assignment.End, query.Error
+	}
+
+	// The members without an extension have the deadline of the assignment
+	var deadline time.Time
+	if len(extensions) < len(memberIds) {
+		deadline = assignment.End
+	}
+
+	for _, extension := range extensions {
+		if extension.End.After(deadline) {
+			deadline = extension.End
+		}
+	}
+
+	return deadline, nil
+}
+
+func (model AssignmentsModel) isLate(assignmentId, userId uint32, submittedOn time.Time) (bool, error) {
+	assignment, err := model.ReadAssignment(assignmentId)
+	if err != nil || assignment == nil {
+		return false, err
+	}
+
+	deadline, err := model.FindDeadline(assignment, userId)
+	if err != nil {
+		return false, err
+	}
+
+	return submittedOn.After(deadline), nil
+}
+
+// Submissions are accepted until the late window after the deadline closes
+func LateWindowEnd(assignment *Assignment, deadline time.Time) time.Time {
+	return deadline.AddDate(0, 0, int(assignment.LateDays))
+}
+
+// Gets the percentage deducted from a grade, the penalty is applied for every day (or part of a day) late
+func LatePenalty(assignment *Assignment, submittedOn, deadline time.Time) float64 {
+	if !submittedOn.After(deadline) || assignment.LatePenalty <= 0 {
+		return 0
+	}
+
+	days := math.Ceil(submittedOn.Sub(deadline).Hours() / 24)
+	return math.Min(100, days * assignment.LatePenalty)
+}
+
+// Gets the submission of a student that is still in place (not canceled)
+func (model AssignmentsModel) FindActiveSubmission(assignmentId, userId uint32) (*Submission, error) {
+	var submission Submission
+
+	query := model.DB().Preload("Attachment").Order("submitted_on desc, id desc").
+		First(&submission, "assignment_id = ? and user_id = ? and status != ?", assignmentId, userId, SubmissionCanceled)
+	if query.Error != nil {
+		// If no Records found, return NIL otherwise return the error
+		switch query.Error {
+		case gorm.ErrRecordNotFound:
+			return nil, nil
+		default:
+			return nil, query.Error
+		}
 	}
 
 	return &submission, nil
 }
+
+// Gets every submission of a student for an assignment, including the withdrawn ones
+func (model AssignmentsModel) FindSubmissionHistory(assignmentId, userId uint32) ([]Submission, error) {
+	submissions := []Submission{}
+
+	query := model.DB().Preload("Attachment").Preload("FeedbackAttachment").Preload("Scores").Order("submitted_on, id").
+		Find(&submissions, "assignment_id = ? and user_id = ?", assignmentId, userId)
+	if query.Error != nil {
+		return nil, query.Error
+	}
+
+	return submissions, nil
+}
+
+// Withdraws the submission of a student so the assignment can be submitted again, it is kept as canceled.
+// Team submissions are withdrawn for the whole team.
+func (model AssignmentsModel) CancelSubmission(assignmentId, userId uint32) (*Submission, error) {
+	submission, err := model.FindActiveSubmission(assignmentId, userId)
+	if err != nil || submission == nil {
+		return nil, err
+	}
+
+	if submission.Status != SubmissionSent {
+		return nil, ErrSubmissionLocked
+	}
+
+	assignment, err := model.ReadAssignment(assignmentId)
+	if err != nil || assignment == nil {
+		return nil, err
+	}
+
+	deadline, err := model.FindDeadline(assignment, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	if time.Now().After(deadline) {
+		return nil, ErrDeadlinePassed
+	}
+
+	canceledOn := time.Now()
+	update := model.DB().Table("submissions").Where("id = ?", submission.ID)
+	if submission.TeamID != nil {
+		update = update.Or("team_id = ? and assignment_id = ? and status = ?", *submission.TeamID, assignmentId, SubmissionSent)
+	}
+
+	query := update.Updates(map[string]interface{}{
+		"status": SubmissionCanceled,
+		"canceled_on": &canceledOn,
+	})
+	if query.Error != nil {
+		return nil, query.Error
+	}
+
+	submission.Status = SubmissionCanceled
+	submission.CanceledOn = &canceledOn
+	return submission, nil
+}
+
+// Marks a submission as being reviewed, from then on it can't be withdrawn
+func (model AssignmentsModel) ReviewSubmission(assignmentId, submissionId uint32) (int64, error) {
+	var submission Submission
+
+	query := model.DB().First(&submission, "id = ? and assignment_id = ? and status = ?", submissionId, assignmentId, SubmissionSent)
+	if query.Error != nil {
+		// If no Records found, nothing is updated
+		switch query.Error {
+		case gorm.ErrRecordNotFound:
+			return 0, nil
+		default:
+			return 0, query.Error
+		}
+	}
+
+	update := model.DB().Table("submissions").Where("id = ?", submission.ID)
+	if submission.TeamID != nil {
+		update = update.Or("team_id = ? and assignment_id = ? and status = ?", *submission.TeamID, assignmentId, SubmissionSent)
+	}
+
+	query = update.Update("status", SubmissionReview)
+	if query.Error != nil {
+		return 0, query.Error
+	}
+
+	return query.RowsAffected, nil
+}
+
+// Grants (or changes) the extension of a student
+func (model AssignmentsModel) GrantExtension(extension AssignmentExtension) (*AssignmentExtension, error) {
+	query := model.DB().Where("assignment_id = ? and user_id = ?", extension.AssignmentID, extension.UserID).Delete(AssignmentExtension{})
+	if query.Error != nil {
+		return nil, query.Error
+	}
+
+	query = model.DB().Create(&extension)
+	if query.Error != nil {
+		return nil, query.Error
+	}
+
+	return &extension, nil
+}
+
+func (model AssignmentsModel) RevokeExtension(assignmentId, userId uint32) (int64, error) {
+	query := model.DB().Where("assignment_id = ? and user_id = ?", assignmentId, userId).Delete(AssignmentExtension{})
+	if query.Error != nil {
+		return 0, query.Error
+	}
+
+	return query.RowsAffected, nil
+}
+
+func (model AssignmentsModel) FindExtension(assignmentId, userId uint32) (*AssignmentExtension, error) {
+	var extension AssignmentExtension
+
+	query := model.DB().First(&extension, "assignment_id = ? and user_id = ?", assignmentId, userId)
+	if query.Error != nil {
+		// If no Records found, return NIL otherwise return the error
+		switch query.Error {
+		case gorm.ErrRecordNotFound:
+			return nil, nil
+		default:
+			return nil, query.Error
+		}
+	}
+
+	return &extension, nil
+}
+
+func (model AssignmentsModel) FindExtensions(assignmentId uint32) ([]AssignmentExtension, error) {
+	extensions := []AssignmentExtension{}
+
+	query := model.DB().Preload("User").Order("user_id").Find(&extensions, "assignment_id = ?", assignmentId)
+	if query.Error != nil {
+		return nil, query.Error
+	}
+
+	return extensions, nil
+}
@@ -112,3 +112,166 @@ func Test_Database_Assignments(t *testing.T) {
 		})
 	})
 }
+
+func Test_Database_Submissions(t *testing.T) {
+	g := Goblin(t)
+	var assignmentId, attachmentId, submissionId uint32
+
+	g.Describe("When submitting an assignment", func() {
+		g.Before(func() {
+			// AC51001 starts without students or assignments
+			DBModule.DB().Create(&UserModule{ UserID: 3, ModuleCode: "AC51001", RoleID: 3, ClassID: 2 })
+			DBModule.DB().Create(&UserModule{ UserID: 5, ModuleCode: "AC51001", RoleID: 3, ClassID: 2 })
+
+			assignment, _ := DBAssignments.CreateAssignment(Assignment{ Title: "Report", Description: "Write a report.", ModuleCode: "AC51001",
+				Start: time.Now(), End: time.Now().AddDate(0, 0, 2), Status: AssignmentAvailable, LateDays: 2, LatePenalty: 10 })
+			assignmentId = assignment.ID
+
+			attachment, _ := DBAttachment.CreateAttachment("report.pdf", "application/pdf", "submissions-test-token")
+			attachmentId = attachment.ID
+		})
+
+		g.It("Should deduct the penalty for every day or part of a day late", func() {
+			assignment := &Assignment{ LatePenalty: 10 }
+			deadline := time.Now()
+
+			g.Assert(LatePenalty(assignment, deadline.Add(-time.Hour), deadline)).Equal(float64(0))
+			g.Assert(LatePenalty(assignment, deadline.Add(time.Hour), deadline)).Equal(float64(10))
+			g.Assert(LatePenalty(assignment, deadline.Add(36 * time.Hour), deadline)).Equal(float64(20))
+			g.Assert(LatePenalty(assignment, deadline.AddDate(0, 0, 20), deadline)).Equal(float64(100))
+		})
+
+		g.It("Should withdraw a submission and allow submitting again", func() {
+			submission, err := DBAssignments.SubmitAssignment(3, assignmentId, attachmentId, "First draft")
+			g.Assert(err == nil).IsTrue()
+			g.Assert(submission.Late).IsFalse()
+			g.Assert(DBPermissions.CanUserSubmitAssignment("student", assignmentId)).IsFalse()
+
+			canceled, err := DBAssignments.CancelSubmission(assignmentId, 3)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(canceled.Status).Equal(SubmissionCanceled)
+			g.Assert(DBPermissions.CanUserSubmitAssignment("student", assignmentId)).IsTrue()
+
+			submission, err = DBAssignments.SubmitAssignment(3, assignmentId, attachmentId, "Final version")
+			g.Assert(err == nil).IsTrue()
+			submissionId = submission.ID
+
+			history, err := DBAssignments.FindSubmissionHistory(assignmentId, 3)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(len(history)).Equal(2)
+
+			active, err := DBAssignments.FindActiveSubmission(assignmentId, 3)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(active.ID).Equal(submissionId)
+		})
+
+		g.It("Should not withdraw a submission under review", func() {
+			count, err := DBAssignments.ReviewSubmission(assignmentId, submissionId)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(count).Equal(int64(1))
+
+			_, err = DBAssignments.CancelSubmission(assignmentId, 3)
+			g.Assert(err).Equal(ErrSubmissionLocked)
+		})
+
+		g.It("Should only accept submissions during the late window of the student", func() {
+			DBAssignments.DB().Table("assignments").Where("id = ?", assignmentId).Update("end", time.Now().AddDate(0, 0, -5))
+			g.Assert(DBPermissions.CanUserSubmitAssignment("jane.johnston", assignmentId)).IsFalse()
+
+			_, err := DBAssignments.GrantExtension(AssignmentExtension{ AssignmentID: assignmentId, UserID: 5, End: time.Now().Add(-12 * time.Hour), GrantedBy: 2 })
+			g.Assert(err == nil).IsTrue()
+			g.Assert(DBPermissions.CanUserSubmitAssignment("jane.johnston", assignmentId)).IsTrue()
+
+			extensions, err := DBAssignments.FindExtensions(assignmentId)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(len(extensions)).Equal(1)
+		})
+
+		g.It("Should apply the late penalty when grading", func() {
+			submission, err := DBAssignments.SubmitAssignment(5, assignmentId, attachmentId, "Sorry it's late")
+			g.Assert(err == nil).IsTrue()
+			g.Assert(submission.Late).IsTrue()
+
+			_, err = DBAssignments.CancelSubmission(assignmentId, 5)
+			g.Assert(err).Equal(ErrDeadlinePassed)
+
+			graded, err := DBAssignments.GradeAssignment(submission.ID, 80)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(graded.RawGrade).Equal(float64(80))
+			g.Assert(graded.Penalty).Equal(float64(10))
+			g.Assert(graded.Grade).Equal(float64(72))
+		})
+
+		g.It("Should revoke an extension", func() {
+			count, err := DBAssignments.RevokeExtension(assignmentId, 5)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(count).Equal(int64(1))
+
+			extension, err := DBAssignments.FindExtension(assignmentId, 5)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(extension == nil).IsTrue()
+		})
+
+		g.After(func() {
+			DBModule.DB().Where("assignment_id = ?", assignmentId).Delete(Submission{})
+			DBAssignments.DeleteAssignment(assignmentId)
+			DBAttachment.DeleteAttachment(attachmentId, nil)
+			DBModule.DB().Where("module_code = ? and user_id in (?)", "AC51001", []uint32{ 3, 5 }).Delete(UserModule{})
+		})
+	})
+}
+
+func Test_Database_AssignmentStatus(t *testing.T) {
+	g := Goblin(t)
+	var assignmentId uint32
+	start := time.Now().AddDate(0, 0, -10)
+
+	g.Describe("When changing the status of an assignment", func() {
+		g.Before(func() {
+			assignment, _ := DBAssignments.CreateAssignment(Assignment{ Title: "Quiz", Description: "A short quiz.", ModuleCode: "AC51001",
+				Start: start, End: start.AddDate(0, 0, 7), Status: AssignmentCreated, LateDays: 1 })
+			assignmentId = assignment.ID
+		})
+
+		g.It("Should reject transitions outside of the state machine", func() {
+			_, err := DBAssignments.SetAssignmentStatus(assignmentId, AssignmentReturned)
+			g.Assert(err).Equal(InvalidTransitionError{ From: "created", To: "returned" })
+
+			_, err = DBAssignments.UpdateAssignment(assignmentId, Assignment{ Title: "Quiz", Status: AssignmentGraded })
+			g.Assert(err != nil).IsTrue()
+
+			g.Assert(AssignmentDraft.CanTransitionTo(AssignmentDraft)).IsTrue()
+			g.Assert(AssignmentReturned.CanTransitionTo(AssignmentAvailable)).IsFalse()
+		})
+
+		g.It("Should open the assignment once it starts", func() {
+			opened, _, err := DBAssignments.UpdateScheduledStatuses(start.AddDate(0, 0, 1))
+			g.Assert(err == nil).IsTrue()
+			g.Assert(opened >= 1).IsTrue()
+
+			assignment, _ := DBAssignments.ReadAssignment(assignmentId)
+			g.Assert(assignment.Status).Equal(AssignmentAvailable)
+		})
+
+		g.It("Should keep it open until the late window of every student ends", func() {
+			DBAssignments.GrantExtension(AssignmentExtension{ AssignmentID: assignmentId, UserID: 3, End: start.AddDate(0, 0, 9) })
+
+			// The deadline and the late day have passed, but not the extension
+			DBAssignments.UpdateScheduledStatuses(start.AddDate(0, 0, 9))
+			assignment, _ := DBAssignments.ReadAssignment(assignmentId)
+			g.Assert(assignment.Status).Equal(AssignmentAvailable)
+
+			_, closed, err := DBAssignments.UpdateScheduledStatuses(start.AddDate(0, 0, 11))
+			g.Assert(err == nil).IsTrue()
+			g.Assert(closed >= 1).IsTrue()
+
+			assignment, _ = DBAssignments.ReadAssignment(assignmentId)
+			g.Assert(assignment.Status).Equal(AssignmentSent)
+		})
+
+		g.After(func() {
+			DBAssignments.RevokeExtension(assignmentId, 3)
+			DBAssignments.DeleteAssignment(assignmentId)
+		})
+	})
+}
@@ -5,26 +5,106 @@ import (
 	"github.com/YagoCarballo/kumquat-academy-api/database"
 )
 
-type AttachmentsModel struct{}
+const (
+	AssignmentOwner		AttachmentOwnerType = "assignment"
+	LectureOwner		AttachmentOwnerType = "lecture"
+	ExamOwner			AttachmentOwnerType = "exam"
+	MaterialOwner		AttachmentOwnerType = "material"
+	AnnouncementOwner	AttachmentOwnerType = "announcement"
+	SubmissionOwner		AttachmentOwnerType = "submission"
+	FeedbackOwner		AttachmentOwnerType = "feedback"
+	AvatarOwner			AttachmentOwnerType = "avatar"
+)
+
+type (
+	AttachmentsModel struct{}
+
+	AttachmentOwnerType string
+
+	// Something an attachment belongs to, with what is needed to know who can download it.
+	// Modules are given by their code or by their ID, depending on the owner.
+	AttachmentOwner struct {
+		Type			AttachmentOwnerType `json:"type"`
+		ID				uint32 `json:"id"`
+		ModuleCode		string `json:"module_code,omitempty"`
+		ModuleID		uint32 `json:"module_id,omitempty"`
+		UserID			uint32 `json:"user_id,omitempty"`
+		TeamID			uint32 `json:"team_id,omitempty"`
+		AssignmentID	uint32 `json:"assignment_id,omitempty"`
+	}
+)
+
 var DBAttachment AttachmentsModel
 
+// Each query selects the id, module code, module id, user id, team id and assignment id of an owner
+var attachmentOwnerQueries = []struct {
+	Type	AttachmentOwnerType
+	Query	string
+}{
+	{AssignmentOwner, `
+		select assignments.id, assignments.module_code, 0, 0, 0, assignments.id from assignment_attachments
+		inner join assignments on assignments.id = assignment_attachments.assignment_id
+		where assignment_attachments.attachment_id = ?`},
+	{LectureOwner, `
+		select lectures.id, '', lectures.module_id, 0, 0, 0 from lecture_attachments
+		inner join lectures on lectures.id = lecture_attachments.lecture_id
+		where lecture_attachments.attachment_id = ?`},
+	{ExamOwner, `select id, module_code, 0, 0, 0, 0 from exams where attachment_id = ?`},
+	{MaterialOwner, `select id, '', module_id, 0, 0, 0 from materials where attachment_id = ?`},
+	{AnnouncementOwner, `select announcement_id, '', 0, 0, 0, 0 from announcement_attachments where attachment_id = ?`},
+	{SubmissionOwner, `
+		select submissions.id, assignments.module_code, 0, submissions.user_id, coalesce(submissions.team_id, 0), submissions.assignment_id from submissions
+		inner join assignments on assignments.id = submissions.assignment_id
+		where submissions.attachment_id = ?`},
+	{FeedbackOwner, `
+		select submissions.id, assignments.module_code, 0, submissions.user_id, coalesce(submissions.team_id, 0), submissions.assignment_id from submissions
+		inner join assignments on assignments.id = submissions.assignment_id
+		where submissions.feedback_attachment_id = ?`},
+	{AvatarOwner, `select id, '', 0, id, 0, 0 from users where avatar_id = ?`},
+}
+
 func (model AttachmentsModel) DB() *gorm.DB {
 	return database.DB
 }
 
 func (model AttachmentsModel) CreateAttachment(name, mimeType, token string) (*Attachment, error) {
-	attachment := Attachment{
+	attachment, _, err := model.CreateUpload(Attachment{
 		Name: name,
 		Type: mimeType,
 		Url: token,
+	})
+
+	return attachment, err
+}
+
+// Creates the attachment of an uploaded file, along with its size and who it counts against.
+// An attachment with a hash takes a reference to the blob of its content,
+// newBlob is false when the content was already stored for another attachment.
+func (model AttachmentsModel) CreateUpload(attachment Attachment) (*Attachment, bool, error) {
+	tx := model.DB().Begin()
+
+	newBlob := true
+	if attachment.Hash != nil {
+		var err error
+		newBlob, err = acquireBlob(tx, *attachment.Hash, attachment.Size)
+		if err != nil {
+			tx.Rollback()
+			return nil, false, err
+		}
 	}
 
-	query := model.DB().Create(&attachment)
+	query := tx.Create(&attachment)
 	if query.Error != nil {
-		return nil, query.Error
+		tx.Rollback()
+		return nil, false, query.Error
 	}
 
-	return &attachment, nil
+	query = tx.Commit()
+	if query.Error != nil {
+		return nil, false, query.Error
+	}
+
+	return &attachment, newBlob, nil
 }
 
 func (model AttachmentsModel) ReadAttachment(id uint32) (*Attachment, error) {
@@ -44,16 +124,60 @@ func (model AttachmentsModel) ReadAttachment(id uint32) (*Attachment, error) {
 	return &attachment, nil
 }
 
-func (model AttachmentsModel) DeleteAttachment(id uint32) (int64, error) {
-	query := model.DB().
+// Deletes the attachment and drops its reference to the blob of its content, the blob goes with the last reference.
+// removeFile (when set) is called with the name of the stored file once nothing uses it. The blob stays locked
+// until the file is removed, so an upload of the same content waits and then stores the file again.
+// Nothing is deleted when the file can't be removed.
+func (model AttachmentsModel) DeleteAttachment(id uint32, removeFile func(name string) error) (int64, error) {
+	var attachment Attachment
+
+	tx := model.DB().Begin()
+
+	query := tx.First(&attachment, "id = ?", id)
+	if query.Error == gorm.ErrRecordNotFound {
+		tx.Rollback()
+		return 0, nil
+	} else if query.Error != nil {
+		tx.Rollback()
+		return 0, query.Error
+	}
+
+	query = tx.
 		Table("attachments").
 		Where("id = ?", id).
 		Delete(Attachment{})
+	if query.Error != nil {
+		tx.Rollback()
+		return 0, query.Error
+	}
+	count := query.RowsAffected
+
+	// Files stored before the blobs belong to a single attachment
+	unused := attachment.Hash == nil
+	if attachment.Hash != nil && count > 0 {
+		removed, err := releaseBlob(tx, *attachment.Hash)
+		if err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+
+		unused = removed
+	}
+
+	if unused && count > 0 && removeFile != nil {
+		err := removeFile(attachment.StorageName())
+		if err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+	}
+
+	query = tx.Commit()
 	if query.Error != nil {
 		return 0, query.Error
 	}
 
-	return query.RowsAffected, nil
+	return count, nil
 }
 
 func (model AttachmentsModel) FindAttachment(name string) (*Attachment, error) {
@@ -72,3 +196,223 @@ func (model AttachmentsModel) FindAttachment(name string) (*Attachment, error) {
 
 	return &attachment, nil
 }
+
+// Every attachment, used to move the stored files between storages
+func (model AttachmentsModel) FindAttachments() ([]Attachment, error) {
+	attachments := []Attachment{}
+
+	query := model.DB().Order("id").Find(&attachments)
+	if query.Error != nil {
+		return nil, query.Error
+	}
+
+	return attachments, nil
+}
+
+// Everything the attachment is linked to, it has no owners until it is added to something
+func (model AttachmentsModel) FindAttachmentOwners(attachmentId uint32) ([]AttachmentOwner, error) {
+	owners := []AttachmentOwner{}
+
+	for _, ownerQuery := range attachmentOwnerQueries {
+		rows, err := model.DB().Raw(ownerQuery.Query, attachmentId).Rows()
+		if err != nil {
+			return nil, err
+		}
+
+		for rows.Next() {
+			owner := AttachmentOwner{ Type: ownerQuery.Type }
+
+			err = rows.Scan(&owner.ID, &owner.ModuleCode, &owner.ModuleID, &owner.UserID, &owner.TeamID, &owner.AssignmentID)
+			if err != nil {
+				rows.Close()
+				return nil, err
+			}
+
+			owners = append(owners, owner)
+		}
+		rows.Close()
+	}
+
+	return owners, nil
+}
+
+// The space taken by the files the user uploaded
+func (model AttachmentsModel) UserUsage(userId uint32) (int64, error) {
+	return model.usage("user_id = ?", userId)
+}
+
+// The space taken by the files uploaded to the module
+func (model AttachmentsModel) ModuleUsage(moduleCode string) (int64, error) {
+	return model.usage("module_code = ?", moduleCode)
+}
+
+func (model AttachmentsModel) usage(filter string, value interface{}) (int64, error) {
+	var size int64
+
+	row := model.DB().Table("attachments").Select("coalesce(sum(size), 0)").Where(filter, value).Row()
+	err := row.Scan(&size)
+	if err != nil {
+		return 0, err
+	}
+
+	return size, nil
+}
+
+// The name the file of the attachment is kept under in the storage
+func (attachment Attachment) StorageName() string {
+	if attachment.Hash != nil {
+		return *attachment.Hash
+	}
+
+	return attachment.Url
+}
+
+func (model AttachmentsModel) ReadBlob(hash string) (*Blob, error) {
+	var blob Blob
+
+	query := model.DB().First(&blob, "hash = ?", hash)
+	if query.Error != nil {
+		// If no Records found, return NIL otherwise return the error
+		switch query.Error {
+		case gorm.ErrRecordNotFound:
+			return nil, nil
+		default:
+			return nil, query.Error
+		}
+	}
+
+	return &blob, nil
+}
+
+func (model AttachmentsModel) FindBlobs() ([]Blob, error) {
+	blobs := []Blob{}
+
+	query := model.DB().Order("hash").Find(&blobs)
+	if query.Error != nil {
+		return nil, query.Error
+	}
+
+	return blobs, nil
+}
+
+// Counts the attachments using each blob, to check the references kept on the blobs
+func (model AttachmentsModel) CountBlobReferences() (map[string]uint32, error) {
+	rows, err := model.DB().Table("attachments").Select("hash, count(*)").Where("hash is not null").Group("hash").Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	references := map[string]uint32{}
+	for rows.Next() {
+		var hash string
+		var count uint32
+		err = rows.Scan(&hash, &count)
+		if err != nil {
+			return nil, err
+		}
+
+		references[hash] = count
+	}
+
+	return references, rows.Err()
+}
+
+// Moves an attachment uploaded before the blobs to the blob of its content.
+// newBlob is false when the content was already stored for another attachment, so the old file can just be removed.
+func (model AttachmentsModel) SetAttachmentBlob(id uint32, hash string, size int64) (bool, error) {
+	tx := model.DB().Begin()
+
+	newBlob, err := acquireBlob(tx, hash, size)
+	if err != nil {
+		tx.Rollback()
+		return false, err
+	}
+
+	query := tx.Table("attachments").Where("id = ? and hash is null", id).Updates(map[string]interface{}{
+		"hash": hash,
+		"size": size,
+	})
+	if query.Error != nil {
+		tx.Rollback()
+		return false, query.Error
+	}
+
+	// The attachment is missing or was moved already
+	if query.RowsAffected <= 0 {
+		tx.Rollback()
+		return false, gorm.ErrRecordNotFound
+	}
+
+	query = tx.Commit()
+	if query.Error != nil {
+		return false, query.Error
+	}
+
+	return newBlob, nil
+}
+
+// Adds a reference to the blob, creating it for the first one. Returns true when the blob is new.
+func acquireBlob(tx *gorm.DB, hash string, size int64) (bool, error) {
+	query := tx.Model(&Blob{}).Where("hash = ?", hash).UpdateColumn("reference_count", gorm.Expr("reference_count + 1"))
+	if query.Error != nil {
+		return false, query.Error
+	}
+
+	if query.RowsAffected > 0 {
+		return false, nil
+	}
+
+	// Postgres aborts the whole transaction on a failed insert, the savepoint keeps it usable
+	query = tx.Exec("SAVEPOINT acquire_blob")
+	if query.Error != nil {
+		return false, query.Error
+	}
+
+	query = tx.Create(&Blob{ Hash: hash, Size: size, ReferenceCount: 1 })
+	if query.Error != nil {
+		if !database.IsDuplicatedError(query.Error) {
+			return false, query.Error
+		}
+
+		// The same content was uploaded at the same time, so the blob exists now
+		query = tx.Exec("ROLLBACK TO SAVEPOINT acquire_blob")
+		if query.Error != nil {
+			return false, query.Error
+		}
+
+		query = tx.Model(&Blob{}).Where("hash = ?", hash).UpdateColumn("reference_count", gorm.Expr("reference_count + 1"))
+		if query.Error != nil {
+			return false, query.Error
+		}
+
+		if query.RowsAffected <= 0 {
+			return false, gorm.ErrRecordNotFound
+		}
+
+		return false, nil
+	}
+
+	query = tx.Exec("RELEASE SAVEPOINT acquire_blob")
+	if query.Error != nil {
+		return false, query.Error
+	}
+
+	return true, nil
+}
+
+// Drops a reference to the blob, which is removed along with the last one. Returns true when the blob was removed.
+// The update locks the row of the blob until the transaction ends, so acquireBlob waits for it.
+func releaseBlob(tx *gorm.DB, hash string) (bool, error) {
+	query := tx.Model(&Blob{}).Where("hash = ? and reference_count > 0", hash).UpdateColumn("reference_count", gorm.Expr("reference_count - 1"))
+	if query.Error != nil {
+		return false, query.Error
+	}
+
+	query = tx.Where("hash = ? and reference_count <= 0", hash).Delete(Blob{})
+	if query.Error != nil {
+		return false, query.Error
+	}
+
+	return query.RowsAffected > 0, nil
+}
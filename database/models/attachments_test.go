@@ -1,6 +1,7 @@
 package models
 
 import (
+	"errors"
 	"testing"
 
 	. "github.com/franela/goblin"
@@ -34,6 +35,20 @@ func Test_Database_Attachments(t *testing.T) {
 			g.Assert(attachment != nil).IsTrue()
 		})
 
+		g.It("Should list the attachment with all the others", func() {
+			attachments, err := DBAttachment.FindAttachments()
+
+			g.Assert(err == nil).IsTrue()
+
+			found := false
+			for _, attachment := range attachments {
+				if attachment.ID == attachmentId {
+					found = attachment.Url == "49860456-4ea5-42ff-8aac-476148e6f422"
+				}
+			}
+			g.Assert(found).IsTrue()
+		})
+
 		g.It("Should be able to get an attachment with it's name", func() {
 			attachment, err := DBAttachment.FindAttachment("49860456-4ea5-42ff-8aac-476148e6f422")
 
@@ -49,14 +64,14 @@ func Test_Database_Attachments(t *testing.T) {
 		})
 
 		g.It("Should be able to delete an attachment", func() {
-			count, err := DBAttachment.DeleteAttachment(attachmentId)
+			count, err := DBAttachment.DeleteAttachment(attachmentId, nil)
 
 			g.Assert(err == nil).IsTrue()
 			g.Assert(count == 1).IsTrue()
 		})
 
 		g.It("Should not delete a missing attachment", func() {
-			count, err := DBAttachment.DeleteAttachment(attachmentId)
+			count, err := DBAttachment.DeleteAttachment(attachmentId, nil)
 
 			g.Assert(err == nil).IsTrue()
 			g.Assert(count == 0).IsTrue()
@@ -69,4 +84,161 @@ func Test_Database_Attachments(t *testing.T) {
 			g.Assert(module == nil).IsTrue()
 		})
 	})
+
+	g.Describe("When counting the storage used", func() {
+		g.It("Should add the size of the uploads of a user and of a module", func() {
+			userId := uint32(4)
+			moduleCode := "AC22001"
+			usedByUser, _ := DBAttachment.UserUsage(userId)
+			usedByModule, _ := DBAttachment.ModuleUsage(moduleCode)
+
+			first, _, err := DBAttachment.CreateUpload(Attachment{ Name: "notes.pdf", Type: "application/pdf", Url: "f0a4b1de-5d8e-4b51-9b44-6a2c4d1e0001", Size: 1000, UserID: &userId, ModuleCode: &moduleCode })
+			g.Assert(err == nil).IsTrue()
+			g.Assert(first.Size).Equal(int64(1000))
+
+			second, _, err := DBAttachment.CreateUpload(Attachment{ Name: "avatar.png", Type: "image/png", Url: "f0a4b1de-5d8e-4b51-9b44-6a2c4d1e0002", Size: 250, UserID: &userId })
+			g.Assert(err == nil).IsTrue()
+
+			used, err := DBAttachment.UserUsage(userId)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(used).Equal(usedByUser + 1250)
+
+			used, err = DBAttachment.ModuleUsage(moduleCode)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(used).Equal(usedByModule + 1000)
+
+			DBAttachment.DeleteAttachment(first.ID, nil)
+			DBAttachment.DeleteAttachment(second.ID, nil)
+
+			used, _ = DBAttachment.UserUsage(userId)
+			g.Assert(used).Equal(usedByUser)
+		})
+
+		g.It("Should not use anything when there are no uploads", func() {
+			used, err := DBAttachment.ModuleUsage("-null-")
+
+			g.Assert(err == nil).IsTrue()
+			g.Assert(used).Equal(int64(0))
+		})
+	})
+
+	g.Describe("When sharing the stored content between attachments", func() {
+		hash := "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"
+		var firstId, secondId uint32
+
+		g.It("Should create the blob with the first attachment", func() {
+			attachment, newBlob, err := DBAttachment.CreateUpload(Attachment{ Name: "slides.pdf", Type: "application/pdf", Url: "f0a4b1de-5d8e-4b51-9b44-6a2c4d1e0003", Size: 4, Hash: &hash })
+
+			g.Assert(err == nil).IsTrue()
+			g.Assert(newBlob).IsTrue()
+			g.Assert(attachment.StorageName()).Equal(hash)
+			firstId = attachment.ID
+
+			blob, _ := DBAttachment.ReadBlob(hash)
+			g.Assert(blob.ReferenceCount).Equal(uint32(1))
+			g.Assert(blob.Size).Equal(int64(4))
+		})
+
+		g.It("Should reuse the blob for the same content", func() {
+			attachment, newBlob, err := DBAttachment.CreateUpload(Attachment{ Name: "slides-2017.pdf", Type: "application/pdf", Url: "f0a4b1de-5d8e-4b51-9b44-6a2c4d1e0004", Size: 4, Hash: &hash })
+
+			g.Assert(err == nil).IsTrue()
+			g.Assert(newBlob).IsFalse()
+			secondId = attachment.ID
+
+			blob, _ := DBAttachment.ReadBlob(hash)
+			g.Assert(blob.ReferenceCount).Equal(uint32(2))
+
+			references, err := DBAttachment.CountBlobReferences()
+			g.Assert(err == nil).IsTrue()
+			g.Assert(references[hash]).Equal(uint32(2))
+		})
+
+		g.It("Should keep the blob until the last attachment is deleted", func() {
+			removed := []string{}
+			removeFile := func(name string) error {
+				removed = append(removed, name)
+				return nil
+			}
+
+			count, err := DBAttachment.DeleteAttachment(firstId, removeFile)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(count).Equal(int64(1))
+			g.Assert(len(removed)).Equal(0)
+
+			blob, _ := DBAttachment.ReadBlob(hash)
+			g.Assert(blob.ReferenceCount).Equal(uint32(1))
+
+			// The attachment stays when its file can't be removed
+			_, err = DBAttachment.DeleteAttachment(secondId, func(name string) error { return errors.New("unavailable") })
+			g.Assert(err != nil).IsTrue()
+
+			blob, _ = DBAttachment.ReadBlob(hash)
+			g.Assert(blob.ReferenceCount).Equal(uint32(1))
+
+			DBAttachment.DeleteAttachment(secondId, removeFile)
+			g.Assert(removed).Equal([]string{ hash })
+
+			blob, err = DBAttachment.ReadBlob(hash)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(blob == nil).IsTrue()
+		})
+
+		g.It("Should store the files uploaded before by their content", func() {
+			first, _ := DBAttachment.CreateAttachment("notes.pdf", "application/pdf", "f0a4b1de-5d8e-4b51-9b44-6a2c4d1e0005")
+			second, _ := DBAttachment.CreateAttachment("notes-copy.pdf", "application/pdf", "f0a4b1de-5d8e-4b51-9b44-6a2c4d1e0006")
+			g.Assert(first.StorageName()).Equal("f0a4b1de-5d8e-4b51-9b44-6a2c4d1e0005")
+
+			newBlob, err := DBAttachment.SetAttachmentBlob(first.ID, hash, 4)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(newBlob).IsTrue()
+
+			newBlob, err = DBAttachment.SetAttachmentBlob(second.ID, hash, 4)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(newBlob).IsFalse()
+
+			attachment, _ := DBAttachment.ReadAttachment(first.ID)
+			g.Assert(attachment.StorageName()).Equal(hash)
+			g.Assert(attachment.Size).Equal(int64(4))
+
+			// An attachment is only moved once
+			_, err = DBAttachment.SetAttachmentBlob(first.ID, hash, 4)
+			g.Assert(err != nil).IsTrue()
+
+			blob, _ := DBAttachment.ReadBlob(hash)
+			g.Assert(blob.ReferenceCount).Equal(uint32(2))
+
+			DBAttachment.DeleteAttachment(first.ID, nil)
+			DBAttachment.DeleteAttachment(second.ID, nil)
+		})
+	})
+
+	g.Describe("When the attachment is part of a submission", func() {
+		var attachment *Attachment
+		var submission *Submission
+
+		g.Before(func() {
+			attachment, _ = DBAttachment.CreateAttachment("cluster.zip", "application/zip", "f0a4b1de-5d8e-4b51-9b44-6a2c4d1e0007")
+			submission, _ = DBAssignments.SubmitAssignment(3, 1, attachment.ID, "My cluster")
+		})
+
+		g.After(func() {
+			DBAssignments.DB().Delete(submission)
+			DBAttachment.DeleteAttachment(attachment.ID, nil)
+		})
+
+		g.It("Should not delete the attachment along with the submission", func() {
+			_, err := DBAttachment.DeleteAttachment(attachment.ID, nil)
+			g.Assert(err != nil).IsTrue()
+
+			var kept Submission
+			query := DBAssignments.DB().First(&kept, "id = ?", submission.ID)
+			g.Assert(query.Error == nil).IsTrue()
+		})
+
+		g.It("Should not delete a student that has submissions", func() {
+			query := DBUser.DB().Where("id = ?", 3).Delete(User{})
+			g.Assert(query.Error != nil).IsTrue()
+		})
+	})
 }
@@ -21,6 +21,10 @@ var (
 func init() {
 	tools.LoadSettings(SETTINGS_PATH)
 	_, db = database.InitDatabase()
+
+	// Brings a fresh database to the current schema and loads the fixtures
+	database.Migrate()
+	Seed(false)
 }
 
 func Test_Database_Courses(t *testing.T) {
@@ -0,0 +1,182 @@
+package models
+
+import (
+	"github.com/jinzhu/gorm"
+	"github.com/YagoCarballo/kumquat-academy-api/database"
+)
+
+type ExamsModel struct{}
+var DBExams ExamsModel
+
+func (model ExamsModel) DB() *gorm.DB {
+	return database.DB
+}
+
+func (model ExamsModel) CreateExam(exam Exam) (*Exam, error) {
+	query := model.DB().Create(&exam)
+	if query.Error != nil {
+		return nil, query.Error
+	}
+
+	return &exam, nil
+}
+
+func (model ExamsModel) ReadExam(moduleCode string, id uint32) (*Exam, error) {
+	var exam Exam
+
+	query := model.DB().Preload("Attachment").First(&exam, "id = ? and module_code = ?", id, moduleCode)
+	if query.Error != nil {
+		// If no Records found, return NIL otherwise return the error
+		switch query.Error {
+		case gorm.ErrRecordNotFound:
+			return nil, nil
+		default:
+			return nil, query.Error
+		}
+	}
+
+	return &exam, nil
+}
+
+func (model ExamsModel) UpdateExam(moduleCode string, id uint32, exam Exam) (*Exam, error) {
+	// MySQL reports no affected rows when nothing changed, so the exam is looked up first
+	dbExam, err := model.ReadExam(moduleCode, id)
+	if err != nil || dbExam == nil {
+		return nil, err
+	}
+
+	query := model.DB().Table("exams").Where("id = ? and module_code = ?", id, moduleCode).Updates(map[string]interface{}{
+		"topic": exam.Topic,
+		"location": exam.Location,
+		"weight": exam.Weight,
+		"date": exam.Date,
+	})
+	if query.Error != nil {
+		return nil, query.Error
+	}
+
+	return model.ReadExam(moduleCode, id)
+}
+
+func (model ExamsModel) DeleteExam(moduleCode string, id uint32) (int64, error) {
+	query := model.DB().
+		Table("exams").
+		Where("id = ? and module_code = ?", id, moduleCode).
+		Delete(Exam{})
+	if query.Error != nil {
+		return 0, query.Error
+	}
+
+	return query.RowsAffected, nil
+}
+
+func (model ExamsModel) FindExamsForModule(moduleCode string) ([]Exam, error) {
+	exams := []Exam{}
+
+	query := model.DB().Preload("Attachment").Order("date").Find(&exams, "module_code = ?", moduleCode)
+	if query.Error != nil {
+		return nil, query.Error
+	}
+
+	return exams, nil
+}
+
+// Links the exam paper to an exam (replacing the previous one)
+func (model ExamsModel) SetExamAttachment(moduleCode string, examId, attachmentId uint32) (int64, error) {
+	query := model.DB().Table("exams").
+				Where("id = ? and module_code = ?", examId, moduleCode).
+				Update("attachment_id", attachmentId)
+	if query.Error != nil {
+		return 0, query.Error
+	}
+
+	return query.RowsAffected, nil
+}
+
+func (model ExamsModel) RemoveExamAttachment(moduleCode string, examId, attachmentId uint32) (int64, error) {
+	query := model.DB().Table("exams").
+				Where("id = ? and module_code = ? and attachment_id = ?", examId, moduleCode, attachmentId).
+				Update("attachment_id", 0)
+	if query.Error != nil {
+		return 0, query.Error
+	}
+
+	return query.RowsAffected, nil
+}
+
+// Creates or replaces the result of a student in an exam
+func (model ExamsModel) SetStudentResult(examId, userId uint32, grade float64, status ExamStatus) (*StudentExam, error) {
+	studentExam, err := model.FindStudentResult(examId, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	// First result for this student
+	if studentExam == nil {
+		studentExam = &StudentExam{
+			ExamID: examId,
+			UserID: userId,
+			Grade: grade,
+			Status: status,
+		}
+
+		query := model.DB().Create(studentExam)
+		if query.Error != nil {
+			return nil, query.Error
+		}
+
+		return studentExam, nil
+	}
+
+	query := model.DB().Table("student_exams").Where("exam_id = ? and user_id = ?", examId, userId).Updates(map[string]interface{}{
+		"grade": grade,
+		"status": status,
+	})
+	if query.Error != nil {
+		return nil, query.Error
+	}
+
+	studentExam.Grade = grade
+	studentExam.Status = status
+	return studentExam, nil
+}
+
+func (model ExamsModel) FindStudentResult(examId, userId uint32) (*StudentExam, error) {
+	var studentExam StudentExam
+
+	query := model.DB().Preload("User").First(&studentExam, "exam_id = ? and user_id = ?", examId, userId)
+	if query.Error != nil {
+		// If no Records found, return NIL otherwise return the error
+		switch query.Error {
+		case gorm.ErrRecordNotFound:
+			return nil, nil
+		default:
+			return nil, query.Error
+		}
+	}
+
+	return &studentExam, nil
+}
+
+func (model ExamsModel) FindResultsForExam(examId uint32) ([]StudentExam, error) {
+	results := []StudentExam{}
+
+	query := model.DB().Preload("User").Find(&results, "exam_id = ?", examId)
+	if query.Error != nil {
+		return nil, query.Error
+	}
+
+	return results, nil
+}
+
+func (model ExamsModel) RemoveStudentResult(examId, userId uint32) (int64, error) {
+	query := model.DB().
+		Table("student_exams").
+		Where("exam_id = ? and user_id = ?", examId, userId).
+		Delete(StudentExam{})
+	if query.Error != nil {
+		return 0, query.Error
+	}
+
+	return query.RowsAffected, nil
+}
@@ -0,0 +1,126 @@
+package models
+
+import (
+	"time"
+	"testing"
+
+	. "github.com/franela/goblin"
+)
+
+func Test_Database_Exams(t *testing.T) {
+	g := Goblin(t)
+	var examId uint32
+
+	g.Describe("When managing the exams of a module", func() {
+		g.It("Should get nothing when reading a missing exam", func() {
+			exam, err := DBExams.ReadExam("AC31007", 77)
+
+			g.Assert(err == nil).IsTrue()
+			g.Assert(exam == nil).IsTrue()
+		})
+
+		g.It("Should be able to create an exam", func() {
+			exam, err := DBExams.CreateExam(Exam{
+				Topic: "Final Exam",
+				Location: "Main Hall",
+				Weight: 0.5,
+				Date: time.Now().AddDate(0, 2, 0),
+				ModuleCode: "AC31007",
+			})
+
+			g.Assert(err == nil).IsTrue()
+			g.Assert(exam != nil).IsTrue()
+			g.Assert(exam.ID != 0).IsTrue()
+
+			examId = exam.ID
+		})
+
+		g.It("Should not find the exam from another module", func() {
+			exam, err := DBExams.ReadExam("AC22001", examId)
+
+			g.Assert(err == nil).IsTrue()
+			g.Assert(exam == nil).IsTrue()
+		})
+
+		g.It("Should be able to update an exam", func() {
+			exam, err := DBExams.UpdateExam("AC31007", examId, Exam{
+				Topic: "Final Exam (Resit)",
+				Location: "Main Hall",
+				Weight: 0.5,
+				Date: time.Now().AddDate(0, 3, 0),
+			})
+
+			g.Assert(err == nil).IsTrue()
+			g.Assert(exam != nil).IsTrue()
+			g.Assert(exam.Topic).Equal("Final Exam (Resit)")
+		})
+
+		g.It("Should not update a missing exam", func() {
+			exam, err := DBExams.UpdateExam("AC31007", 77, Exam{ Topic: "Missing" })
+
+			g.Assert(err == nil).IsTrue()
+			g.Assert(exam == nil).IsTrue()
+		})
+
+		g.It("Should list the exams of a module", func() {
+			exams, err := DBExams.FindExamsForModule("AC31007")
+
+			g.Assert(err == nil).IsTrue()
+			g.Assert(len(exams) > 0).IsTrue()
+		})
+
+		g.It("Should be able to attach and remove the exam paper", func() {
+			attachment, err := DBAttachment.CreateAttachment("paper.pdf", "application/pdf", "-exam-paper-")
+			g.Assert(err == nil).IsTrue()
+
+			count, err := DBExams.SetExamAttachment("AC31007", examId, attachment.ID)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(count == 1).IsTrue()
+
+			exam, _ := DBExams.ReadExam("AC31007", examId)
+			g.Assert(exam.Attachment != nil).IsTrue()
+			g.Assert(exam.Attachment.ID).Equal(attachment.ID)
+
+			count, err = DBExams.RemoveExamAttachment("AC31007", examId, attachment.ID)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(count == 1).IsTrue()
+		})
+
+		g.It("Should be able to set and replace the result of a student", func() {
+			result, err := DBExams.SetStudentResult(examId, 3, 55, ExamReview)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(result.Status).Equal(ExamReview)
+
+			result, err = DBExams.SetStudentResult(examId, 3, 72, ExamGraded)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(result.Grade).Equal(float64(72))
+
+			result, err = DBExams.FindStudentResult(examId, 3)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(result.Grade).Equal(float64(72))
+			g.Assert(result.Status).Equal(ExamGraded)
+		})
+
+		g.It("Should list the results of an exam", func() {
+			results, err := DBExams.FindResultsForExam(examId)
+
+			g.Assert(err == nil).IsTrue()
+			g.Assert(len(results)).Equal(1)
+			g.Assert(results[0].User != nil).IsTrue()
+		})
+
+		g.It("Should be able to remove the result of a student", func() {
+			count, err := DBExams.RemoveStudentResult(examId, 3)
+
+			g.Assert(err == nil).IsTrue()
+			g.Assert(count == 1).IsTrue()
+		})
+
+		g.It("Should be able to delete an exam", func() {
+			count, err := DBExams.DeleteExam("AC31007", examId)
+
+			g.Assert(err == nil).IsTrue()
+			g.Assert(count == 1).IsTrue()
+		})
+	})
+}
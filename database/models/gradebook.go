@@ -0,0 +1,331 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/YagoCarballo/kumquat-academy-api/database"
+)
+
+var ErrTeamGradeConflict = errors.New("The members of a team can't get different grades.")
+
+const (
+	GradeAssignment	= "assignment"
+	GradeExam		= "exam"
+)
+
+type (
+	// An assignment or exam that counts towards the module mark
+	GradebookComponent struct {
+		Type		string	`json:"type"`
+		ID			uint32	`json:"id"`
+		Title		string	`json:"title"`
+		Weight		float64	`json:"weight"`
+		Statistics	GradeStatistics `json:"statistics"`
+	}
+
+	GradebookGrade struct {
+		Type	string	`json:"type"`
+		ID		uint32	`json:"id"`
+		Grade	*float64 `json:"grade"`
+	}
+
+	// The marks of a student, the mark is the weighted average of the graded components
+	// and it only becomes final once every component has been graded
+	StudentGrades struct {
+		User			User	`json:"user"`
+		Grades			[]GradebookGrade `json:"grades"`
+		GradedWeight	float64	`json:"graded_weight"`
+		Mark			*float64 `json:"mark"`
+		FinalMark		*float64 `json:"final_mark"`
+		Provisional		bool	`json:"provisional"`
+	}
+
+	GradeStatistics struct {
+		Count			int		`json:"count"`
+		Mean			float64	`json:"mean"`
+		Median			float64	`json:"median"`
+		Min				float64	`json:"min"`
+		Max				float64	`json:"max"`
+		Distribution	map[string]int `json:"distribution"`
+	}
+
+	// A student of the module with their latest submission of an assignment, if any
+	RosterEntry struct {
+		User		User	`json:"user"`
+		Submission	*Submission `json:"submission"`
+	}
+
+	// Weights are fractions of the module mark, so they should add up to 1 (100%)
+	Gradebook struct {
+		ModuleCode		string	`json:"module_code"`
+		TotalWeight		float64	`json:"total_weight"`
+		WeightsComplete	bool	`json:"weights_complete"`
+		Components		[]GradebookComponent `json:"components"`
+		Students		[]StudentGrades `json:"students"`
+		Statistics		GradeStatistics `json:"statistics"`
+	}
+)
+
+type GradebookModel struct{}
+var DBGradebook GradebookModel
+
+func (model GradebookModel) DB() *gorm.DB {
+	return database.DB
+}
+
+// Combines the graded submissions and exam results of the students of a module by weight.
+// When releasedOnly is set, assignments count as ungraded until their grades are released.
+func (model GradebookModel) FindGradebook(moduleCode string, releasedOnly bool) (*Gradebook, error) {
+	gradebook := Gradebook{
+		ModuleCode: moduleCode,
+		Components: []GradebookComponent{},
+		Students: []StudentGrades{},
+	}
+
+	var assignments []Assignment
+	query := model.DB().Order("start, id").Find(&assignments, "module_code = ?", moduleCode)
+	if query.Error != nil {
+		return nil, query.Error
+	}
+
+	exams, err := DBExams.FindExamsForModule(moduleCode)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, assignment := range assignments {
+		gradebook.Components = append(gradebook.Components, GradebookComponent{ Type: GradeAssignment, ID: assignment.ID, Title: assignment.Title, Weight: assignment.Weight })
+	}
+
+	for _, exam := range exams {
+		gradebook.Components = append(gradebook.Components, GradebookComponent{ Type: GradeExam, ID: exam.ID, Title: exam.Topic, Weight: exam.Weight })
+	}
+
+	for _, component := range gradebook.Components {
+		gradebook.TotalWeight += component.Weight
+	}
+	gradebook.WeightsComplete = math.Abs(gradebook.TotalWeight - 1) < 0.0001
+
+	grades, err := model.findGrades(moduleCode, releasedOnly)
+	if err != nil {
+		return nil, err
+	}
+
+	students, err := DBModule.FindStudentsForModule(moduleCode, "Student")
+	if err != nil {
+		return nil, err
+	}
+
+	marks := []float64{}
+	componentGrades := make([][]float64, len(gradebook.Components))
+	for _, student := range students {
+		studentGrades := StudentGrades{ User: student, Grades: []GradebookGrade{}, Provisional: true }
+
+		var weighted float64
+		for index, component := range gradebook.Components {
+			grade := GradebookGrade{ Type: component.Type, ID: component.ID }
+
+			if value, ok := grades[gradeKey(component.Type, component.ID, student.ID)]; ok {
+				grade.Grade = &value
+				weighted += value * component.Weight
+				studentGrades.GradedWeight += component.Weight
+				componentGrades[index] = append(componentGrades[index], value)
+			}
+
+			studentGrades.Grades = append(studentGrades.Grades, grade)
+		}
+
+		if studentGrades.GradedWeight > 0 {
+			mark := weighted / studentGrades.GradedWeight
+			studentGrades.Mark = &mark
+			marks = append(marks, mark)
+
+			// Once everything is graded and the weights are complete the mark is final
+			if gradebook.WeightsComplete && math.Abs(studentGrades.GradedWeight - gradebook.TotalWeight) < 0.0001 {
+				studentGrades.FinalMark = &mark
+				studentGrades.Provisional = false
+			}
+		}
+
+		gradebook.Students = append(gradebook.Students, studentGrades)
+	}
+
+	for index := range gradebook.Components {
+		gradebook.Components[index].Statistics = NewGradeStatistics(componentGrades[index])
+	}
+	gradebook.Statistics = NewGradeStatistics(marks)
+
+	return &gradebook, nil
+}
+
+// Gets the graded submissions and exam results of a module, indexed by component and student
+func (model GradebookModel) findGrades(moduleCode string, releasedOnly bool) (map[string]float64, error) {
+	grades := map[string]float64{}
+
+	var submissions []Submission
+	query := model.DB().Table("submissions").Select("submissions.*").Joins(
+		"inner join assignments on assignments.id = submissions.assignment_id",
+	).Where("assignments.module_code = ? and submissions.graded_on is not null", moduleCode)
+	if releasedOnly {
+		query = query.Where("assignments.status = ?", AssignmentReturned)
+	}
+
+	query = query.Order("submissions.graded_on, submissions.id").Find(&submissions)
+	if query.Error != nil {
+		return nil, query.Error
+	}
+
+	// The latest graded submission of a student is the one that counts
+	for _, submission := range submissions {
+		grades[gradeKey(GradeAssignment, submission.AssignmentID, submission.UserID)] = submission.Grade
+	}
+
+	var results []StudentExam
+	query = model.DB().Table("student_exams").Select("student_exams.*").Joins(
+		"inner join exams on exams.id = student_exams.exam_id",
+	).Where("exams.module_code = ? and student_exams.status = ?", moduleCode, ExamGraded).Find(&results)
+	if query.Error != nil {
+		return nil, query.Error
+	}
+
+	for _, result := range results {
+		grades[gradeKey(GradeExam, result.ExamID, result.UserID)] = result.Grade
+	}
+
+	return grades, nil
+}
+
+func (model GradebookModel) FindAssignmentRoster(moduleCode string, assignmentId uint32) ([]RosterEntry, error) {
+	roster := []RosterEntry{}
+
+	students, err := DBModule.FindStudentsForModule(moduleCode, "Student")
+	if err != nil {
+		return nil, err
+	}
+
+	var submissions []Submission
+	query := model.DB().Order("submitted_on, id").Find(&submissions, "assignment_id = ? and status != ?", assignmentId, SubmissionCanceled)
+	if query.Error != nil {
+		return nil, query.Error
+	}
+
+	latest := map[uint32]Submission{}
+	for _, submission := range submissions {
+		latest[submission.UserID] = submission
+	}
+
+	for _, student := range students {
+		entry := RosterEntry{ User: student }
+		if submission, ok := latest[student.ID]; ok {
+			entry.Submission = &submission
+		}
+
+		roster = append(roster, entry)
+	}
+
+	return roster, nil
+}
+
+// Grades several submissions of an assignment in a single transaction, keyed by submission id.
+// As in GradeAssignment, the grade of a team submission is given to all the members and late penalties are applied.
+// Each team is graded once, so the submissions of a team can't be given different grades.
+func (model GradebookModel) GradeSubmissions(assignmentId uint32, grades map[uint32]float64) (int64, error) {
+	gradedOn := time.Now()
+	teamGrades := map[uint32]float64{}
+	var count int64
+
+	tx := model.DB().Begin()
+
+	for submissionId, grade := range grades {
+		var submission Submission
+		query := tx.Where("id = ? and assignment_id = ?", submissionId, assignmentId).First(&submission)
+		if query.Error != nil {
+			tx.Rollback()
+			return 0, query.Error
+		}
+
+		if submission.TeamID != nil {
+			teamGrade, graded := teamGrades[*submission.TeamID]
+			if graded && teamGrade != grade {
+				tx.Rollback()
+				return 0, ErrTeamGradeConflict
+			}
+
+			if graded {
+				continue
+			}
+
+			teamGrades[*submission.TeamID] = grade
+		}
+
+		err := DBAssignments.gradeSubmission(tx, submission, grade, gradedOn)
+		if err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+
+		count++
+	}
+
+	query := tx.Commit()
+	if query.Error != nil {
+		return 0, query.Error
+	}
+
+	return count, nil
+}
+
+func gradeKey(componentType string, componentId, userId uint32) string {
+	return fmt.Sprintf("%s-%d-%d", componentType, componentId, userId)
+}
+
+// Calculates the statistics of a list of grades out of 100, the distribution groups the grades in bands of 10
+func NewGradeStatistics(grades []float64) GradeStatistics {
+	statistics := GradeStatistics{ Count: len(grades), Distribution: map[string]int{} }
+	for band := 0; band < 100; band += 10 {
+		statistics.Distribution[gradeBand(float64(band))] = 0
+	}
+
+	if len(grades) <= 0 {
+		return statistics
+	}
+
+	sorted := append([]float64{}, grades...)
+	sort.Float64s(sorted)
+
+	var sum float64
+	for _, grade := range sorted {
+		sum += grade
+		statistics.Distribution[gradeBand(grade)]++
+	}
+
+	middle := len(sorted) / 2
+	statistics.Median = sorted[middle]
+	if len(sorted) % 2 == 0 {
+		statistics.Median = (sorted[middle - 1] + sorted[middle]) / 2
+	}
+
+	statistics.Mean = sum / float64(len(sorted))
+	statistics.Min = sorted[0]
+	statistics.Max = sorted[len(sorted) - 1]
+
+	return statistics
+}
+
+func gradeBand(grade float64) string {
+	band := int(math.Floor(grade / 10)) * 10
+	if band >= 90 {
+		return "90-100"
+	}
+
+	if band < 0 {
+		band = 0
+	}
+
+	return fmt.Sprintf("%d-%d", band, band + 9)
+}
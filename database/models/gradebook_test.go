@@ -0,0 +1,111 @@
+package models
+
+import (
+	"math"
+	"time"
+	"testing"
+
+	. "github.com/franela/goblin"
+)
+
+func Test_Database_Gradebook(t *testing.T) {
+	g := Goblin(t)
+	var assignmentId, examId uint32
+
+	g.Describe("When calculating the marks of a module", func() {
+		g.Before(func() {
+			// AC51001 starts without students, assignments or exams
+			DBModule.DB().Create(&UserModule{ UserID: 3, ModuleCode: "AC51001", RoleID: 3, ClassID: 2 })
+			DBModule.DB().Create(&UserModule{ UserID: 5, ModuleCode: "AC51001", RoleID: 3, ClassID: 2 })
+
+			assignment, _ := DBAssignments.CreateAssignment(Assignment{ Title: "Essay", Description: "Write an essay.", Weight: 0.4, ModuleCode: "AC51001",
+				Start: time.Now(), End: time.Now().AddDate(0, 1, 0), Status: AssignmentAvailable })
+			assignmentId = assignment.ID
+
+			exam, _ := DBExams.CreateExam(Exam{ Topic: "Final Exam", Location: "Main Hall", Weight: 0.5, Date: time.Now(), ModuleCode: "AC51001" })
+			examId = exam.ID
+		})
+
+		g.It("Should flag weights that don't add up to 100%", func() {
+			gradebook, err := DBGradebook.FindGradebook("AC51001", false)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(gradebook.WeightsComplete).IsFalse()
+			g.Assert(len(gradebook.Components)).Equal(2)
+			g.Assert(len(gradebook.Students)).Equal(2)
+			g.Assert(gradebook.Students[0].Mark == nil).IsTrue()
+		})
+
+		g.It("Should combine the graded components by weight", func() {
+			attachment, _ := DBAttachment.CreateAttachment("essay.pdf", "application/pdf", "gradebook-test-token")
+			submission, err := DBAssignments.SubmitAssignment(3, assignmentId, attachment.ID, "My essay")
+			g.Assert(err == nil).IsTrue()
+			_, err = DBAssignments.GradeAssignment(submission.ID, 80)
+			g.Assert(err == nil).IsTrue()
+
+			DBExams.SetStudentResult(examId, 3, 60, ExamGraded)
+			DBExams.SetStudentResult(examId, 5, 70, ExamGraded)
+
+			gradebook, err := DBGradebook.FindGradebook("AC51001", false)
+			g.Assert(err == nil).IsTrue()
+
+			for _, student := range gradebook.Students {
+				g.Assert(student.Provisional).IsTrue()
+				g.Assert(student.FinalMark == nil).IsTrue()
+
+				if student.User.ID == 3 {
+					g.Assert(math.Abs(*student.Mark - 62 / 0.9) < 0.001).IsTrue()
+				}
+			}
+		})
+
+		g.It("Should give a final mark once everything is graded", func() {
+			DBExams.UpdateExam("AC51001", examId, Exam{ Topic: "Final Exam", Location: "Main Hall", Weight: 0.6, Date: time.Now() })
+
+			gradebook, err := DBGradebook.FindGradebook("AC51001", false)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(gradebook.WeightsComplete).IsTrue()
+
+			for _, student := range gradebook.Students {
+				if student.User.ID == 3 {
+					g.Assert(student.Provisional).IsFalse()
+					g.Assert(math.Abs(*student.FinalMark - 68) < 0.001).IsTrue()
+				} else {
+					g.Assert(student.Provisional).IsTrue()
+					g.Assert(*student.Mark).Equal(float64(70))
+				}
+			}
+
+			g.Assert(gradebook.Statistics.Count).Equal(2)
+			g.Assert(math.Abs(gradebook.Statistics.Mean - 69) < 0.001).IsTrue()
+			g.Assert(gradebook.Components[1].Statistics.Median).Equal(float64(65))
+		})
+
+		g.After(func() {
+			DBModule.DB().Where("assignment_id = ?", assignmentId).Delete(Submission{})
+			DBAssignments.DeleteAssignment(assignmentId)
+			DBExams.RemoveStudentResult(examId, 3)
+			DBExams.RemoveStudentResult(examId, 5)
+			DBExams.DeleteExam("AC51001", examId)
+			DBModule.DB().Where("module_code = ? and user_id in (?)", "AC51001", []uint32{ 3, 5 }).Delete(UserModule{})
+		})
+	})
+
+	g.Describe("When calculating grade statistics", func() {
+		g.It("Should work out the median and the distribution", func() {
+			statistics := NewGradeStatistics([]float64{ 100, 42, 95 })
+
+			g.Assert(statistics.Median).Equal(float64(95))
+			g.Assert(statistics.Min).Equal(float64(42))
+			g.Assert(statistics.Distribution["90-100"]).Equal(2)
+			g.Assert(statistics.Distribution["40-49"]).Equal(1)
+			g.Assert(statistics.Distribution["0-9"]).Equal(0)
+		})
+
+		g.It("Should return empty statistics without grades", func() {
+			statistics := NewGradeStatistics([]float64{})
+
+			g.Assert(statistics.Count).Equal(0)
+			g.Assert(len(statistics.Distribution)).Equal(10)
+		})
+	})
+}
@@ -0,0 +1,80 @@
+package models
+
+import (
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/YagoCarballo/kumquat-academy-api/database"
+)
+
+type JobRunsModel struct{}
+var DBJobRuns JobRunsModel
+
+func (model JobRunsModel) DB() *gorm.DB {
+	return database.DB
+}
+
+func (model JobRunsModel) FindJobRun(name string) (*JobRun, error) {
+	var jobRun JobRun
+
+	query := model.DB().First(&jobRun, "name = ?", name)
+	if query.Error != nil {
+		// If no Records found, return NIL otherwise return the error
+		switch query.Error {
+		case gorm.ErrRecordNotFound:
+			return nil, nil
+		default:
+			return nil, query.Error
+		}
+	}
+
+	return &jobRun, nil
+}
+
+// Claims the next run of a job, given the number of runs seen when it was scheduled.
+// Only one claim succeeds for each run, so restarted (or concurrent) servers don't run a job twice.
+func (model JobRunsModel) ClaimJobRun(name string, runs uint64, now time.Time) (bool, error) {
+	if runs == 0 {
+		query := model.DB().Create(&JobRun{ Name: name, Runs: 1, LastRun: now })
+		if query.Error != nil {
+			if database.IsDuplicatedError(query.Error) {
+				return false, nil
+			}
+
+			return false, query.Error
+		}
+
+		return true, nil
+	}
+
+	query := model.DB().Table("job_runs").Where("name = ? and runs = ?", name, runs).Updates(map[string]interface{}{
+		"runs": runs + 1,
+		"last_run": now,
+	})
+	if query.Error != nil {
+		return false, query.Error
+	}
+
+	return query.RowsAffected == 1, nil
+}
+
+// Stores the outcome of the last run of a job, an empty error means it succeeded
+func (model JobRunsModel) FinishJobRun(name string, jobError error) error {
+	lastError := ""
+	if jobError != nil {
+		lastError = jobError.Error()
+	}
+
+	return model.DB().Table("job_runs").Where("name = ?", name).Update("last_error", lastError).Error
+}
+
+func (model JobRunsModel) FindJobRuns() ([]JobRun, error) {
+	jobRuns := []JobRun{}
+
+	query := model.DB().Order("name").Find(&jobRuns)
+	if query.Error != nil {
+		return nil, query.Error
+	}
+
+	return jobRuns, nil
+}
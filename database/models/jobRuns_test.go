@@ -0,0 +1,78 @@
+package models
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/franela/goblin"
+)
+
+func Test_Database_JobRuns(t *testing.T) {
+	g := Goblin(t)
+	now := time.Now()
+
+	g.Describe("When keeping track of the scheduled jobs", func() {
+		g.It("Should not find a job that never ran", func() {
+			jobRun, err := DBJobRuns.FindJobRun("-test-job-")
+
+			g.Assert(err == nil).IsTrue()
+			g.Assert(jobRun == nil).IsTrue()
+		})
+
+		g.It("Should claim the first run only once", func() {
+			claimed, err := DBJobRuns.ClaimJobRun("-test-job-", 0, now)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(claimed).IsTrue()
+
+			claimed, err = DBJobRuns.ClaimJobRun("-test-job-", 0, now)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(claimed).IsFalse()
+		})
+
+		g.It("Should claim the next runs only once", func() {
+			claimed, err := DBJobRuns.ClaimJobRun("-test-job-", 1, now.Add(time.Hour))
+			g.Assert(err == nil).IsTrue()
+			g.Assert(claimed).IsTrue()
+
+			claimed, err = DBJobRuns.ClaimJobRun("-test-job-", 1, now.Add(time.Hour))
+			g.Assert(err == nil).IsTrue()
+			g.Assert(claimed).IsFalse()
+
+			jobRun, err := DBJobRuns.FindJobRun("-test-job-")
+			g.Assert(err == nil).IsTrue()
+			g.Assert(jobRun.Runs).Equal(uint64(2))
+		})
+
+		g.It("Should save the error of the last run", func() {
+			err := DBJobRuns.FinishJobRun("-test-job-", errors.New("Something went wrong."))
+			g.Assert(err == nil).IsTrue()
+
+			jobRun, _ := DBJobRuns.FindJobRun("-test-job-")
+			g.Assert(jobRun.LastError).Equal("Something went wrong.")
+
+			DBJobRuns.FinishJobRun("-test-job-", nil)
+			jobRun, _ = DBJobRuns.FindJobRun("-test-job-")
+			g.Assert(jobRun.LastError).Equal("")
+		})
+
+		g.It("Should remove the expired sessions", func() {
+			session, _ := DBSession.Create(1, "-expired-job-test-")
+			DBSession.DB().Model(Session{}).Where("token = ?", session.Token).Updates(map[string]interface{}{
+				"expires_in": now.Add(-time.Hour),
+				"refresh_expires_in": now.Add(-time.Hour),
+			})
+
+			removed, err := DBSession.RemoveExpiredSessions(now)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(removed >= 1).IsTrue()
+
+			expired, _ := DBSession.FindSession(session.Token)
+			g.Assert(expired == nil).IsTrue()
+		})
+
+		g.After(func() {
+			DBJobRuns.DB().Where("name = ?", "-test-job-").Delete(JobRun{})
+		})
+	})
+}
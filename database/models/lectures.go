@@ -83,8 +83,14 @@ func (model LecturesModel) UpdateLecture(lectureId, moduleId uint32, location, t
 	return &lecture, nil
 }
 
+// Deletes a lecture, its materials stay in the library without a lecture
 func (model LecturesModel) DeleteLecture(lectureId uint32) (int64, error) {
-	query := model.DB().Table("lectures").Where("id = ?", lectureId).Delete(Lecture{})
+	query := model.DB().Table("materials").Where("lecture_id = ?", lectureId).Update("lecture_id", nil)
+	if query.Error != nil {
+		return 0, query.Error
+	}
+
+	query = model.DB().Table("lectures").Where("id = ?", lectureId).Delete(Lecture{})
 	if query.Error != nil {
 		return 0, query.Error
 	}
@@ -149,7 +155,7 @@ func (model LecturesModel) FindLecturesForUserInRange(userId uint32, start, end
 	query := model.DB().Table("lectures").Preload("LectureSlot").Preload("Attachments").Preload("Module").Limit(limit).Order("start").Joins(
 		"inner join level_modules on level_modules.module_id = lectures.module_id " +
 		"inner join user_modules on user_modules.module_code = level_modules.code",
-	).Where("user_modules.user_id = ? and lectures.start >= ? and lectures.start <= ? and canceled = 0", userId, start, end).Find(&lectures)
+	).Where("user_modules.user_id = ? and lectures.start >= ? and lectures.start <= ? and canceled = ?", userId, start, end, false).Find(&lectures)
 	if query.Error != nil {
 		return nil, query.Error
 	}
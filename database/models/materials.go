@@ -0,0 +1,92 @@
+package models
+
+import (
+	"github.com/jinzhu/gorm"
+	"github.com/YagoCarballo/kumquat-academy-api/database"
+)
+
+type MaterialsModel struct{}
+var DBMaterials MaterialsModel
+
+func (model MaterialsModel) DB() *gorm.DB {
+	return database.DB
+}
+
+func (model MaterialsModel) CreateMaterial(material Materials) (*Materials, error) {
+	query := model.DB().Create(&material)
+	if query.Error != nil {
+		return nil, query.Error
+	}
+
+	return &material, nil
+}
+
+func (model MaterialsModel) ReadMaterial(moduleId, id uint32) (*Materials, error) {
+	var material Materials
+
+	query := model.DB().Preload("Attachment").First(&material, "id = ? and module_id = ?", id, moduleId)
+	if query.Error != nil {
+		// If no Records found, return NIL otherwise return the error
+		switch query.Error {
+		case gorm.ErrRecordNotFound:
+			return nil, nil
+		default:
+			return nil, query.Error
+		}
+	}
+
+	return &material, nil
+}
+
+func (model MaterialsModel) UpdateMaterial(moduleId, id uint32, material Materials) (*Materials, error) {
+	query := model.DB().Table("materials").Where("id = ? and module_id = ?", id, moduleId).Updates(map[string]interface{}{
+		"type": material.Type,
+		"title": material.Title,
+		"url": material.Url,
+		"week": material.Week,
+		"lecture_id": material.LectureID,
+	})
+	if query.Error != nil {
+		return nil, query.Error
+	}
+
+	return model.ReadMaterial(moduleId, id)
+}
+
+// Links an uploaded file to a material, replacing the previous one
+func (model MaterialsModel) SetMaterialAttachment(moduleId, id, attachmentId uint32) (int64, error) {
+	query := model.DB().Table("materials").
+				Where("id = ? and module_id = ?", id, moduleId).
+				Update("attachment_id", attachmentId)
+	if query.Error != nil {
+		return 0, query.Error
+	}
+
+	return query.RowsAffected, nil
+}
+
+func (model MaterialsModel) DeleteMaterial(moduleId, id uint32) (int64, error) {
+	query := model.DB().Where("id = ? and module_id = ?", id, moduleId).Delete(Materials{})
+	if query.Error != nil {
+		return 0, query.Error
+	}
+
+	return query.RowsAffected, nil
+}
+
+// Finds the materials of a module ordered by week, when the type is empty all the materials are returned
+func (model MaterialsModel) FindMaterialsForModule(moduleId uint32, materialType MaterialType) ([]Materials, error) {
+	materials := []Materials{}
+
+	query := model.DB().Preload("Attachment").Where("module_id = ?", moduleId)
+	if materialType != "" {
+		query = query.Where("type = ?", materialType)
+	}
+
+	query = query.Order("week, id").Find(&materials)
+	if query.Error != nil {
+		return nil, query.Error
+	}
+
+	return materials, nil
+}
@@ -0,0 +1,82 @@
+package models
+
+import (
+	"testing"
+
+	. "github.com/franela/goblin"
+)
+
+func Test_Database_Materials(t *testing.T) {
+	g := Goblin(t)
+	var slidesId, videoId uint32
+
+	g.Describe("When adding materials to a module", func() {
+		g.It("Should be able to create materials", func() {
+			material, err := DBMaterials.CreateMaterial(Materials{ ModuleID: 1, Type: MaterialSlides, Title: "Introduction slides", Week: 1, LectureID: uint32Pointer(1) })
+			g.Assert(err == nil).IsTrue()
+			g.Assert(material.ID > 0).IsTrue()
+			slidesId = material.ID
+
+			material, err = DBMaterials.CreateMaterial(Materials{ ModuleID: 1, Type: MaterialVideo, Title: "What is Hadoop", Url: "https://example.com/hadoop", Week: 2 })
+			g.Assert(err == nil).IsTrue()
+			videoId = material.ID
+		})
+
+		g.It("Should not read a material from another module", func() {
+			material, err := DBMaterials.ReadMaterial(2, slidesId)
+
+			g.Assert(err == nil).IsTrue()
+			g.Assert(material == nil).IsTrue()
+		})
+
+		g.It("Should link an attachment to a material", func() {
+			attachment, err := DBAttachment.CreateAttachment("intro.pdf", "application/pdf", "materials-test-token")
+			g.Assert(err == nil).IsTrue()
+
+			count, err := DBMaterials.SetMaterialAttachment(1, slidesId, attachment.ID)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(count).Equal(int64(1))
+
+			material, err := DBMaterials.ReadMaterial(1, slidesId)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(material.Attachment.Name).Equal("intro.pdf")
+		})
+
+		g.It("Should update a material", func() {
+			material, err := DBMaterials.UpdateMaterial(1, videoId, Materials{ Type: MaterialVideo, Title: "Hadoop in 5 minutes", Url: "https://example.com/hadoop", Week: 3 })
+			g.Assert(err == nil).IsTrue()
+			g.Assert(material.Title).Equal("Hadoop in 5 minutes")
+			g.Assert(material.Week).Equal(uint32(3))
+			g.Assert(material.LectureID == nil).IsTrue()
+		})
+
+		g.It("Should list the materials of a module by week", func() {
+			materials, err := DBMaterials.FindMaterialsForModule(1, "")
+			g.Assert(err == nil).IsTrue()
+			g.Assert(len(materials)).Equal(2)
+			g.Assert(materials[0].ID).Equal(slidesId)
+
+			materials, err = DBMaterials.FindMaterialsForModule(1, MaterialVideo)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(len(materials)).Equal(1)
+			g.Assert(materials[0].ID).Equal(videoId)
+		})
+
+		g.It("Should only accept the known material types", func() {
+			g.Assert(MaterialDataset.IsValid()).IsTrue()
+			g.Assert(MaterialType("podcast").IsValid()).IsFalse()
+		})
+
+		g.It("Should delete materials", func() {
+			for _, id := range []uint32{ slidesId, videoId } {
+				count, err := DBMaterials.DeleteMaterial(1, id)
+				g.Assert(err == nil).IsTrue()
+				g.Assert(count).Equal(int64(1))
+			}
+
+			materials, err := DBMaterials.FindMaterialsForModule(1, "")
+			g.Assert(err == nil).IsTrue()
+			g.Assert(len(materials)).Equal(0)
+		})
+	})
+}
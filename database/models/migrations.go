@@ -0,0 +1,505 @@
+package models
+
+import (
+	"github.com/jinzhu/gorm"
+	"github.com/YagoCarballo/kumquat-academy-api/database"
+)
+
+type tableForeignKeys struct {
+	Model	interface{}
+	Keys	[]database.ForeignKey
+}
+
+// Foreign keys added on top of the tables created by the first migrations.
+// Referenced tables come first, as SQLite has to rebuild each table to add its keys.
+var foreignKeys = []tableForeignKeys{
+	{&Session{}, []database.ForeignKey{{Field: "user_id", Reference: "users(id)"}}},
+	{&Class{}, []database.ForeignKey{{Field: "course_id", Reference: "courses(id)"}}},
+	{&CourseLevel{}, []database.ForeignKey{{Field: "class_id", Reference: "classes(id)"}, {Field: "course_id", Reference: "courses(id)"}}},
+	{&LevelModule{}, []database.ForeignKey{{Field: "class_id", Reference: "classes(id)"}, {Field: "module_id", Reference: "modules(id)"}}},
+	{&UserModule{}, []database.ForeignKey{{Field: "user_id", Reference: "users(id)"}, {Field: "role_id", Reference: "roles(id)"}, {Field: "class_id", Reference: "classes(id)"}}},
+	{&UserCourse{}, []database.ForeignKey{{Field: "user_id", Reference: "users(id)"}, {Field: "course_id", Reference: "courses(id)"}, {Field: "role_id", Reference: "roles(id)"}}},
+	{&ResetPassword{}, []database.ForeignKey{{Field: "user_id", Reference: "users(id)"}}},
+	{&AssignmentAttachments{}, []database.ForeignKey{{Field: "assignment_id", Reference: "assignments(id)"}, {Field: "attachment_id", Reference: "attachments(id)"}}},
+	{&Submission{}, []database.ForeignKey{{Field: "user_id", Reference: "users(id)", OnDelete: database.Restrict}, {Field: "assignment_id", Reference: "assignments(id)"}, {Field: "attachment_id", Reference: "attachments(id)", OnDelete: database.Restrict}}},
+	{&StudentExam{}, []database.ForeignKey{{Field: "exam_id", Reference: "exams(id)"}, {Field: "user_id", Reference: "users(id)", OnDelete: database.Restrict}}},
+	{&Task{}, []database.ForeignKey{{Field: "assignment_id", Reference: "assignments(id)"}}},
+	{&CompletedTask{}, []database.ForeignKey{{Field: "user_id", Reference: "users(id)"}, {Field: "task_id", Reference: "tasks(id)"}}},
+	{&Team{}, []database.ForeignKey{{Field: "assignment_id", Reference: "assignments(id)"}}},
+	{&TeamMember{}, []database.ForeignKey{{Field: "team_id", Reference: "teams(id)"}, {Field: "user_id", Reference: "users(id)"}}},
+	{&TeamCompletedTask{}, []database.ForeignKey{{Field: "team_id", Reference: "teams(id)"}, {Field: "task_id", Reference: "tasks(id)"}}},
+	{&Lecture{}, []database.ForeignKey{{Field: "module_id", Reference: "modules(id)"}}},
+	{&LectureSlot{}, []database.ForeignKey{{Field: "module_id", Reference: "modules(id)"}}},
+	{&LectureAttachments{}, []database.ForeignKey{{Field: "lecture_id", Reference: "lectures(id)"}, {Field: "attachment_id", Reference: "attachments(id)"}}},
+	{&Materials{}, []database.ForeignKey{{Field: "module_id", Reference: "modules(id)"}}},
+	{&Page{}, []database.ForeignKey{{Field: "module_id", Reference: "modules(id)"}}},
+}
+
+// Foreign keys of the announcement tables, added when announcements were first used
+var announcementForeignKeys = []tableForeignKeys{
+	{&Announcement{}, []database.ForeignKey{{Field: "user_id", Reference: "users(id)", OnDelete: database.Restrict}, {Field: "assignment_id", Reference: "assignments(id)"}, {Field: "course_id", Reference: "courses(id)"}}},
+	{&AnnouncementAttachments{}, []database.ForeignKey{{Field: "announcement_id", Reference: "announcements(id)"}, {Field: "attachment_id", Reference: "attachments(id)"}}},
+	{&AnnouncementRead{}, []database.ForeignKey{{Field: "announcement_id", Reference: "announcements(id)"}, {Field: "user_id", Reference: "users(id)"}}},
+}
+
+// Shape of the announcements table before it was used, restored when rolling back
+type legacyAnnouncement struct {
+	ID				uint32	`gorm:"primary_key" sql:"type:int unsigned"`
+	UserID			uint32
+	ModuleID		uint32
+	AssignmentID	uint32
+	CourseID 		uint32
+}
+
+func (legacyAnnouncement) TableName() string {
+	return "announcements"
+}
+
+func init() {
+	database.RegisterMigration(database.Migration{
+		Version: 1,
+		Name: "create_core_tables",
+		Up: func(db *gorm.DB) error {
+			return database.AutoMigrate(db,
+				&Attachment{},
+				&User{},
+				&Session{},
+				&ResetPassword{},
+				&Role{},
+				&Course{},
+				&Module{},
+				&Class{},
+				&CourseLevel{},
+				&LevelModule{},
+				&UserModule{},
+				&UserCourse{},
+			)
+		},
+		Down: func(db *gorm.DB) error {
+			return db.DropTableIfExists(
+				&UserCourse{},
+				&UserModule{},
+				&LevelModule{},
+				&CourseLevel{},
+				&Class{},
+				&Module{},
+				&Course{},
+				&Role{},
+				&ResetPassword{},
+				&Session{},
+				&User{},
+				&Attachment{},
+			).Error
+		},
+	})
+
+	database.RegisterMigration(database.Migration{
+		Version: 2,
+		Name: "create_teaching_tables",
+		Up: func(db *gorm.DB) error {
+			return database.AutoMigrate(db,
+				&AssignmentAttachments{},
+				&Assignment{},
+				&Submission{},
+				&Task{},
+				&CompletedTask{},
+				&Team{},
+				&TeamMember{},
+				&TeamCompletedTask{},
+				&Exam{},
+				&StudentExam{},
+				&LectureSlot{},
+				&LectureAttachments{},
+				&Lecture{},
+				&Materials{},
+				&Page{},
+				&Announcement{},
+			)
+		},
+		Down: func(db *gorm.DB) error {
+			return db.DropTableIfExists(
+				&Announcement{},
+				&Page{},
+				&Materials{},
+				&LectureAttachments{},
+				&Lecture{},
+				&LectureSlot{},
+				&StudentExam{},
+				&Exam{},
+				&TeamCompletedTask{},
+				&TeamMember{},
+				&Team{},
+				&CompletedTask{},
+				&Task{},
+				&Submission{},
+				&AssignmentAttachments{},
+				&Assignment{},
+			).Error
+		},
+	})
+
+	database.RegisterMigration(database.Migration{
+		Version: 3,
+		Name: "add_foreign_keys",
+		Up: func(db *gorm.DB) error {
+			for _, table := range foreignKeys {
+				err := database.AddForeignKeys(db, table.Model, table.Keys...)
+				if err != nil {
+					return err
+				}
+			}
+
+			return nil
+		},
+		Down: func(db *gorm.DB) error {
+			for index := len(foreignKeys) - 1; index >= 0; index-- {
+				err := database.DropForeignKeys(db, foreignKeys[index].Model, foreignKeys[index].Keys...)
+				if err != nil {
+					return err
+				}
+			}
+
+			return nil
+		},
+	})
+	database.RegisterMigration(database.Migration{
+		Version: 4,
+		Name: "add_unique_class_titles",
+		Up: func(db *gorm.DB) error {
+			return db.Model(&Class{}).AddUniqueIndex("idx_classes_course_title", "course_id", "title").Error
+		},
+		Down: func(db *gorm.DB) error {
+			return db.Model(&Class{}).RemoveIndex("idx_classes_course_title").Error
+		},
+	})
+
+	database.RegisterMigration(database.Migration{
+		Version: 5,
+		Name: "add_team_assignments",
+		Up: func(db *gorm.DB) error {
+			return database.AutoMigrate(db, &Assignment{}, &Team{}, &Submission{})
+		},
+		Down: func(db *gorm.DB) error {
+			query := db.Model(&Submission{}).DropColumn("team_id")
+			if query.Error != nil {
+				return query.Error
+			}
+
+			query = db.Model(&Team{}).DropColumn("name")
+			if query.Error != nil {
+				return query.Error
+			}
+
+			return db.Model(&Assignment{}).DropColumn("team_assignment").Error
+		},
+	})
+
+	database.RegisterMigration(database.Migration{
+		Version: 6,
+		Name: "add_assignment_tasks",
+		Up: func(db *gorm.DB) error {
+			return database.AutoMigrate(db, &Task{})
+		},
+		Down: func(db *gorm.DB) error {
+			for _, column := range []string{"due", "position", "description", "title"} {
+				query := db.Model(&Task{}).DropColumn(column)
+				if query.Error != nil {
+					return query.Error
+				}
+			}
+
+			return nil
+		},
+	})
+
+	database.RegisterMigration(database.Migration{
+		Version: 7,
+		Name: "create_announcements",
+		Up: func(db *gorm.DB) error {
+			// The table was never used, so it is created again with an auto incremented id
+			query := db.DropTableIfExists(&Announcement{})
+			if query.Error != nil {
+				return query.Error
+			}
+
+			err := database.AutoMigrate(db, &Announcement{}, &AnnouncementAttachments{}, &AnnouncementRead{})
+			if err != nil {
+				return err
+			}
+
+			for _, table := range announcementForeignKeys {
+				err = database.AddForeignKeys(db, table.Model, table.Keys...)
+				if err != nil {
+					return err
+				}
+			}
+
+			return nil
+		},
+		Down: func(db *gorm.DB) error {
+			query := db.DropTableIfExists(&AnnouncementRead{}, &AnnouncementAttachments{}, &Announcement{})
+			if query.Error != nil {
+				return query.Error
+			}
+
+			return database.AutoMigrate(db, &legacyAnnouncement{})
+		},
+	})
+
+	database.RegisterMigration(database.Migration{
+		Version: 8,
+		Name: "add_page_revisions",
+		Up: func(db *gorm.DB) error {
+			err := database.AutoMigrate(db, &Page{}, &PageRevision{})
+			if err != nil {
+				return err
+			}
+
+			return database.AddForeignKeys(db, &PageRevision{},
+				database.ForeignKey{Field: "page_id", Reference: "pages(id)"},
+				database.ForeignKey{Field: "user_id", Reference: "users(id)", OnDelete: database.Restrict},
+			)
+		},
+		Down: func(db *gorm.DB) error {
+			query := db.DropTableIfExists(&PageRevision{})
+			if query.Error != nil {
+				return query.Error
+			}
+
+			for _, column := range []string{"position", "parent_id"} {
+				query = db.Model(&Page{}).DropColumn(column)
+				if query.Error != nil {
+					return query.Error
+				}
+			}
+
+			return nil
+		},
+	})
+
+	database.RegisterMigration(database.Migration{
+		Version: 9,
+		Name: "add_materials_library",
+		Up: func(db *gorm.DB) error {
+			return database.AutoMigrate(db, &Materials{})
+		},
+		Down: func(db *gorm.DB) error {
+			for _, column := range []string{"created_at", "week", "url", "title"} {
+				query := db.Model(&Materials{}).DropColumn(column)
+				if query.Error != nil {
+					return query.Error
+				}
+			}
+
+			return nil
+		},
+	})
+
+	database.RegisterMigration(database.Migration{
+		Version: 10,
+		Name: "add_submission_lifecycle",
+		Up: func(db *gorm.DB) error {
+			err := database.AutoMigrate(db, &Assignment{}, &Submission{}, &AssignmentExtension{})
+			if err != nil {
+				return err
+			}
+
+			err = database.AddForeignKeys(db, &AssignmentExtension{},
+				database.ForeignKey{Field: "assignment_id", Reference: "assignments(id)"},
+				database.ForeignKey{Field: "user_id", Reference: "users(id)"},
+			)
+			if err != nil {
+				return err
+			}
+
+			// Submissions graded so far had no penalties
+			query := db.Table("submissions").Where("graded_on is not null").Updates(map[string]interface{}{
+				"raw_grade": gorm.Expr("grade"),
+				"status": SubmissionGraded,
+			})
+			return query.Error
+		},
+		Down: func(db *gorm.DB) error {
+			query := db.DropTableIfExists(&AssignmentExtension{})
+			if query.Error != nil {
+				return query.Error
+			}
+
+			for _, column := range []string{"raw_grade", "penalty", "late", "canceled_on"} {
+				query = db.Model(&Submission{}).DropColumn(column)
+				if query.Error != nil {
+					return query.Error
+				}
+			}
+
+			for _, column := range []string{"late_days", "late_penalty"} {
+				query = db.Model(&Assignment{}).DropColumn(column)
+				if query.Error != nil {
+					return query.Error
+				}
+			}
+
+			return nil
+		},
+	})
+
+	database.RegisterMigration(database.Migration{
+		Version: 11,
+		Name: "add_grading_rubrics",
+		Up: func(db *gorm.DB) error {
+			err := database.AutoMigrate(db, &RubricCriterion{}, &RubricLevel{}, &SubmissionScore{}, &Submission{})
+			if err != nil {
+				return err
+			}
+
+			err = database.AddForeignKeys(db, &RubricCriterion{}, database.ForeignKey{Field: "assignment_id", Reference: "assignments(id)"})
+			if err != nil {
+				return err
+			}
+
+			err = database.AddForeignKeys(db, &RubricLevel{}, database.ForeignKey{Field: "criterion_id", Reference: "rubric_criteria(id)"})
+			if err != nil {
+				return err
+			}
+
+			return database.AddForeignKeys(db, &SubmissionScore{},
+				database.ForeignKey{Field: "submission_id", Reference: "submissions(id)"},
+				database.ForeignKey{Field: "criterion_id", Reference: "rubric_criteria(id)"},
+				database.ForeignKey{Field: "level_id", Reference: "rubric_levels(id)"},
+			)
+		},
+		Down: func(db *gorm.DB) error {
+			query := db.DropTableIfExists(&SubmissionScore{}, &RubricLevel{}, &RubricCriterion{})
+			if query.Error != nil {
+				return query.Error
+			}
+
+			for _, column := range []string{"feedback", "feedback_attachment_id"} {
+				query = db.Model(&Submission{}).DropColumn(column)
+				if query.Error != nil {
+					return query.Error
+				}
+			}
+
+			return nil
+		},
+	})
+
+	database.RegisterMigration(database.Migration{
+		Version: 12,
+		Name: "create_job_runs",
+		Up: func(db *gorm.DB) error {
+			return database.AutoMigrate(db, &JobRun{})
+		},
+		Down: func(db *gorm.DB) error {
+			return db.DropTableIfExists(&JobRun{}).Error
+		},
+	})
+
+	database.RegisterMigration(database.Migration{
+		Version: 13,
+		Name: "create_outbox_emails",
+		Up: func(db *gorm.DB) error {
+			return database.AutoMigrate(db, &OutboxEmail{})
+		},
+		Down: func(db *gorm.DB) error {
+			return db.DropTableIfExists(&OutboxEmail{}).Error
+		},
+	})
+
+	database.RegisterMigration(database.Migration{
+		Version: 14,
+		Name: "add_session_refresh",
+		Up: func(db *gorm.DB) error {
+			err := database.AutoMigrate(db, &Session{})
+			if err != nil {
+				return err
+			}
+
+			// The existing sessions were last seen when created, they can't be refreshed
+			return db.Table("sessions").Update("last_seen", gorm.Expr("created_on")).Error
+		},
+		Down: func(db *gorm.DB) error {
+			// SQLite can't drop a column that is still indexed
+			query := db.Model(&Session{}).RemoveIndex("idx_sessions_refresh_token")
+			if query.Error != nil {
+				return query.Error
+			}
+
+			for _, column := range []string{"last_seen", "refresh_token", "refresh_expires_in"} {
+				query := db.Model(&Session{}).DropColumn(column)
+				if query.Error != nil {
+					return query.Error
+				}
+			}
+
+			return nil
+		},
+	})
+
+	database.RegisterMigration(database.Migration{
+		Version: 15,
+		Name: "add_unique_role_names",
+		Up: func(db *gorm.DB) error {
+			return db.Model(&Role{}).AddUniqueIndex("idx_roles_name", "name").Error
+		},
+		Down: func(db *gorm.DB) error {
+			return db.Model(&Role{}).RemoveIndex("idx_roles_name").Error
+		},
+	})
+
+	database.RegisterMigration(database.Migration{
+		Version: 16,
+		Name: "create_role_capabilities",
+		Up: func(db *gorm.DB) error {
+			err := database.AutoMigrate(db, &RoleCapability{})
+			if err != nil {
+				return err
+			}
+
+			return database.AddForeignKeys(db, &RoleCapability{}, database.ForeignKey{Field: "role_id", Reference: "roles(id)"})
+		},
+		Down: func(db *gorm.DB) error {
+			return db.DropTableIfExists(&RoleCapability{}).Error
+		},
+	})
+
+	database.RegisterMigration(database.Migration{
+		Version: 17,
+		Name: "add_upload_limits",
+		Up: func(db *gorm.DB) error {
+			return database.AutoMigrate(db, &Attachment{}, &Assignment{})
+		},
+		Down: func(db *gorm.DB) error {
+			for _, column := range []string{"size", "user_id", "module_code"} {
+				query := db.Model(&Attachment{}).DropColumn(column)
+				if query.Error != nil {
+					return query.Error
+				}
+			}
+
+			return db.Model(&Assignment{}).DropColumn("allowed_extensions").Error
+		},
+	})
+
+	database.RegisterMigration(database.Migration{
+		Version: 18,
+		Name: "add_attachment_blobs",
+		Up: func(db *gorm.DB) error {
+			return database.AutoMigrate(db, &Blob{}, &Attachment{})
+		},
+		Down: func(db *gorm.DB) error {
+			query := db.Model(&Attachment{}).RemoveIndex("idx_attachments_hash")
+			if query.Error != nil {
+				return query.Error
+			}
+
+			query = db.Model(&Attachment{}).DropColumn("hash")
+			if query.Error != nil {
+				return query.Error
+			}
+
+			return db.DropTableIfExists(&Blob{}).Error
+		},
+	})
+}
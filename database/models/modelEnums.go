@@ -2,6 +2,7 @@ package models
 
 import (
 	"errors"
+	"fmt"
 	"database/sql/driver"
 )
 
@@ -23,22 +24,63 @@ const (
 	SubmissionGraded	SubmissionStatus = "graded"
 	SubmissionCanceled	SubmissionStatus = "canceled"
 
-	ExamComplete	SubmissionStatus = "complete"
-	ExamReview		SubmissionStatus = "review"
-	ExamGraded		SubmissionStatus = "graded"
+	ExamComplete	ExamStatus = "complete"
+	ExamReview		ExamStatus = "review"
+	ExamGraded		ExamStatus = "graded"
+
+	MaterialSlides	MaterialType = "slides"
+	MaterialReading	MaterialType = "reading"
+	MaterialVideo	MaterialType = "video"
+	MaterialDataset	MaterialType = "dataset"
+
+	EmailPending	EmailStatus = "pending"
+	EmailSending	EmailStatus = "sending"
+	EmailSent		EmailStatus = "sent"
+	EmailFailed		EmailStatus = "failed"
 )
 
+// The statuses each status can move to, the status of a module only moves forward unless an ended module is reopened
+var moduleTransitions = map[ModuleStatus][]ModuleStatus{
+	ModuleDraft:	{ModuleFuture, ModuleOngoing},
+	ModuleFuture:	{ModuleDraft, ModuleOngoing},
+	ModuleOngoing:	{ModuleEnded},
+	ModuleEnded:	{ModuleOngoing},
+}
+
+// Assignments open for submissions when available and close when sent,
+// once graded the grades are returned to the students (and can be taken back to amend them)
+var assignmentTransitions = map[AssignmentStatus][]AssignmentStatus{
+	AssignmentDraft:		{AssignmentCreated, AssignmentAvailable},
+	AssignmentCreated:		{AssignmentDraft, AssignmentAvailable},
+	AssignmentAvailable:	{AssignmentCreated, AssignmentSent},
+	AssignmentSent:			{AssignmentAvailable, AssignmentGraded, AssignmentReturned},
+	AssignmentGraded:		{AssignmentSent, AssignmentReturned},
+	AssignmentReturned:		{AssignmentGraded},
+}
+
+// Returned when a status can't be changed to the requested one
+type InvalidTransitionError struct {
+	From	string
+	To		string
+}
+
+func (err InvalidTransitionError) Error() string {
+	return fmt.Sprintf("The status can't be changed from '%s' to '%s'.", err.From, err.To)
+}
+
 type ModuleStatus string
 type AssignmentStatus string
 type SubmissionStatus string
 type ExamStatus string
+type MaterialType string
+type EmailStatus string
 
 func (status *ModuleStatus) Scan(value interface{}) error {
-	asBytes, ok := value.([]byte)
-	if !ok {
-		return errors.New("Scan source is not []byte")
+	asString, err := scanString(value)
+	if err != nil {
+		return err
 	}
-	*status = ModuleStatus(string(asBytes))
+	*status = ModuleStatus(asString)
 	return nil
 }
 
@@ -46,12 +88,32 @@ func (status ModuleStatus) Value() (driver.Value, error)  {
 	return string(status), nil
 }
 
+func (status ModuleStatus) IsValid() bool {
+	_, ok := moduleTransitions[status]
+	return ok
+}
+
+// Checks that the status can be changed to the next one, keeping the same status is always allowed
+func (status ModuleStatus) CanTransitionTo(next ModuleStatus) bool {
+	if status == next {
+		return true
+	}
+
+	for _, allowed := range moduleTransitions[status] {
+		if allowed == next {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (status *AssignmentStatus) Scan(value interface{}) error {
-	asBytes, ok := value.([]byte)
-	if !ok {
-		return errors.New("Scan source is not []byte")
+	asString, err := scanString(value)
+	if err != nil {
+		return err
 	}
-	*status = AssignmentStatus(string(asBytes))
+	*status = AssignmentStatus(asString)
 	return nil
 }
 
@@ -59,12 +121,32 @@ func (status AssignmentStatus) Value() (driver.Value, error)  {
 	return string(status), nil
 }
 
+func (status AssignmentStatus) IsValid() bool {
+	_, ok := assignmentTransitions[status]
+	return ok
+}
+
+// Checks that the status can be changed to the next one, keeping the same status is always allowed
+func (status AssignmentStatus) CanTransitionTo(next AssignmentStatus) bool {
+	if status == next {
+		return true
+	}
+
+	for _, allowed := range assignmentTransitions[status] {
+		if allowed == next {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (status *SubmissionStatus) Scan(value interface{}) error {
-	asBytes, ok := value.([]byte)
-	if !ok {
-		return errors.New("Scan source is not []byte")
+	asString, err := scanString(value)
+	if err != nil {
+		return err
 	}
-	*status = SubmissionStatus(string(asBytes))
+	*status = SubmissionStatus(asString)
 	return nil
 }
 
@@ -73,14 +155,61 @@ func (status SubmissionStatus) Value() (driver.Value, error)  {
 }
 
 func (status *ExamStatus) Scan(value interface{}) error {
-	asBytes, ok := value.([]byte)
-	if !ok {
-		return errors.New("Scan source is not []byte")
+	asString, err := scanString(value)
+	if err != nil {
+		return err
 	}
-	*status = ExamStatus(string(asBytes))
+	*status = ExamStatus(asString)
 	return nil
 }
 
 func (status ExamStatus) Value() (driver.Value, error)  {
 	return string(status), nil
 }
+
+// MySQL returns the enums as []byte while SQLite returns them as string
+func (materialType *MaterialType) Scan(value interface{}) error {
+	asString, err := scanString(value)
+	if err != nil {
+		return err
+	}
+	*materialType = MaterialType(asString)
+	return nil
+}
+
+func (materialType MaterialType) Value() (driver.Value, error)  {
+	return string(materialType), nil
+}
+
+// Checks that the type is one of the known material types
+func (materialType MaterialType) IsValid() bool {
+	switch materialType {
+	case MaterialSlides, MaterialReading, MaterialVideo, MaterialDataset:
+		return true
+	}
+
+	return false
+}
+
+func scanString(value interface{}) (string, error) {
+	switch source := value.(type) {
+	case []byte:
+		return string(source), nil
+	case string:
+		return source, nil
+	}
+
+	return "", errors.New("Scan source is not []byte")
+}
+func (status *EmailStatus) Scan(value interface{}) error {
+	asString, err := scanString(value)
+	if err != nil {
+		return err
+	}
+	*status = EmailStatus(asString)
+	return nil
+}
+
+func (status EmailStatus) Value() (driver.Value, error)  {
+	return string(status), nil
+}
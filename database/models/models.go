@@ -2,6 +2,8 @@ package models
 
 import (
 	"time"
+
+	. "github.com/YagoCarballo/kumquat-academy-api/constants"
 )
 
 // Models Declaration
@@ -25,13 +27,18 @@ type User struct {
 	Avatar	 	 *Attachment`json:"avatar,omitempty"`
 }
 
+// The access token expires after a week without being used, the refresh token can replace it for longer
 type Session struct {
 	Token     string	`json:"token" gorm:"primary_key"`
 	DeviceID  string	`json:"device_id" sql:"not null"`
 	ExpiresIn time.Time	`json:"expires_in"`
 	CreatedOn time.Time	`json:"created_on"`
+	LastSeen  time.Time	`json:"last_seen"`
 	UserID    uint32    	`json:"user_id" sql:"not null"`
 	User	  *User		`json:"user,omitempty"`
+
+	RefreshToken		string	`json:"-" sql:"type:varchar(255);index"`
+	RefreshExpiresIn	time.Time `json:"-"`
 }
 
 type Course struct {
@@ -48,7 +55,7 @@ type Module struct {
 	Title  		string	`json:"title" sql:"not null"`
 	Description string	`json:"description"`
 	Color 		string	`json:"color"`
-	Icon		string	`json:"icon" sql:"default:\"icon-book\""`
+	Icon		string	`json:"icon" sql:"default:'icon-book'"`
 	Duration	uint32 	`json:"duration"`
 	Assignments []Assignment `json:"assignments,omitempty"`
 	Permissions *PermissionsTable `json:"role,omitempty"`
@@ -62,11 +69,18 @@ type Role struct {
 	CanWrite    bool	`json:"write" sql:"not null;default:false"`
 	CanDelete   bool	`json:"delete" sql:"not null;default:false"`
 	CanUpdate   bool	`json:"update" sql:"not null;default:false"`
+	Capabilities []RoleCapability `json:"capabilities" gorm:"ForeignKey:RoleID"`
+}
+
+// A capability granted by a role, it's written in JSON as the name of the capability
+type RoleCapability struct {
+	RoleID		uint32		`json:"-" gorm:"primary_key" sql:"type:int unsigned"`
+	Capability	AccessRight	`json:"capability" gorm:"primary_key" sql:"type:varchar(64)"`
 }
 
 type Class struct {
 	ID    		uint32	`json:"id" gorm:"primary_key"`
-	CourseID    	uint32	`json:"course_id" gorm:"primary_key" sql:"type:int(10) unsigned"`
+	CourseID    	uint32	`json:"course_id" gorm:"primary_key" sql:"type:int unsigned"`
 	Title  		string	`json:"title" sql:"not null"`
 	Start  		time.Time`json:"start"`
 	End  		time.Time`json:"end"`
@@ -74,10 +88,10 @@ type Class struct {
 }
 
 type CourseLevel struct {
-	Level		uint32	`json:"level" gorm:"primary_key" sql:"type:int(10) unsigned"`
-	ClassID    	uint32	`json:"class_id" gorm:"primary_key" sql:"type:int(10) unsigned"`
+	Level		uint32	`json:"level" gorm:"primary_key" sql:"type:int unsigned"`
+	ClassID    	uint32	`json:"class_id" gorm:"primary_key" sql:"type:int unsigned"`
 	Class		*Class	`json:"class,omitempty"`
-	CourseID    	uint32	`json:"course_id" gorm:"primary_key" sql:"type:int(10) unsigned"`
+	CourseID    	uint32	`json:"course_id" gorm:"primary_key" sql:"type:int unsigned"`
 	Course		*Course	`json:"course,omitempty"`
 
 	Start 		time.Time`json:"start"`
@@ -87,7 +101,7 @@ type CourseLevel struct {
 type LevelModule struct {
 	Code  		string	`json:"code" gorm:"primary_key"`
 
-	ClassID    	uint32  `json:"class_id,omitempty" gorm:"primary_key" sql:"type:int(10) unsigned"`
+	ClassID    	uint32  `json:"class_id,omitempty" gorm:"primary_key" sql:"type:int unsigned"`
 	Class		*Class	`json:"class,omitempty"`
 
 	Level		uint32  `json:"level,omitempty" sql:"not null"`
@@ -101,7 +115,7 @@ type LevelModule struct {
 }
 
 type UserModule struct {
-	UserID   uint32	`json:"user_id" gorm:"primary_key" sql:"type:int(10) unsigned"`
+	UserID   uint32	`json:"user_id" gorm:"primary_key" sql:"type:int unsigned"`
 	User	 *User	`json:"user,omitempty"`
 
 	ModuleCode string `json:"module_code" gorm:"primary_key"`
@@ -115,10 +129,10 @@ type UserModule struct {
 }
 
 type UserCourse struct {
-	UserID   uint32	`json:"user_id" gorm:"primary_key" sql:"type:int(10) unsigned"`
+	UserID   uint32	`json:"user_id" gorm:"primary_key" sql:"type:int unsigned"`
 	User	 *User	`json:"user,omitempty"`
 
-	CourseID uint32	`json:"course_id" gorm:"primary_key" sql:"type:int(10) unsigned"`
+	CourseID uint32	`json:"course_id" gorm:"primary_key" sql:"type:int unsigned"`
 	Course	 *Course`json:"course,omitempty"`
 
 	RoleID   uint32	`json:"role_id" sql:"not null"`
@@ -131,6 +145,11 @@ type Assignment struct {
 	Description    	string	`json:"description" sql:"type:varchar(4096); not null"`
 	Status			AssignmentStatus `json:"status"`
 	Weight			float64	`json:"weight"`
+	TeamAssignment	bool	`json:"team_assignment"`
+	LateDays		uint32	`json:"late_days"`
+	LatePenalty		float64	`json:"late_penalty"`
+	// The extensions the submitted files can have (like ".zip,.pdf"), any extension when empty
+	AllowedExtensions	string	`json:"allowed_extensions" sql:"not null;default:''"`
 	Start   		time.Time `json:"start"`
 	End   			time.Time `json:"end"`
 
@@ -144,7 +163,7 @@ type Assignment struct {
 }
 
 type Exam struct {
-	ID     			uint32	`json:"id' gorm:"primary_key"`
+	ID     			uint32	`json:"id" gorm:"primary_key"`
 	Topic    		string	`json:"topic" sql:"not null"`
 	Location    	string	`json:"location" sql:"not null"`
 	Weight			float64	`json:"weight"`
@@ -162,6 +181,23 @@ type Attachment struct {
 	Name   string	`json:"name" sql:"not null"`
 	Type   string	`json:"type" sql:"not null"`
 	Url    string	`json:"url" sql:"not null"`
+	Size   int64	`json:"size" sql:"not null;default:0"`
+
+	// Who uploaded the file and to which module, the file counts against both of their quotas
+	UserID		*uint32	`json:"user_id,omitempty"`
+	ModuleCode	*string	`json:"module_code,omitempty"`
+
+	// The SHA-256 of the content, the file is stored under it and shared by all the attachments with the same content.
+	// Files uploaded before are stored under their url and have no hash.
+	Hash		*string	`json:"hash,omitempty" sql:"index"`
+}
+
+// A stored file, counting the attachments that use it so it's only removed with the last one
+type Blob struct {
+	Hash			string	`json:"hash" gorm:"primary_key"`
+	Size			int64	`json:"size" sql:"not null"`
+	ReferenceCount	uint32	`json:"reference_count" sql:"not null"`
+	CreatedAt		time.Time `json:"created_at"`
 }
 
 type AssignmentAttachments struct {
@@ -176,9 +212,28 @@ type Page struct {
 	ID     		uint32	`json:"id" gorm:"primary_key"`
 	ModuleID    uint32	`json:"module_id" sql:"not null"`
 	Title   	string	`json:"title" sql:"not null"`
-	Content   	string	`json:"content" sql:"not null"`
+	Content   	string	`json:"content,omitempty" sql:"not null"`
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
+
+	ParentID	*uint32	`json:"parent_id"`
+	Position	uint32	`json:"position" sql:"type:int unsigned"`
+
+	Html		string	`json:"html,omitempty" sql:"-"`
+	Children	[]*Page	`json:"children,omitempty" sql:"-"`
+}
+
+type PageRevision struct {
+	ID     		uint32	`json:"id" gorm:"primary_key"`
+	Title   	string	`json:"title" sql:"not null"`
+	Content   	string	`json:"content" sql:"not null"`
+	CreatedAt   time.Time `json:"created_at"`
+
+	PageID		uint32	`json:"page_id" sql:"not null"`
+	Page		*Page	`json:"page,omitempty"`
+
+	UserID		uint32	`json:"user_id" sql:"not null"`
+	User		*User	`json:"user,omitempty"`
 }
 
 type Lecture struct {
@@ -198,6 +253,7 @@ type Lecture struct {
 	End           time.Time `json:"end"`
 
 	Attachments		[]Attachment `json:"attachments,omitempty"gorm:"many2many:lecture_attachments;"`
+	Materials		[]Materials `json:"materials,omitempty" sql:"-"`
 }
 
 type LectureAttachments struct {
@@ -222,25 +278,34 @@ type LectureSlot struct {
 
 type Materials struct {
 	ID     			uint32	`json:"id" gorm:"primary_key"`
-	Type   			string	`json:"type"`
+	Type   			MaterialType	`json:"type"`
+	Title			string	`json:"title"`
+	Url				string	`json:"url,omitempty"`
+	Week			uint32	`json:"week"`
+	CreatedAt		time.Time `json:"created_at"`
 
 	ModuleID    	uint32	`json:"module_id" sql:"not null"`
 	Module			*Module	`json:"module,omitempty"`
 
-	LectureID   	uint32	`json:"lecture_id"`
+	LectureID   	*uint32	`json:"lecture_id,omitempty"`
 	Lecture			*Lecture `json:"lecture,omitempty"`
 
-	AttachmentID    uint32	`json:"attachment_id"`
-	Attachment		*Attachment	`json:"attachment"`
+	AttachmentID    *uint32	`json:"attachment_id,omitempty"`
+	Attachment		*Attachment	`json:"attachment,omitempty"`
 }
 
 type Submission struct {
 	ID     			uint32	`json:"id" gorm:"primary_key"`
 	Grade     		float64	`json:"grade"`
+	RawGrade		float64	`json:"raw_grade"`
+	Penalty			float64	`json:"penalty"`
 	Status     		SubmissionStatus `json:"status"`
 	Description   	string	`json:"description" sql:"type:varchar(4096); not null"`
+	Late			bool	`json:"late"`
 	SubmittedOn	   	time.Time `json:"submitted_on" sql:"not null"`
 	GradedOn	   	*time.Time `json:"graded_on"`
+	CanceledOn		*time.Time `json:"canceled_on,omitempty"`
+	Feedback		string	`json:"feedback" sql:"type:varchar(4096)"`
 
 	UserID			uint32	`json:"user_id" sql:"not null"`
 	User 			*User	`json:"user,omitempty"`
@@ -250,13 +315,84 @@ type Submission struct {
 
 	AttachmentID	uint32	`json:"attachment_id" sql:"not null"`
 	Attachment		*Attachment `json:"attachment,omitempty"`
+
+	TeamID			*uint32	`json:"team_id,omitempty"`
+
+	FeedbackAttachmentID	*uint32	`json:"feedback_attachment_id,omitempty"`
+	FeedbackAttachment		*Attachment	`json:"feedback_attachment,omitempty"`
+
+	Scores			[]SubmissionScore `json:"scores,omitempty"`
+}
+
+// Hides the grade and feedback of a submission, used until the grades of the assignment are released
+func (submission *Submission) WithholdGrade() {
+	submission.Grade = 0
+	submission.RawGrade = 0
+	submission.Penalty = 0
+	submission.Feedback = ""
+	submission.FeedbackAttachmentID = nil
+	submission.FeedbackAttachment = nil
+	submission.Scores = nil
+	if submission.Status == SubmissionGraded {
+		submission.Status = SubmissionReview
+	}
+	submission.GradedOn = nil
+}
+
+// A criterion of the rubric of an assignment, graded by picking one of its levels
+type RubricCriterion struct {
+	ID     			uint32	`json:"id" gorm:"primary_key"`
+	Title			string	`json:"title" sql:"not null"`
+	Description		string	`json:"description" sql:"type:varchar(1024)"`
+	Position		uint32	`json:"position"`
+
+	AssignmentID	uint32	`json:"assignment_id" sql:"not null"`
+	Assignment		*Assignment `json:"assignment,omitempty"`
+
+	Levels			[]RubricLevel `json:"levels" gorm:"ForeignKey:CriterionID"`
+}
+
+func (RubricCriterion) TableName() string {
+	return "rubric_criteria"
+}
+
+type RubricLevel struct {
+	ID     			uint32	`json:"id" gorm:"primary_key"`
+	Title			string	`json:"title" sql:"not null"`
+	Description		string	`json:"description" sql:"type:varchar(1024)"`
+	Points			float64	`json:"points"`
+
+	CriterionID		uint32	`json:"criterion_id" sql:"not null"`
+}
+
+// The level picked for a criterion of the rubric when grading a submission
+type SubmissionScore struct {
+	SubmissionID	uint32	`json:"submission_id" gorm:"primary_key" sql:"type:int unsigned"`
+	CriterionID		uint32	`json:"criterion_id" gorm:"primary_key" sql:"type:int unsigned"`
+	LevelID			uint32	`json:"level_id" sql:"not null"`
+	Points			float64	`json:"points"`
+	Comment			string	`json:"comment" sql:"type:varchar(1024)"`
+}
+
+// Moves the deadline of an assignment for a single student
+type AssignmentExtension struct {
+	AssignmentID	uint32	`json:"assignment_id" gorm:"primary_key" sql:"type:int unsigned"`
+	Assignment		*Assignment `json:"assignment,omitempty"`
+
+	UserID			uint32	`json:"user_id" gorm:"primary_key" sql:"type:int unsigned"`
+	User 			*User	`json:"user,omitempty"`
+
+	End				time.Time `json:"end" sql:"not null"`
+	Reason			string	`json:"reason"`
+	GrantedBy		uint32	`json:"granted_by"`
+	CreatedAt		time.Time `json:"created_at"`
 }
 
 type StudentExam struct {
-	ExamID     		uint32	`json:"id" gorm:"primary_key" sql:"type:int(10) unsigned"`
+	ExamID     		uint32	`json:"exam_id" gorm:"primary_key" sql:"type:int unsigned"`
 	Exam			*Exam	`json:"exam,omitempty"`
 
-	UserID			uint32	`json:"user_id" gorm:"primary_key" sql:"type:int(10) unsigned"`
+	UserID			uint32	`json:"user_id" gorm:"primary_key" sql:"type:int unsigned"`
 	User 			*User	`json:"user,omitempty"`
 
 	Grade     		float64	`json:"grade"`
@@ -265,16 +401,22 @@ type StudentExam struct {
 
 type Task struct {
 	ID     			uint32	`json:"id" gorm:"primary_key"`
+	Title    		string	`json:"title"`
+	Description    	string	`json:"description" sql:"type:varchar(4096)"`
+	Position		uint32	`json:"position" sql:"type:int unsigned"`
+	Due				*time.Time `json:"due"`
 
 	AssignmentID	uint32	`json:"assignment_id" sql:"not null"`
 	Assignment		*Assignment `json:"assignment,omitempty"`
+
+	Completed		bool `json:"completed" sql:"-"`
 }
 
 type CompletedTask struct {
-	UserID    uint32 `json:"user_id" gorm:"primary_key" sql:"type:int(10) unsigned"`
+	UserID    uint32 `json:"user_id" gorm:"primary_key" sql:"type:int unsigned"`
 	User	*User `json:"user,omitempty"`
 
-	TaskID	uint32 `json:"task_id" gorm:"primary_key" sql:"type:int(10) unsigned"`
+	TaskID	uint32 `json:"task_id" gorm:"primary_key" sql:"type:int unsigned"`
 	Task	*Task `json:"task,omitempty"`
 
 	Date	time.Time `json:"date"`
@@ -282,43 +424,70 @@ type CompletedTask struct {
 
 type Team struct {
 	ID     			uint32	`json:"id" gorm:"primary_key"`
+	Name			string	`json:"name"`
 
 	AssignmentID	uint32	`json:"assignment_id" sql:"not null"`
 	Assignment		*Assignment `json:"assignment,omitempty"`
+
+	Members			[]User	`json:"members,omitempty" gorm:"many2many:team_members;"`
 }
 
 type TeamMember struct {
-	TeamID	uint32 `json:"team_id" gorm:"primary_key" sql:"type:int(10) unsigned"`
+	TeamID	uint32 `json:"team_id" gorm:"primary_key" sql:"type:int unsigned"`
 	Team	*Team `json:"team,omitempty"`
 
-	UserID	uint32 `json:"user_id" gorm:"primary_key" sql:"type:int(10) unsigned"`
+	UserID	uint32 `json:"user_id" gorm:"primary_key" sql:"type:int unsigned"`
 	User	*User `json:"user,omitempty"`
 }
 
 type TeamCompletedTask struct {
-	TeamID  uint32 `json:"team_id" gorm:"primary_key" sql:"type:int(10) unsigned"`
+	TeamID  uint32 `json:"team_id" gorm:"primary_key" sql:"type:int unsigned"`
 	Team	*Team `json:"team,omitempty"`
 
-	TaskID	uint32	`json:"task_id" gorm:"primary_key" sql:"type:int(10) unsigned"`
+	TaskID	uint32	`json:"task_id" gorm:"primary_key" sql:"type:int unsigned"`
 	Task	*Task `json:"task,omitempty"`
 
 	Date	time.Time `json:"date"`
 }
 
 type Announcement struct {
-	ID				uint32	`json:"id" gorm:"primary_key" sql:"type:int(10) unsigned"`
+	ID				uint32	`json:"id" gorm:"primary_key"`
+	Title			string	`json:"title" sql:"not null"`
+	Body			string	`json:"body" sql:"type:varchar(4096); not null"`
+	CreatedAt		time.Time `json:"created_at"`
 
-	UserID			uint32 `json:"user_id"`
+	UserID			uint32 `json:"user_id" sql:"not null"`
 	User			*User `json:"user,omitempty"`
 
-	ModuleID		uint32 `json:"module_id"`
-	Module			*Module `json:"module,omitempty"`
+	ModuleCode		*string `json:"module_code,omitempty"`
 
-	AssignmentID	uint32 `json:"assignment_id"`
+	AssignmentID	*uint32 `json:"assignment_id,omitempty"`
 	Assignment		*Assignment `json:"assignment,omitempty"`
 
-	CourseID 		uint32 `json:"course_id"`
+	CourseID 		*uint32 `json:"course_id,omitempty"`
 	Course	 		*Course `json:"course,omitempty"`
+
+	Attachments		[]Attachment `json:"attachments,omitempty" gorm:"many2many:announcement_attachments;"`
+
+	Read			bool `json:"read" sql:"-"`
+}
+
+type AnnouncementAttachments struct {
+	AttachmentID    uint32	`json:"attachment_id"`
+	Attachment		*Attachment `json:"attachment,omitempty"`
+
+	AnnouncementID  uint32	`json:"announcement_id"`
+	Announcement	*Announcement `json:"announcement,omitempty"`
+}
+
+type AnnouncementRead struct {
+	AnnouncementID  uint32	`json:"announcement_id" gorm:"primary_key" sql:"type:int unsigned"`
+	Announcement	*Announcement `json:"announcement,omitempty"`
+
+	UserID			uint32	`json:"user_id" gorm:"primary_key" sql:"type:int unsigned"`
+	User 			*User	`json:"user,omitempty"`
+
+	Date			time.Time `json:"date"`
 }
 
 type ResetPassword struct {
@@ -329,3 +498,26 @@ type ResetPassword struct {
 
 	Expires	 		time.Time `json:"expires"`
 }
+
+// The last run of a scheduled job, runs counts the times it has been claimed so it never runs twice for the same slot
+type JobRun struct {
+	Name			string	`json:"name" gorm:"primary_key" sql:"type:varchar(255)"`
+	Runs			uint64	`json:"runs"`
+	LastRun			time.Time `json:"last_run"`
+	LastError		string	`json:"last_error" sql:"type:varchar(1024)"`
+}
+
+// An email waiting to be sent (or already sent), failed emails are retried until they run out of attempts
+type OutboxEmail struct {
+	ID				uint32	`json:"id" gorm:"primary_key"`
+	Recipient		string	`json:"recipient" sql:"type:varchar(255); not null"`
+	Subject			string	`json:"subject" sql:"type:varchar(255); not null"`
+	Text			string	`json:"text" sql:"type:text"`
+	HTML			string	`json:"html" sql:"type:text"`
+	Status			EmailStatus `json:"status" sql:"type:varchar(20); not null"`
+	Attempts		int		`json:"attempts"`
+	NextAttempt		time.Time `json:"next_attempt"`
+	LastError		string	`json:"last_error" sql:"type:varchar(1024)"`
+	CreatedOn		time.Time `json:"created_on" sql:"not null"`
+	SentOn			*time.Time `json:"sent_on"`
+}
@@ -1,12 +1,12 @@
 package models
 
 import (
+	"strconv"
 	"github.com/jinzhu/gorm"
 	"github.com/YagoCarballo/kumquat-academy-api/database"
 	"database/sql"
 	"time"
 	"fmt"
-	"strings"
 )
 
 type ModulesModel struct {}
@@ -129,13 +129,13 @@ func (model ModulesModel) FindModulesForUser(username string) ([]OutputModule, e
 			Select(`
 				modules.id, level_modules.level, classes.course_id, level_modules.class_id, level_modules.code,
 				modules.title, modules.description, modules.color, modules.icon, modules.duration, level_modules.status,
-				moduleRole.id 'roleId',
-				moduleRole.name 'roleName',
-				moduleRole.description 'roleDescription',
-				(moduleRole.can_read + coalesce(courseRole.can_read, 0) != 0) 'read',
-				(moduleRole.can_write + coalesce(courseRole.can_write, 0) != 0) 'write',
-				(moduleRole.can_delete + coalesce(courseRole.can_delete, 0) != 0) 'delete',
-				(moduleRole.can_update + coalesce(courseRole.can_update, 0) != 0) 'update',
+				moduleRole.id as role_id,
+				moduleRole.name as role_name,
+				moduleRole.description as role_description,
+				(moduleRole.can_read or coalesce(courseRole.can_read, false)) as can_read,
+				(moduleRole.can_write or coalesce(courseRole.can_write, false)) as can_write,
+				(moduleRole.can_delete or coalesce(courseRole.can_delete, false)) as can_delete,
+				(moduleRole.can_update or coalesce(courseRole.can_update, false)) as can_update,
 				users.admin,
 				classes.start,
 				classes.end,
@@ -149,19 +149,19 @@ func (model ModulesModel) FindModulesForUser(username string) ([]OutputModule, e
 				inner join courses as course on course.id = classes.course_id
 				left outer join user_courses on user_courses.course_id = course.id and user_courses.user_id = user_modules.user_id
 				left outer join roles as courseRole on courseRole.id = user_courses.role_id
-			`).Where("users.username = ? OR users.admin = 1", username).Rows()
+			`).Where("users.username = ? OR users.admin = ?", username, true).Rows()
 	} else {
 		rows, err = model.DB().Table("level_modules").
 		Select(`  modules.id, level_modules.level, classes.course_id, level_modules.class_id, level_modules.code,
 			  modules.title, modules.description, modules.color, modules.icon, modules.duration, level_modules.status,
-			  0 'roleId',
-			  'Admin' as roleName,
-			  'Admin of a module / course.' as roleDescription,
-			  0 'read',
-			  0 'write',
-			  0 'delete',
-			  0 'update',
-			  1 'admin',
+			  0 as role_id,
+			  'Admin' as role_name,
+			  'Admin of a module / course.' as role_description,
+			  false as can_read,
+			  false as can_write,
+			  false as can_delete,
+			  false as can_update,
+			  true as admin,
 			  classes.start,
 			  classes.end,
 			  course.id, course.title, course.description
@@ -175,6 +175,7 @@ func (model ModulesModel) FindModulesForUser(username string) ([]OutputModule, e
 	if err != nil {
 		return modules, err
 	}
+	defer rows.Close()
 
 	for rows.Next() {
 		module := OutputModule{}
@@ -214,7 +215,10 @@ func (model ModulesModel) FindModulesForUser(username string) ([]OutputModule, e
 func (model ModulesModel) FindRawModules (query string, page int) ([]Module, error) {
 	modules := []Module{}
 
-	dbQuery := model.DB().Limit(10).Offset(page * 10).Where("modules.title like ? or id = ?", fmt.Sprint("%", query, "%"), query).Find(&modules)
+	// Only numeric queries can match an id (Postgres won't compare an integer column with text)
+	id, _ := strconv.ParseUint(query, 10, 32)
+
+	dbQuery := model.DB().Limit(10).Offset(page * 10).Where("modules.title like ? or id = ?", fmt.Sprint("%", query, "%"), id).Find(&modules)
 	if dbQuery.Error != nil {
 		return modules, dbQuery.Error
 	}
@@ -245,6 +249,7 @@ func (model ModulesModel) GetLevelModel(classId, level, moduleId uint32) (*Outpu
 	if err != nil {
 		return module, err
 	}
+	defer rows.Close()
 
 	// Read only one
 	rows.Next()
@@ -299,6 +304,7 @@ func (model ModulesModel) FindModulesForLevel(classId, level uint32) ([]OutputMo
 	if err != nil {
 		return modules, err
 	}
+	defer rows.Close()
 
 	for rows.Next() {
 		module := OutputModule{}
@@ -387,7 +393,7 @@ func (model ModulesModel) AddStudentToModule(moduleCode string, userId uint32) (
 
 	query := model.DB().Create(&userModule)
 	if query.Error != nil {
-		isDuplicated := strings.HasPrefix(query.Error.Error(), "Error 1062")
+		isDuplicated := database.IsDuplicatedError(query.Error)
 		if isDuplicated {
 			return nil, nil
 		}
@@ -410,3 +416,60 @@ func (model ModulesModel) RemoveStudentFromModule(moduleCode string, userId uint
 
 	return query.RowsAffected, nil
 }
+
+// Moves a module to a new status, failing with an InvalidTransitionError if it can't be reached from the current one
+func (model ModulesModel) SetModuleStatus(code string, status ModuleStatus) (*LevelModule, error) {
+	levelModule, err := model.FindModuleWithCode(code)
+	if err != nil || levelModule == nil {
+		return nil, err
+	}
+
+	if !levelModule.Status.CanTransitionTo(status) {
+		return nil, InvalidTransitionError{ From: string(levelModule.Status), To: string(status) }
+	}
+
+	query := model.DB().Table("level_modules").
+					Where("code = ? and class_id = ?", levelModule.Code, levelModule.ClassID).
+					Update("status", status)
+	if query.Error != nil {
+		return nil, query.Error
+	}
+
+	levelModule.Status = status
+	return levelModule, nil
+}
+
+// Starts the future modules that have begun and ends the ongoing ones that have run for their whole duration,
+// returns the number of modules started and ended
+func (model ModulesModel) UpdateScheduledStatuses(now time.Time) (int64, int64, error) {
+	query := model.DB().Table("level_modules").
+					Where("status = ? and start <= ?", ModuleFuture, now).
+					Update("status", ModuleOngoing)
+	if query.Error != nil {
+		return 0, 0, query.Error
+	}
+	started := query.RowsAffected
+
+	var levelModules []LevelModule
+	query = model.DB().Preload("Module").Find(&levelModules, "status = ?", ModuleOngoing)
+	if query.Error != nil {
+		return started, 0, query.Error
+	}
+
+	var ended int64
+	for _, levelModule := range levelModules {
+		if levelModule.Module == nil || !now.After(levelModule.Start.AddDate(0, 0, int(levelModule.Module.Duration * 7))) {
+			continue
+		}
+
+		query = model.DB().Table("level_modules").
+						Where("code = ? and class_id = ? and status = ?", levelModule.Code, levelModule.ClassID, ModuleOngoing).
+						Update("status", ModuleEnded)
+		if query.Error != nil {
+			return started, ended, query.Error
+		}
+		ended += query.RowsAffected
+	}
+
+	return started, ended, nil
+}
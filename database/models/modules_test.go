@@ -2,6 +2,7 @@ package models
 
 import (
 	"testing"
+	"time"
 
 	. "github.com/franela/goblin"
 )
@@ -195,3 +196,46 @@ func Test_Database_Modules(t *testing.T) {
 		})
 	})
 }
+
+func Test_Database_ModuleStatus(t *testing.T) {
+	g := Goblin(t)
+
+	g.Describe("When changing the status of a module", func() {
+		g.It("Should reject transitions outside of the state machine", func() {
+			_, err := DBModule.SetModuleStatus("AC21009", ModuleEnded)
+			g.Assert(err).Equal(InvalidTransitionError{ From: "future", To: "ended" })
+
+			module, err := DBModule.SetModuleStatus("-missing-", ModuleOngoing)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(module == nil).IsTrue()
+		})
+
+		g.It("Should start the modules once they begin", func() {
+			started, _, err := DBModule.UpdateScheduledStatuses(time.Date(2015, time.September, 2, 0, 0, 0, 0, time.UTC))
+			g.Assert(err == nil).IsTrue()
+			g.Assert(started).Equal(int64(1))
+
+			module, _ := DBModule.FindModuleWithCode("AC21009")
+			g.Assert(module.Status).Equal(ModuleOngoing)
+		})
+
+		g.It("Should end the modules after their duration", func() {
+			// Twelve weeks have passed, but the 24 week module is still going
+			_, ended, err := DBModule.UpdateScheduledStatuses(time.Date(2015, time.December, 1, 0, 0, 0, 0, time.UTC))
+			g.Assert(err == nil).IsTrue()
+			g.Assert(ended).Equal(int64(3))
+
+			module, _ := DBModule.FindModuleWithCode("AC22001")
+			g.Assert(module.Status).Equal(ModuleOngoing)
+
+			module, err = DBModule.SetModuleStatus("AC31007", ModuleOngoing)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(module.Status).Equal(ModuleOngoing)
+		})
+
+		g.After(func() {
+			DBModule.DB().Table("level_modules").Where("code in (?)", []string{ "AC51001", "AC31007" }).Update("status", ModuleOngoing)
+			DBModule.DB().Table("level_modules").Where("code = ?", "AC21009").Update("status", ModuleFuture)
+		})
+	})
+}
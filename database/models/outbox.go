@@ -0,0 +1,138 @@
+package models
+
+import (
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/YagoCarballo/kumquat-academy-api/database"
+)
+
+// How long an email stays claimed, if the server stops while sending it the email is picked up again after this
+const EMAIL_SENDING_TIMEOUT = 10 * time.Minute
+
+type OutboxModel struct{}
+var DBOutbox OutboxModel
+
+func (model OutboxModel) DB() *gorm.DB {
+	return database.DB
+}
+
+// Stores an email to be sent as soon as possible
+func (model OutboxModel) QueueEmail(email OutboxEmail) (*OutboxEmail, error) {
+	email.Status = EmailPending
+	email.Attempts = 0
+	email.CreatedOn = time.Now()
+	email.NextAttempt = email.CreatedOn
+
+	query := model.DB().Create(&email)
+	if query.Error != nil {
+		return nil, query.Error
+	}
+
+	return &email, nil
+}
+
+func (model OutboxModel) ReadEmail(id uint32) (*OutboxEmail, error) {
+	var email OutboxEmail
+
+	query := model.DB().First(&email, "id = ?", id)
+	if query.Error != nil {
+		// If no Records found, return NIL otherwise return the error
+		switch query.Error {
+		case gorm.ErrRecordNotFound:
+			return nil, nil
+		default:
+			return nil, query.Error
+		}
+	}
+
+	return &email, nil
+}
+
+// Claims the emails that are due to be sent (up to the limit), each email is only claimed once
+// so concurrent deliveries don't send it twice. Emails claimed but never finished are claimed again after a timeout.
+func (model OutboxModel) ClaimDueEmails(now time.Time, limit int) ([]OutboxEmail, error) {
+	claimed := []OutboxEmail{}
+
+	var emails []OutboxEmail
+	query := model.DB().Order("next_attempt, id").Limit(limit).
+		Find(&emails, "status in (?) and next_attempt <= ?", []EmailStatus{EmailPending, EmailSending}, now)
+	if query.Error != nil {
+		return nil, query.Error
+	}
+
+	for _, email := range emails {
+		lease := now.Add(EMAIL_SENDING_TIMEOUT)
+		query = model.DB().Model(OutboxEmail{}).Where("id = ? and status = ? and attempts = ?", email.ID, email.Status, email.Attempts).
+			Updates(map[string]interface{}{
+				"status": EmailSending,
+				"attempts": email.Attempts + 1,
+				"next_attempt": lease,
+			})
+		if query.Error != nil {
+			return nil, query.Error
+		}
+
+		// Someone else claimed it first
+		if query.RowsAffected != 1 {
+			continue
+		}
+
+		email.Status = EmailSending
+		email.Attempts++
+		email.NextAttempt = lease
+		claimed = append(claimed, email)
+	}
+
+	return claimed, nil
+}
+
+func (model OutboxModel) MarkEmailSent(id uint32, now time.Time) error {
+	return model.DB().Model(OutboxEmail{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status": EmailSent,
+		"sent_on": now,
+		"last_error": "",
+	}).Error
+}
+
+// Stores why an email couldn't be sent, it is tried again on the given time or given up on if there is no retry
+func (model OutboxModel) MarkEmailFailed(id uint32, sendError error, retryOn *time.Time) error {
+	lastError := sendError.Error()
+	if len(lastError) > 1024 {
+		lastError = lastError[:1024]
+	}
+
+	changes := map[string]interface{}{
+		"status": EmailFailed,
+		"last_error": lastError,
+	}
+
+	if retryOn != nil {
+		changes["status"] = EmailPending
+		changes["next_attempt"] = *retryOn
+	}
+
+	return model.DB().Model(OutboxEmail{}).Where("id = ?", id).Updates(changes).Error
+}
+
+// Gets the emails with a status, the newest first
+func (model OutboxModel) FindEmails(status EmailStatus) ([]OutboxEmail, error) {
+	emails := []OutboxEmail{}
+
+	query := model.DB().Order("created_on desc, id desc").Find(&emails, "status = ?", status)
+	if query.Error != nil {
+		return nil, query.Error
+	}
+
+	return emails, nil
+}
+
+// Removes the emails sent before the given time
+func (model OutboxModel) RemoveSentEmails(before time.Time) (int64, error) {
+	query := model.DB().Where("status = ? and sent_on < ?", EmailSent, before).Delete(OutboxEmail{})
+	if query.Error != nil {
+		return 0, query.Error
+	}
+
+	return query.RowsAffected, nil
+}
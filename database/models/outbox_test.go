@@ -0,0 +1,57 @@
+package models
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/franela/goblin"
+)
+
+func Test_Database_Outbox(t *testing.T) {
+	g := Goblin(t)
+	var emailId uint32
+
+	g.Describe("When using the email outbox", func() {
+		g.It("Should queue an email as pending", func() {
+			email, err := DBOutbox.QueueEmail(OutboxEmail{ Recipient: "student@kumquat.academy", Subject: "Hello", Text: "Hi!" })
+
+			g.Assert(err == nil).IsTrue()
+			g.Assert(email.Status).Equal(EmailPending)
+			emailId = email.ID
+		})
+
+		g.It("Should claim a due email only once", func() {
+			emails, err := DBOutbox.ClaimDueEmails(time.Now(), 10)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(len(emails)).Equal(1)
+			g.Assert(emails[0].Attempts).Equal(1)
+
+			emails, err = DBOutbox.ClaimDueEmails(time.Now(), 10)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(len(emails)).Equal(0)
+		})
+
+		g.It("Should claim it again if the sending never finished", func() {
+			emails, _ := DBOutbox.ClaimDueEmails(time.Now().Add(EMAIL_SENDING_TIMEOUT + time.Minute), 10)
+			g.Assert(len(emails)).Equal(1)
+			g.Assert(emails[0].Attempts).Equal(2)
+		})
+
+		g.It("Should give up on an email without retries", func() {
+			err := DBOutbox.MarkEmailFailed(emailId, errors.New("Mailbox not found."), nil)
+			g.Assert(err == nil).IsTrue()
+
+			email, _ := DBOutbox.ReadEmail(emailId)
+			g.Assert(email.Status).Equal(EmailFailed)
+			g.Assert(email.LastError).Equal("Mailbox not found.")
+
+			emails, _ := DBOutbox.ClaimDueEmails(time.Now().Add(time.Hour), 10)
+			g.Assert(len(emails)).Equal(0)
+		})
+
+		g.After(func() {
+			DBOutbox.DB().Where("id = ?", emailId).Delete(OutboxEmail{})
+		})
+	})
+}
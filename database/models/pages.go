@@ -0,0 +1,264 @@
+package models
+
+import (
+	"errors"
+
+	"github.com/jinzhu/gorm"
+	"github.com/YagoCarballo/kumquat-academy-api/database"
+)
+
+type PagesModel struct{}
+var DBPages PagesModel
+
+var ErrInvalidParentPage = errors.New("A page can't be moved inside itself or one of its children.")
+
+func (model PagesModel) DB() *gorm.DB {
+	return database.DB
+}
+
+// Creates a page along with its first revision, pages without a position are added at the end of their parent
+func (model PagesModel) CreatePage(page Page, userId uint32) (*Page, error) {
+	if page.Position == 0 {
+		position, err := model.nextPosition(page.ModuleID, page.ParentID)
+		if err != nil {
+			return nil, err
+		}
+
+		page.Position = position
+	}
+
+	tx := model.DB().Begin()
+
+	query := tx.Create(&page)
+	if query.Error != nil {
+		tx.Rollback()
+		return nil, query.Error
+	}
+
+	query = tx.Create(&PageRevision{ PageID: page.ID, UserID: userId, Title: page.Title, Content: page.Content })
+	if query.Error != nil {
+		tx.Rollback()
+		return nil, query.Error
+	}
+
+	query = tx.Commit()
+	if query.Error != nil {
+		return nil, query.Error
+	}
+
+	return &page, nil
+}
+
+func (model PagesModel) ReadPage(moduleId, id uint32) (*Page, error) {
+	var page Page
+
+	query := model.DB().First(&page, "id = ? and module_id = ?", id, moduleId)
+	if query.Error != nil {
+		// If no Records found, return NIL otherwise return the error
+		switch query.Error {
+		case gorm.ErrRecordNotFound:
+			return nil, nil
+		default:
+			return nil, query.Error
+		}
+	}
+
+	return &page, nil
+}
+
+// Updates the title and content of a page, keeping the previous version as a revision
+func (model PagesModel) UpdatePage(moduleId, id uint32, page Page, userId uint32) (*Page, error) {
+	dbPage, err := model.ReadPage(moduleId, id)
+	if err != nil || dbPage == nil {
+		return nil, err
+	}
+
+	tx := model.DB().Begin()
+
+	query := tx.Table("pages").Where("id = ? and module_id = ?", id, moduleId).Updates(map[string]interface{}{
+		"title": page.Title,
+		"content": page.Content,
+	})
+	if query.Error != nil {
+		tx.Rollback()
+		return nil, query.Error
+	}
+
+	query = tx.Create(&PageRevision{ PageID: id, UserID: userId, Title: page.Title, Content: page.Content })
+	if query.Error != nil {
+		tx.Rollback()
+		return nil, query.Error
+	}
+
+	query = tx.Commit()
+	if query.Error != nil {
+		return nil, query.Error
+	}
+
+	return model.ReadPage(moduleId, id)
+}
+
+// Moves a page under another page (or to the top level when parentId is nil) at the given position
+func (model PagesModel) MovePage(moduleId, id uint32, parentId *uint32, position uint32) (*Page, error) {
+	dbPage, err := model.ReadPage(moduleId, id)
+	if err != nil || dbPage == nil {
+		return nil, err
+	}
+
+	// Walk up from the new parent to make sure the page is not moved inside itself
+	for ancestorId := parentId; ancestorId != nil; {
+		if *ancestorId == id {
+			return nil, ErrInvalidParentPage
+		}
+
+		ancestor, err := model.ReadPage(moduleId, *ancestorId)
+		if err != nil {
+			return nil, err
+		}
+
+		if ancestor == nil {
+			return nil, ErrInvalidParentPage
+		}
+
+		ancestorId = ancestor.ParentID
+	}
+
+	if position == 0 {
+		position, err = model.nextPosition(moduleId, parentId)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	query := model.DB().Table("pages").Where("id = ? and module_id = ?", id, moduleId).Updates(map[string]interface{}{
+		"parent_id": parentId,
+		"position": position,
+	})
+	if query.Error != nil {
+		return nil, query.Error
+	}
+
+	return model.ReadPage(moduleId, id)
+}
+
+// Deletes a page with its revisions, its children are moved up to its parent
+func (model PagesModel) DeletePage(moduleId, id uint32) (int64, error) {
+	dbPage, err := model.ReadPage(moduleId, id)
+	if err != nil || dbPage == nil {
+		return 0, err
+	}
+
+	tx := model.DB().Begin()
+
+	query := tx.Table("pages").Where("parent_id = ? and module_id = ?", id, moduleId).Update("parent_id", dbPage.ParentID)
+	if query.Error != nil {
+		tx.Rollback()
+		return 0, query.Error
+	}
+
+	query = tx.Where("page_id = ?", id).Delete(PageRevision{})
+	if query.Error != nil {
+		tx.Rollback()
+		return 0, query.Error
+	}
+
+	query = tx.Table("pages").Where("id = ? and module_id = ?", id, moduleId).Delete(Page{})
+	if query.Error != nil {
+		tx.Rollback()
+		return 0, query.Error
+	}
+
+	count := query.RowsAffected
+	query = tx.Commit()
+	if query.Error != nil {
+		return 0, query.Error
+	}
+
+	return count, nil
+}
+
+// Builds the table of contents of a module, nesting each page inside its parent
+func (model PagesModel) FindTableOfContents(moduleId uint32) ([]*Page, error) {
+	pages := []*Page{}
+
+	query := model.DB().
+		Select("id, module_id, title, parent_id, position, created_at, updated_at").
+		Order("position, id").
+		Find(&pages, "module_id = ?", moduleId)
+	if query.Error != nil {
+		return nil, query.Error
+	}
+
+	pagesById := map[uint32]*Page{}
+	for _, page := range pages {
+		pagesById[page.ID] = page
+	}
+
+	contents := []*Page{}
+	for _, page := range pages {
+		if page.ParentID != nil {
+			if parent, ok := pagesById[*page.ParentID]; ok {
+				parent.Children = append(parent.Children, page)
+				continue
+			}
+		}
+
+		contents = append(contents, page)
+	}
+
+	return contents, nil
+}
+
+func (model PagesModel) FindRevisions(pageId uint32) ([]PageRevision, error) {
+	revisions := []PageRevision{}
+
+	query := model.DB().
+		Select("id, page_id, user_id, title, created_at").
+		Preload("User").
+		Order("id desc").
+		Find(&revisions, "page_id = ?", pageId)
+	if query.Error != nil {
+		return nil, query.Error
+	}
+
+	return revisions, nil
+}
+
+func (model PagesModel) ReadRevision(pageId, id uint32) (*PageRevision, error) {
+	var revision PageRevision
+
+	query := model.DB().Preload("User").First(&revision, "id = ? and page_id = ?", id, pageId)
+	if query.Error != nil {
+		// If no Records found, return NIL otherwise return the error
+		switch query.Error {
+		case gorm.ErrRecordNotFound:
+			return nil, nil
+		default:
+			return nil, query.Error
+		}
+	}
+
+	return &revision, nil
+}
+
+func (model PagesModel) nextPosition(moduleId uint32, parentId *uint32) (uint32, error) {
+	var positions []uint32
+
+	query := model.DB().Table("pages").Where("module_id = ?", moduleId)
+	if parentId != nil {
+		query = query.Where("parent_id = ?", *parentId)
+	} else {
+		query = query.Where("parent_id is null")
+	}
+
+	query = query.Order("position desc").Limit(1).Pluck("position", &positions)
+	if query.Error != nil {
+		return 0, query.Error
+	}
+
+	if len(positions) <= 0 {
+		return 1, nil
+	}
+
+	return positions[0] + 1, nil
+}
@@ -0,0 +1,78 @@
+package models
+
+import (
+	"testing"
+
+	. "github.com/franela/goblin"
+)
+
+func Test_Database_Pages(t *testing.T) {
+	g := Goblin(t)
+	var introId, setupId uint32
+
+	g.Describe("When writing the wiki of a module", func() {
+		g.It("Should be able to create pages", func() {
+			page, err := DBPages.CreatePage(Page{ ModuleID: 1, Title: "Introduction", Content: "# Welcome" }, 2)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(page.Position).Equal(uint32(1))
+			introId = page.ID
+
+			page, err = DBPages.CreatePage(Page{ ModuleID: 1, Title: "Setup", Content: "Install Java\nInstall Hadoop" }, 2)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(page.Position).Equal(uint32(2))
+			setupId = page.ID
+		})
+
+		g.It("Should not read a page from another module", func() {
+			page, err := DBPages.ReadPage(2, introId)
+
+			g.Assert(err == nil).IsTrue()
+			g.Assert(page == nil).IsTrue()
+		})
+
+		g.It("Should keep a revision for every change", func() {
+			page, err := DBPages.UpdatePage(1, setupId, Page{ Title: "Setup", Content: "Install Java 8\nInstall Hadoop" }, 2)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(page.Content).Equal("Install Java 8\nInstall Hadoop")
+
+			revisions, err := DBPages.FindRevisions(setupId)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(len(revisions)).Equal(2)
+
+			first, err := DBPages.ReadRevision(setupId, revisions[1].ID)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(first.Content).Equal("Install Java\nInstall Hadoop")
+		})
+
+		g.It("Should nest pages into a table of contents", func() {
+			page, err := DBPages.MovePage(1, setupId, &introId, 0)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(*page.ParentID).Equal(introId)
+
+			contents, err := DBPages.FindTableOfContents(1)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(len(contents)).Equal(1)
+			g.Assert(len(contents[0].Children)).Equal(1)
+			g.Assert(contents[0].Children[0].ID).Equal(setupId)
+		})
+
+		g.It("Should not move a page inside one of its children", func() {
+			_, err := DBPages.MovePage(1, introId, &setupId, 0)
+
+			g.Assert(err).Equal(ErrInvalidParentPage)
+		})
+
+		g.It("Should move the children up when deleting a page", func() {
+			count, err := DBPages.DeletePage(1, introId)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(count == 1).IsTrue()
+
+			page, _ := DBPages.ReadPage(1, setupId)
+			g.Assert(page.ParentID == nil).IsTrue()
+
+			count, err = DBPages.DeletePage(1, setupId)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(count == 1).IsTrue()
+		})
+	})
+}
@@ -1,6 +1,8 @@
 package models
 
 import (
+	"time"
+
 	"github.com/jinzhu/gorm"
 	"github.com/YagoCarballo/kumquat-academy-api/database"
 	. "github.com/YagoCarballo/kumquat-academy-api/constants"
@@ -20,6 +22,8 @@ type (
 		Write			bool `json:"write"`
 		Delete			bool `json:"delete"`
 		Update			bool `json:"update"`
+		CourseRoleId	uint32 `json:"-"`
+		Capabilities	[]AccessRight `json:"capabilities"`
 	}
 )
 
@@ -29,13 +33,33 @@ func (model PermissionsModel) DB () *gorm.DB {
 	return database.DB
 }
 
-func (model PermissionsModel) IsActionPermittedOnModule(userId uint32, id interface{}, action AccessRight) bool {
-	moduleId := uint32(id.(uint32))
-	permissions, err := model.GetPermissionsForModule(&userId, &moduleId, nil)
-	if err != nil {
-		return false
+// Checks an action on any kind of resource, modules can be given by their code or ID
+func (model PermissionsModel) IsActionPermitted(userId uint32, resource ResourceType, id interface{}, action AccessRight) bool {
+	switch resource {
+	case ModuleResource:
+		if _, isCode := id.(string); isCode {
+			return model.IsActionPermittedOnModuleWithCode(userId, id, action)
+		}
+		return model.IsActionPermittedOnModule(userId, id, action)
+
+	case CourseResource:
+		return model.IsActionPermittedOnCourse(userId, id, action)
+
+	case AnnouncementResource:
+		return model.IsActionPermittedOnAnnouncement(userId, id, action)
+
+	case AttachmentResource:
+		return model.IsActionPermittedOnAttachment(userId, id, action)
+
+	case AdminResource:
+		return model.IsAdmin(userId)
 	}
 
+	return false
+}
+
+// Admins can do anything, the rest need the right in their role
+func (permissions *PermissionsTable) Allows(action AccessRight) bool {
 	if permissions.Admin {
 		return true
 	}
@@ -51,9 +75,25 @@ func (model PermissionsModel) IsActionPermittedOnModule(userId uint32, id interf
 		return permissions.Update
 	}
 
+	for _, capability := range permissions.Capabilities {
+		if capability == action {
+			return true
+		}
+	}
+
 	return false
 }
 
+func (model PermissionsModel) IsActionPermittedOnModule(userId uint32, id interface{}, action AccessRight) bool {
+	moduleId := uint32(id.(uint32))
+	permissions, err := model.GetPermissionsForModule(&userId, &moduleId, nil)
+	if err != nil {
+		return false
+	}
+
+	return permissions.Allows(action)
+}
+
 func (model PermissionsModel) IsActionPermittedOnModuleWithCode(userId uint32, code interface{}, action AccessRight) bool {
 	var moduleId uint32
 	moduleCode := code.(string)
@@ -62,48 +102,124 @@ func (model PermissionsModel) IsActionPermittedOnModuleWithCode(userId uint32, c
 		return false
 	}
 
-	if permissions.Admin {
-		return true
+	return permissions.Allows(action)
+}
+
+func (model PermissionsModel) IsActionPermittedOnCourse(userId uint32, courseId interface{}, action AccessRight) bool {
+	permissions, err := model.GetPermissionsForCourse(userId, courseId.(uint32))
+	if err != nil {
+		return false
 	}
 
-	switch action {
-	case ReadPermission:
-		return permissions.Read
-	case WritePermission:
-		return permissions.Write
-	case DeletePermission:
-		return permissions.Delete
-	case UpdatePermission:
-		return permissions.Update
+	return permissions.Allows(action)
+}
+
+// Checks the action on the module, course or assignment the announcement was posted to
+func (model PermissionsModel) IsActionPermittedOnAnnouncement(userId uint32, id interface{}, action AccessRight) bool {
+	announcement, err := DBAnnouncements.ReadAnnouncement(id.(uint32))
+	if err != nil || announcement == nil {
+		return false
+	}
+
+	switch {
+	case announcement.CourseID != nil:
+		return model.IsActionPermittedOnCourse(userId, *announcement.CourseID, action)
+
+	case announcement.ModuleCode != nil:
+		return model.IsActionPermittedOnModuleWithCode(userId, *announcement.ModuleCode, action)
+
+	case announcement.AssignmentID != nil:
+		assignment, err := DBAssignments.ReadAssignment(*announcement.AssignmentID)
+		if err != nil || assignment == nil {
+			return false
+		}
+
+		return model.IsActionPermittedOnModuleWithCode(userId, assignment.ModuleCode, action)
 	}
 
 	return false
 }
 
-func (model PermissionsModel) IsActionPermittedOnCourse(userId uint32, courseId interface{}, action AccessRight) bool {
-	permissions, err := model.GetPermissionsForCourse(userId, courseId.(uint32))
+// Checks the action on everything the attachment belongs to, being allowed on one of them is enough.
+// Attachments that don't belong to anything yet are only for admins.
+func (model PermissionsModel) IsActionPermittedOnAttachment(userId uint32, name interface{}, action AccessRight) bool {
+	if model.IsAdmin(userId) {
+		return true
+	}
+
+	attachment, err := DBAttachment.FindAttachment(name.(string))
+	if err != nil || attachment == nil {
+		return false
+	}
+
+	owners, err := DBAttachment.FindAttachmentOwners(attachment.ID)
 	if err != nil {
 		return false
 	}
 
-	if permissions.Admin {
-		return true
+	// Until it's added somewhere, the file can still be read by who uploaded it
+	if len(owners) <= 0 && action == ReadPermission {
+		return attachment.UserID != nil && *attachment.UserID == userId
 	}
 
-	switch action {
-	case ReadPermission:
-		return permissions.Read
-	case WritePermission:
-		return permissions.Write
-	case DeletePermission:
-		return permissions.Delete
-	case UpdatePermission:
-		return permissions.Update
+	for _, owner := range owners {
+		if model.isActionPermittedOnOwner(userId, owner, action) {
+			return true
+		}
 	}
 
 	return false
 }
 
+// Students can read their own submissions (and their feedback once the grades are returned),
+// the rest need to be able to download the submissions of the module
+func (model PermissionsModel) isActionPermittedOnOwner(userId uint32, owner AttachmentOwner, action AccessRight) bool {
+	switch owner.Type {
+	case AnnouncementOwner:
+		return model.IsActionPermittedOnAnnouncement(userId, owner.ID, action)
+
+	case LectureOwner, MaterialOwner:
+		return model.IsActionPermittedOnModule(userId, owner.ModuleID, action)
+
+	case AvatarOwner:
+		return action == ReadPermission || owner.UserID == userId
+
+	case SubmissionOwner, FeedbackOwner:
+		if action != ReadPermission {
+			return model.IsActionPermittedOnModuleWithCode(userId, owner.ModuleCode, action)
+		}
+
+		if model.isSubmissionOf(userId, owner) {
+			if owner.Type == SubmissionOwner {
+				return true
+			}
+
+			assignment, err := DBAssignments.ReadAssignment(owner.AssignmentID)
+			if err == nil && assignment != nil && assignment.Status == AssignmentReturned {
+				return true
+			}
+		}
+
+		return model.IsActionPermittedOnModuleWithCode(userId, owner.ModuleCode, DownloadSubmissionPermission)
+	}
+
+	return model.IsActionPermittedOnModuleWithCode(userId, owner.ModuleCode, action)
+}
+
+// The submission was sent by the user, or by a member of their team
+func (model PermissionsModel) isSubmissionOf(userId uint32, owner AttachmentOwner) bool {
+	if owner.UserID == userId {
+		return true
+	}
+
+	if owner.TeamID == 0 {
+		return false
+	}
+
+	team, err := DBTeams.FindTeamForUser(owner.AssignmentID, userId)
+	return err == nil && team != nil && team.ID == owner.TeamID
+}
+
 func (model PermissionsModel) IsAdmin(userId uint32) bool {
 	var isAdmin []bool
 
@@ -132,28 +248,42 @@ func (model PermissionsModel) CanUserSubmitAssignment(username string, assignmen
 	var canSubmit []uint32
 
 	query := model.DB().Table("users").Select("assignments.id").Joins(
-		"inner join user_modules on user_modules.`user_id` = users.id " +
-		"left outer join assignments on assignments.`module_code` = user_modules.`module_code` ",
+		"inner join user_modules on user_modules.user_id = users.id " +
+		"left outer join assignments on assignments.module_code = user_modules.module_code",
 	).Where(
-		"users.username = ? and assignments.status = ? and assignments.id = ?",
+		"users.username = ? and assignments.status = ? and assignments.id = ? and " +
+		"(select count(*) from submissions " +
+		"where submissions.status != ? " +
+		"and submissions.assignment_id = assignments.id " +
+		"and submissions.user_id = users.id) = 0",
 		username,
 		AssignmentAvailable,
 		assignmentId,
-	).Having(
-		"(select count(*) from submissions " +
-		"where submissions.`status` != 'canceled' " +
-		"and submissions.`assignment_id` = assignments.`id` " +
-		"and submissions.`user_id` = users.`id`) = 0",
+		SubmissionCanceled,
 	).Pluck("assignments.id", &canSubmit)
 
 	// If there are any errors or no results, then the studen't cannot submit the assignment
 	if query.Error != nil || len(canSubmit) <= 0 {
 		return false
+	}
 
-	// If there are results, then the student can submit the assignment
-	} else {
-		return true
+	// Submissions are accepted until the late window after the deadline (or the extension of the student) closes
+	user, err := DBUser.FindUser(username)
+	if err != nil || user == nil {
+		return false
+	}
+
+	assignment, err := DBAssignments.ReadAssignment(assignmentId)
+	if err != nil || assignment == nil {
+		return false
+	}
+
+	deadline, err := DBAssignments.FindDeadline(assignment, user.ID)
+	if err != nil {
+		return false
 	}
+
+	return !time.Now().After(LateWindowEnd(assignment, deadline))
 }
 
 func (model PermissionsModel) GetPermissionsForModule (userId, moduleId *uint32, code *string) (*PermissionsTable, error) {
@@ -173,13 +303,14 @@ func (model PermissionsModel) GetPermissionsForModule (userId, moduleId *uint32,
 			level_modules.code,
 			level_modules.module_id,
 			users.admin,
-			moduleRole.id 'role_id',
-			moduleRole.name 'role_name',
-			moduleRole.description 'role_description',
-			(moduleRole.can_read + coalesce(courseRole.can_read, 0) != 0) 'read',
-			(moduleRole.can_write + coalesce(courseRole.can_write, 0) != 0) 'write',
-			(moduleRole.can_delete + coalesce(courseRole.can_delete, 0) != 0) 'delete',
-			(moduleRole.can_update + coalesce(courseRole.can_update, 0) != 0) 'update'
+			moduleRole.id as role_id,
+			moduleRole.name as role_name,
+			moduleRole.description as role_description,
+			(moduleRole.can_read or coalesce(courseRole.can_read, false)) as can_read,
+			(moduleRole.can_write or coalesce(courseRole.can_write, false)) as can_write,
+			(moduleRole.can_delete or coalesce(courseRole.can_delete, false)) as can_delete,
+			(moduleRole.can_update or coalesce(courseRole.can_update, false)) as can_update,
+			coalesce(courseRole.id, 0) as course_role_id
 		`).Joins(`
 			inner join users on users.id = user_modules.user_id
 			inner join level_modules on level_modules.code = user_modules.module_code
@@ -194,6 +325,7 @@ func (model PermissionsModel) GetPermissionsForModule (userId, moduleId *uint32,
 	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
 
 	for rows.Next() {
 		permission := PermissionsTable{}
@@ -209,6 +341,7 @@ func (model PermissionsModel) GetPermissionsForModule (userId, moduleId *uint32,
 			&permission.Write,
 			&permission.Delete,
 			&permission.Update,
+			&permission.CourseRoleId,
 		)
 
 		if err != nil {
@@ -227,6 +360,12 @@ func (model PermissionsModel) GetPermissionsForModule (userId, moduleId *uint32,
 		}, nil
 	}
 
+	// The capabilities of the roles on the module and on its course are merged too
+	permissions[0].Capabilities, err = model.findCapabilities(permissions[0].RoleId, permissions[0].CourseRoleId)
+	if err != nil {
+		return nil, err
+	}
+
 	return &permissions[0], nil
 }
 
@@ -236,13 +375,13 @@ func (model PermissionsModel) GetPermissionsForCourse (userId, courseId uint32)
 	rows, err := model.DB().Table("user_courses").
 	Select(`
 			users.admin,
-			courseRole.id 'role_id',
-			courseRole.name 'role_name',
-			courseRole.description 'role_description',
-			(courseRole.can_read != 0) 'read',
-			(courseRole.can_write != 0) 'write',
-			(courseRole.can_delete != 0) 'delete',
-			(courseRole.can_update != 0) 'update'
+			courseRole.id as role_id,
+			courseRole.name as role_name,
+			courseRole.description as role_description,
+			courseRole.can_read as can_read,
+			courseRole.can_write as can_write,
+			courseRole.can_delete as can_delete,
+			courseRole.can_update as can_update
 		`).Joins(`
 			inner join users on users.id = user_courses.user_id
 			inner join roles as courseRole on courseRole.id = user_courses.role_id
@@ -252,6 +391,7 @@ func (model PermissionsModel) GetPermissionsForCourse (userId, courseId uint32)
 	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
 
 	for rows.Next() {
 		permission := PermissionsTable{}
@@ -282,5 +422,36 @@ func (model PermissionsModel) GetPermissionsForCourse (userId, courseId uint32)
 		}, nil
 	}
 
+	permissions[0].Capabilities, err = model.findCapabilities(permissions[0].RoleId)
+	if err != nil {
+		return nil, err
+	}
+
 	return &permissions[0], nil
 }
+
+// The capabilities granted by any of the roles, in the order they are declared
+func (model PermissionsModel) findCapabilities(roleIds ...uint32) ([]AccessRight, error) {
+	var roles []Role
+
+	query := model.DB().Preload("Capabilities").Where("id in (?)", roleIds).Find(&roles)
+	if query.Error != nil {
+		return nil, query.Error
+	}
+
+	granted := map[AccessRight]bool{}
+	for _, role := range roles {
+		for _, capability := range role.Granted() {
+			granted[capability] = true
+		}
+	}
+
+	capabilities := []AccessRight{}
+	for _, capability := range Capabilities {
+		if granted[capability.Name] {
+			capabilities = append(capabilities, capability.Name)
+		}
+	}
+
+	return capabilities, nil
+}
@@ -210,6 +210,99 @@ func Test_Database_Permissions(t *testing.T) {
 		})
 	})
 
+	g.Describe("When asking for permissions on any kind of resource, ", func () {
+		g.It("Modules can be given by their code or ID", func() {
+			g.Assert(DBPermissions.IsActionPermitted(uint32(3), ModuleResource, "AC31007", ReadPermission)).IsTrue()
+			g.Assert(DBPermissions.IsActionPermitted(uint32(3), ModuleResource, "AC31007", WritePermission)).IsFalse()
+			g.Assert(DBPermissions.IsActionPermitted(uint32(2), ModuleResource, uint32(1), WritePermission)).IsTrue()
+			g.Assert(DBPermissions.IsActionPermitted(uint32(4), ModuleResource, uint32(1), ReadPermission)).IsFalse()
+		})
+
+		g.It("Courses should use the role of the user on the course", func() {
+			g.Assert(DBPermissions.IsActionPermitted(uint32(2), CourseResource, uint32(2), UpdatePermission)).IsTrue()
+			g.Assert(DBPermissions.IsActionPermitted(uint32(4), CourseResource, uint32(1), ReadPermission)).IsFalse()
+		})
+
+		g.It("Roles without capabilities should get the ones allowed by their flags", func() {
+			g.Assert(DBPermissions.IsActionPermitted(uint32(2), ModuleResource, "AC31007", GradeAssignmentPermission)).IsTrue()
+			g.Assert(DBPermissions.IsActionPermitted(uint32(3), ModuleResource, "AC31007", GradeAssignmentPermission)).IsFalse()
+			g.Assert(DBPermissions.IsActionPermitted(uint32(3), ModuleResource, "AC31007", StudentEmailsPermission)).IsFalse()
+			g.Assert(DBPermissions.IsActionPermitted(uint32(2), CourseResource, uint32(2), EnrolStudentPermission)).IsTrue()
+		})
+
+		g.It("Only admins should have access to the admin resource", func() {
+			g.Assert(DBPermissions.IsActionPermitted(uint32(1), AdminResource, nil, ReadPermission)).IsTrue()
+			g.Assert(DBPermissions.IsActionPermitted(uint32(2), AdminResource, nil, ReadPermission)).IsFalse()
+		})
+	})
+
+	g.Describe("When asking for permissions on an attachment, ", func () {
+		var lectureFile, submissionFile, orphanFile *Attachment
+		var submission *Submission
+
+		g.Before(func() {
+			lectureFile, _ = DBAttachment.CreateAttachment("slides.pdf", "application/pdf", "f1e0a7b2-0000-4000-8000-000000000001")
+			submissionFile, _ = DBAttachment.CreateAttachment("submission.zip", "application/zip", "f1e0a7b2-0000-4000-8000-000000000002")
+			orphanFile, _ = DBAttachment.CreateAttachment("orphan.txt", "text/plain", "f1e0a7b2-0000-4000-8000-000000000003")
+
+			DBLecture.AddAttachmentToLecture(1, lectureFile.ID)
+			submission, _ = DBAssignments.SubmitAssignment(3, 1, submissionFile.ID, "My cluster")
+		})
+
+		g.After(func() {
+			DBLecture.RemoveAttachmentFromLecture(1, lectureFile.ID)
+			DBAttachment.DB().Delete(submission)
+			for _, attachment := range []*Attachment{ lectureFile, submissionFile, orphanFile } {
+				DBAttachment.DeleteAttachment(attachment.ID, nil)
+			}
+		})
+
+		g.It("Should know what the attachment belongs to", func() {
+			owners, err := DBAttachment.FindAttachmentOwners(lectureFile.ID)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(len(owners)).Equal(1)
+			g.Assert(owners[0].Type).Equal(LectureOwner)
+			g.Assert(owners[0].ModuleID).Equal(uint32(1))
+
+			owners, err = DBAttachment.FindAttachmentOwners(submissionFile.ID)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(len(owners)).Equal(1)
+			g.Assert(owners[0].Type).Equal(SubmissionOwner)
+			g.Assert(owners[0].ModuleCode).Equal("AC31007")
+			g.Assert(owners[0].UserID).Equal(uint32(3))
+
+			owners, err = DBAttachment.FindAttachmentOwners(orphanFile.ID)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(len(owners)).Equal(0)
+		})
+
+		g.It("Only the users of the module should read the attachments of a lecture", func() {
+			g.Assert(DBPermissions.IsActionPermitted(uint32(3), AttachmentResource, lectureFile.Url, ReadPermission)).IsTrue()
+			g.Assert(DBPermissions.IsActionPermitted(uint32(4), AttachmentResource, lectureFile.Url, ReadPermission)).IsFalse()
+		})
+
+		g.It("Only the student and the graders should read a submission", func() {
+			g.Assert(DBPermissions.IsActionPermitted(uint32(3), AttachmentResource, submissionFile.Url, ReadPermission)).IsTrue()
+			g.Assert(DBPermissions.IsActionPermitted(uint32(2), AttachmentResource, submissionFile.Url, ReadPermission)).IsTrue()
+			g.Assert(DBPermissions.IsActionPermitted(uint32(5), AttachmentResource, submissionFile.Url, ReadPermission)).IsFalse()
+			g.Assert(DBPermissions.IsActionPermitted(uint32(4), AttachmentResource, submissionFile.Url, ReadPermission)).IsFalse()
+		})
+
+		g.It("Only admins should read an attachment that belongs to nothing", func() {
+			g.Assert(DBPermissions.IsActionPermitted(uint32(1), AttachmentResource, orphanFile.Url, ReadPermission)).IsTrue()
+			g.Assert(DBPermissions.IsActionPermitted(uint32(2), AttachmentResource, orphanFile.Url, ReadPermission)).IsFalse()
+			g.Assert(DBPermissions.IsActionPermitted(uint32(2), AttachmentResource, "-missing-", ReadPermission)).IsFalse()
+		})
+
+		g.It("Only the uploader should read their attachment before it belongs to something", func() {
+			DBAttachment.DB().Table("attachments").Where("id = ?", orphanFile.ID).Update("user_id", 2)
+
+			g.Assert(DBPermissions.IsActionPermitted(uint32(2), AttachmentResource, orphanFile.Url, ReadPermission)).IsTrue()
+			g.Assert(DBPermissions.IsActionPermitted(uint32(2), AttachmentResource, orphanFile.Url, WritePermission)).IsFalse()
+			g.Assert(DBPermissions.IsActionPermitted(uint32(3), AttachmentResource, orphanFile.Url, ReadPermission)).IsFalse()
+		})
+	})
+
 	// Remove the Temporary Settings
 	os.Remove(SETTINGS_PATH)
 }
@@ -0,0 +1,168 @@
+package models
+
+import (
+	"errors"
+
+	"github.com/jinzhu/gorm"
+	"github.com/YagoCarballo/kumquat-academy-api/database"
+)
+
+var ErrRubricInUse = errors.New("The rubric has already been used to grade submissions.")
+var ErrInvalidScores = errors.New("Every criterion of the rubric must be scored once with one of its levels.")
+var ErrInvalidGrade = errors.New("The grade must be between 0 and 100.")
+
+type RubricsModel struct{}
+var DBRubrics RubricsModel
+
+func (model RubricsModel) DB() *gorm.DB {
+	return database.DB
+}
+
+// Gets the criteria of the rubric of an assignment with their levels, in order
+func (model RubricsModel) FindRubric(assignmentId uint32) ([]RubricCriterion, error) {
+	criteria := []RubricCriterion{}
+
+	query := model.DB().Preload("Levels", func(db *gorm.DB) *gorm.DB {
+		return db.Order("points, id")
+	}).Order("position, id").Find(&criteria, "assignment_id = ?", assignmentId)
+	if query.Error != nil {
+		return nil, query.Error
+	}
+
+	return criteria, nil
+}
+
+// Replaces the rubric of an assignment, it can only be changed until a submission has been scored with it
+func (model RubricsModel) SaveRubric(assignmentId uint32, criteria []RubricCriterion) ([]RubricCriterion, error) {
+	tx := model.DB().Begin()
+
+	err := model.deleteRubric(tx, assignmentId)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	for index := range criteria {
+		criterion := criteria[index]
+		criterion.ID = 0
+		criterion.AssignmentID = assignmentId
+		criterion.Position = uint32(index)
+		criterion.Levels = nil
+
+		query := tx.Create(&criterion)
+		if query.Error != nil {
+			tx.Rollback()
+			return nil, query.Error
+		}
+
+		for _, level := range criteria[index].Levels {
+			level.ID = 0
+			level.CriterionID = criterion.ID
+
+			query = tx.Create(&level)
+			if query.Error != nil {
+				tx.Rollback()
+				return nil, query.Error
+			}
+		}
+	}
+
+	query := tx.Commit()
+	if query.Error != nil {
+		return nil, query.Error
+	}
+
+	return model.FindRubric(assignmentId)
+}
+
+func (model RubricsModel) DeleteRubric(assignmentId uint32) error {
+	tx := model.DB().Begin()
+
+	err := model.deleteRubric(tx, assignmentId)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+func (model RubricsModel) deleteRubric(tx *gorm.DB, assignmentId uint32) error {
+	var scores int
+	query := tx.Table("submission_scores").Joins(
+		"inner join rubric_criteria on rubric_criteria.id = submission_scores.criterion_id",
+	).Where("rubric_criteria.assignment_id = ?", assignmentId).Count(&scores)
+	if query.Error != nil {
+		return query.Error
+	}
+
+	if scores > 0 {
+		return ErrRubricInUse
+	}
+
+	var criteria []uint32
+	query = tx.Table("rubric_criteria").Where("assignment_id = ?", assignmentId).Pluck("id", &criteria)
+	if query.Error != nil {
+		return query.Error
+	}
+
+	if len(criteria) <= 0 {
+		return nil
+	}
+
+	query = tx.Where("criterion_id in (?)", criteria).Delete(RubricLevel{})
+	if query.Error != nil {
+		return query.Error
+	}
+
+	return tx.Where("assignment_id = ?", assignmentId).Delete(RubricCriterion{}).Error
+}
+
+// Checks that every criterion is scored once with one of its levels and works out the grade out of 100.
+// The points of each score are taken from the chosen level.
+func ScoreRubric(criteria []RubricCriterion, scores []SubmissionScore) ([]SubmissionScore, float64, error) {
+	if len(scores) != len(criteria) {
+		return nil, 0, ErrInvalidScores
+	}
+
+	byCriterion := map[uint32]SubmissionScore{}
+	for _, score := range scores {
+		byCriterion[score.CriterionID] = score
+	}
+
+	var points, maxPoints float64
+	scored := []SubmissionScore{}
+	for _, criterion := range criteria {
+		score, ok := byCriterion[criterion.ID]
+		if !ok {
+			return nil, 0, ErrInvalidScores
+		}
+
+		var criterionMax float64
+		found := false
+		for _, level := range criterion.Levels {
+			if level.Points > criterionMax {
+				criterionMax = level.Points
+			}
+
+			if level.ID == score.LevelID {
+				score.Points = level.Points
+				found = true
+			}
+		}
+
+		if !found {
+			return nil, 0, ErrInvalidScores
+		}
+
+		points += score.Points
+		maxPoints += criterionMax
+		scored = append(scored, score)
+	}
+
+	if maxPoints <= 0 {
+		return scored, 0, nil
+	}
+
+	return scored, points / maxPoints * 100, nil
+}
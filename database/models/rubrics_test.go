@@ -0,0 +1,111 @@
+package models
+
+import (
+	"time"
+	"testing"
+
+	. "github.com/franela/goblin"
+)
+
+func Test_Database_Rubrics(t *testing.T) {
+	g := Goblin(t)
+	var assignmentId, submissionId uint32
+	var criteria []RubricCriterion
+
+	g.Describe("When grading with a rubric", func() {
+		g.Before(func() {
+			// AC51001 starts without students or assignments
+			DBModule.DB().Create(&UserModule{ UserID: 3, ModuleCode: "AC51001", RoleID: 3, ClassID: 2 })
+
+			assignment, _ := DBAssignments.CreateAssignment(Assignment{ Title: "Poster", Description: "Design a poster.", Weight: 1, ModuleCode: "AC51001",
+				Start: time.Now(), End: time.Now().AddDate(0, 1, 0), Status: AssignmentAvailable })
+			assignmentId = assignment.ID
+
+			attachment, _ := DBAttachment.CreateAttachment("poster.pdf", "application/pdf", "rubrics-test-token")
+			submission, _ := DBAssignments.SubmitAssignment(3, assignmentId, attachment.ID, "My poster")
+			submissionId = submission.ID
+		})
+
+		g.It("Should save the criteria of a rubric in order", func() {
+			var err error
+			criteria, err = DBRubrics.SaveRubric(assignmentId, []RubricCriterion{
+				{ Title: "Design", Levels: []RubricLevel{ { Title: "Poor", Points: 0 }, { Title: "Good", Points: 6 }, { Title: "Great", Points: 10 } } },
+				{ Title: "Content", Levels: []RubricLevel{ { Title: "Poor", Points: 2 }, { Title: "Great", Points: 10 } } },
+			})
+
+			g.Assert(err == nil).IsTrue()
+			g.Assert(len(criteria)).Equal(2)
+			g.Assert(criteria[0].Title).Equal("Design")
+			g.Assert(len(criteria[0].Levels)).Equal(3)
+			g.Assert(criteria[0].Levels[2].Points).Equal(float64(10))
+		})
+
+		g.It("Should require a level for every criterion", func() {
+			_, _, err := ScoreRubric(criteria, []SubmissionScore{ { CriterionID: criteria[0].ID, LevelID: criteria[0].Levels[1].ID } })
+			g.Assert(err).Equal(ErrInvalidScores)
+
+			// The level must belong to the criterion
+			_, _, err = ScoreRubric(criteria, []SubmissionScore{
+				{ CriterionID: criteria[0].ID, LevelID: criteria[1].Levels[0].ID },
+				{ CriterionID: criteria[1].ID, LevelID: criteria[1].Levels[0].ID },
+			})
+			g.Assert(err).Equal(ErrInvalidScores)
+		})
+
+		g.It("Should work out the grade from the scores", func() {
+			submission, err := DBAssignments.GradeSubmission(submissionId, SubmissionGrading{
+				Feedback: "Nice layout, the content needs more depth.",
+				Scores: []SubmissionScore{
+					{ CriterionID: criteria[0].ID, LevelID: criteria[0].Levels[1].ID, Comment: "Good use of colour" },
+					{ CriterionID: criteria[1].ID, LevelID: criteria[1].Levels[0].ID },
+				},
+			})
+
+			g.Assert(err == nil).IsTrue()
+			g.Assert(submission.Grade).Equal(float64(40))
+			g.Assert(submission.Status).Equal(SubmissionGraded)
+			g.Assert(submission.Feedback).Equal("Nice layout, the content needs more depth.")
+			g.Assert(len(submission.Scores)).Equal(2)
+		})
+
+		g.It("Should not change a rubric that has been used", func() {
+			_, err := DBRubrics.SaveRubric(assignmentId, []RubricCriterion{})
+			g.Assert(err).Equal(ErrRubricInUse)
+		})
+
+		g.It("Should only count released grades when asked to", func() {
+			gradebook, err := DBGradebook.FindGradebook("AC51001", true)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(gradebook.Students[0].Mark == nil).IsTrue()
+
+			_, err = DBAssignments.SetAssignmentStatus(assignmentId, AssignmentSent)
+			g.Assert(err == nil).IsTrue()
+
+			assignment, err := DBAssignments.ReleaseGrades(assignmentId)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(assignment.Status).Equal(AssignmentReturned)
+
+			gradebook, err = DBGradebook.FindGradebook("AC51001", true)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(*gradebook.Students[0].Mark).Equal(float64(40))
+		})
+
+		g.It("Should hide the grade and feedback when withheld", func() {
+			submission, _ := DBAssignments.ReadSubmission(assignmentId, submissionId)
+			submission.WithholdGrade()
+
+			g.Assert(submission.Grade).Equal(float64(0))
+			g.Assert(submission.Feedback).Equal("")
+			g.Assert(submission.Scores == nil).IsTrue()
+			g.Assert(submission.Status).Equal(SubmissionReview)
+		})
+
+		g.After(func() {
+			DBModule.DB().Where("submission_id = ?", submissionId).Delete(SubmissionScore{})
+			DBModule.DB().Where("assignment_id = ?", assignmentId).Delete(Submission{})
+			DBRubrics.DeleteRubric(assignmentId)
+			DBAssignments.DeleteAssignment(assignmentId)
+			DBModule.DB().Where("module_code = ? and user_id = ?", "AC51001", 3).Delete(UserModule{})
+		})
+	})
+}
@@ -0,0 +1,184 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/YagoCarballo/kumquat-academy-api/database"
+)
+
+// Bcrypt hash shared by all the fixture users (the password is the sha512 used by the auth tests)
+const seedPasswordHash = "$2a$10$YVdxE1rxas7zTh09Hez.G.mGsKDYrtkP1.Z95CuJxMthM5fYTqHdq"
+
+var ErrDatabaseNotEmpty = errors.New("The database already contains data, use reset to replace it.")
+
+// Tables cleared before seeding, children first so no foreign key is left dangling
+var seedTables = []interface{}{
+	&RoleCapability{},
+	&OutboxEmail{},
+	&JobRun{},
+	&AnnouncementRead{},
+	&AnnouncementAttachments{},
+	&Announcement{},
+	&PageRevision{},
+	&Page{},
+	&Materials{},
+	&LectureAttachments{},
+	&Lecture{},
+	&LectureSlot{},
+	&StudentExam{},
+	&Exam{},
+	&TeamCompletedTask{},
+	&TeamMember{},
+	&Team{},
+	&CompletedTask{},
+	&Task{},
+	&AssignmentExtension{},
+	&SubmissionScore{},
+	&Submission{},
+	&RubricLevel{},
+	&RubricCriterion{},
+	&AssignmentAttachments{},
+	&Assignment{},
+	&UserCourse{},
+	&UserModule{},
+	&LevelModule{},
+	&CourseLevel{},
+	&Class{},
+	&Module{},
+	&Course{},
+	&Role{},
+	&ResetPassword{},
+	&Session{},
+	&User{},
+	&Attachment{},
+	&Blob{},
+}
+
+// Loads the fixture dataset used by the test suites.
+// If reset is false and the database already has users, ErrDatabaseNotEmpty is returned.
+func Seed(reset bool) error {
+	var users int
+	query := database.DB.Model(&User{}).Count(&users)
+	if query.Error != nil {
+		return query.Error
+	}
+
+	if users > 0 && !reset {
+		return ErrDatabaseNotEmpty
+	}
+
+	tx := database.DB.Begin()
+
+	for _, table := range seedTables {
+		query = tx.Delete(table)
+		if query.Error != nil {
+			tx.Rollback()
+			return query.Error
+		}
+	}
+
+	for _, record := range seedRecords() {
+		query = tx.Create(record)
+		if query.Error != nil {
+			tx.Rollback()
+			return query.Error
+		}
+	}
+
+	// Postgres doesn't move the sequences forward when the ids are given, so they continue after the fixtures
+	if tx.Dialect().GetName() == "postgres" {
+		for _, table := range seedTables {
+			scope := tx.NewScope(table)
+			field, found := scope.FieldByName("ID")
+			if !found || !field.IsPrimaryKey {
+				continue
+			}
+
+			query = tx.Exec(fmt.Sprintf(
+				"SELECT setval(pg_get_serial_sequence('%s', 'id'), coalesce(max(id), 0) + 1, false) FROM %s",
+				scope.TableName(), scope.QuotedTableName(),
+			))
+			if query.Error != nil {
+				tx.Rollback()
+				return query.Error
+			}
+		}
+	}
+
+	return tx.Commit().Error
+}
+
+func seedDate(year int, month time.Month, day, hour int) time.Time {
+	return time.Date(year, month, day, hour, 0, 0, 0, time.UTC)
+}
+
+// The fixture dataset, in insertion order
+func seedRecords() []interface{} {
+	classStart := seedDate(2015, time.September, 1, 0)
+	classEnd := seedDate(2016, time.June, 30, 0)
+	matricDate := seedDate(2015, time.September, 1, 9)
+
+	return []interface{}{
+		// Roles
+		&Role{ID: 1, Name: "Admin", Description: "Admin of a module / course.", CanRead: true, CanWrite: true, CanDelete: true, CanUpdate: true},
+		&Role{ID: 2, Name: "Teacher", Description: "Teacher of a module / course.", CanRead: true, CanWrite: true, CanDelete: true, CanUpdate: true},
+		&Role{ID: 3, Name: "Student", Description: "Student of a module / course.", CanRead: true},
+
+		// Users
+		&User{ID: 1, FirstName: "Admin", LastName: "Kumquat", Username: "admin", Email: "admin@kumquat.academy", Password: seedPasswordHash,
+			Active: true, Admin: true, DateOfBirth: seedDate(1980, time.January, 1, 0), MatricNumber: "000000001", MatricDate: matricDate},
+		&User{ID: 2, FirstName: "John", LastName: "Teacher", Username: "teacher", Email: "teacher@kumquat.academy", Password: seedPasswordHash,
+			Active: true, DateOfBirth: seedDate(1975, time.March, 12, 0), MatricNumber: "000000002", MatricDate: matricDate},
+		&User{ID: 3, FirstName: "Jim", LastName: "Student", Username: "student", Email: "student@kumquat.academy", Password: seedPasswordHash,
+			Active: true, DateOfBirth: seedDate(1994, time.May, 20, 0), MatricNumber: "130000003", MatricDate: matricDate},
+		&User{ID: 4, FirstName: "Guest", LastName: "User", Username: "guest", Email: "guest@kumquat.academy", Password: seedPasswordHash,
+			Active: true, DateOfBirth: seedDate(1990, time.July, 4, 0), MatricNumber: "000000004", MatricDate: matricDate},
+		&User{ID: 5, FirstName: "Jane", LastName: "Johnston", Username: "jane.johnston", Email: "jane.johnston68@example.com", Password: seedPasswordHash,
+			Active: true, DateOfBirth: seedDate(1995, time.October, 8, 0), MatricNumber: "130000005", MatricDate: matricDate},
+
+		// Courses, Classes and Levels
+		&Course{ID: 1, Title: "Computing", Description: "BSc (Hons) Computing"},
+		&Course{ID: 2, Title: "Applied Computing", Description: "BSc (Hons) Applied Computing"},
+		&Class{ID: 1, CourseID: 1, Title: "2015/2016", Start: classStart, End: classEnd},
+		&Class{ID: 2, CourseID: 2, Title: "2015/2016", Start: classStart, End: classEnd},
+		&CourseLevel{Level: 1, ClassID: 1, CourseID: 1, Start: classStart, End: classEnd},
+		&CourseLevel{Level: 2, ClassID: 1, CourseID: 1, Start: classStart, End: classEnd},
+		&CourseLevel{Level: 1, ClassID: 2, CourseID: 2, Start: classStart, End: classEnd},
+
+		// Modules
+		&Module{ID: 1, Title: "Big Data", Description: "Introduction to the world of Big Data", Color: "#9C0098", Icon: "fa-cloud", Duration: 12},
+		&Module{ID: 2, Title: "Computer Graphics", Description: "Rendering pipelines and shaders", Color: "#00897B", Icon: "fa-cube", Duration: 12},
+		&Module{ID: 3, Title: "Agile Software Engineering", Description: "Building software in teams", Color: "#F57C00", Icon: "fa-users", Duration: 24},
+		&Module{ID: 4, Title: "Databases", Description: "Relational databases and SQL", Color: "#1976D2", Icon: "fa-database", Duration: 12},
+		&LevelModule{Code: "AC31007", ClassID: 1, Level: 1, ModuleID: 1, Status: ModuleOngoing, Start: classStart},
+		&LevelModule{Code: "AC22001", ClassID: 1, Level: 1, ModuleID: 3, Status: ModuleOngoing, Start: classStart},
+		&LevelModule{Code: "AC21009", ClassID: 1, Level: 2, ModuleID: 2, Status: ModuleFuture, Start: classStart},
+		&LevelModule{Code: "AC51001", ClassID: 2, Level: 1, ModuleID: 4, Status: ModuleOngoing, Start: classStart},
+
+		// Enrolments
+		&UserModule{UserID: 2, ModuleCode: "AC31007", RoleID: 2, ClassID: 1},
+		&UserModule{UserID: 2, ModuleCode: "AC22001", RoleID: 2, ClassID: 1},
+		&UserModule{UserID: 3, ModuleCode: "AC31007", RoleID: 3, ClassID: 1},
+		&UserModule{UserID: 5, ModuleCode: "AC31007", RoleID: 3, ClassID: 1},
+		&UserCourse{UserID: 2, CourseID: 2, RoleID: 2},
+
+		// Teaching
+		&LectureSlot{ID: 1, ModuleID: 1, Location: "Seminar Room 1", Type: "Lecture",
+			Start: seedDate(2015, time.September, 7, 10), End: seedDate(2015, time.September, 7, 12)},
+		&Lecture{ID: 1, ModuleID: 1, LectureSlotID: uint32Pointer(1), Location: "Seminar Room 1", Topic: "Introduction",
+			Description: "What Big Data is and why it matters.", Start: seedDate(2015, time.September, 7, 10), End: seedDate(2015, time.September, 7, 12)},
+		&Lecture{ID: 2, ModuleID: 1, LectureSlotID: uint32Pointer(1), Location: "Seminar Room 1", Topic: "MapReduce",
+			Description: "Processing large datasets in parallel.", Start: seedDate(2015, time.September, 14, 10), End: seedDate(2015, time.September, 14, 12)},
+		&Assignment{ID: 1, Title: "Hadoop cluster", Description: "Set up a small Hadoop cluster and run a word count.", Status: AssignmentAvailable,
+			Weight: 0.25, Start: seedDate(2015, time.October, 1, 9), End: seedDate(2015, time.November, 1, 17), ModuleCode: "AC31007"},
+		&Task{ID: 1, AssignmentID: 1, Position: 1, Title: "Install Hadoop", Description: "Install Hadoop on every node of the cluster."},
+		&Task{ID: 2, AssignmentID: 1, Position: 2, Title: "Run the word count", Description: "Run the word count example over the provided dataset."},
+	}
+}
+
+func uint32Pointer(value uint32) *uint32 {
+	return &value
+}
+
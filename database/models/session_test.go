@@ -1,6 +1,7 @@
 package models
 
 import (
+	"time"
 	"testing"
 	. "github.com/franela/goblin"
 )
@@ -47,4 +48,82 @@ func Test_Database_Sessions(t *testing.T) {
 			g.Assert(count == 1).IsTrue()
 		})
 	})
+
+	g.Describe("When managing the sessions of the devices", func() {
+		var laptop, phone *Session
+
+		g.Before(func() {
+			laptop, _ = DBSession.Create(2, "-laptop-")
+			phone, _ = DBSession.Create(2, "-phone-")
+		})
+
+		g.It("Should list the active sessions of a user", func() {
+			sessions, err := DBSession.FindUserSessions(2, time.Now())
+
+			g.Assert(err == nil).IsTrue()
+			g.Assert(len(sessions)).Equal(2)
+		})
+
+		g.It("Should not accept an expired session", func() {
+			DBSession.DB().Model(Session{}).Where("token = ?", phone.Token).Update("expires_in", time.Now().Add(-time.Minute))
+
+			session, err := DBSession.FindSession(phone.Token)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(session == nil).IsTrue()
+
+			sessions, _ := DBSession.FindUserSessions(2, time.Now())
+			g.Assert(len(sessions)).Equal(1)
+		})
+
+		g.It("Should extend a session when it is used", func() {
+			later := time.Now().Add(time.Hour)
+			err := DBSession.TouchSession(laptop, later)
+			g.Assert(err == nil).IsTrue()
+
+			session, _ := DBSession.FindSession(laptop.Token)
+			g.Assert(session.LastSeen.Unix()).Equal(later.Unix())
+			g.Assert(session.ExpiresIn.Unix()).Equal(later.Add(SESSION_LIFETIME).Unix())
+		})
+
+		g.It("Should swap a refresh token for a new session only once", func() {
+			refreshed, err := DBSession.RefreshSession(phone.RefreshToken, time.Now())
+			g.Assert(err == nil).IsTrue()
+			g.Assert(refreshed != nil).IsTrue()
+			g.Assert(refreshed.DeviceID).Equal("-phone-")
+			g.Assert(refreshed.Token != phone.Token).IsTrue()
+			g.Assert(refreshed.RefreshToken != phone.RefreshToken).IsTrue()
+
+			again, err := DBSession.RefreshSession(phone.RefreshToken, time.Now())
+			g.Assert(err == nil).IsTrue()
+			g.Assert(again == nil).IsTrue()
+
+			empty, _ := DBSession.RefreshSession("", time.Now())
+			g.Assert(empty == nil).IsTrue()
+			phone = refreshed
+		})
+
+		g.It("Should revoke the session on a device", func() {
+			removed, err := DBSession.RemoveUserSessionOnDevice(2, "-phone-")
+			g.Assert(err == nil).IsTrue()
+			g.Assert(removed).Equal(int64(1))
+
+			session, _ := DBSession.FindSession(phone.Token)
+			g.Assert(session == nil).IsTrue()
+		})
+
+		g.It("Should revoke every other session", func() {
+			DBSession.Create(2, "-phone-")
+
+			removed, err := DBSession.RemoveUserSessions(2, laptop.Token)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(removed).Equal(int64(1))
+
+			session, _ := DBSession.FindSession(laptop.Token)
+			g.Assert(session != nil).IsTrue()
+		})
+
+		g.After(func() {
+			DBSession.DB().Where("user_id = ? and device_id in (?)", 2, []string{"-laptop-", "-phone-"}).Delete(Session{})
+		})
+	})
 }
@@ -8,6 +8,17 @@ import (
 	"github.com/YagoCarballo/kumquat-academy-api/database"
 )
 
+const (
+	// Sessions expire after a week without being used
+	SESSION_LIFETIME = 7 * 24 * time.Hour
+
+	// The refresh token can get a new session for a month
+	REFRESH_LIFETIME = 30 * 24 * time.Hour
+
+	// How often the last time a session was seen is saved, so not every request writes to the database
+	SESSION_TOUCH_INTERVAL = time.Minute
+)
+
 type SessionModel struct {}
 var DBSession SessionModel
 
@@ -21,39 +32,63 @@ func (model SessionModel) Create(userId uint32, deviceId string) (*Session, erro
 	if findError != nil {
 		return nil, findError
 	} else if dbSession != nil {
-		return dbSession, nil
+		if dbSession.ExpiresIn.After(time.Now()) {
+			return dbSession, nil
+		}
+
+		// The old session has expired, so it is replaced
+		_, err := model.RemoveSession(dbSession.Token)
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	// Generates an Access Token
-	accessToken, err := uuid.NewRandom()
+	session, err := newSession(userId, deviceId, time.Now())
 	if err != nil {
 		return nil, err
 	}
 
-	// Creates the Session
-	session := Session{
-		Token:		accessToken.String(),
-		UserID:		userId,
-		DeviceID:	deviceId,
-		ExpiresIn:	time.Now().AddDate(0, 0, 7),
-		CreatedOn:	time.Now(),
-	}
-
 	// Create the Session
 	query := model.DB().Create(session)
 	if query.Error != nil {
 		return nil, query.Error
 	}
 
-	return &session, nil
+	return session, nil
+}
+
+// Generates the tokens of a new session
+func newSession(userId uint32, deviceId string, now time.Time) (*Session, error) {
+	// Generates an Access Token
+	accessToken, err := uuid.NewRandom()
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, err := uuid.NewRandom()
+	if err != nil {
+		return nil, err
+	}
+
+	// Creates the Session
+	return &Session{
+		Token:		accessToken.String(),
+		UserID:		userId,
+		DeviceID:	deviceId,
+		ExpiresIn:	now.Add(SESSION_LIFETIME),
+		CreatedOn:	now,
+		LastSeen:	now,
+		RefreshToken:		refreshToken.String(),
+		RefreshExpiresIn:	now.Add(REFRESH_LIFETIME),
+	}, nil
 }
 
 func (model SessionModel) FindSession(accessToken string) (*Session, error) {
 	// Creates empty Session
 	var session Session
 
-	// Query the Session
-	query := model.DB().Find(&session, "token = ?", accessToken)
+	// Query the Session (expired sessions are no longer valid)
+	query := model.DB().Find(&session, "token = ? and expires_in > ?", accessToken, time.Now())
 	if query.Error != nil {
 		// If no Records found, return NIL otherwise return the error
 		switch query.Error {
@@ -98,3 +133,113 @@ func (model SessionModel) RemoveSession(accessToken string) (int64, error) {
 	// Returns the Session
 	return query.RowsAffected, nil
 }
+
+// Removes the sessions that expired before the given time (and can't be refreshed anymore)
+func (model SessionModel) RemoveExpiredSessions(now time.Time) (int64, error) {
+	query := model.DB().Where("expires_in <= ? and refresh_expires_in <= ?", now, now).Delete(Session{})
+	if query.Error != nil {
+		return 0, query.Error
+	}
+
+	return query.RowsAffected, nil
+}
+
+// Marks a session as seen, extending its expiration
+func (model SessionModel) TouchSession(session *Session, now time.Time) error {
+	if now.Sub(session.LastSeen) < SESSION_TOUCH_INTERVAL {
+		return nil
+	}
+
+	session.LastSeen = now
+	session.ExpiresIn = now.Add(SESSION_LIFETIME)
+
+	return model.DB().Model(Session{}).Where("token = ?", session.Token).Updates(map[string]interface{}{
+		"last_seen": session.LastSeen,
+		"expires_in": session.ExpiresIn,
+	}).Error
+}
+
+// Replaces a session with a new one (with new tokens) given its refresh token, each refresh token can only be used once
+func (model SessionModel) RefreshSession(refreshToken string, now time.Time) (*Session, error) {
+	if refreshToken == "" {
+		return nil, nil
+	}
+
+	var session Session
+	query := model.DB().Find(&session, "refresh_token = ? and refresh_expires_in > ?", refreshToken, now)
+	if query.Error != nil {
+		// If no Records found, return NIL otherwise return the error
+		switch query.Error {
+		case gorm.ErrRecordNotFound:
+			return nil, nil
+		default:
+			return nil, query.Error
+		}
+	}
+
+	refreshed, err := newSession(session.UserID, session.DeviceID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	// The device keeps the time it signed in
+	refreshed.CreatedOn = session.CreatedOn
+
+	tx := model.DB().Begin()
+
+	query = tx.Where("token = ? and refresh_token = ?", session.Token, refreshToken).Delete(Session{})
+	if query.Error != nil {
+		tx.Rollback()
+		return nil, query.Error
+	}
+
+	// Someone else used the refresh token first
+	if query.RowsAffected != 1 {
+		tx.Rollback()
+		return nil, nil
+	}
+
+	query = tx.Create(refreshed)
+	if query.Error != nil {
+		tx.Rollback()
+		return nil, query.Error
+	}
+
+	query = tx.Commit()
+	if query.Error != nil {
+		return nil, query.Error
+	}
+
+	return refreshed, nil
+}
+
+// Gets the active sessions of a user, the most recently used first
+func (model SessionModel) FindUserSessions(userId uint32, now time.Time) ([]Session, error) {
+	sessions := []Session{}
+
+	query := model.DB().Order("last_seen desc").Find(&sessions, "user_id = ? and expires_in > ?", userId, now)
+	if query.Error != nil {
+		return nil, query.Error
+	}
+
+	return sessions, nil
+}
+
+func (model SessionModel) RemoveUserSessionOnDevice(userId uint32, deviceId string) (int64, error) {
+	query := model.DB().Where("user_id = ? and device_id = ?", userId, deviceId).Delete(Session{})
+	if query.Error != nil {
+		return 0, query.Error
+	}
+
+	return query.RowsAffected, nil
+}
+
+// Removes all the sessions of a user except the given one (an empty token removes them all)
+func (model SessionModel) RemoveUserSessions(userId uint32, keepToken string) (int64, error) {
+	query := model.DB().Where("user_id = ? and token != ?", userId, keepToken).Delete(Session{})
+	if query.Error != nil {
+		return 0, query.Error
+	}
+
+	return query.RowsAffected, nil
+}
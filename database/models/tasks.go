@@ -0,0 +1,236 @@
+package models
+
+import (
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/YagoCarballo/kumquat-academy-api/database"
+)
+
+type TasksModel struct{}
+var DBTasks TasksModel
+
+func (model TasksModel) DB() *gorm.DB {
+	return database.DB
+}
+
+// Creates a task, tasks without a position are added at the end of the checklist
+func (model TasksModel) CreateTask(task Task) (*Task, error) {
+	if task.Position == 0 {
+		var positions []uint32
+
+		query := model.DB().Table("tasks").Where("assignment_id = ?", task.AssignmentID).Order("position desc").Limit(1).Pluck("position", &positions)
+		if query.Error != nil {
+			return nil, query.Error
+		}
+
+		task.Position = 1
+		if len(positions) > 0 {
+			task.Position = positions[0] + 1
+		}
+	}
+
+	query := model.DB().Create(&task)
+	if query.Error != nil {
+		return nil, query.Error
+	}
+
+	return &task, nil
+}
+
+func (model TasksModel) ReadTask(assignmentId, id uint32) (*Task, error) {
+	var task Task
+
+	query := model.DB().First(&task, "id = ? and assignment_id = ?", id, assignmentId)
+	if query.Error != nil {
+		// If no Records found, return NIL otherwise return the error
+		switch query.Error {
+		case gorm.ErrRecordNotFound:
+			return nil, nil
+		default:
+			return nil, query.Error
+		}
+	}
+
+	return &task, nil
+}
+
+func (model TasksModel) UpdateTask(assignmentId, id uint32, task Task) (*Task, error) {
+	// MySQL reports no affected rows when nothing changed, so the task is looked up first
+	dbTask, err := model.ReadTask(assignmentId, id)
+	if err != nil || dbTask == nil {
+		return nil, err
+	}
+
+	changes := map[string]interface{}{
+		"title": task.Title,
+		"description": task.Description,
+		"due": task.Due,
+	}
+
+	if task.Position > 0 {
+		changes["position"] = task.Position
+	}
+
+	query := model.DB().Table("tasks").Where("id = ? and assignment_id = ?", id, assignmentId).Updates(changes)
+	if query.Error != nil {
+		return nil, query.Error
+	}
+
+	return model.ReadTask(assignmentId, id)
+}
+
+func (model TasksModel) DeleteTask(assignmentId, id uint32) (int64, error) {
+	query := model.DB().
+		Table("tasks").
+		Where("id = ? and assignment_id = ?", id, assignmentId).
+		Delete(Task{})
+	if query.Error != nil {
+		return 0, query.Error
+	}
+
+	return query.RowsAffected, nil
+}
+
+// Lists the tasks of an assignment in order, marking the ones completed by the user (or by its team)
+func (model TasksModel) FindTasksForAssignment(assignmentId, userId uint32) ([]Task, error) {
+	tasks := []Task{}
+
+	query := model.DB().Order("position, id").Find(&tasks, "assignment_id = ?", assignmentId)
+	if query.Error != nil {
+		return nil, query.Error
+	}
+
+	completed, err := model.FindCompletedTasks(assignmentId, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	for index, task := range tasks {
+		for _, taskId := range completed {
+			if task.ID == taskId {
+				tasks[index].Completed = true
+			}
+		}
+	}
+
+	return tasks, nil
+}
+
+// Sets the position of each task following the order of the given ids
+func (model TasksModel) ReorderTasks(assignmentId uint32, taskIds []uint32) error {
+	tx := model.DB().Begin()
+
+	for index, taskId := range taskIds {
+		query := tx.Table("tasks").Where("id = ? and assignment_id = ?", taskId, assignmentId).Update("position", index + 1)
+		if query.Error != nil {
+			tx.Rollback()
+			return query.Error
+		}
+	}
+
+	return tx.Commit().Error
+}
+
+func (model TasksModel) CompleteTask(userId, taskId uint32) (*CompletedTask, error) {
+	completedTask := CompletedTask{
+		UserID: userId,
+		TaskID: taskId,
+		Date: time.Now(),
+	}
+
+	query := model.DB().Create(&completedTask)
+	if query.Error != nil {
+		isDuplicated := database.IsDuplicatedError(query.Error)
+		if isDuplicated {
+			return nil, nil
+		}
+
+		return nil, query.Error
+	}
+
+	return &completedTask, nil
+}
+
+func (model TasksModel) UncompleteTask(userId, taskId uint32) (int64, error) {
+	query := model.DB().
+		Where("user_id = ? and task_id = ?", userId, taskId).
+		Delete(CompletedTask{})
+	if query.Error != nil {
+		return 0, query.Error
+	}
+
+	return query.RowsAffected, nil
+}
+
+func (model TasksModel) CompleteTeamTask(teamId, taskId uint32) (*TeamCompletedTask, error) {
+	completedTask := TeamCompletedTask{
+		TeamID: teamId,
+		TaskID: taskId,
+		Date: time.Now(),
+	}
+
+	query := model.DB().Create(&completedTask)
+	if query.Error != nil {
+		isDuplicated := database.IsDuplicatedError(query.Error)
+		if isDuplicated {
+			return nil, nil
+		}
+
+		return nil, query.Error
+	}
+
+	return &completedTask, nil
+}
+
+func (model TasksModel) UncompleteTeamTask(teamId, taskId uint32) (int64, error) {
+	query := model.DB().
+		Where("team_id = ? and task_id = ?", teamId, taskId).
+		Delete(TeamCompletedTask{})
+	if query.Error != nil {
+		return 0, query.Error
+	}
+
+	return query.RowsAffected, nil
+}
+
+// Finds the ids of the tasks of an assignment completed by the user or by any of its teams
+func (model TasksModel) FindCompletedTasks(assignmentId, userId uint32) ([]uint32, error) {
+	var taskIds []uint32
+
+	query := model.DB().Table("tasks").Where(
+		"tasks.assignment_id = ? and (" +
+		"exists (select 1 from completed_tasks " +
+			"where completed_tasks.task_id = tasks.id and completed_tasks.user_id = ?) or " +
+		"exists (select 1 from team_completed_tasks " +
+			"inner join team_members on team_members.team_id = team_completed_tasks.team_id " +
+			"where team_completed_tasks.task_id = tasks.id and team_members.user_id = ?))",
+		assignmentId, userId, userId,
+	).Pluck("tasks.id", &taskIds)
+	if query.Error != nil {
+		return nil, query.Error
+	}
+
+	return taskIds, nil
+}
+
+// Gets the percentage (0 to 100) of the tasks of an assignment completed by the user
+func (model TasksModel) GetProgress(assignmentId, userId uint32) (float64, error) {
+	var total int
+
+	query := model.DB().Table("tasks").Where("assignment_id = ?", assignmentId).Count(&total)
+	if query.Error != nil {
+		return 0, query.Error
+	}
+
+	if total <= 0 {
+		return 0, nil
+	}
+
+	completed, err := model.FindCompletedTasks(assignmentId, userId)
+	if err != nil {
+		return 0, err
+	}
+
+	return float64(len(completed)) * 100 / float64(total), nil
+}
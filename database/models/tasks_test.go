@@ -0,0 +1,98 @@
+package models
+
+import (
+	"time"
+	"testing"
+
+	. "github.com/franela/goblin"
+)
+
+func Test_Database_Tasks(t *testing.T) {
+	g := Goblin(t)
+	var taskId uint32
+
+	g.Describe("When working with the checklist of an assignment", func() {
+		g.It("Should list the tasks in order", func() {
+			tasks, err := DBTasks.FindTasksForAssignment(1, 3)
+
+			g.Assert(err == nil).IsTrue()
+			g.Assert(len(tasks)).Equal(2)
+			g.Assert(tasks[0].Title).Equal("Install Hadoop")
+			g.Assert(tasks[1].Completed).IsFalse()
+		})
+
+		g.It("Should add new tasks at the end of the checklist", func() {
+			due := time.Now().AddDate(0, 0, 7)
+			task, err := DBTasks.CreateTask(Task{
+				Title: "Write the report",
+				Due: &due,
+				AssignmentID: 1,
+			})
+
+			g.Assert(err == nil).IsTrue()
+			g.Assert(task.Position).Equal(uint32(3))
+
+			taskId = task.ID
+		})
+
+		g.It("Should be able to update a task", func() {
+			task, err := DBTasks.UpdateTask(1, taskId, Task{ Title: "Write the final report" })
+
+			g.Assert(err == nil).IsTrue()
+			g.Assert(task.Title).Equal("Write the final report")
+			g.Assert(task.Position).Equal(uint32(3))
+			g.Assert(task.Due == nil).IsTrue()
+		})
+
+		g.It("Should track the progress of a student", func() {
+			completed, err := DBTasks.CompleteTask(3, 1)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(completed != nil).IsTrue()
+
+			// Ticking the same task twice is ignored
+			completed, err = DBTasks.CompleteTask(3, 1)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(completed == nil).IsTrue()
+
+			progress, err := DBTasks.GetProgress(1, 3)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(progress > 33 && progress < 34).IsTrue()
+
+			progress, err = DBTasks.GetProgress(1, 5)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(progress).Equal(float64(0))
+		})
+
+		g.It("Should show the progress of each student in the assignments", func() {
+			assignments, err := DBAssignments.FindAssignmentsForModule("AC31007", false)
+			g.Assert(err == nil).IsTrue()
+
+			for _, student := range assignments[0].Students {
+				if student["id"] == uint32(3) {
+					g.Assert(student["progress"].(float64) > 0).IsTrue()
+				}
+			}
+		})
+
+		g.It("Should be able to reorder the tasks", func() {
+			err := DBTasks.ReorderTasks(1, []uint32{taskId, 1, 2})
+			g.Assert(err == nil).IsTrue()
+
+			tasks, _ := DBTasks.FindTasksForAssignment(1, 3)
+			g.Assert(tasks[0].ID).Equal(taskId)
+			g.Assert(tasks[1].Completed).IsTrue()
+		})
+
+		g.It("Should be able to untick and delete tasks", func() {
+			count, err := DBTasks.UncompleteTask(3, 1)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(count == 1).IsTrue()
+
+			count, err = DBTasks.DeleteTask(1, taskId)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(count == 1).IsTrue()
+
+			DBTasks.ReorderTasks(1, []uint32{1, 2})
+		})
+	})
+}
@@ -0,0 +1,237 @@
+package models
+
+import (
+	"fmt"
+
+	"github.com/jinzhu/gorm"
+	"github.com/YagoCarballo/kumquat-academy-api/database"
+)
+
+type TeamsModel struct{}
+var DBTeams TeamsModel
+
+func (model TeamsModel) DB() *gorm.DB {
+	return database.DB
+}
+
+func (model TeamsModel) CreateTeam(assignmentId uint32, name string, memberIds []uint32) (*Team, error) {
+	tx := model.DB().Begin()
+
+	team := Team{
+		Name: name,
+		AssignmentID: assignmentId,
+	}
+
+	query := tx.Create(&team)
+	if query.Error != nil {
+		tx.Rollback()
+		return nil, query.Error
+	}
+
+	for _, userId := range memberIds {
+		query = tx.Create(&TeamMember{ TeamID: team.ID, UserID: userId })
+		if query.Error != nil {
+			tx.Rollback()
+			return nil, query.Error
+		}
+	}
+
+	query = tx.Commit()
+	if query.Error != nil {
+		return nil, query.Error
+	}
+
+	return model.ReadTeam(assignmentId, team.ID)
+}
+
+func (model TeamsModel) ReadTeam(assignmentId, id uint32) (*Team, error) {
+	var team Team
+
+	query := model.DB().Preload("Members").First(&team, "id = ? and assignment_id = ?", id, assignmentId)
+	if query.Error != nil {
+		// If no Records found, return NIL otherwise return the error
+		switch query.Error {
+		case gorm.ErrRecordNotFound:
+			return nil, nil
+		default:
+			return nil, query.Error
+		}
+	}
+
+	return &team, nil
+}
+
+func (model TeamsModel) DeleteTeam(assignmentId, id uint32) (int64, error) {
+	tx := model.DB().Begin()
+
+	// The submissions made by the team stay with each member
+	query := tx.Table("submissions").Where("team_id = ?", id).Update("team_id", nil)
+	if query.Error != nil {
+		tx.Rollback()
+		return 0, query.Error
+	}
+
+	query = tx.Where("team_id = ?", id).Delete(TeamMember{})
+	if query.Error != nil {
+		tx.Rollback()
+		return 0, query.Error
+	}
+
+	query = tx.Table("teams").Where("id = ? and assignment_id = ?", id, assignmentId).Delete(Team{})
+	if query.Error != nil || query.RowsAffected <= 0 {
+		tx.Rollback()
+		return 0, query.Error
+	}
+
+	count := query.RowsAffected
+	query = tx.Commit()
+	if query.Error != nil {
+		return 0, query.Error
+	}
+
+	return count, nil
+}
+
+func (model TeamsModel) FindTeamsForAssignment(assignmentId uint32) ([]Team, error) {
+	teams := []Team{}
+
+	query := model.DB().Preload("Members").Order("id").Find(&teams, "assignment_id = ?", assignmentId)
+	if query.Error != nil {
+		return nil, query.Error
+	}
+
+	return teams, nil
+}
+
+// Finds the team of a student for an assignment, returns nil if the student has no team
+func (model TeamsModel) FindTeamForUser(assignmentId, userId uint32) (*Team, error) {
+	var team Team
+
+	query := model.DB().
+		Preload("Members").
+		Joins("inner join team_members on team_members.team_id = teams.id").
+		Where("teams.assignment_id = ? and team_members.user_id = ?", assignmentId, userId).
+		First(&team)
+	if query.Error != nil {
+		// If no Records found, return NIL otherwise return the error
+		switch query.Error {
+		case gorm.ErrRecordNotFound:
+			return nil, nil
+		default:
+			return nil, query.Error
+		}
+	}
+
+	return &team, nil
+}
+
+func (model TeamsModel) AddTeamMember(teamId, userId uint32) (*TeamMember, error) {
+	member := TeamMember{
+		TeamID: teamId,
+		UserID: userId,
+	}
+
+	query := model.DB().Create(&member)
+	if query.Error != nil {
+		isDuplicated := database.IsDuplicatedError(query.Error)
+		if isDuplicated {
+			return nil, nil
+		}
+
+		return nil, query.Error
+	}
+
+	return &member, nil
+}
+
+func (model TeamsModel) RemoveTeamMember(teamId, userId uint32) (int64, error) {
+	query := model.DB().
+		Where("team_id = ? and user_id = ?", teamId, userId).
+		Delete(TeamMember{})
+	if query.Error != nil {
+		return 0, query.Error
+	}
+
+	return query.RowsAffected, nil
+}
+
+// Replaces the teams of an assignment with teams of (at most) the given size,
+// spreading the students of the module so that the sizes differ by one at most.
+func (model TeamsModel) GenerateTeams(assignmentId uint32, moduleCode string, size int) ([]Team, error) {
+	if size <= 0 {
+		return nil, fmt.Errorf("Invalid team size %d", size)
+	}
+
+	students, err := DBModule.FindStudentsForModule(moduleCode, "Student")
+	if err != nil {
+		return nil, err
+	}
+
+	teams, err := model.FindTeamsForAssignment(assignmentId)
+	if err != nil {
+		return nil, err
+	}
+
+	teamIds := []uint32{}
+	for _, team := range teams {
+		teamIds = append(teamIds, team.ID)
+	}
+
+	teamCount := (len(students) + size - 1) / size
+	members := make([][]uint32, teamCount)
+	for index, student := range students {
+		members[index % teamCount] = append(members[index % teamCount], student.ID)
+	}
+
+	// The old teams are only dropped if the new ones can be created
+	tx := model.DB().Begin()
+
+	if len(teamIds) > 0 {
+		// The submissions made by the old teams stay with each member
+		query := tx.Table("submissions").Where("team_id in (?)", teamIds).Update("team_id", nil)
+		if query.Error != nil {
+			tx.Rollback()
+			return nil, query.Error
+		}
+
+		query = tx.Where("team_id in (?)", teamIds).Delete(TeamMember{})
+		if query.Error != nil {
+			tx.Rollback()
+			return nil, query.Error
+		}
+
+		query = tx.Table("teams").Where("id in (?) and assignment_id = ?", teamIds, assignmentId).Delete(Team{})
+		if query.Error != nil {
+			tx.Rollback()
+			return nil, query.Error
+		}
+	}
+
+	for index, memberIds := range members {
+		team := Team{
+			Name: fmt.Sprintf("Team %d", index + 1),
+			AssignmentID: assignmentId,
+		}
+
+		query := tx.Create(&team)
+		if query.Error != nil {
+			tx.Rollback()
+			return nil, query.Error
+		}
+
+		for _, userId := range memberIds {
+			query = tx.Create(&TeamMember{ TeamID: team.ID, UserID: userId })
+			if query.Error != nil {
+				tx.Rollback()
+				return nil, query.Error
+			}
+		}
+	}
+
+	query := tx.Commit()
+	if query.Error != nil {
+		return nil, query.Error
+	}
+
+	return model.FindTeamsForAssignment(assignmentId)
+}
@@ -0,0 +1,202 @@
+package models
+
+import (
+	"time"
+	"testing"
+
+	. "github.com/franela/goblin"
+)
+
+func Test_Database_Teams(t *testing.T) {
+	g := Goblin(t)
+	var assignmentId, teamId uint32
+
+	g.Describe("When working in teams", func() {
+		g.Before(func() {
+			assignment, err := DBAssignments.CreateAssignment(Assignment{
+				Title: "Team Project",
+				Description: "Build something together",
+				Status: AssignmentAvailable,
+				Weight: 0.3,
+				TeamAssignment: true,
+				Start: time.Now(),
+				End: time.Now().AddDate(0, 1, 0),
+				ModuleCode: "AC31007",
+			})
+
+			g.Assert(err == nil).IsTrue()
+			assignmentId = assignment.ID
+		})
+
+		g.After(func() {
+			DBAssignments.DeleteAssignment(assignmentId)
+		})
+
+		g.It("Should be able to create a team with members", func() {
+			team, err := DBTeams.CreateTeam(assignmentId, "The Mappers", []uint32{3})
+
+			g.Assert(err == nil).IsTrue()
+			g.Assert(team != nil).IsTrue()
+			g.Assert(team.Name).Equal("The Mappers")
+			g.Assert(len(team.Members)).Equal(1)
+
+			teamId = team.ID
+		})
+
+		g.It("Should be able to add a member to a team", func() {
+			member, err := DBTeams.AddTeamMember(teamId, 5)
+
+			g.Assert(err == nil).IsTrue()
+			g.Assert(member != nil).IsTrue()
+		})
+
+		g.It("Should find the team of a student", func() {
+			team, err := DBTeams.FindTeamForUser(assignmentId, 5)
+
+			g.Assert(err == nil).IsTrue()
+			g.Assert(team != nil).IsTrue()
+			g.Assert(team.ID).Equal(teamId)
+			g.Assert(len(team.Members)).Equal(2)
+		})
+
+		g.It("Should not find a team for a student without one", func() {
+			team, err := DBTeams.FindTeamForUser(assignmentId, 2)
+
+			g.Assert(err == nil).IsTrue()
+			g.Assert(team == nil).IsTrue()
+		})
+
+		g.It("Should cancel the submissions the members made before", func() {
+			team, _ := DBTeams.ReadTeam(assignmentId, teamId)
+			attachment, _ := DBAttachment.CreateAttachment("solo.zip", "application/zip", "-solo-submission-")
+			solo, err := DBAssignments.SubmitAssignment(5, assignmentId, attachment.ID, "My part")
+			g.Assert(err == nil).IsTrue()
+
+			submissions, err := DBAssignments.SubmitTeamAssignment(team, assignmentId, attachment.ID, "Our draft")
+			g.Assert(err == nil).IsTrue()
+			g.Assert(len(submissions)).Equal(2)
+
+			var previous Submission
+			DBAssignments.DB().First(&previous, "id = ?", solo.ID)
+			g.Assert(previous.Status).Equal(SubmissionCanceled)
+
+			active, _ := DBAssignments.FindActiveSubmission(assignmentId, 5)
+			g.Assert(active.TeamID != nil).IsTrue()
+		})
+
+		g.It("Should submit and grade the assignment for the whole team", func() {
+			team, _ := DBTeams.ReadTeam(assignmentId, teamId)
+			attachment, err := DBAttachment.CreateAttachment("team.zip", "application/zip", "-team-submission-")
+			g.Assert(err == nil).IsTrue()
+
+			submissions, err := DBAssignments.SubmitTeamAssignment(team, assignmentId, attachment.ID, "Our project")
+			g.Assert(err == nil).IsTrue()
+			g.Assert(len(submissions)).Equal(2)
+
+			submission, err := DBAssignments.GradeAssignment(submissions[0].ID, 68)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(submission.Grade).Equal(float64(68))
+
+			var teammate Submission
+			DBAssignments.DB().First(&teammate, "id = ?", submissions[1].ID)
+			g.Assert(teammate.Grade).Equal(float64(68))
+			g.Assert(teammate.GradedOn != nil).IsTrue()
+		})
+
+		g.It("Should not submit again once the team has been graded", func() {
+			team, _ := DBTeams.ReadTeam(assignmentId, teamId)
+			attachment, _ := DBAttachment.CreateAttachment("late.zip", "application/zip", "-team-resubmission-")
+
+			_, err := DBAssignments.SubmitTeamAssignment(team, assignmentId, attachment.ID, "One more change")
+			g.Assert(err).Equal(ErrSubmissionLocked)
+		})
+
+		g.It("Should be able to remove a member from a team", func() {
+			count, err := DBTeams.RemoveTeamMember(teamId, 5)
+
+			g.Assert(err == nil).IsTrue()
+			g.Assert(count == 1).IsTrue()
+		})
+
+		g.It("Should generate balanced teams from the students of the module", func() {
+			teams, err := DBTeams.GenerateTeams(assignmentId, "AC31007", 1)
+
+			g.Assert(err == nil).IsTrue()
+			g.Assert(len(teams)).Equal(2)
+			g.Assert(len(teams[0].Members)).Equal(1)
+			g.Assert(len(teams[1].Members)).Equal(1)
+
+			// The previous teams are replaced
+			team, err := DBTeams.ReadTeam(assignmentId, teamId)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(team == nil).IsTrue()
+		})
+
+		g.It("Should not generate teams with an invalid size", func() {
+			_, err := DBTeams.GenerateTeams(assignmentId, "AC31007", 0)
+
+			g.Assert(err != nil).IsTrue()
+		})
+
+		g.It("Should be able to delete a team", func() {
+			teams, _ := DBTeams.FindTeamsForAssignment(assignmentId)
+			count, err := DBTeams.DeleteTeam(assignmentId, teams[0].ID)
+
+			g.Assert(err == nil).IsTrue()
+			g.Assert(count == 1).IsTrue()
+		})
+	})
+
+	g.Describe("When only one member of a team has an extension", func() {
+		var lateAssignmentId uint32
+		var team *Team
+
+		g.Before(func() {
+			assignment, _ := DBAssignments.CreateAssignment(Assignment{
+				Title: "Late Team Project",
+				Description: "Finish it together",
+				Status: AssignmentAvailable,
+				TeamAssignment: true,
+				LateDays: 5,
+				LatePenalty: 10,
+				Start: time.Now().AddDate(0, 0, -10),
+				End: time.Now().AddDate(0, 0, -2),
+				ModuleCode: "AC31007",
+			})
+			lateAssignmentId = assignment.ID
+
+			team, _ = DBTeams.CreateTeam(lateAssignmentId, "The Latecomers", []uint32{ 3, 5 })
+			DBAssignments.GrantExtension(AssignmentExtension{ AssignmentID: lateAssignmentId, UserID: 5, End: time.Now().AddDate(0, 0, 1) })
+		})
+
+		g.After(func() {
+			DBAssignments.DB().Where("assignment_id = ?", lateAssignmentId).Delete(Submission{})
+			DBAssignments.RevokeExtension(lateAssignmentId, 5)
+			DBTeams.DeleteTeam(lateAssignmentId, team.ID)
+			DBAssignments.DeleteAssignment(lateAssignmentId)
+		})
+
+		g.It("Should use the latest deadline of the members for the whole team", func() {
+			deadline, err := DBAssignments.FindTeamDeadline(&Assignment{ ID: lateAssignmentId, End: time.Now().AddDate(0, 0, -2) }, team.ID)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(deadline.After(time.Now())).IsTrue()
+		})
+
+		g.It("Should give every member the same penalty whichever submission is graded", func() {
+			attachment, _ := DBAttachment.CreateAttachment("late-team.zip", "application/zip", "-late-team-submission-")
+			submissions, err := DBAssignments.SubmitTeamAssignment(team, lateAssignmentId, attachment.ID, "Just in time")
+			g.Assert(err == nil).IsTrue()
+
+			for _, submission := range submissions {
+				g.Assert(submission.Late).IsFalse()
+			}
+
+			for _, submission := range submissions {
+				graded, err := DBAssignments.GradeAssignment(submission.ID, 80)
+				g.Assert(err == nil).IsTrue()
+				g.Assert(graded.Penalty).Equal(float64(0))
+				g.Assert(graded.Grade).Equal(float64(80))
+			}
+		})
+	})
+}
@@ -1,11 +1,11 @@
 package models
 
 import (
+	"strconv"
 	"io"
 	"fmt"
 	"time"
 	"regexp"
-	"strings"
 	"encoding/json"
 
 	"github.com/albrow/forms"
@@ -101,7 +101,7 @@ func (model UserModel) CreateUser(user *User) (int64, error) {
 	query := DBUser.DB().Create(user)
 
 	if query.Error != nil {
-		isDuplicated := strings.HasPrefix(query.Error.Error(), "Error 1062")
+		isDuplicated := database.IsDuplicatedError(query.Error)
 		if isDuplicated {
 			return -1, nil
 		}
@@ -113,13 +113,12 @@ func (model UserModel) CreateUser(user *User) (int64, error) {
 }
 
 func (model UserModel) CleanResetPasswordTokens(userId *uint32) (int64, error) {
-	queryText := "expires <= now()"
-
+	query := model.DB().Table("reset_passwords").Where("expires <= ?", time.Now())
 	if userId != nil {
-		queryText = fmt.Sprintf("%s or user_id = %d", queryText, *userId)
+		query = query.Or("user_id = ?", *userId)
 	}
 
-	query := model.DB().Table("reset_passwords").Where(queryText).Delete(ResetPassword{})
+	query = query.Delete(ResetPassword{})
 	if query.Error != nil {
 		return 0, query.Error
 	}
@@ -146,7 +145,7 @@ func (model UserModel) AddResetPasswordToken(userId uint32) (*string, error) {
 	}
 
 	// Insert into DB
-	query := DBUser.DB().Create(resetPassword)
+	query := DBUser.DB().Create(&resetPassword)
 	if query.Error != nil {
 		return nil, query.Error
 	}
@@ -191,7 +190,7 @@ func (model UserModel) UpdateStudent(user *User) (*User, error) {
 	})
 
 	if query.Error != nil {
-		isDuplicated := strings.HasPrefix(query.Error.Error(), "Error 1062")
+		isDuplicated := database.IsDuplicatedError(query.Error)
 		if isDuplicated {
 			return nil, nil
 		}
@@ -233,13 +232,16 @@ func (model UserModel) SearchUsers(text, moduleCode string) ([]User, error) {
 	var users []User
 	queryText := fmt.Sprint("%", text, "%")
 
+	// Only numeric queries can match an id (Postgres won't compare an integer column with text)
+	id, _ := strconv.ParseUint(text, 10, 32)
+
 	// Query the User
 	query := model.DB().Table("users").Preload("Avatar").Limit(10).Select("distinct users.*").Find(
 		&users,
 		"(users.username like ? or users.email like ? or users.matric_number like ? or users.first_name like ? or " +
 		"users.last_name like ? or users.id = ?) and " +
 		"(users.id not in (select user_id from user_modules where module_code = ?))",
-		queryText, queryText, queryText, queryText, queryText, text, moduleCode,
+		queryText, queryText, queryText, queryText, queryText, id, moduleCode,
 	)
 	if query.Error != nil {
 		return users, query.Error
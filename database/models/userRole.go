@@ -1,8 +1,11 @@
 package models
 
 import (
+	"encoding/json"
+
 	"github.com/jinzhu/gorm"
 	"github.com/YagoCarballo/kumquat-academy-api/database"
+	. "github.com/YagoCarballo/kumquat-academy-api/constants"
 )
 
 type (
@@ -29,4 +32,243 @@ func (model UserRoleModel) FindUserRole(roleName string) (*Role, error) {
 	}
 
 	return &userRole, nil
-}
\ No newline at end of file
+}
+
+func (model UserRoleModel) FindRoles() ([]Role, error) {
+	var roles []Role
+
+	query := model.DB().Preload("Capabilities").Order("id").Find(&roles)
+	if query.Error != nil {
+		return nil, query.Error
+	}
+
+	return roles, nil
+}
+
+func (model UserRoleModel) ReadRole(roleId uint32) (*Role, error) {
+	var role Role
+
+	query := model.DB().Preload("Capabilities").First(&role, "id = ?", roleId)
+	if query.Error != nil {
+		// If no Records found, return NIL otherwise return the error
+		switch query.Error {
+		case gorm.ErrRecordNotFound:
+			return nil, nil
+		default:
+			return nil, query.Error
+		}
+	}
+
+	return &role, nil
+}
+
+func (model UserRoleModel) CreateRole(role Role) (*Role, error) {
+	capabilities := role.Capabilities
+	role.ID = 0
+	role.Capabilities = nil
+
+	tx := model.DB().Begin()
+
+	query := tx.Create(&role)
+	if query.Error != nil {
+		tx.Rollback()
+		return nil, query.Error
+	}
+
+	err := setCapabilities(tx, role.ID, capabilities)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	query = tx.Commit()
+	if query.Error != nil {
+		return nil, query.Error
+	}
+
+	return model.ReadRole(role.ID)
+}
+
+// Replaces the name, description, rights and capabilities of a role, the users with the role get them straight away
+func (model UserRoleModel) UpdateRole(roleId uint32, role Role) (*Role, error) {
+	existing, err := model.ReadRole(roleId)
+	if err != nil || existing == nil {
+		return nil, err
+	}
+
+	tx := model.DB().Begin()
+
+	// A map is used so the rights can be set to false
+	query := tx.Table("roles").Where("id = ?", roleId).Updates(map[string]interface{}{
+		"name": role.Name,
+		"description": role.Description,
+		"can_read": role.CanRead,
+		"can_write": role.CanWrite,
+		"can_delete": role.CanDelete,
+		"can_update": role.CanUpdate,
+	})
+	if query.Error != nil {
+		tx.Rollback()
+		return nil, query.Error
+	}
+
+	err = setCapabilities(tx, roleId, role.Capabilities)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	query = tx.Commit()
+	if query.Error != nil {
+		return nil, query.Error
+	}
+
+	return model.ReadRole(roleId)
+}
+
+func setCapabilities(tx *gorm.DB, roleId uint32, capabilities []RoleCapability) error {
+	query := tx.Where("role_id = ?", roleId).Delete(RoleCapability{})
+	if query.Error != nil {
+		return query.Error
+	}
+
+	added := map[AccessRight]bool{}
+	for _, capability := range capabilities {
+		if added[capability.Capability] {
+			continue
+		}
+
+		query = tx.Create(&RoleCapability{RoleID: roleId, Capability: capability.Capability})
+		if query.Error != nil {
+			return query.Error
+		}
+		added[capability.Capability] = true
+	}
+
+	return nil
+}
+
+func (role Role) HasFlag(flag AccessRight) bool {
+	switch flag {
+	case ReadPermission:
+		return role.CanRead
+	case WritePermission:
+		return role.CanWrite
+	case DeletePermission:
+		return role.CanDelete
+	case UpdatePermission:
+		return role.CanUpdate
+	}
+
+	return false
+}
+
+// The capabilities the role grants, roles that don't list any get the ones allowed by their flags
+func (role Role) Granted() []AccessRight {
+	granted := []AccessRight{}
+
+	if len(role.Capabilities) > 0 {
+		for _, capability := range role.Capabilities {
+			granted = append(granted, capability.Capability)
+		}
+		return granted
+	}
+
+	for _, capability := range Capabilities {
+		if role.HasFlag(capability.Flag) {
+			granted = append(granted, capability.Name)
+		}
+	}
+
+	return granted
+}
+
+func (capability RoleCapability) MarshalJSON() ([]byte, error) {
+	return json.Marshal(capability.Capability)
+}
+
+func (capability *RoleCapability) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, &capability.Capability)
+}
+
+// Gives the user a role on the module, replacing the role they had
+func (model UserRoleModel) AssignModuleRole(moduleCode string, userId, roleId uint32) (*UserModule, error) {
+	levelModule, err := DBModule.FindModuleWithCode(moduleCode)
+	if err != nil || levelModule == nil {
+		return nil, err
+	}
+
+	userModule := UserModule{
+		UserID: userId,
+		ModuleCode: moduleCode,
+		RoleID: roleId,
+		ClassID: levelModule.ClassID,
+	}
+
+	var existing int
+	query := model.DB().Table("user_modules").Where("user_id = ? and module_code = ?", userId, moduleCode).Count(&existing)
+	if query.Error != nil {
+		return nil, query.Error
+	}
+
+	if existing > 0 {
+		query = model.DB().Table("user_modules").Where("user_id = ? and module_code = ?", userId, moduleCode).Update("role_id", roleId)
+	} else {
+		query = model.DB().Create(&userModule)
+	}
+	if query.Error != nil {
+		return nil, query.Error
+	}
+
+	return &userModule, nil
+}
+
+func (model UserRoleModel) UnassignModuleRole(moduleCode string, userId uint32) (int64, error) {
+	query := model.DB().
+		Table("user_modules").
+		Where("user_id = ? and module_code = ?", userId, moduleCode).
+		Delete(UserModule{})
+	if query.Error != nil {
+		return 0, query.Error
+	}
+
+	return query.RowsAffected, nil
+}
+
+// Gives the user a role on the course, replacing the role they had
+func (model UserRoleModel) AssignCourseRole(courseId, userId, roleId uint32) (*UserCourse, error) {
+	userCourse := UserCourse{
+		UserID: userId,
+		CourseID: courseId,
+		RoleID: roleId,
+	}
+
+	var existing int
+	query := model.DB().Table("user_courses").Where("user_id = ? and course_id = ?", userId, courseId).Count(&existing)
+	if query.Error != nil {
+		return nil, query.Error
+	}
+
+	if existing > 0 {
+		query = model.DB().Table("user_courses").Where("user_id = ? and course_id = ?", userId, courseId).Update("role_id", roleId)
+	} else {
+		query = model.DB().Create(&userCourse)
+	}
+	if query.Error != nil {
+		return nil, query.Error
+	}
+
+	return &userCourse, nil
+}
+
+func (model UserRoleModel) UnassignCourseRole(courseId, userId uint32) (int64, error) {
+	query := model.DB().
+		Table("user_courses").
+		Where("user_id = ? and course_id = ?", userId, courseId).
+		Delete(UserCourse{})
+	if query.Error != nil {
+		return 0, query.Error
+	}
+
+	return query.RowsAffected, nil
+}
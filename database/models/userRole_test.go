@@ -2,7 +2,9 @@ package models
 
 import (
 	"testing"
+	"encoding/json"
 	. "github.com/franela/goblin"
+	. "github.com/YagoCarballo/kumquat-academy-api/constants"
 )
 
 func Test_Database_UserRoles(t *testing.T) {
@@ -23,4 +25,146 @@ func Test_Database_UserRoles(t *testing.T) {
 			g.Assert(user == nil).IsTrue()
 		})
 	})
+	g.Describe("When administering Roles", func() {
+		var role *Role
+
+		g.It("Should create a custom role", func() {
+			var err error
+			role, err = DBUserRole.CreateRole(Role{Name: "-reviewer-", Description: "Reviews the work.", CanRead: true, CanUpdate: true})
+
+			g.Assert(err == nil).IsTrue()
+			g.Assert(role.ID > 0).IsTrue()
+
+			roles, err := DBUserRole.FindRoles()
+			g.Assert(err == nil).IsTrue()
+			g.Assert(roles[len(roles) - 1].Name).Equal("-reviewer-")
+		})
+
+		g.It("Should not create two roles with the same name", func() {
+			duplicated, err := DBUserRole.CreateRole(Role{Name: "-reviewer-"})
+
+			g.Assert(err != nil).IsTrue()
+			g.Assert(duplicated == nil).IsTrue()
+		})
+
+		g.It("Should give a user a role on a module", func() {
+			g.Assert(DBPermissions.IsActionPermitted(uint32(4), ModuleResource, "AC31007", ReadPermission)).IsFalse()
+
+			userModule, err := DBUserRole.AssignModuleRole("AC31007", uint32(4), role.ID)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(userModule.ClassID > 0).IsTrue()
+
+			g.Assert(DBPermissions.IsActionPermitted(uint32(4), ModuleResource, "AC31007", UpdatePermission)).IsTrue()
+			g.Assert(DBPermissions.IsActionPermitted(uint32(4), ModuleResource, "AC31007", WritePermission)).IsFalse()
+		})
+
+		g.It("Should apply the changes of a role to its users", func() {
+			updated, err := DBUserRole.UpdateRole(role.ID, Role{Name: "-reviewer-", CanRead: true})
+			g.Assert(err == nil).IsTrue()
+			g.Assert(updated.CanUpdate).IsFalse()
+
+			g.Assert(DBPermissions.IsActionPermitted(uint32(4), ModuleResource, "AC31007", ReadPermission)).IsTrue()
+			g.Assert(DBPermissions.IsActionPermitted(uint32(4), ModuleResource, "AC31007", UpdatePermission)).IsFalse()
+		})
+
+		g.It("Should replace the role of a user on a module", func() {
+			_, err := DBUserRole.AssignModuleRole("AC31007", uint32(4), uint32(2))
+			g.Assert(err == nil).IsTrue()
+			g.Assert(DBPermissions.IsActionPermitted(uint32(4), ModuleResource, "AC31007", WritePermission)).IsTrue()
+
+			rows, err := DBUserRole.UnassignModuleRole("AC31007", uint32(4))
+			g.Assert(err == nil).IsTrue()
+			g.Assert(rows).Equal(int64(1))
+			g.Assert(DBPermissions.IsActionPermitted(uint32(4), ModuleResource, "AC31007", ReadPermission)).IsFalse()
+		})
+
+		g.It("Should not give a role on a missing module", func() {
+			userModule, err := DBUserRole.AssignModuleRole("-missing-", uint32(4), role.ID)
+
+			g.Assert(err == nil).IsTrue()
+			g.Assert(userModule == nil).IsTrue()
+		})
+
+		g.It("Should give and take a user's role on a course", func() {
+			_, err := DBUserRole.AssignCourseRole(uint32(1), uint32(4), role.ID)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(DBPermissions.IsActionPermitted(uint32(4), CourseResource, uint32(1), ReadPermission)).IsTrue()
+			g.Assert(DBPermissions.IsActionPermitted(uint32(4), CourseResource, uint32(1), WritePermission)).IsFalse()
+
+			rows, err := DBUserRole.UnassignCourseRole(uint32(1), uint32(4))
+			g.Assert(err == nil).IsTrue()
+			g.Assert(rows).Equal(int64(1))
+			g.Assert(DBPermissions.IsActionPermitted(uint32(4), CourseResource, uint32(1), ReadPermission)).IsFalse()
+		})
+
+		g.After(func() {
+			DBUserRole.DB().Where("name = ?", "-reviewer-").Delete(Role{})
+		})
+	})
+	g.Describe("When roles grant capabilities", func() {
+		var role *Role
+
+		g.It("Should give the legacy roles the capabilities allowed by their flags", func() {
+			teacher, _ := DBUserRole.FindUserRole("Teacher")
+			student, _ := DBUserRole.FindUserRole("Student")
+
+			g.Assert(len(teacher.Granted())).Equal(len(Capabilities))
+			g.Assert(len(student.Granted())).Equal(0)
+		})
+
+		g.It("Should create a role with capabilities", func() {
+			var err error
+			role, err = DBUserRole.CreateRole(Role{
+				Name: "-grader-",
+				CanRead: true,
+				Capabilities: []RoleCapability{{Capability: GradeAssignmentPermission}, {Capability: GradeAssignmentPermission}},
+			})
+
+			g.Assert(err == nil).IsTrue()
+			g.Assert(len(role.Capabilities)).Equal(1)
+			g.Assert(role.Granted()).Equal([]AccessRight{GradeAssignmentPermission})
+		})
+
+		g.It("Should let the users grade without editing", func() {
+			_, err := DBUserRole.AssignModuleRole("AC31007", uint32(4), role.ID)
+			g.Assert(err == nil).IsTrue()
+
+			g.Assert(DBPermissions.IsActionPermitted(uint32(4), ModuleResource, "AC31007", GradeAssignmentPermission)).IsTrue()
+			g.Assert(DBPermissions.IsActionPermitted(uint32(4), ModuleResource, "AC31007", WritePermission)).IsFalse()
+			g.Assert(DBPermissions.IsActionPermitted(uint32(4), ModuleResource, "AC31007", EditLecturePermission)).IsFalse()
+		})
+
+		g.It("Should replace the capabilities of a role", func() {
+			updated, err := DBUserRole.UpdateRole(role.ID, Role{
+				Name: "-grader-",
+				CanRead: true,
+				Capabilities: []RoleCapability{{Capability: EditLecturePermission}},
+			})
+			g.Assert(err == nil).IsTrue()
+			g.Assert(updated.Granted()).Equal([]AccessRight{EditLecturePermission})
+
+			g.Assert(DBPermissions.IsActionPermitted(uint32(4), ModuleResource, "AC31007", GradeAssignmentPermission)).IsFalse()
+			g.Assert(DBPermissions.IsActionPermitted(uint32(4), ModuleResource, "AC31007", EditLecturePermission)).IsTrue()
+		})
+
+		g.It("Should write the capabilities in JSON as their names", func() {
+			data, err := json.Marshal(Role{Name: "-grader-", Capabilities: []RoleCapability{{Capability: EditLecturePermission}}})
+			g.Assert(err == nil).IsTrue()
+
+			var parsed Role
+			err = json.Unmarshal(data, &parsed)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(parsed.Capabilities[0].Capability).Equal(EditLecturePermission)
+
+			var raw map[string]interface{}
+			json.Unmarshal(data, &raw)
+			g.Assert(raw["capabilities"]).Equal([]interface{}{"lecture.edit"})
+		})
+
+		g.After(func() {
+			DBUserRole.UnassignModuleRole("AC31007", uint32(4))
+			DBUserRole.DB().Where("role_id = ?", role.ID).Delete(RoleCapability{})
+			DBUserRole.DB().Where("name = ?", "-grader-").Delete(Role{})
+		})
+	})
 }
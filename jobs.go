@@ -0,0 +1,169 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/YagoCarballo/kumquat-academy-api/database/models"
+	"github.com/YagoCarballo/kumquat-academy-api/mailer"
+	"github.com/YagoCarballo/kumquat-academy-api/scheduler"
+	"github.com/YagoCarballo/kumquat-academy-api/tools"
+)
+
+// How long before the deadline the students that haven't submitted an assignment get a reminder
+const REMINDER_NOTICE = 24 * time.Hour
+
+// How long the emails are kept in the outbox after being sent
+const SENT_EMAILS_KEPT = 30 * 24 * time.Hour
+
+// Creates the scheduler with the background jobs set up in the settings
+func loadJobs(settings tools.Scheduler) (*scheduler.Scheduler, error) {
+	jobs := scheduler.New()
+
+	schedules := []struct {
+		name		string
+		expression	string
+		fallback	string
+		run			scheduler.JobFunc
+	}{
+		{"statuses", settings.Statuses, "* * * * *", updateStatuses},
+		{"sessions", settings.Sessions, "@hourly", removeExpiredSessions},
+		{"reset_passwords", settings.ResetPasswords, "@daily", removeExpiredResetTokens},
+		{"reminders", settings.Reminders, "0 * * * *", sendDeadlineReminders},
+		{"emails", settings.Emails, "* * * * *", deliverEmails},
+	}
+
+	for _, schedule := range schedules {
+		expression := schedule.expression
+		if expression == "off" {
+			continue
+		}
+
+		if expression == "" {
+			expression = schedule.fallback
+		}
+
+		err := jobs.Add(schedule.name, expression, schedule.run)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid schedule for the job '%s': %s", schedule.name, err)
+		}
+	}
+
+	return jobs, nil
+}
+
+// Emails are only sent by the "emails" job, so with a transport set up the job has to run
+// or the emails would be kept in the outbox forever
+func checkEmailDelivery(settings *tools.Settings) error {
+	// The SMTP transport (the default one) needs a server to be set up
+	smtp := settings.Email.Transport == "" || settings.Email.Transport == "smtp"
+	if smtp && settings.Email.Server == "" {
+		return nil
+	}
+
+	if settings.Scheduler.Disabled || settings.Scheduler.Emails == "off" {
+		return fmt.Errorf("Emails are only sent by the 'emails' job, it has to be enabled in the scheduler settings.")
+	}
+
+	return nil
+}
+
+// Opens and closes the assignments and modules based on their dates
+func updateStatuses(since, now time.Time) error {
+	opened, closed, err := models.DBAssignments.UpdateScheduledStatuses(now)
+	if err != nil {
+		return err
+	}
+
+	if opened > 0 || closed > 0 {
+		log.Printf("%d assignment(s) opened, %d closed\n", opened, closed)
+	}
+
+	started, ended, err := models.DBModule.UpdateScheduledStatuses(now)
+	if err != nil {
+		return err
+	}
+
+	if started > 0 || ended > 0 {
+		log.Printf("%d module(s) started, %d ended\n", started, ended)
+	}
+
+	return nil
+}
+
+func removeExpiredSessions(since, now time.Time) error {
+	_, err := models.DBSession.RemoveExpiredSessions(now)
+	return err
+}
+
+func removeExpiredResetTokens(since, now time.Time) error {
+	_, err := models.DBUser.CleanResetPasswordTokens(nil)
+	return err
+}
+
+// Reminds the students that haven't submitted yet about the assignments due soon.
+// Each run covers the deadlines that came into the notice period since the last run, so nobody is reminded twice.
+func sendDeadlineReminders(since, now time.Time) error {
+	if since.IsZero() {
+		since = now
+	}
+
+	assignments, err := models.DBAssignments.FindAssignmentsDueBetween(since.Add(REMINDER_NOTICE), now.Add(REMINDER_NOTICE))
+	if err != nil {
+		return err
+	}
+
+	for _, assignment := range assignments {
+		roster, err := models.DBGradebook.FindAssignmentRoster(assignment.ModuleCode, assignment.ID)
+		if err != nil {
+			return err
+		}
+
+		for _, entry := range roster {
+			if entry.Submission != nil {
+				continue
+			}
+
+			// Students with an extension have a different deadline
+			extension, err := models.DBAssignments.FindExtension(assignment.ID, entry.User.ID)
+			if err != nil || extension != nil {
+				continue
+			}
+
+			err = emailDeadlineReminder(entry.User, assignment)
+			if err != nil {
+				log.Printf("Error reminding %s about the assignment %d: %s\n", entry.User.Email, assignment.ID, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func emailDeadlineReminder(user models.User, assignment models.Assignment) error {
+	_, err := mailer.Queue(user.Email, "deadline_reminder", "", map[string]interface{}{
+		"FirstName": user.FirstName,
+		"Assignment": assignment.Title,
+		"AssignmentID": assignment.ID,
+		"ModuleCode": assignment.ModuleCode,
+		"Deadline": assignment.End.Format("Monday 2 January 15:04"),
+	})
+
+	return err
+}
+
+// Sends the emails waiting in the outbox and removes the ones sent a while ago
+func deliverEmails(since, now time.Time) error {
+	sent, failed, err := mailer.Deliver(now)
+	if err != nil {
+		return err
+	}
+
+	if sent > 0 || failed > 0 {
+		log.Printf("%d email(s) sent, %d failed\n", sent, failed)
+	}
+
+	_, err = models.DBOutbox.RemoveSentEmails(now.Add(-SENT_EMAILS_KEPT))
+	return err
+}
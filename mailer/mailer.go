@@ -0,0 +1,110 @@
+package mailer
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/YagoCarballo/kumquat-academy-api/database/models"
+	"github.com/YagoCarballo/kumquat-academy-api/tools"
+)
+
+const (
+	// Emails that fail are tried again after 1, 2, 4 and 8 minutes before giving up
+	MAX_ATTEMPTS = 5
+	FIRST_RETRY = time.Minute
+
+	// How many emails are sent on each delivery
+	DELIVERY_BATCH = 50
+)
+
+var ErrTemplateNotFound = errors.New("Email template not found.")
+var ErrMissingSubject = errors.New("The email template doesn't define a subject.")
+
+// An email ready to be sent
+type Message struct {
+	To			string
+	Subject		string
+	Text		string
+	HTML		string
+}
+
+// Renders a template and stores the email in the outbox, the email is sent later by Deliver
+func Queue(to, template, language string, data map[string]interface{}) (*models.OutboxEmail, error) {
+	message, err := Render(template, language, data)
+	if err != nil {
+		return nil, err
+	}
+
+	return models.DBOutbox.QueueEmail(models.OutboxEmail{
+		Recipient: to,
+		Subject: message.Subject,
+		Text: message.Text,
+		HTML: message.HTML,
+	})
+}
+
+// Sends the emails due in the outbox with the transport in the settings,
+// the ones that fail are tried again later with an increasing delay
+func Deliver(now time.Time) (sent, failed int, err error) {
+	transport, err := NewTransport(tools.GetSettings().Email)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	emails, err := models.DBOutbox.ClaimDueEmails(now, DELIVERY_BATCH)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	for _, email := range emails {
+		sendError := safeSend(transport, Message{
+			To: email.Recipient,
+			Subject: email.Subject,
+			Text: email.Text,
+			HTML: email.HTML,
+		})
+
+		if sendError == nil {
+			sent++
+			err = models.DBOutbox.MarkEmailSent(email.ID, time.Now())
+		} else {
+			failed++
+			log.Printf("Error sending the email %d to %s (attempt %d): %s\n", email.ID, email.Recipient, email.Attempts, sendError)
+			err = models.DBOutbox.MarkEmailFailed(email.ID, sendError, NextAttempt(email.Attempts, now))
+		}
+
+		if err != nil {
+			return sent, failed, err
+		}
+	}
+
+	return sent, failed, nil
+}
+
+// Works out when to try an email again after the given number of attempts, nil when there are no attempts left
+func NextAttempt(attempts int, now time.Time) *time.Time {
+	if attempts >= MAX_ATTEMPTS {
+		return nil
+	}
+
+	delay := FIRST_RETRY
+	for attempt := 1; attempt < attempts; attempt++ {
+		delay *= 2
+	}
+
+	retryOn := now.Add(delay)
+	return &retryOn
+}
+
+// Sends a message, turning a panic of the transport into an error
+func safeSend(transport Transport, message Message) (err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("panic: %v", recovered)
+		}
+	}()
+
+	return transport.Send(message)
+}
@@ -0,0 +1,149 @@
+package mailer
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/franela/goblin"
+
+	"github.com/YagoCarballo/kumquat-academy-api/database"
+	"github.com/YagoCarballo/kumquat-academy-api/database/models"
+	"github.com/YagoCarballo/kumquat-academy-api/tools"
+)
+
+// The path to the Settings file
+const SETTINGS_PATH = "test-settings.toml"
+
+func init() {
+	tools.LoadSettings(SETTINGS_PATH)
+	database.InitDatabase()
+
+	// Brings a fresh database to the current schema and loads the fixtures
+	database.Migrate()
+	models.Seed(false)
+}
+
+// Fails every time, to check the retries
+type failingTransport struct{}
+
+func (transport failingTransport) Send(message Message) error {
+	return errors.New("Server unavailable.")
+}
+
+func Test_Mailer(t *testing.T) {
+	g := Goblin(t)
+	settings := tools.GetSettings()
+
+	g.Describe("When rendering the email templates", func() {
+		g.Before(func() {
+			settings.Email.Templates = "../templates/emails"
+			settings.Server.BaseUrl = "https://example.com/"
+		})
+
+		g.It("Should render the subject, text and HTML with the base URL", func() {
+			message, err := Render("reset_password", "en", map[string]interface{}{ "Token": "abc" })
+
+			g.Assert(err == nil).IsTrue()
+			g.Assert(message.Subject).Equal("Password Reset")
+			g.Assert(strings.Contains(message.Text, "https://example.com/password/reset/abc")).IsTrue()
+			g.Assert(strings.Contains(message.HTML, `href="https://example.com/password/reset/abc"`)).IsTrue()
+		})
+
+		g.It("Should use the template of the language", func() {
+			message, err := Render("reset_password", "es", map[string]interface{}{ "Token": "abc" })
+
+			g.Assert(err == nil).IsTrue()
+			g.Assert(message.Subject).Equal("Cambio de contraseña")
+		})
+
+		g.It("Should fall back to the default language", func() {
+			message, err := Render("welcome", "fr", map[string]interface{}{ "Token": "abc" })
+
+			g.Assert(err == nil).IsTrue()
+			g.Assert(strings.HasPrefix(message.Subject, "Welcome to")).IsTrue()
+		})
+
+		g.It("Should fail with a missing template", func() {
+			_, err := Render("-missing-", "en", nil)
+			g.Assert(err).Equal(ErrTemplateNotFound)
+		})
+
+		g.It("Should read the language of the Accept-Language header", func() {
+			g.Assert(HeaderLanguage("es-ES,es;q=0.8,en")).Equal("es")
+			g.Assert(HeaderLanguage("EN")).Equal("en")
+			g.Assert(HeaderLanguage("*")).Equal("")
+			g.Assert(HeaderLanguage("")).Equal("")
+		})
+	})
+
+	g.Describe("When delivering the emails", func() {
+		var directory string
+
+		g.Before(func() {
+			directory, _ = ioutil.TempDir("", "mailer-test")
+			settings.Email.Transport = "file"
+			settings.Email.Directory = directory
+		})
+
+		g.It("Should double the delay between attempts and give up after the last one", func() {
+			now := time.Now()
+
+			g.Assert(NextAttempt(1, now).Sub(now)).Equal(time.Minute)
+			g.Assert(NextAttempt(3, now).Sub(now)).Equal(4 * time.Minute)
+			g.Assert(NextAttempt(MAX_ATTEMPTS, now) == nil).IsTrue()
+		})
+
+		g.It("Should send the queued emails with the transport", func() {
+			email, err := Queue("student@kumquat.academy", "welcome", "", map[string]interface{}{ "Token": "abc" })
+			g.Assert(err == nil).IsTrue()
+			g.Assert(email.Status).Equal(models.EmailPending)
+
+			sent, failed, err := Deliver(time.Now())
+			g.Assert(err == nil).IsTrue()
+			g.Assert(sent).Equal(1)
+			g.Assert(failed).Equal(0)
+
+			files, _ := ioutil.ReadDir(directory)
+			g.Assert(len(files)).Equal(1)
+
+			email, _ = models.DBOutbox.ReadEmail(email.ID)
+			g.Assert(email.Status).Equal(models.EmailSent)
+			g.Assert(email.SentOn != nil).IsTrue()
+
+			// Nothing else is due
+			sent, _, _ = Deliver(time.Now())
+			g.Assert(sent).Equal(0)
+		})
+
+		g.It("Should retry the emails that fail", func() {
+			email, _ := Queue("student@kumquat.academy", "welcome", "", map[string]interface{}{ "Token": "abc" })
+
+			emails, _ := models.DBOutbox.ClaimDueEmails(time.Now(), DELIVERY_BATCH)
+			g.Assert(len(emails)).Equal(1)
+
+			err := safeSend(failingTransport{}, Message{ To: email.Recipient })
+			models.DBOutbox.MarkEmailFailed(email.ID, err, NextAttempt(emails[0].Attempts, time.Now()))
+
+			email, _ = models.DBOutbox.ReadEmail(email.ID)
+			g.Assert(email.Status).Equal(models.EmailPending)
+			g.Assert(email.Attempts).Equal(1)
+			g.Assert(email.LastError).Equal("Server unavailable.")
+
+			// It isn't due until the retry
+			sent, _, _ := Deliver(time.Now())
+			g.Assert(sent).Equal(0)
+
+			sent, _, _ = Deliver(time.Now().Add(2 * time.Minute))
+			g.Assert(sent).Equal(1)
+		})
+
+		g.After(func() {
+			models.DBOutbox.DB().Delete(models.OutboxEmail{})
+			os.RemoveAll(directory)
+		})
+	})
+}
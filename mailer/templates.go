@@ -0,0 +1,124 @@
+package mailer
+
+import (
+	"bytes"
+	htmlTemplate "html/template"
+	"os"
+	"path/filepath"
+	"strings"
+	textTemplate "text/template"
+
+	"github.com/YagoCarballo/kumquat-academy-api/tools"
+)
+
+// Every email has a text and an HTML template (name.txt and name.html) inside the folder of each language,
+// the subject is defined in the text template with {{define "subject"}}...{{end}}
+const (
+	TEXT_EXTENSION = ".txt"
+	HTML_EXTENSION = ".html"
+	SUBJECT_TEMPLATE = "subject"
+	DEFAULT_LANGUAGE = "en"
+	DEFAULT_TEMPLATES = "./templates/emails"
+	DEFAULT_BASE_URL = "https://kumquat.academy"
+)
+
+// Renders a template in the given language (falling back to the default one), the data can use
+// the {{.BaseUrl}} and {{.Title}} of the settings as well as its own values
+func Render(name, language string, data map[string]interface{}) (*Message, error) {
+	folder, err := findTemplateFolder(name, language)
+	if err != nil {
+		return nil, err
+	}
+
+	settings := tools.GetSettings()
+	baseUrl := settings.Server.BaseUrl
+	if baseUrl == "" {
+		baseUrl = DEFAULT_BASE_URL
+	}
+
+	values := map[string]interface{}{
+		"BaseUrl": strings.TrimRight(baseUrl, "/"),
+		"Title": settings.Title,
+	}
+	for key, value := range data {
+		values[key] = value
+	}
+
+	text, err := textTemplate.ParseFiles(filepath.Join(folder, name + TEXT_EXTENSION))
+	if err != nil {
+		return nil, err
+	}
+
+	if text.Lookup(SUBJECT_TEMPLATE) == nil {
+		return nil, ErrMissingSubject
+	}
+
+	var subject, textBody bytes.Buffer
+	err = text.ExecuteTemplate(&subject, SUBJECT_TEMPLATE, values)
+	if err != nil {
+		return nil, err
+	}
+
+	err = text.Execute(&textBody, values)
+	if err != nil {
+		return nil, err
+	}
+
+	message := &Message{
+		Subject: strings.TrimSpace(subject.String()),
+		Text: strings.TrimSpace(textBody.String()),
+	}
+
+	// The HTML version is optional
+	htmlPath := filepath.Join(folder, name + HTML_EXTENSION)
+	if _, err := os.Stat(htmlPath); err == nil {
+		html, err := htmlTemplate.ParseFiles(htmlPath)
+		if err != nil {
+			return nil, err
+		}
+
+		var htmlBody bytes.Buffer
+		err = html.Execute(&htmlBody, values)
+		if err != nil {
+			return nil, err
+		}
+		message.HTML = strings.TrimSpace(htmlBody.String())
+	}
+
+	return message, nil
+}
+
+func findTemplateFolder(name, language string) (string, error) {
+	emailSettings := tools.GetSettings().Email
+	templates := emailSettings.Templates
+	if templates == "" {
+		templates = DEFAULT_TEMPLATES
+	}
+
+	for _, candidate := range []string{ language, emailSettings.Language, DEFAULT_LANGUAGE } {
+		if candidate == "" {
+			continue
+		}
+
+		// The language is only used as a folder name
+		folder := filepath.Join(templates, filepath.Base(candidate))
+		if _, err := os.Stat(filepath.Join(folder, name + TEXT_EXTENSION)); err == nil {
+			return folder, nil
+		}
+	}
+
+	return "", ErrTemplateNotFound
+}
+
+// Gets the preferred language of an Accept-Language header ("es-ES,es;q=0.8,en" gives "es")
+func HeaderLanguage(header string) string {
+	language := strings.TrimSpace(strings.Split(header, ",")[0])
+	language = strings.Split(language, ";")[0]
+	language = strings.Split(language, "-")[0]
+
+	if language == "*" {
+		return ""
+	}
+
+	return strings.ToLower(language)
+}
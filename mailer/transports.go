@@ -0,0 +1,94 @@
+package mailer
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/smtp"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	emailHandler "github.com/jordan-wright/email"
+
+	"github.com/YagoCarballo/kumquat-academy-api/tools"
+)
+
+type (
+	// Delivers a message, returning an error means the message will be tried again later
+	Transport interface {
+		Send(message Message) error
+	}
+
+	smtpTransport struct {
+		settings	tools.Email
+	}
+
+	// Prints the emails instead of sending them, useful for development
+	logTransport struct{}
+
+	// Writes each email into a file in the directory, useful to check the emails in the tests
+	fileTransport struct {
+		directory	string
+	}
+)
+
+// Creates the transport set up in the email settings, SMTP is used by default
+func NewTransport(settings tools.Email) (Transport, error) {
+	switch settings.Transport {
+	case "", "smtp":
+		return smtpTransport{ settings: settings }, nil
+	case "log":
+		return logTransport{}, nil
+	case "file":
+		err := os.MkdirAll(settings.Directory, 0755)
+		if err != nil {
+			return nil, err
+		}
+		return fileTransport{ directory: settings.Directory }, nil
+	default:
+		return nil, fmt.Errorf("Unknown email transport '%s'.", settings.Transport)
+	}
+}
+
+func (transport smtpTransport) Send(message Message) error {
+	emailTemplate := &emailHandler.Email {
+		To: []string{ message.To },
+		From: transport.settings.Sender,
+		Subject: message.Subject,
+		Text: []byte(message.Text),
+		HTML: []byte(message.HTML),
+		Headers: textproto.MIMEHeader{},
+	}
+
+	smtpServer := fmt.Sprintf("%s:%d", transport.settings.Server, transport.settings.Port)
+	return emailTemplate.Send(
+		smtpServer,
+		smtp.PlainAuth("", transport.settings.User, transport.settings.Password, transport.settings.Server),
+	)
+}
+
+func (transport logTransport) Send(message Message) error {
+	log.Printf("Email to: %s\nSubject: %s\n\n%s\n", message.To, message.Subject, message.Text)
+	return nil
+}
+
+func (transport fileTransport) Send(message Message) error {
+	// Only letters, numbers and a few symbols are kept from the address in the file name
+	recipient := strings.Map(func(char rune) rune {
+		if char >= 'a' && char <= 'z' || char >= 'A' && char <= 'Z' || char >= '0' && char <= '9' || strings.ContainsRune("@.-_", char) {
+			return char
+		}
+		return '_'
+	}, message.To)
+
+	name := fmt.Sprintf("%d-%s.eml", time.Now().UnixNano(), recipient)
+	content := fmt.Sprintf("To: %s\nSubject: %s\n\n%s\n", message.To, message.Subject, message.Text)
+	if message.HTML != "" {
+		content += fmt.Sprintf("\n--- HTML ---\n%s\n", message.HTML)
+	}
+
+	return ioutil.WriteFile(filepath.Join(transport.directory, name), []byte(content), 0644)
+}
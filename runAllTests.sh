@@ -1,6 +1,9 @@
 #!/usr/bin/env bash
 
 set -e
+
+# The suites run against the database in test-settings.toml,
+# set DB_TYPE (mysql, postgres or sqlite) and DB_HOST, DB_USER, DB_PASS, DB_NAME to use another one.
 echo "" > coverage.txt
 
 for d in $(find ./* -maxdepth 10 -type d); do
@@ -0,0 +1,182 @@
+package scheduler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// When a job runs, parsed from a cron expression with the minute, hour, day of month, month and day of week fields.
+// Each field can be *, a number, a range (1-5), a list (1,15) and have a step (*/10 or 0-30/5).
+type Schedule struct {
+	minutes		uint64
+	hours		uint64
+	days		uint64
+	months		uint64
+	weekdays	uint64
+
+	// When both days are restricted, matching any of them is enough (as in cron)
+	anyDay		bool
+	anyWeekday	bool
+}
+
+type cronField struct {
+	name	string
+	min		int
+	max		int
+}
+
+var cronFields = []cronField{
+	{"minute", 0, 59},
+	{"hour", 0, 23},
+	{"day of month", 1, 31},
+	{"month", 1, 12},
+	{"day of week", 0, 7},
+}
+
+var cronDescriptors = map[string]string{
+	"@yearly":		"0 0 1 1 *",
+	"@annually":	"0 0 1 1 *",
+	"@monthly":		"0 0 1 * *",
+	"@weekly":		"0 0 * * 0",
+	"@daily":		"0 0 * * *",
+	"@midnight":	"0 0 * * *",
+	"@hourly":		"0 * * * *",
+}
+
+// Parses a cron expression (or one of the @hourly, @daily, @weekly, @monthly and @yearly descriptors)
+func ParseSchedule(expression string) (*Schedule, error) {
+	expression = strings.TrimSpace(expression)
+	if descriptor, ok := cronDescriptors[expression]; ok {
+		expression = descriptor
+	}
+
+	fields := strings.Fields(expression)
+	if len(fields) != len(cronFields) {
+		return nil, fmt.Errorf("Invalid cron expression '%s', it must have %d fields.", expression, len(cronFields))
+	}
+
+	bits := make([]uint64, len(cronFields))
+	for index, field := range fields {
+		var err error
+		bits[index], err = parseCronField(field, cronFields[index])
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Sunday can be either 0 or 7
+	if bits[4] & (1 << 7) != 0 {
+		bits[4] |= 1
+	}
+
+	return &Schedule{
+		minutes: bits[0],
+		hours: bits[1],
+		days: bits[2],
+		months: bits[3],
+		weekdays: bits[4],
+		anyDay: fields[2] == "*",
+		anyWeekday: fields[4] == "*",
+	}, nil
+}
+
+func parseCronField(field string, bounds cronField) (uint64, error) {
+	var bits uint64
+
+	for _, part := range strings.Split(field, ",") {
+		step := 1
+		stepped := false
+		if index := strings.Index(part, "/"); index >= 0 {
+			var err error
+			step, err = strconv.Atoi(part[index + 1:])
+			if err != nil || step <= 0 {
+				return 0, fmt.Errorf("Invalid step in the %s field '%s'.", bounds.name, field)
+			}
+			part = part[:index]
+			stepped = true
+		}
+
+		start, end := bounds.min, bounds.max
+		if part != "*" {
+			var err error
+			values := strings.SplitN(part, "-", 2)
+			start, err = strconv.Atoi(values[0])
+			if err != nil {
+				return 0, fmt.Errorf("Invalid value in the %s field '%s'.", bounds.name, field)
+			}
+
+			// A single value with a step (5/15) goes on until the end of the field
+			end = start
+			if stepped {
+				end = bounds.max
+			}
+
+			if len(values) > 1 {
+				end, err = strconv.Atoi(values[1])
+				if err != nil {
+					return 0, fmt.Errorf("Invalid range in the %s field '%s'.", bounds.name, field)
+				}
+			}
+		}
+
+		if start < bounds.min || end > bounds.max || start > end {
+			return 0, fmt.Errorf("The %s field '%s' must be between %d and %d.", bounds.name, field, bounds.min, bounds.max)
+		}
+
+		for value := start; value <= end; value += step {
+			bits |= 1 << uint(value)
+		}
+	}
+
+	return bits, nil
+}
+
+// Gets the first time after the given one that matches the schedule, to the minute.
+// A zero time is returned if nothing matches in the next five years (like the 30th of February).
+func (schedule *Schedule) Next(after time.Time) time.Time {
+	next := after.Truncate(time.Minute).Add(time.Minute)
+	limit := next.Year() + 5
+
+	for next.Year() <= limit {
+		if !hasBit(schedule.months, int(next.Month())) {
+			next = time.Date(next.Year(), next.Month() + 1, 1, 0, 0, 0, 0, next.Location())
+			continue
+		}
+
+		if !schedule.matchesDay(next) {
+			next = time.Date(next.Year(), next.Month(), next.Day() + 1, 0, 0, 0, 0, next.Location())
+			continue
+		}
+
+		if !hasBit(schedule.hours, next.Hour()) {
+			next = time.Date(next.Year(), next.Month(), next.Day(), next.Hour() + 1, 0, 0, 0, next.Location())
+			continue
+		}
+
+		if !hasBit(schedule.minutes, next.Minute()) {
+			next = next.Add(time.Minute)
+			continue
+		}
+
+		return next
+	}
+
+	return time.Time{}
+}
+
+func (schedule *Schedule) matchesDay(date time.Time) bool {
+	day := hasBit(schedule.days, date.Day())
+	weekday := hasBit(schedule.weekdays, int(date.Weekday()))
+
+	if schedule.anyDay || schedule.anyWeekday {
+		return day && weekday
+	}
+
+	return day || weekday
+}
+
+func hasBit(bits uint64, value int) bool {
+	return bits & (1 << uint(value)) != 0
+}
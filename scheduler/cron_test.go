@@ -0,0 +1,78 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/franela/goblin"
+
+	"github.com/YagoCarballo/kumquat-academy-api/database/models"
+)
+
+func Test_Scheduler_Cron(t *testing.T) {
+	g := Goblin(t)
+	start := time.Date(2016, time.March, 10, 10, 7, 30, 0, time.UTC) // Thursday
+
+	g.Describe("When parsing cron expressions", func() {
+		g.It("Should reject invalid expressions", func() {
+			for _, expression := range []string{ "", "* * * *", "60 * * * *", "* * 0 * *", "*/0 * * * *", "a * * * *", "5-1 * * * *", "@never" } {
+				_, err := ParseSchedule(expression)
+				g.Assert(err != nil).IsTrue()
+			}
+		})
+
+		g.It("Should find the next time with steps", func() {
+			schedule, err := ParseSchedule("*/15 * * * *")
+			g.Assert(err == nil).IsTrue()
+			g.Assert(schedule.Next(start)).Equal(time.Date(2016, time.March, 10, 10, 15, 0, 0, time.UTC))
+
+			schedule, _ = ParseSchedule("5/20 * * * *")
+			g.Assert(schedule.Next(start)).Equal(time.Date(2016, time.March, 10, 10, 25, 0, 0, time.UTC))
+		})
+
+		g.It("Should understand the descriptors", func() {
+			schedule, err := ParseSchedule("@daily")
+			g.Assert(err == nil).IsTrue()
+			g.Assert(schedule.Next(start)).Equal(time.Date(2016, time.March, 11, 0, 0, 0, 0, time.UTC))
+
+			schedule, _ = ParseSchedule("@hourly")
+			g.Assert(schedule.Next(start)).Equal(time.Date(2016, time.March, 10, 11, 0, 0, 0, time.UTC))
+		})
+
+		g.It("Should match either day when both are restricted", func() {
+			schedule, _ := ParseSchedule("0 9 15 * 1")
+			g.Assert(schedule.Next(start)).Equal(time.Date(2016, time.March, 14, 9, 0, 0, 0, time.UTC))
+
+			schedule, _ = ParseSchedule("0 9 15 * *")
+			g.Assert(schedule.Next(start)).Equal(time.Date(2016, time.March, 15, 9, 0, 0, 0, time.UTC))
+		})
+
+		g.It("Should take 7 as Sunday", func() {
+			schedule, _ := ParseSchedule("30 8 * * 7")
+			g.Assert(schedule.Next(start)).Equal(time.Date(2016, time.March, 13, 8, 30, 0, 0, time.UTC))
+		})
+
+		g.It("Should never match impossible dates", func() {
+			schedule, _ := ParseSchedule("0 0 30 2 *")
+			g.Assert(schedule.Next(start).IsZero()).IsTrue()
+		})
+	})
+
+	g.Describe("When working out the next run of a job", func() {
+		schedule, _ := ParseSchedule("@hourly")
+
+		g.It("Should wait for the schedule if the job never ran", func() {
+			g.Assert(NextRun(schedule, nil, start)).Equal(time.Date(2016, time.March, 10, 11, 0, 0, 0, time.UTC))
+		})
+
+		g.It("Should catch up straight away with a missed run", func() {
+			jobRun := &models.JobRun{ Name: "test", Runs: 3, LastRun: start.Add(-3 * time.Hour) }
+			g.Assert(NextRun(schedule, jobRun, start)).Equal(start)
+		})
+
+		g.It("Should wait for the schedule after an up to date run", func() {
+			jobRun := &models.JobRun{ Name: "test", Runs: 3, LastRun: start.Add(-time.Minute) }
+			g.Assert(NextRun(schedule, jobRun, start)).Equal(time.Date(2016, time.March, 10, 11, 0, 0, 0, time.UTC))
+		})
+	})
+}
@@ -0,0 +1,158 @@
+package scheduler
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/YagoCarballo/kumquat-academy-api/database/models"
+)
+
+type (
+	// A task that runs on a schedule, since is when it last ran (or zero if it never ran before)
+	JobFunc func(since, now time.Time) error
+
+	Job struct {
+		Name		string
+		Schedule	*Schedule
+		Run			JobFunc
+	}
+
+	// Runs the jobs inside the API process, the last run of each job is kept in the database
+	// so a restart carries on where it left off and only catches up once with the runs it missed.
+	Scheduler struct {
+		jobs	[]Job
+		stop	chan struct{}
+		running	sync.WaitGroup
+		started	bool
+	}
+)
+
+func New() *Scheduler {
+	return &Scheduler{
+		jobs: []Job{},
+		stop: make(chan struct{}),
+	}
+}
+
+// Adds a job with a cron expression, jobs must be added before the scheduler starts
+func (scheduler *Scheduler) Add(name, expression string, run JobFunc) error {
+	schedule, err := ParseSchedule(expression)
+	if err != nil {
+		return err
+	}
+
+	scheduler.jobs = append(scheduler.jobs, Job{ Name: name, Schedule: schedule, Run: run })
+	return nil
+}
+
+func (scheduler *Scheduler) Jobs() []Job {
+	return scheduler.jobs
+}
+
+func (scheduler *Scheduler) Start() {
+	if scheduler.started {
+		return
+	}
+	scheduler.started = true
+
+	for _, job := range scheduler.jobs {
+		scheduler.running.Add(1)
+		go scheduler.loop(job)
+	}
+}
+
+// Stops scheduling new runs and waits for the jobs that are running to finish
+func (scheduler *Scheduler) Stop() {
+	if !scheduler.started {
+		return
+	}
+	scheduler.started = false
+
+	close(scheduler.stop)
+	scheduler.running.Wait()
+}
+
+func (scheduler *Scheduler) loop(job Job) {
+	defer scheduler.running.Done()
+
+	for {
+		jobRun, err := models.DBJobRuns.FindJobRun(job.Name)
+		if err != nil {
+			log.Printf("Error reading the last run of the job '%s': %s\n", job.Name, err)
+		}
+
+		next := NextRun(job.Schedule, jobRun, time.Now())
+		if next.IsZero() {
+			log.Printf("The job '%s' will never run again\n", job.Name)
+			return
+		}
+
+		timer := time.NewTimer(next.Sub(time.Now()))
+		select {
+		case <-scheduler.stop:
+			timer.Stop()
+			return
+		case <-timer.C:
+			scheduler.run(job, jobRun)
+		}
+	}
+}
+
+// Works out when a job runs next. Jobs that have never run wait for their schedule,
+// while jobs that missed a run (because the server was down) run straight away.
+func NextRun(schedule *Schedule, jobRun *models.JobRun, now time.Time) time.Time {
+	if jobRun == nil {
+		return schedule.Next(now)
+	}
+
+	next := schedule.Next(jobRun.LastRun)
+	if !next.IsZero() && next.Before(now) {
+		return now
+	}
+
+	return next
+}
+
+func (scheduler *Scheduler) run(job Job, jobRun *models.JobRun) {
+	var runs uint64
+	var since time.Time
+	if jobRun != nil {
+		runs = jobRun.Runs
+		since = jobRun.LastRun
+	}
+
+	now := time.Now()
+	claimed, err := models.DBJobRuns.ClaimJobRun(job.Name, runs, now)
+	if err != nil {
+		log.Printf("Error claiming the job '%s': %s\n", job.Name, err)
+		return
+	}
+
+	// Someone else already ran it
+	if !claimed {
+		return
+	}
+
+	err = scheduler.safeRun(job, since, now)
+	if err != nil {
+		log.Printf("The job '%s' failed: %s\n", job.Name, err)
+	}
+
+	err = models.DBJobRuns.FinishJobRun(job.Name, err)
+	if err != nil {
+		log.Printf("Error saving the run of the job '%s': %s\n", job.Name, err)
+	}
+}
+
+// Runs a job, turning a panic into an error so it doesn't take the server down
+func (scheduler *Scheduler) safeRun(job Job, since, now time.Time) (err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("panic: %v", recovered)
+		}
+	}()
+
+	return job.Run(since, now)
+}
@@ -0,0 +1,74 @@
+package storage
+
+import (
+	"errors"
+	"io"
+)
+
+// A stored file that can be read and seeked like a local one (so it can be given to http.ServeContent).
+// Nothing is read until it's needed, and every seek starts a new stream from the new offset.
+type File struct {
+	*FileInfo
+	storage		Storage
+	offset		int64
+	reader		io.ReadCloser
+}
+
+var errInvalidSeek = errors.New("The offset is outside of the file.")
+
+func Open(storage Storage, name string) (*File, error) {
+	info, err := storage.Stat(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &File{ FileInfo: info, storage: storage }, nil
+}
+
+func (file *File) Read(buffer []byte) (int, error) {
+	if file.offset >= file.Size {
+		return 0, io.EOF
+	}
+
+	if file.reader == nil {
+		reader, err := file.storage.Stream(file.Name, file.offset, -1)
+		if err != nil {
+			return 0, err
+		}
+		file.reader = reader
+	}
+
+	read, err := file.reader.Read(buffer)
+	file.offset += int64(read)
+	return read, err
+}
+
+func (file *File) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekCurrent:
+		offset += file.offset
+	case io.SeekEnd:
+		offset += file.Size
+	}
+
+	if offset < 0 {
+		return file.offset, errInvalidSeek
+	}
+
+	if offset != file.offset {
+		file.Close()
+		file.offset = offset
+	}
+
+	return offset, nil
+}
+
+func (file *File) Close() error {
+	if file.reader == nil {
+		return nil
+	}
+
+	err := file.reader.Close()
+	file.reader = nil
+	return err
+}
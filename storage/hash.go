@@ -0,0 +1,44 @@
+package storage
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+)
+
+// Hashes the content with SHA-256, the hash is the name the content is stored under.
+// The content is rewound afterwards.
+func HashContent(content io.ReadSeeker) (string, int64, error) {
+	hash, size, err := hashReader(content)
+	if err != nil {
+		return "", 0, err
+	}
+
+	_, err = content.Seek(0, io.SeekStart)
+	if err != nil {
+		return "", 0, err
+	}
+
+	return hash, size, nil
+}
+
+// Reads a stored file and hashes it, to check it is still what was uploaded
+func Checksum(storage Storage, name string) (string, int64, error) {
+	file, err := storage.Get(name)
+	if err != nil {
+		return "", 0, err
+	}
+	defer file.Close()
+
+	return hashReader(file)
+}
+
+func hashReader(content io.Reader) (string, int64, error) {
+	hash := sha256.New()
+	size, err := io.Copy(hash, content)
+	if err != nil {
+		return "", 0, err
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), size, nil
+}
@@ -0,0 +1,158 @@
+package storage
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const tempPrefix = ".upload-"
+
+type (
+	// Keeps the files in a folder of the server
+	localStorage struct {
+		directory	string
+	}
+
+	// Closes the file once the limited part of it has been read
+	limitedFile struct {
+		io.Reader
+		file	*os.File
+	}
+)
+
+func NewLocal(directory string) (Storage, error) {
+	err := os.MkdirAll(directory, 0755)
+	if err != nil {
+		return nil, err
+	}
+
+	return localStorage{ directory: directory }, nil
+}
+
+func (storage localStorage) path(name string) (string, error) {
+	err := checkName(name)
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(storage.directory, name), nil
+}
+
+// The file is written to a temporary file first, so a failed upload never leaves half a file behind
+func (storage localStorage) Put(name string, content io.Reader, size int64, mimeType string) error {
+	path, err := storage.path(name)
+	if err != nil {
+		return err
+	}
+
+	temp, err := ioutil.TempFile(storage.directory, tempPrefix)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temp.Name())
+
+	_, err = io.Copy(temp, content)
+	if err != nil {
+		temp.Close()
+		return err
+	}
+
+	err = temp.Close()
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(temp.Name(), path)
+}
+
+func (storage localStorage) Get(name string) (io.ReadCloser, error) {
+	return storage.Stream(name, 0, -1)
+}
+
+func (storage localStorage) Stat(name string) (*FileInfo, error) {
+	path, err := storage.path(name)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return nil, ErrNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	return &FileInfo{
+		Name: name,
+		Size: info.Size(),
+		ModTime: info.ModTime(),
+	}, nil
+}
+
+// Removing a missing file is not an error
+func (storage localStorage) Delete(name string) error {
+	path, err := storage.path(name)
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
+
+func (storage localStorage) Stream(name string, offset, length int64) (io.ReadCloser, error) {
+	path, err := storage.path(name)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, ErrNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	if offset > 0 {
+		_, err = file.Seek(offset, io.SeekStart)
+		if err != nil {
+			file.Close()
+			return nil, err
+		}
+	}
+
+	if length < 0 {
+		return file, nil
+	}
+
+	return limitedFile{ Reader: io.LimitReader(file, length), file: file }, nil
+}
+
+func (storage localStorage) List() ([]string, error) {
+	files, err := ioutil.ReadDir(storage.directory)
+	if err != nil {
+		return nil, err
+	}
+
+	names := []string{}
+	for _, file := range files {
+		if file.IsDir() || strings.HasPrefix(file.Name(), tempPrefix) {
+			continue
+		}
+
+		names = append(names, file.Name())
+	}
+
+	return names, nil
+}
+
+func (file limitedFile) Close() error {
+	return file.file.Close()
+}
@@ -0,0 +1,196 @@
+package storage
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	. "github.com/franela/goblin"
+)
+
+func Test_Storage_Local(t *testing.T) {
+	g := Goblin(t)
+
+	directory, err := ioutil.TempDir("", "storage-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(directory)
+
+	store, err := NewLocal(directory)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	describeStorage(g, "When keeping the files in a folder", store)
+
+	g.Describe("When keeping the files in a folder", func() {
+		g.It("Should not leave temporary files behind", func() {
+			files, err := ioutil.ReadDir(directory)
+			g.Assert(err == nil).IsTrue()
+
+			for _, file := range files {
+				g.Assert(strings.HasPrefix(file.Name(), tempPrefix)).IsFalse()
+			}
+		})
+	})
+}
+
+// The behaviour every driver has to share
+func describeStorage(g *G, description string, store Storage) {
+	g.Describe(description, func() {
+		g.It("Should store a file", func() {
+			err := store.Put("file-a", strings.NewReader("0123456789"), 10, "text/plain")
+			g.Assert(err == nil).IsTrue()
+		})
+
+		g.It("Should store a file without knowing its size", func() {
+			err := store.Put("file-b", strings.NewReader("abc"), -1, "")
+			g.Assert(err == nil).IsTrue()
+		})
+
+		g.It("Should read a stored file", func() {
+			file, err := store.Get("file-a")
+			g.Assert(err == nil).IsTrue()
+			defer file.Close()
+
+			content, err := ioutil.ReadAll(file)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(string(content)).Equal("0123456789")
+		})
+
+		g.It("Should read a part of a stored file", func() {
+			file, err := store.Stream("file-a", 2, 3)
+			g.Assert(err == nil).IsTrue()
+			content, _ := ioutil.ReadAll(file)
+			file.Close()
+			g.Assert(string(content)).Equal("234")
+
+			file, err = store.Stream("file-a", 7, -1)
+			g.Assert(err == nil).IsTrue()
+			content, _ = ioutil.ReadAll(file)
+			file.Close()
+			g.Assert(string(content)).Equal("789")
+		})
+
+		g.It("Should give the size of a stored file", func() {
+			info, err := store.Stat("file-a")
+			g.Assert(err == nil).IsTrue()
+			g.Assert(info.Name).Equal("file-a")
+			g.Assert(info.Size).Equal(int64(10))
+		})
+
+		g.It("Should list the stored files", func() {
+			names, err := store.List()
+			g.Assert(err == nil).IsTrue()
+			g.Assert(strings.Join(names, ",")).Equal("file-a,file-b")
+		})
+
+		g.It("Should replace a file stored with the same name", func() {
+			err := store.Put("file-b", strings.NewReader("abcdef"), 6, "")
+			g.Assert(err == nil).IsTrue()
+
+			info, err := store.Stat("file-b")
+			g.Assert(err == nil).IsTrue()
+			g.Assert(info.Size).Equal(int64(6))
+		})
+
+		g.It("Should open a stored file that can be seeked", func() {
+			file, err := Open(store, "file-a")
+			g.Assert(err == nil).IsTrue()
+			defer file.Close()
+			g.Assert(file.Size).Equal(int64(10))
+
+			end, err := file.Seek(-4, io.SeekEnd)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(end).Equal(int64(6))
+
+			buffer := make([]byte, 2)
+			_, err = io.ReadFull(file, buffer)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(string(buffer)).Equal("67")
+
+			file.Seek(1, io.SeekStart)
+			content, err := ioutil.ReadAll(file)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(string(content)).Equal("123456789")
+
+			_, err = file.Seek(-1, io.SeekStart)
+			g.Assert(err != nil).IsTrue()
+
+			_, err = Open(store, "missing-file")
+			g.Assert(err == ErrNotFound).IsTrue()
+		})
+
+		g.It("Should copy a file to another storage only when it is not there already", func() {
+			directory, err := ioutil.TempDir("", "storage-copy")
+			g.Assert(err == nil).IsTrue()
+			defer os.RemoveAll(directory)
+
+			other, err := NewLocal(directory)
+			g.Assert(err == nil).IsTrue()
+
+			copied, err := Copy(store, other, "file-a", "text/plain")
+			g.Assert(err == nil).IsTrue()
+			g.Assert(copied).IsTrue()
+
+			copied, err = Copy(store, other, "file-a", "text/plain")
+			g.Assert(err == nil).IsTrue()
+			g.Assert(copied).IsFalse()
+
+			_, err = Copy(store, other, "missing-file", "")
+			g.Assert(err == ErrNotFound).IsTrue()
+		})
+
+		g.It("Should hash a stored file by its content", func() {
+			hash, size, err := HashContent(strings.NewReader("0123456789"))
+			g.Assert(err == nil).IsTrue()
+			g.Assert(hash).Equal("84d89877f0d4041efb6bf91a16f0248f2fd573e6af05c19f96bedb9f882f7882")
+			g.Assert(size).Equal(int64(10))
+
+			checksum, size, err := Checksum(store, "file-a")
+			g.Assert(err == nil).IsTrue()
+			g.Assert(checksum).Equal(hash)
+			g.Assert(size).Equal(int64(10))
+
+			_, _, err = Checksum(store, "missing-file")
+			g.Assert(err == ErrNotFound).IsTrue()
+		})
+
+		g.It("Should delete a stored file", func() {
+			err := store.Delete("file-a")
+			g.Assert(err == nil).IsTrue()
+
+			_, err = store.Stat("file-a")
+			g.Assert(err == ErrNotFound).IsTrue()
+
+			err = store.Delete("file-b")
+			g.Assert(err == nil).IsTrue()
+		})
+
+		g.It("Should not complain when deleting a missing file", func() {
+			err := store.Delete("file-a")
+			g.Assert(err == nil).IsTrue()
+		})
+
+		g.It("Should not find a missing file", func() {
+			_, err := store.Get("file-a")
+			g.Assert(err == ErrNotFound).IsTrue()
+
+			_, err = store.Stat("file-a")
+			g.Assert(err == ErrNotFound).IsTrue()
+		})
+
+		g.It("Should reject names pointing outside of the storage", func() {
+			for _, name := range []string{ "", ".", "..", "../settings.toml", "folder/file" } {
+				err := store.Put(name, strings.NewReader("x"), 1, "")
+				g.Assert(err == ErrInvalidName).IsTrue()
+
+				_, err = store.Get(name)
+				g.Assert(err == ErrInvalidName).IsTrue()
+			}
+		})
+	})
+}
@@ -0,0 +1,30 @@
+package storage
+
+// Copies a file from one storage to another, returns false when the target already had the same file.
+// Files are compared by their size, as the drivers don't share a checksum.
+func Copy(from, to Storage, name, mimeType string) (bool, error) {
+	source, err := from.Stat(name)
+	if err != nil {
+		return false, err
+	}
+
+	target, err := to.Stat(name)
+	if err == nil && target.Size == source.Size {
+		return false, nil
+	} else if err != nil && err != ErrNotFound {
+		return false, err
+	}
+
+	content, err := from.Get(name)
+	if err != nil {
+		return false, err
+	}
+	defer content.Close()
+
+	err = to.Put(name, content, source.Size, mimeType)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
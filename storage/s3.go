@@ -0,0 +1,386 @@
+package storage
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/YagoCarballo/kumquat-academy-api/tools"
+)
+
+const (
+	S3_DEFAULT_REGION = "us-east-1"
+	s3Algorithm = "AWS4-HMAC-SHA256"
+	s3UnsignedPayload = "UNSIGNED-PAYLOAD"
+	s3TimeFormat = "20060102T150405Z"
+)
+
+type (
+	// Keeps the files in a bucket of S3, or of any service with the same API (like MinIO).
+	// The bucket is addressed in the path, so the endpoint can be any host.
+	s3Storage struct {
+		settings	tools.S3
+		endpoint	*url.URL
+		client		*http.Client
+		now			func() time.Time
+	}
+
+	s3Error struct {
+		Code		string
+		Message		string
+	}
+
+	s3ListResult struct {
+		Contents				[]struct{ Key string }
+		IsTruncated				bool
+		NextContinuationToken	string
+	}
+)
+
+func NewS3(settings tools.S3) (Storage, error) {
+	if settings.Endpoint == "" || settings.Bucket == "" {
+		return nil, fmt.Errorf("The S3 storage needs an endpoint and a bucket.")
+	}
+
+	endpoint, err := url.Parse(strings.TrimRight(settings.Endpoint, "/"))
+	if err != nil {
+		return nil, err
+	}
+
+	if settings.Region == "" {
+		settings.Region = S3_DEFAULT_REGION
+	}
+
+	return s3Storage{
+		settings: settings,
+		endpoint: endpoint,
+		client: &http.Client{},
+		now: time.Now,
+	}, nil
+}
+
+func (storage s3Storage) objectUrl(name string) (*url.URL, error) {
+	err := checkName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	objectUrl := *storage.endpoint
+	objectUrl.Path = fmt.Sprintf("%s/%s/%s", objectUrl.Path, storage.settings.Bucket, name)
+	return &objectUrl, nil
+}
+
+func (storage s3Storage) Put(name string, content io.Reader, size int64, mimeType string) error {
+	objectUrl, err := storage.objectUrl(name)
+	if err != nil {
+		return err
+	}
+
+	// S3 needs to know the length of the file before it is sent
+	if size < 0 {
+		data, err := ioutil.ReadAll(content)
+		if err != nil {
+			return err
+		}
+
+		content = bytes.NewReader(data)
+		size = int64(len(data))
+	}
+
+	request, err := http.NewRequest("PUT", objectUrl.String(), content)
+	if err != nil {
+		return err
+	}
+
+	request.ContentLength = size
+	if mimeType != "" {
+		request.Header.Set("Content-Type", mimeType)
+	}
+
+	response, err := storage.do(request)
+	if err != nil {
+		return err
+	}
+
+	response.Body.Close()
+	return nil
+}
+
+func (storage s3Storage) Get(name string) (io.ReadCloser, error) {
+	return storage.Stream(name, 0, -1)
+}
+
+func (storage s3Storage) Stat(name string) (*FileInfo, error) {
+	objectUrl, err := storage.objectUrl(name)
+	if err != nil {
+		return nil, err
+	}
+
+	request, err := http.NewRequest("HEAD", objectUrl.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := storage.do(request)
+	if err != nil {
+		return nil, err
+	}
+	response.Body.Close()
+
+	modTime, _ := http.ParseTime(response.Header.Get("Last-Modified"))
+	return &FileInfo{
+		Name: name,
+		Size: response.ContentLength,
+		Type: response.Header.Get("Content-Type"),
+		ModTime: modTime,
+	}, nil
+}
+
+// S3 doesn't complain when the file is missing either
+func (storage s3Storage) Delete(name string) error {
+	objectUrl, err := storage.objectUrl(name)
+	if err != nil {
+		return err
+	}
+
+	request, err := http.NewRequest("DELETE", objectUrl.String(), nil)
+	if err != nil {
+		return err
+	}
+
+	response, err := storage.do(request)
+	if err == ErrNotFound {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	response.Body.Close()
+	return nil
+}
+
+func (storage s3Storage) Stream(name string, offset, length int64) (io.ReadCloser, error) {
+	objectUrl, err := storage.objectUrl(name)
+	if err != nil {
+		return nil, err
+	}
+
+	request, err := http.NewRequest("GET", objectUrl.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if length == 0 {
+		return ioutil.NopCloser(bytes.NewReader(nil)), nil
+	} else if length > 0 {
+		request.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", offset, offset + length - 1))
+	} else if offset > 0 {
+		request.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+
+	response, err := storage.do(request)
+	if err != nil {
+		return nil, err
+	}
+
+	return response.Body, nil
+}
+
+// Lists the bucket a page at a time
+func (storage s3Storage) List() ([]string, error) {
+	names := []string{}
+	continuation := ""
+
+	for {
+		listUrl := *storage.endpoint
+		listUrl.Path = fmt.Sprintf("%s/%s", listUrl.Path, storage.settings.Bucket)
+		query := url.Values{}
+		query.Set("list-type", "2")
+		if continuation != "" {
+			query.Set("continuation-token", continuation)
+		}
+		listUrl.RawQuery = query.Encode()
+
+		request, err := http.NewRequest("GET", listUrl.String(), nil)
+		if err != nil {
+			return nil, err
+		}
+
+		response, err := storage.do(request)
+		if err != nil {
+			return nil, err
+		}
+
+		var result s3ListResult
+		err = xml.NewDecoder(response.Body).Decode(&result)
+		response.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, object := range result.Contents {
+			names = append(names, object.Key)
+		}
+
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return names, nil
+		}
+		continuation = result.NextContinuationToken
+	}
+}
+
+// Signs and sends the request, the errors of S3 are turned into Go errors
+func (storage s3Storage) do(request *http.Request) (*http.Response, error) {
+	storage.sign(request, storage.now())
+
+	response, err := storage.client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+
+	if response.StatusCode >= 200 && response.StatusCode < 300 {
+		return response, nil
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode == http.StatusNotFound {
+		return nil, ErrNotFound
+	}
+
+	var s3Err s3Error
+	body, _ := ioutil.ReadAll(response.Body)
+	if xml.Unmarshal(body, &s3Err) != nil || s3Err.Code == "" {
+		s3Err.Code = strconv.Itoa(response.StatusCode)
+		s3Err.Message = http.StatusText(response.StatusCode)
+	}
+
+	return nil, s3Err
+}
+
+func (err s3Error) Error() string {
+	return fmt.Sprintf("S3 error %s: %s", err.Code, err.Message)
+}
+
+// Adds the Authorization header of AWS Signature Version 4.
+// The payload isn't hashed, so files can be sent as they are read.
+func (storage s3Storage) sign(request *http.Request, now time.Time) {
+	now = now.UTC()
+	date := now.Format("20060102")
+
+	if request.Header.Get("X-Amz-Content-Sha256") == "" {
+		payload := s3UnsignedPayload
+		if request.Body == nil {
+			payload = hashHex([]byte{})
+		}
+		request.Header.Set("X-Amz-Content-Sha256", payload)
+	}
+	request.Header.Set("X-Amz-Date", now.Format(s3TimeFormat))
+
+	// Every header set so far is signed, along with the host
+	headers := map[string]string{ "host": request.URL.Host }
+	for name, values := range request.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+
+	names := []string{}
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	canonicalHeaders := ""
+	for _, name := range names {
+		canonicalHeaders += name + ":" + headers[name] + "\n"
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		request.Method,
+		encodePath(request.URL.Path),
+		canonicalQuery(request.URL.Query()),
+		canonicalHeaders,
+		signedHeaders,
+		request.Header.Get("X-Amz-Content-Sha256"),
+	}, "\n")
+
+	scope := fmt.Sprintf("%s/%s/s3/aws4_request", date, storage.settings.Region)
+	stringToSign := strings.Join([]string{
+		s3Algorithm,
+		now.Format(s3TimeFormat),
+		scope,
+		hashHex([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4" + storage.settings.SecretKey), date)
+	key = hmacSHA256(key, storage.settings.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	request.Header.Set("Authorization", fmt.Sprintf(
+		"%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s3Algorithm, storage.settings.AccessKey, scope, signedHeaders, signature,
+	))
+}
+
+func hashHex(data []byte) string {
+	hash := sha256.Sum256(data)
+	return hex.EncodeToString(hash[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// Encodes everything but the unreserved characters (and the slashes of the path)
+func encodePath(path string) string {
+	if path == "" {
+		return "/"
+	}
+
+	segments := strings.Split(path, "/")
+	for index, segment := range segments {
+		segments[index] = encodeValue(segment)
+	}
+
+	return strings.Join(segments, "/")
+}
+
+func encodeValue(value string) string {
+	encoded := ""
+	for _, char := range []byte(value) {
+		if ('A' <= char && char <= 'Z') || ('a' <= char && char <= 'z') || ('0' <= char && char <= '9') ||
+			char == '-' || char == '_' || char == '.' || char == '~' {
+			encoded += string(char)
+		} else {
+			encoded += fmt.Sprintf("%%%02X", char)
+		}
+	}
+
+	return encoded
+}
+
+func canonicalQuery(query url.Values) string {
+	pairs := []string{}
+	for key, values := range query {
+		for _, value := range values {
+			pairs = append(pairs, encodeValue(key) + "=" + encodeValue(value))
+		}
+	}
+	sort.Strings(pairs)
+
+	return strings.Join(pairs, "&")
+}
@@ -0,0 +1,69 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/YagoCarballo/kumquat-academy-api/tools"
+)
+
+type (
+	// Keeps the uploaded files, each driver stores them somewhere else
+	Storage interface {
+		Put(name string, content io.Reader, size int64, mimeType string) error
+		Get(name string) (io.ReadCloser, error)
+		Stat(name string) (*FileInfo, error)
+		Delete(name string) error
+		// Reads length bytes from the offset, a negative length reads until the end of the file
+		Stream(name string, offset, length int64) (io.ReadCloser, error)
+		// The names of all the stored files
+		List() ([]string, error)
+	}
+
+	FileInfo struct {
+		Name		string
+		Size		int64
+		Type		string
+		ModTime		time.Time
+	}
+)
+
+var ErrNotFound = errors.New("The file does not exist.")
+var ErrInvalidName = errors.New("The name of the file is not valid.")
+
+// Creates one of the drivers ("local" or "s3"), set up with the given settings
+func New(driver string, settings tools.Settings) (Storage, error) {
+	switch driver {
+	case "", "local":
+		return NewLocal(settings.Server.UploadsPath)
+	case "s3":
+		return NewS3(settings.Storage.S3)
+	default:
+		return nil, fmt.Errorf("Unknown storage driver '%s'.", driver)
+	}
+}
+
+// The storage set up in the settings, where the attachments are kept
+func Default() (Storage, error) {
+	settings := tools.GetSettings()
+	return New(settings.Storage.Driver, *settings)
+}
+
+// Creates a ZIP entry for a stored file
+func ZipSource(storage Storage, name, file string) tools.ZipSource {
+	return tools.ZipSource{ Name: name, Open: func() (io.ReadCloser, error) {
+		return storage.Get(file)
+	}}
+}
+
+// Names are kept flat, so they can't point outside of the storage
+func checkName(name string) error {
+	if name == "" || name == "." || name == ".." || strings.ContainsAny(name, "/\\") {
+		return ErrInvalidName
+	}
+
+	return nil
+}
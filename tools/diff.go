@@ -0,0 +1,114 @@
+package tools
+
+import (
+	"errors"
+	"strings"
+)
+
+const (
+	DiffEqual	= "equal"
+	DiffInsert	= "insert"
+	DiffDelete	= "delete"
+
+	// How many changed lines each text can have, the comparison takes memory for every pair of them
+	MaxDiffLines = 1000
+)
+
+var ErrDiffTooLarge = errors.New("The texts have too many changes to compare.")
+
+type DiffLine struct {
+	Type	string	`json:"type"`
+	Text	string	`json:"text"`
+}
+
+// Compares two texts line by line, using the longest common subsequence of their lines.
+// Only the lines between the common start and end are compared, up to MaxDiffLines on each side.
+func DiffLines(before, after string) ([]DiffLine, error) {
+	oldLines := splitLines(before)
+	newLines := splitLines(after)
+
+	prefix := 0
+	for prefix < len(oldLines) && prefix < len(newLines) && oldLines[prefix] == newLines[prefix] {
+		prefix++
+	}
+
+	suffix := 0
+	for suffix < len(oldLines) - prefix && suffix < len(newLines) - prefix &&
+		oldLines[len(oldLines) - 1 - suffix] == newLines[len(newLines) - 1 - suffix] {
+		suffix++
+	}
+
+	oldChanged := oldLines[prefix:len(oldLines) - suffix]
+	newChanged := newLines[prefix:len(newLines) - suffix]
+	if len(oldChanged) > MaxDiffLines || len(newChanged) > MaxDiffLines {
+		return nil, ErrDiffTooLarge
+	}
+
+	diff := []DiffLine{}
+	for _, line := range oldLines[:prefix] {
+		diff = append(diff, DiffLine{DiffEqual, line})
+	}
+
+	diff = append(diff, diffChangedLines(oldChanged, newChanged)...)
+
+	for _, line := range oldLines[len(oldLines) - suffix:] {
+		diff = append(diff, DiffLine{DiffEqual, line})
+	}
+
+	return diff, nil
+}
+
+func diffChangedLines(oldLines, newLines []string) []DiffLine {
+	// common[i][j] is the length of the longest common subsequence of oldLines[i:] and newLines[j:]
+	common := make([][]int, len(oldLines) + 1)
+	for i := range common {
+		common[i] = make([]int, len(newLines) + 1)
+	}
+
+	for i := len(oldLines) - 1; i >= 0; i-- {
+		for j := len(newLines) - 1; j >= 0; j-- {
+			if oldLines[i] == newLines[j] {
+				common[i][j] = common[i + 1][j + 1] + 1
+			} else if common[i + 1][j] >= common[i][j + 1] {
+				common[i][j] = common[i + 1][j]
+			} else {
+				common[i][j] = common[i][j + 1]
+			}
+		}
+	}
+
+	diff := []DiffLine{}
+	i, j := 0, 0
+	for i < len(oldLines) && j < len(newLines) {
+		switch {
+		case oldLines[i] == newLines[j]:
+			diff = append(diff, DiffLine{DiffEqual, oldLines[i]})
+			i++
+			j++
+		case common[i + 1][j] >= common[i][j + 1]:
+			diff = append(diff, DiffLine{DiffDelete, oldLines[i]})
+			i++
+		default:
+			diff = append(diff, DiffLine{DiffInsert, newLines[j]})
+			j++
+		}
+	}
+
+	for ; i < len(oldLines); i++ {
+		diff = append(diff, DiffLine{DiffDelete, oldLines[i]})
+	}
+
+	for ; j < len(newLines); j++ {
+		diff = append(diff, DiffLine{DiffInsert, newLines[j]})
+	}
+
+	return diff
+}
+
+func splitLines(text string) []string {
+	if text == "" {
+		return []string{}
+	}
+
+	return strings.Split(strings.Replace(text, "\r\n", "\n", -1), "\n")
+}
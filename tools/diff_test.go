@@ -0,0 +1,64 @@
+package tools
+
+import (
+	"strings"
+	"testing"
+
+	. "github.com/franela/goblin"
+)
+
+func Test_Diff(t *testing.T) {
+	g := Goblin(t)
+
+	g.Describe("When comparing two texts", func() {
+		g.It("Should find no changes on the same text", func() {
+			diff, err := DiffLines("one\ntwo", "one\ntwo")
+
+			g.Assert(err == nil).IsTrue()
+			g.Assert(len(diff)).Equal(2)
+			g.Assert(diff[0].Type).Equal(DiffEqual)
+			g.Assert(diff[1].Type).Equal(DiffEqual)
+		})
+
+		g.It("Should find the inserted and deleted lines", func() {
+			diff, err := DiffLines("one\ntwo\nthree", "one\n2\nthree\nfour")
+
+			g.Assert(err == nil).IsTrue()
+			g.Assert(diff).Equal([]DiffLine{
+				{DiffEqual, "one"},
+				{DiffDelete, "two"},
+				{DiffInsert, "2"},
+				{DiffEqual, "three"},
+				{DiffInsert, "four"},
+			})
+		})
+
+		g.It("Should handle empty texts", func() {
+			diff, _ := DiffLines("", "")
+			g.Assert(len(diff)).Equal(0)
+
+			diff, _ = DiffLines("", "new")
+			g.Assert(diff).Equal([]DiffLine{{DiffInsert, "new"}})
+
+			diff, _ = DiffLines("old\r\n", "")
+			g.Assert(diff).Equal([]DiffLine{{DiffDelete, "old"}, {DiffDelete, ""}})
+		})
+
+		g.It("Should only compare the lines between the common start and end", func() {
+			common := strings.Repeat("same\n", MaxDiffLines * 2)
+			diff, err := DiffLines(common + "old\n" + common, common + "new\n" + common)
+
+			g.Assert(err == nil).IsTrue()
+			g.Assert(len(diff)).Equal(MaxDiffLines * 4 + 3)
+			g.Assert(diff[MaxDiffLines * 2]).Equal(DiffLine{DiffDelete, "old"})
+			g.Assert(diff[MaxDiffLines * 2 + 1]).Equal(DiffLine{DiffInsert, "new"})
+		})
+
+		g.It("Should refuse to compare texts with too many changes", func() {
+			diff, err := DiffLines(strings.Repeat("old\n", MaxDiffLines + 1), strings.Repeat("new\n", MaxDiffLines + 1))
+
+			g.Assert(err).Equal(ErrDiffTooLarge)
+			g.Assert(diff == nil).IsTrue()
+		})
+	})
+}
@@ -0,0 +1,49 @@
+package tools
+
+import (
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/dvsekhvalnov/jose2go"
+)
+
+// What a signed download link gives access to, it can be used without a session until it expires
+type DownloadToken struct {
+	Attachment	string	`json:"attachment"`
+	ExpiresIn	time.Time `json:"expires_in"`
+}
+
+var ErrInvalidDownloadToken = errors.New("The download link is not valid.")
+var ErrExpiredDownloadToken = errors.New("The download link has expired.")
+
+// Signs a link to download the attachment with the given token, only the server can sign them
+func SignDownloadToken(attachment string, expiresIn time.Time, privateKey *rsa.PrivateKey) (string, error) {
+	payload, err := json.Marshal(DownloadToken{ Attachment: attachment, ExpiresIn: expiresIn })
+	if err != nil {
+		return "", err
+	}
+
+	return jose.Sign(string(payload), jose.RS256, privateKey)
+}
+
+// Checks the signature and the expiry of a download link
+func ParseDownloadToken(token string, publicKey *rsa.PublicKey, now time.Time) (*DownloadToken, error) {
+	payload, headers, err := jose.Decode(token, publicKey)
+	if err != nil || headers["alg"] != jose.RS256 {
+		return nil, ErrInvalidDownloadToken
+	}
+
+	var download DownloadToken
+	err = json.Unmarshal([]byte(payload), &download)
+	if err != nil || download.Attachment == "" {
+		return nil, ErrInvalidDownloadToken
+	}
+
+	if now.After(download.ExpiresIn) {
+		return nil, ErrExpiredDownloadToken
+	}
+
+	return &download, nil
+}
@@ -0,0 +1,56 @@
+package tools
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	. "github.com/franela/goblin"
+	"github.com/dvsekhvalnov/jose2go"
+)
+
+func Test_Download_Tokens(t *testing.T) {
+	g := Goblin(t)
+
+	privateKey, publicKey, err := LoadKey("downloadKey.pem", "downloadKey.pub")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove("downloadKey.pem")
+	defer os.Remove("downloadKey.pub")
+
+	now := time.Date(2017, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	g.Describe("When signing download links", func() {
+		g.It("Should read back the attachment of a signed link", func() {
+			token, err := SignDownloadToken("49860456-4ea5-42ff-8aac-476148e6f422", now.Add(time.Minute), privateKey)
+			g.Assert(err == nil).IsTrue()
+
+			download, err := ParseDownloadToken(token, publicKey, now)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(download.Attachment).Equal("49860456-4ea5-42ff-8aac-476148e6f422")
+		})
+
+		g.It("Should reject an expired link", func() {
+			token, _ := SignDownloadToken("49860456-4ea5-42ff-8aac-476148e6f422", now.Add(time.Minute), privateKey)
+
+			_, err := ParseDownloadToken(token, publicKey, now.Add(2 * time.Minute))
+			g.Assert(err == ErrExpiredDownloadToken).IsTrue()
+		})
+
+		g.It("Should reject a link that was changed or not signed by the server", func() {
+			token, _ := SignDownloadToken("49860456-4ea5-42ff-8aac-476148e6f422", now.Add(time.Minute), privateKey)
+
+			_, err := ParseDownloadToken(token + "x", publicKey, now)
+			g.Assert(err == ErrInvalidDownloadToken).IsTrue()
+
+			unsigned, _ := jose.Sign(`{"attachment":"49860456-4ea5-42ff-8aac-476148e6f422","expires_in":"2030-01-01T00:00:00Z"}`, jose.NONE, nil)
+			_, err = ParseDownloadToken(unsigned, publicKey, now)
+			g.Assert(err == ErrInvalidDownloadToken).IsTrue()
+
+			encrypted, _ := jose.Encrypt(`{"attachment":"49860456-4ea5-42ff-8aac-476148e6f422","expires_in":"2030-01-01T00:00:00Z"}`, jose.RSA_OAEP, jose.A256GCM, publicKey)
+			_, err = ParseDownloadToken(encrypted, publicKey, now)
+			g.Assert(err == ErrInvalidDownloadToken).IsTrue()
+		})
+	})
+}
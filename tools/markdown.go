@@ -0,0 +1,12 @@
+package tools
+
+import (
+	"github.com/russross/blackfriday"
+	"github.com/microcosm-cc/bluemonday"
+)
+
+// Renders Markdown into HTML, removing anything unsafe (scripts, event handlers, etc.) from the output
+func RenderMarkdown(content string) string {
+	unsafe := blackfriday.MarkdownCommon([]byte(content))
+	return string(bluemonday.UGCPolicy().SanitizeBytes(unsafe))
+}
@@ -17,11 +17,15 @@ type (
 		Server      Server
 		Email       Email
 		Api         Api
+		Scheduler   Scheduler
+		Storage     Storage
+		Uploads     Uploads
 	}
 	Database struct {
-		Type   string
-		Mysql  MySQL
-		Sqlite SQLite
+		Type     string
+		Mysql    MySQL
+		Postgres Postgres
+		Sqlite   SQLite
 	}
 	MySQL struct {
 		Username string
@@ -29,6 +33,13 @@ type (
 		Host     string
 		Name     string
 	}
+	Postgres struct {
+		Username string
+		Password string
+		Host     string
+		Name     string
+		SSLMode  string
+	}
 	SQLite struct {
 		Path	 string
 	}
@@ -39,6 +50,8 @@ type (
 		PrivateKey	string
 		PublicKey	string
 		UploadsPath string
+		// The address of the web app, used for the links in the emails
+		BaseUrl		string
 	}
 	Email struct {
 		Server   string
@@ -46,11 +59,50 @@ type (
 		User     string
 		Password string
 		Sender   string
+		// How the emails are sent: "smtp", "log" (prints them) or "file" (writes them to the directory).
+		// Emails are only sent by the "emails" job of the scheduler, they stay in the outbox until it runs.
+		Transport string
+		Directory string
+		// The templates are kept in a folder per language, the default language is used when one is missing
+		Templates string
+		Language  string
 	}
 	Api struct {
 		Prefix  string
 		Version int
 	}
+	// Where the attachments are kept: "local" (the uploads path of the server) or "s3"
+	Storage struct {
+		Driver	string
+		S3		S3
+	}
+	// Any service with the API of S3 works (like MinIO), the bucket has to exist
+	S3 struct {
+		Endpoint	string
+		Region		string
+		Bucket		string
+		AccessKey	string
+		SecretKey	string
+	}
+	// Limits of the uploaded files, sizes are in bytes and 0 uses the default limit.
+	// The quotas are the space the files of a user or of a module can take, 0 leaves them without a quota.
+	Uploads struct {
+		MaxSize		int64
+		MaxFiles	int
+		AvatarSize	int64
+		AvatarTypes	[]string
+		UserQuota	int64
+		ModuleQuota	int64
+	}
+	// Cron expressions of the background jobs, empty uses the default schedule and "off" disables the job
+	Scheduler struct {
+		Disabled		bool
+		Statuses		string
+		Sessions		string
+		ResetPasswords	string
+		Reminders		string
+		Emails			string
+	}
 )
 
 var localSetting Settings
@@ -67,6 +119,13 @@ func createSettings(path string) {
 				Host:     "localhost:3306",
 				Name:     "KumquatAcademy",
 			},
+			Postgres: Postgres{
+				Username: "KumquatAcademy",
+				Password: "KumquatAcademy",
+				Host:     "localhost:5432",
+				Name:     "KumquatAcademy",
+				SSLMode:  "disable",
+			},
 			Sqlite:	  SQLite{
 				Path:	  "./default.sqlite",
 			},
@@ -78,6 +137,7 @@ func createSettings(path string) {
 			PrivateKey: "./privateKey.pem",
 			PublicKey: "./publicKey.pub",
 			UploadsPath: "./attachments",
+			BaseUrl: "https://kumquat.academy",
 		},
 		Email: Email{
 			Server: "smtp.gmail.com",
@@ -85,11 +145,39 @@ func createSettings(path string) {
 			User: 		"test@gmail.com",
 			Password: 	"password",
 			Sender: 	"Kumquat Academy <do-not-reply@kumquat.academy>",
+			Transport:	"smtp",
+			Directory:	"./emails",
+			Templates:	"./templates/emails",
+			Language:	"en",
 		},
 		Api: Api{
 			Prefix:  "/api",
 			Version: 1,
 		},
+		Scheduler: Scheduler{
+			Disabled:		false,
+			Statuses:		"* * * * *",
+			Sessions:		"@hourly",
+			ResetPasswords:	"@daily",
+			Reminders:		"0 * * * *",
+			Emails:			"* * * * *",
+		},
+		Storage: Storage{
+			Driver:	"local",
+			S3:		S3{
+				Endpoint:	"http://localhost:9000",
+				Region:		"us-east-1",
+				Bucket:		"kumquat-academy",
+			},
+		},
+		Uploads: Uploads{
+			MaxSize:		DEFAULT_MAX_UPLOAD_SIZE,
+			MaxFiles:		DEFAULT_MAX_UPLOAD_FILES,
+			AvatarSize:		DEFAULT_AVATAR_SIZE,
+			AvatarTypes:	DEFAULT_AVATAR_TYPES,
+			UserQuota:		500 << 20,
+			ModuleQuota:	5 << 30,
+		},
 	}
 
 	str, _ := toml.Marshal(defaultSettings)
@@ -137,20 +225,33 @@ func LoadSettings(path string) error {
 		}
 	}
 
+	if os.Getenv("DB_TYPE") != "" {
+		localSetting.Database.Type = os.Getenv("DB_TYPE")
+	}
+
+	// The server settings apply to both MySQL and Postgres, the type decides which one is used
 	if os.Getenv("DB_HOST") != "" {
 		localSetting.Database.Mysql.Host = os.Getenv("DB_HOST")
+		localSetting.Database.Postgres.Host = os.Getenv("DB_HOST")
 	}
 
 	if os.Getenv("DB_USER") != "" {
 		localSetting.Database.Mysql.Username = os.Getenv("DB_USER")
+		localSetting.Database.Postgres.Username = os.Getenv("DB_USER")
 	}
 
 	if os.Getenv("DB_PASS") != "" {
 		localSetting.Database.Mysql.Password = os.Getenv("DB_PASS")
+		localSetting.Database.Postgres.Password = os.Getenv("DB_PASS")
 	}
 
 	if os.Getenv("DB_NAME") != "" {
 		localSetting.Database.Mysql.Name = os.Getenv("DB_NAME")
+		localSetting.Database.Postgres.Name = os.Getenv("DB_NAME")
+	}
+
+	if os.Getenv("DB_SSLMODE") != "" {
+		localSetting.Database.Postgres.SSLMode = os.Getenv("DB_SSLMODE")
 	}
 
 	if os.Getenv("DB_PATH") != "" {
@@ -182,10 +283,38 @@ func LoadSettings(path string) error {
 		}
 	}
 
+	if os.Getenv("EMAIL_TRANSPORT") != "" {
+		localSetting.Email.Transport = os.Getenv("EMAIL_TRANSPORT")
+	}
+
+	if os.Getenv("BASE_URL") != "" {
+		localSetting.Server.BaseUrl = os.Getenv("BASE_URL")
+	}
+
 	if os.Getenv("UPLOADS_PATH") != "" {
 		localSetting.Server.UploadsPath = os.Getenv("UPLOADS_PATH")
 	}
 
+	if os.Getenv("STORAGE_DRIVER") != "" {
+		localSetting.Storage.Driver = os.Getenv("STORAGE_DRIVER")
+	}
+
+	if os.Getenv("S3_ENDPOINT") != "" {
+		localSetting.Storage.S3.Endpoint = os.Getenv("S3_ENDPOINT")
+	}
+
+	if os.Getenv("S3_BUCKET") != "" {
+		localSetting.Storage.S3.Bucket = os.Getenv("S3_BUCKET")
+	}
+
+	if os.Getenv("S3_ACCESS_KEY") != "" {
+		localSetting.Storage.S3.AccessKey = os.Getenv("S3_ACCESS_KEY")
+	}
+
+	if os.Getenv("S3_SECRET_KEY") != "" {
+		localSetting.Storage.S3.SecretKey = os.Getenv("S3_SECRET_KEY")
+	}
+
 	if os.Getenv("PRODUCTION") != "" {
 		localSetting.Server.Production = (os.Getenv("PRODUCTION") == "true")
 	}
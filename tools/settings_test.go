@@ -57,6 +57,11 @@ func Test_LoadSettings(t *testing.T) {
 			g.Assert(reflect.TypeOf(database.Mysql.Password).String()).Equal("string")
 			g.Assert(reflect.TypeOf(database.Mysql.Host).String()).Equal("string")
 			g.Assert(reflect.TypeOf(database.Mysql.Name).String()).Equal("string")
+			g.Assert(reflect.TypeOf(database.Postgres.Username).String()).Equal("string")
+			g.Assert(reflect.TypeOf(database.Postgres.Password).String()).Equal("string")
+			g.Assert(reflect.TypeOf(database.Postgres.Host).String()).Equal("string")
+			g.Assert(reflect.TypeOf(database.Postgres.Name).String()).Equal("string")
+			g.Assert(reflect.TypeOf(database.Postgres.SSLMode).String()).Equal("string")
 			g.Assert(reflect.TypeOf(database.Sqlite.Path).String()).Equal("string")
 		})
 
@@ -77,6 +82,7 @@ func Test_LoadSettings(t *testing.T) {
 	})
 
 	g.Describe("Environment Variable Settings - Success", func() {
+		os.Unsetenv("DB_TYPE")
 		os.Setenv("DB_USER", "johndoe")
 		os.Setenv("DB_PASS", "password")
 		os.Setenv("DB_HOST", "example.com:3306")
@@ -101,6 +107,8 @@ func Test_LoadSettings(t *testing.T) {
 			g.Assert(settings.Database.Mysql.Username).Equal("johndoe")
 			g.Assert(settings.Database.Mysql.Password).Equal("password")
 			g.Assert(settings.Database.Mysql.Host).Equal("example.com:3306")
+			g.Assert(settings.Database.Postgres.Username).Equal("johndoe")
+			g.Assert(settings.Database.Postgres.Host).Equal("example.com:3306")
 			g.Assert(settings.Database.Sqlite.Path).Equal("./default.sqlite")
 			g.Assert(settings.Email.User).Equal("johndoe")
 			g.Assert(settings.Email.Password).Equal("password")
@@ -111,6 +119,24 @@ func Test_LoadSettings(t *testing.T) {
 		})
 	})
 
+	g.Describe("Environment Variable Settings - Database Type", func() {
+		os.Setenv("DB_TYPE", "postgres")
+		os.Setenv("DB_SSLMODE", "require")
+
+		LoadSettings(SETTINGS_PATH)
+
+		// Loads the Settings
+		settings := GetSettings()
+
+		g.It("Should select the database from the Environment Variables", func() {
+			g.Assert(settings.Database.Type).Equal("postgres")
+			g.Assert(settings.Database.Postgres.SSLMode).Equal("require")
+		})
+
+		os.Unsetenv("DB_TYPE")
+		os.Unsetenv("DB_SSLMODE")
+	})
+
 	g.Describe("Environment Variable Settings - Fail", func() {
 		os.Setenv("APIPORT", "NaN")
 
@@ -0,0 +1,98 @@
+package tools
+
+import (
+	"bytes"
+	"encoding/csv"
+	"path"
+	"strings"
+
+	"github.com/tealeg/xlsx"
+)
+
+const (
+	SpreadsheetCSV	= "csv"
+	SpreadsheetXLSX	= "xlsx"
+)
+
+// Writes a table into a CSV or XLSX file, in XLSX files all the rows go into a single sheet
+func WriteSpreadsheet(format, sheetName string, rows [][]string) ([]byte, error) {
+	var buffer bytes.Buffer
+
+	if format == SpreadsheetXLSX {
+		file := xlsx.NewFile()
+		sheet, err := file.AddSheet(sheetName)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, values := range rows {
+			row := sheet.AddRow()
+			for _, value := range values {
+				row.AddCell().SetString(value)
+			}
+		}
+
+		err = file.Write(&buffer)
+		if err != nil {
+			return nil, err
+		}
+
+		return buffer.Bytes(), nil
+	}
+
+	writer := csv.NewWriter(&buffer)
+	err := writer.WriteAll(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// Reads the rows of a CSV or XLSX file (only its first sheet), the format is taken from the file name
+func ReadSpreadsheet(fileName string, content []byte) ([][]string, error) {
+	if SpreadsheetFormat(fileName) == SpreadsheetXLSX {
+		file, err := xlsx.OpenBinary(content)
+		if err != nil {
+			return nil, err
+		}
+
+		rows := [][]string{}
+		if len(file.Sheets) <= 0 {
+			return rows, nil
+		}
+
+		for _, row := range file.Sheets[0].Rows {
+			values := []string{}
+			for _, cell := range row.Cells {
+				values = append(values, strings.TrimSpace(cell.Value))
+			}
+
+			rows = append(rows, values)
+		}
+
+		return rows, nil
+	}
+
+	reader := csv.NewReader(bytes.NewReader(content))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	return reader.ReadAll()
+}
+
+func SpreadsheetFormat(fileName string) string {
+	if strings.ToLower(path.Ext(fileName)) == ".xlsx" {
+		return SpreadsheetXLSX
+	}
+
+	return SpreadsheetCSV
+}
+
+func SpreadsheetContentType(format string) string {
+	if format == SpreadsheetXLSX {
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+
+	return "text/csv"
+}
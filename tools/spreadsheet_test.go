@@ -0,0 +1,32 @@
+package tools
+
+import (
+	"testing"
+
+	. "github.com/franela/goblin"
+)
+
+func Test_Spreadsheet(t *testing.T) {
+	g := Goblin(t)
+
+	g.Describe("When exporting tables as CSV, ", func() {
+		g.It("the rows can be read back.", func() {
+			content, err := WriteSpreadsheet(SpreadsheetCSV, "Grades", [][]string{
+				{ "matric_number", "name", "grade" },
+				{ "120010001", "Smith, John", "65" },
+			})
+			g.Assert(err == nil).IsTrue()
+
+			rows, err := ReadSpreadsheet("grades.csv", content)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(len(rows)).Equal(2)
+			g.Assert(rows[1][1]).Equal("Smith, John")
+		})
+
+		g.It("the format is taken from the file extension.", func() {
+			g.Assert(SpreadsheetFormat("Grades.XLSX")).Equal(SpreadsheetXLSX)
+			g.Assert(SpreadsheetFormat("grades.csv")).Equal(SpreadsheetCSV)
+			g.Assert(SpreadsheetFormat("grades")).Equal(SpreadsheetCSV)
+		})
+	})
+}
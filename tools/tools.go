@@ -26,6 +26,15 @@ type (
 		Device			string	`json:"device"`
 		Username		string	`json:"username"`
 		Admin			bool	`json:"admin"`
+
+		// Only handed to token clients, it is never stored in the cookie
+		RefreshToken	string	`json:"-"`
+	}
+
+	// A file to add into a ZIP, opened only when it is written
+	ZipSource struct {
+		Name	string
+		Open	func() (io.ReadCloser, error)
 	}
 
 	JWTSessionAccessRights struct {
@@ -196,7 +205,27 @@ func FirstDayOfISOWeek(year int, week int, timezone *time.Location) time.Time {
 	return date
 }
 
+// Zips the files of a multipart form into the given path
 func ZipFiles(zipFilePath string, fileHeaders []*multipart.FileHeader) (*os.File, error) {
+	sources := []ZipSource{}
+	for _, fileHeader := range fileHeaders {
+		fileHeader := fileHeader
+		sources = append(sources, ZipSource{ Name: fileHeader.Filename, Open: func() (io.ReadCloser, error) {
+			return fileHeader.Open()
+		}})
+	}
+
+	return ZipSources(zipFilePath, sources)
+}
+
+// Creates a ZIP entry for a file already stored on disk
+func FileZipSource(name, path string) ZipSource {
+	return ZipSource{ Name: name, Open: func() (io.ReadCloser, error) {
+		return os.Open(path)
+	}}
+}
+
+func ZipSources(zipFilePath string, sources []ZipSource) (*os.File, error) {
 	// Creates the ZIP file
 	zipFile, err := os.Create(zipFilePath)
 	if err != nil {
@@ -208,9 +237,9 @@ func ZipFiles(zipFilePath string, fileHeaders []*multipart.FileHeader) (*os.File
 	archive := zip.NewWriter(zipFile)
 	defer archive.Close()
 
-	for index, fileHeader := range fileHeaders {
+	for _, source := range sources {
 		// Get the File
-		file, err := fileHeaders[index].Open()
+		file, err := source.Open()
 		if err != nil {
 			return nil, err
 		}
@@ -218,7 +247,7 @@ func ZipFiles(zipFilePath string, fileHeaders []*multipart.FileHeader) (*os.File
 
 		// Convert the file header into a valid ZIP header
 		header := &zip.FileHeader{
-			Name:         fileHeader.Filename,
+			Name:         source.Name,
 			Method:       zip.Store,
 			ModifiedTime: uint16(time.Now().UnixNano()),
 			ModifiedDate: uint16(time.Now().UnixNano()),
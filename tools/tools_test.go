@@ -6,6 +6,8 @@ import (
 	. "github.com/franela/goblin"
 	"crypto/rsa"
 	"os"
+	"archive/zip"
+	"io/ioutil"
 )
 
 const TMP_PRIVATE_KEY_PATH = "testKey.pem"
@@ -55,3 +57,42 @@ func Test_Tools(t *testing.T) {
 	os.Remove(TMP_PRIVATE_KEY_PATH)
 	os.Remove(TMP_PUBLIC_KEY_PATH)
 }
+
+func Test_Tools_Zip(t *testing.T) {
+	g := Goblin(t)
+
+	g.Describe("When zipping stored files, ", func() {
+		g.It("each file is saved with its name.", func() {
+			ioutil.WriteFile("slides.tmp", []byte("slides"), 0644)
+			ioutil.WriteFile("reading.tmp", []byte("reading"), 0644)
+
+			_, err := ZipSources("materials.zip", []ZipSource{
+				FileZipSource("slides/week1.pdf", "slides.tmp"),
+				FileZipSource("reading/paper.pdf", "reading.tmp"),
+			})
+			g.Assert(err == nil).IsTrue()
+
+			archive, err := zip.OpenReader("materials.zip")
+			g.Assert(err == nil).IsTrue()
+			defer archive.Close()
+
+			g.Assert(len(archive.File)).Equal(2)
+			g.Assert(archive.File[0].Name).Equal("slides/week1.pdf")
+
+			file, err := archive.File[1].Open()
+			g.Assert(err == nil).IsTrue()
+			content, _ := ioutil.ReadAll(file)
+			file.Close()
+			g.Assert(string(content)).Equal("reading")
+		})
+
+		g.It("a missing file returns an error.", func() {
+			_, err := ZipSources("materials.zip", []ZipSource{ FileZipSource("missing.pdf", "missing.tmp") })
+			g.Assert(err != nil).IsTrue()
+		})
+	})
+
+	os.Remove("slides.tmp")
+	os.Remove("reading.tmp")
+	os.Remove("materials.zip")
+}
@@ -0,0 +1,148 @@
+package tools
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	DEFAULT_MAX_UPLOAD_SIZE = 50 << 20
+	DEFAULT_MAX_UPLOAD_FILES = 10
+	DEFAULT_AVATAR_SIZE = 2 << 20
+)
+
+var DEFAULT_AVATAR_TYPES = []string{ "image/png", "image/jpeg", "image/gif", "image/webp" }
+
+// Types that hold other formats, the extension of the file tells which one it is
+var containerTypes = map[string]bool{
+	"application/octet-stream": true,
+	"application/zip": true,
+	"text/plain": true,
+}
+
+// Types the browser would run scripts from, files found to be one of them are stored as plain text.
+// They are never taken from the extension either.
+var activeTypes = map[string]bool{
+	"text/html": true,
+	"text/xml": true,
+	"text/javascript": true,
+	"application/javascript": true,
+	"application/xml": true,
+	"application/xhtml+xml": true,
+	"image/svg+xml": true,
+}
+
+// What the active types are stored as, so they are shown as text instead of being run
+const inertType = "text/plain; charset=utf-8"
+
+// The limits of the settings, with the default ones where they were left empty
+func (uploads Uploads) WithDefaults() Uploads {
+	if uploads.MaxSize <= 0 {
+		uploads.MaxSize = DEFAULT_MAX_UPLOAD_SIZE
+	}
+
+	if uploads.MaxFiles <= 0 {
+		uploads.MaxFiles = DEFAULT_MAX_UPLOAD_FILES
+	}
+
+	if uploads.AvatarSize <= 0 {
+		uploads.AvatarSize = DEFAULT_AVATAR_SIZE
+	}
+
+	if len(uploads.AvatarTypes) <= 0 {
+		uploads.AvatarTypes = DEFAULT_AVATAR_TYPES
+	}
+
+	return uploads
+}
+
+// Finds the type of a file from its first bytes, so the Content-Type sent by the client isn't trusted.
+// The extension is only used to tell apart the formats sharing a container (like the documents inside a ZIP),
+// and content the browser would run (like HTML) is stored as plain text. The file is rewound afterwards.
+func SniffContentType(name string, content io.ReadSeeker) (string, error) {
+	head := make([]byte, 512)
+	read, err := io.ReadFull(content, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return "", err
+	}
+
+	_, err = content.Seek(0, io.SeekStart)
+	if err != nil {
+		return "", err
+	}
+
+	detected := http.DetectContentType(head[:read])
+	base, _, _ := mime.ParseMediaType(detected)
+	if activeTypes[base] {
+		return inertType, nil
+	}
+
+	if !containerTypes[base] {
+		return detected, nil
+	}
+
+	byExtension := mime.TypeByExtension(strings.ToLower(filepath.Ext(name)))
+	extensionBase, _, _ := mime.ParseMediaType(byExtension)
+	if byExtension == "" || activeTypes[extensionBase] || strings.HasPrefix(extensionBase, "image/") {
+		return detected, nil
+	}
+
+	return byExtension, nil
+}
+
+// Splits a comma separated list of extensions, lowercased and starting with a dot (like ".zip")
+func SplitExtensions(list string) []string {
+	extensions := []string{}
+	for _, extension := range strings.Split(list, ",") {
+		extension = strings.ToLower(strings.TrimSpace(extension))
+		if extension == "" {
+			continue
+		}
+
+		if !strings.HasPrefix(extension, ".") {
+			extension = "." + extension
+		}
+
+		extensions = append(extensions, extension)
+	}
+
+	return extensions
+}
+
+// Any extension is allowed when none are given
+func HasExtension(name string, extensions []string) bool {
+	if len(extensions) <= 0 {
+		return true
+	}
+
+	extension := strings.ToLower(filepath.Ext(name))
+	for _, allowed := range extensions {
+		if extension == allowed {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Formats a size in bytes for the messages (like "2.0 MB")
+func FormatSize(size int64) string {
+	units := []string{ "bytes", "KB", "MB", "GB", "TB" }
+
+	value := float64(size)
+	unit := 0
+	for value >= 1024 && unit < len(units) - 1 {
+		value /= 1024
+		unit++
+	}
+
+	if unit == 0 {
+		return fmt.Sprintf("%d %s", size, units[unit])
+	}
+
+	return fmt.Sprintf("%.1f %s", value, units[unit])
+}
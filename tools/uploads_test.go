@@ -0,0 +1,90 @@
+package tools
+
+import (
+	"bytes"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	. "github.com/franela/goblin"
+)
+
+func Test_Uploads(t *testing.T) {
+	g := Goblin(t)
+
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\x0dIHDR")
+	zip := []byte("PK\x03\x04\x14\x00\x00\x00\x08\x00")
+
+	g.Describe("When finding the type of an uploaded file", func() {
+		g.It("Should use the content of the file and ignore its extension", func() {
+			mimeType, err := SniffContentType("report.pdf", bytes.NewReader(png))
+			g.Assert(err == nil).IsTrue()
+			g.Assert(mimeType).Equal("image/png")
+		})
+
+		g.It("Should tell apart the formats of a container from the extension", func() {
+			mimeType, _ := SniffContentType("essay.docx", bytes.NewReader(zip))
+			g.Assert(mimeType).Equal("application/vnd.openxmlformats-officedocument.wordprocessingml.document")
+
+			mimeType, _ = SniffContentType("code.zip", bytes.NewReader(zip))
+			g.Assert(mimeType).Equal("application/zip")
+		})
+
+		g.It("Should not take an active or image type from the extension", func() {
+			mimeType, _ := SniffContentType("page.html", strings.NewReader("just some text"))
+			g.Assert(mimeType).Equal("text/plain; charset=utf-8")
+
+			mimeType, _ = SniffContentType("avatar.png", strings.NewReader("just some text"))
+			g.Assert(mimeType).Equal("text/plain; charset=utf-8")
+		})
+
+		g.It("Should store the content a browser would run as plain text", func() {
+			mimeType, _ := SniffContentType("essay.html", strings.NewReader("<html><script>alert(1)</script></html>"))
+			g.Assert(mimeType).Equal("text/plain; charset=utf-8")
+
+			mimeType, _ = SniffContentType("avatar.svg", strings.NewReader(`<?xml version="1.0"?><svg xmlns="http://www.w3.org/2000/svg"></svg>`))
+			g.Assert(mimeType).Equal("text/plain; charset=utf-8")
+		})
+
+		g.It("Should rewind the file after reading it", func() {
+			content := bytes.NewReader(png)
+			SniffContentType("avatar.png", content)
+
+			data, _ := ioutil.ReadAll(content)
+			g.Assert(bytes.Equal(data, png)).IsTrue()
+		})
+	})
+
+	g.Describe("When checking the extension of an uploaded file", func() {
+		g.It("Should normalise a list of extensions", func() {
+			g.Assert(SplitExtensions(" .ZIP, pdf,,")).Equal([]string{ ".zip", ".pdf" })
+			g.Assert(SplitExtensions("")).Equal([]string{})
+		})
+
+		g.It("Should only allow the listed extensions", func() {
+			g.Assert(HasExtension("Essay.PDF", []string{ ".zip", ".pdf" })).IsTrue()
+			g.Assert(HasExtension("essay.exe", []string{ ".zip", ".pdf" })).IsFalse()
+			g.Assert(HasExtension("essay", []string{ ".zip" })).IsFalse()
+		})
+
+		g.It("Should allow any extension when none are listed", func() {
+			g.Assert(HasExtension("essay.exe", []string{})).IsTrue()
+		})
+	})
+
+	g.Describe("When reading the upload limits", func() {
+		g.It("Should use the defaults for the limits left empty", func() {
+			limits := Uploads{ MaxSize: 1024 }.WithDefaults()
+			g.Assert(limits.MaxSize).Equal(int64(1024))
+			g.Assert(limits.MaxFiles).Equal(DEFAULT_MAX_UPLOAD_FILES)
+			g.Assert(limits.AvatarSize).Equal(int64(DEFAULT_AVATAR_SIZE))
+			g.Assert(limits.AvatarTypes).Equal(DEFAULT_AVATAR_TYPES)
+		})
+
+		g.It("Should format sizes for the messages", func() {
+			g.Assert(FormatSize(512)).Equal("512 bytes")
+			g.Assert(FormatSize(2 << 20)).Equal("2.0 MB")
+			g.Assert(FormatSize(1536)).Equal("1.5 KB")
+		})
+	})
+}